@@ -0,0 +1,29 @@
+// Command content-sanitize sanitizes the content of the translations saved
+// before the content pipeline existed and derives their excerpts. Run it once
+// after applying the excerpt migration; running it again is harmless.
+package main
+
+import (
+	"context"
+	"log"
+
+	newsService "github.com/JubaerHossain/cn-api/domain/news/service"
+	"github.com/JubaerHossain/cn-api/pkg/config"
+	"github.com/JubaerHossain/rootx/pkg/core/app"
+)
+
+func main() {
+	application, err := app.StartApp()
+	if err != nil {
+		log.Fatalf("❌ Failed to start application: %v", err)
+	}
+	if _, err := config.LoadConfig(); err != nil {
+		log.Fatalf("❌ Failed to load config: %v", err)
+	}
+
+	changed, err := newsService.NewService(application).SanitizeContent(context.Background())
+	if err != nil {
+		log.Fatalf("❌ Content sanitization stopped after %d translations: %v", changed, err)
+	}
+	log.Printf("✅ Sanitized the content of %d translations", changed)
+}
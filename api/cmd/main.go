@@ -14,7 +14,12 @@ import (
 	"time"
 
 	_ "github.com/JubaerHossain/cn-api/docs"
+	engagementService "github.com/JubaerHossain/cn-api/domain/engagement/service"
+	newsService "github.com/JubaerHossain/cn-api/domain/news/service"
+	newsletterService "github.com/JubaerHossain/cn-api/domain/newsletters/service"
 	"github.com/JubaerHossain/cn-api/pkg/api"
+	"github.com/JubaerHossain/cn-api/pkg/config"
+	"github.com/JubaerHossain/cn-api/pkg/scheduler"
 	"github.com/JubaerHossain/rootx/pkg/core/app"
 	"github.com/JubaerHossain/rootx/pkg/core/health"
 	"github.com/JubaerHossain/rootx/pkg/core/middleware"
@@ -44,8 +49,45 @@ func main() {
 		log.Fatalf("❌ Failed to start application: %v", err)
 	}
 
+	// Load the news API settings
+	if _, err := config.LoadConfig(); err != nil {
+		log.Fatalf("❌ Failed to load config: %v", err)
+	}
+
+	news := newsService.NewService(application)
+
+	// Start the publishing scheduler, one replica at a time runs it
+	publishScheduler := scheduler.New(
+		application.MDB,
+		"news_publishing",
+		time.Duration(config.GlobalConfig.SchedulerInterval)*time.Second,
+		news.RunSchedule,
+		application.Logger,
+	)
+	publishScheduler.Start()
+
+	// Start writing buffered article and category views to the database
+	news.StartViewFlusher()
+
+	// Start writing buffered poll votes and reactions to the database
+	engagement := engagementService.NewService(application)
+	engagement.StartTallyFlusher()
+
+	// Start the newsletter scheduler, one replica at a time sends the digests
+	newsletters := newsletterService.NewService(application)
+	newsletterScheduler := scheduler.New(
+		application.MDB,
+		"newsletter_digests",
+		time.Duration(config.GlobalConfig.NewsletterInterval)*time.Second,
+		newsletters.RunDigests,
+		application.Logger,
+	)
+	newsletterScheduler.Start()
+
 	// Initialize HTTP server
 	httpServer := initHTTPServer(application)
+	// Shutdown waits for open requests, event streams are closed as soon as it starts
+	httpServer.RegisterOnShutdown(news.StopLiveEvents)
 
 	go func() {
 		if err := startHTTPServer(application, httpServer); err != nil {
@@ -66,7 +108,7 @@ func main() {
 	}
 
 	// Graceful shutdown
-	gracefulShutdown(httpServer, 5*time.Second)
+	gracefulShutdown(httpServer, 5*time.Second, publishScheduler.Stop, newsletterScheduler.Stop, news.StopViewFlusher, engagement.StopTallyFlusher)
 }
 
 func initHTTPServer(application *app.App) *http.Server {
@@ -157,7 +199,12 @@ func setupRoutes(application *app.App) http.Handler {
 		utils.WriteJSONResponse(w, http.StatusOK, map[string]interface{}{"message": "Welcome to the API"})
 	}))))
 
-	return middleware.PrometheusMiddleware(mux, monitor.RequestsTotal(), monitor.RequestDuration())
+	// Event streams stay open and flush every event, they skip the request metrics
+	root := http.NewServeMux()
+	root.Handle("/api/public/v1/stream/", http.StripPrefix("/api/public/v1", api.StreamRouter(application)))
+	root.Handle("/", middleware.PrometheusMiddleware(mux, monitor.RequestsTotal(), monitor.RequestDuration()))
+
+	return root
 }
 
 func openBrowser(url string) {
@@ -181,7 +228,8 @@ func openBrowser(url string) {
 	}
 }
 
-func gracefulShutdown(server *http.Server, timeout time.Duration) {
+// gracefulShutdown stops the server and the background workers within timeout
+func gracefulShutdown(server *http.Server, timeout time.Duration, workers ...func(context.Context) error) {
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
@@ -194,5 +242,11 @@ func gracefulShutdown(server *http.Server, timeout time.Duration) {
 		log.Fatalf("❌ Could not gracefully shutdown the server: %v", err)
 	}
 
+	for _, stop := range workers {
+		if err := stop(ctx); err != nil {
+			log.Printf("❌ Could not gracefully stop worker: %v", err)
+		}
+	}
+
 	log.Printf("✅ Server gracefully stopped")
 }
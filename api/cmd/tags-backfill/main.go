@@ -0,0 +1,29 @@
+// Command tags-backfill converts the JSON tag arrays stored on news translations
+// into tag rows. Run it once after applying the tags migration; running it again
+// is harmless.
+package main
+
+import (
+	"context"
+	"log"
+
+	newsService "github.com/JubaerHossain/cn-api/domain/news/service"
+	"github.com/JubaerHossain/cn-api/pkg/config"
+	"github.com/JubaerHossain/rootx/pkg/core/app"
+)
+
+func main() {
+	application, err := app.StartApp()
+	if err != nil {
+		log.Fatalf("❌ Failed to start application: %v", err)
+	}
+	if _, err := config.LoadConfig(); err != nil {
+		log.Fatalf("❌ Failed to load config: %v", err)
+	}
+
+	converted, err := newsService.NewService(application).BackfillTags(context.Background())
+	if err != nil {
+		log.Fatalf("❌ Tag backfill stopped after %d translations: %v", converted, err)
+	}
+	log.Printf("✅ Converted the tags of %d translations", converted)
+}
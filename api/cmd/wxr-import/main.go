@@ -0,0 +1,122 @@
+// Command wxr-import imports a WordPress export (WXR) into the news domain:
+// authors become users, categories keep their hierarchy and posts become
+// articles with their tags and featured image renditions. Attachments and the
+// images of the content are stored and the content links to the stored copies.
+//
+//	go run ./cmd/wxr-import -dry-run export.xml
+//	go run ./cmd/wxr-import -report report.json export.xml
+//
+// Every imported item is recorded by its source GUID. Running the command
+// again skips what was imported, so an interrupted import resumes.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"sort"
+	"syscall"
+
+	"github.com/JubaerHossain/cn-api/domain/news/entity"
+	newsService "github.com/JubaerHossain/cn-api/domain/news/service"
+	"github.com/JubaerHossain/cn-api/pkg/config"
+	"github.com/JubaerHossain/cn-api/pkg/wxr"
+	"github.com/JubaerHossain/rootx/pkg/core/app"
+)
+
+func main() {
+	var options entity.ImportOptions
+	reportFile := flag.String("report", "", "Write the full report as JSON to this file")
+	flag.BoolVar(&options.DryRun, "dry-run", false, "Report what would be imported without writing anything")
+	flag.StringVar(&options.Locale, "locale", "", "Locale of the imported articles, the export language when empty")
+	flag.StringVar(&options.Type, "type", "news", "Type of the imported articles")
+	flag.UintVar(&options.RoleID, "role", 3, "Role of the imported authors")
+	flag.UintVar(&options.AuthorID, "author", 0, "User ID credited with posts whose author is not imported, 0 to skip them")
+	flag.BoolVar(&options.Images, "images", true, "Download attachments and images and link the content to the stored copies")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] export.xml\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	file, err := os.Open(flag.Arg(0))
+	if err != nil {
+		log.Fatalf("❌ Failed to open export: %v", err)
+	}
+	export, err := wxr.Parse(file)
+	file.Close()
+	if err != nil {
+		log.Fatalf("❌ Failed to read export: %v", err)
+	}
+	log.Printf("Read %q: %d authors, %d categories, %d posts, %d attachments",
+		export.Title, len(export.Authors), len(export.Categories), len(export.Posts), len(export.Attachments))
+
+	application, err := app.StartApp()
+	if err != nil {
+		log.Fatalf("❌ Failed to start application: %v", err)
+	}
+	if _, err := config.LoadConfig(); err != nil {
+		log.Fatalf("❌ Failed to load config: %v", err)
+	}
+
+	// An interrupted import stops between items, the next run resumes
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	report, importErr := newsService.NewService(application).ImportWXR(ctx, export, options)
+	if report != nil {
+		printReport(report)
+		if *reportFile != "" {
+			if err := writeReport(*reportFile, report); err != nil {
+				log.Printf("❌ Failed to write report: %v", err)
+			}
+		}
+	}
+	if importErr != nil {
+		log.Fatalf("❌ Import stopped: %v", importErr)
+	}
+	if report.DryRun {
+		log.Printf("✅ Dry run finished, nothing was written")
+		return
+	}
+	log.Printf("✅ Import finished")
+}
+
+// printReport logs the counts of the report and every failed and skipped item
+func printReport(report *entity.ImportReport) {
+	kinds := make([]string, 0, len(report.Created))
+	for kind := range report.Created {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	verb := "Created"
+	if report.DryRun {
+		verb = "Would create"
+	}
+	for _, kind := range kinds {
+		log.Printf("%s %d %s(s)", verb, report.Created[kind], kind)
+	}
+	log.Printf("Skipped %d item(s), failed %d item(s)", len(report.Skipped), len(report.Failed))
+	for _, issue := range report.Skipped {
+		log.Printf("  skipped %s %s %q: %s", issue.Kind, issue.Source, issue.Title, issue.Reason)
+	}
+	for _, issue := range report.Failed {
+		log.Printf("  ❌ failed %s %s %q: %s", issue.Kind, issue.Source, issue.Title, issue.Reason)
+	}
+}
+
+// writeReport saves the report as indented JSON
+func writeReport(name string, report *entity.ImportReport) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(name, data, 0o644)
+}
@@ -0,0 +1,106 @@
+package entity
+
+import (
+	"errors"
+
+	"github.com/JubaerHossain/rootx/pkg/core/entity"
+)
+
+var (
+	// ErrCommentNotFound is returned when a comment does not exist
+	ErrCommentNotFound = errors.New("comment not found")
+	// ErrNewsNotFound is returned when the article does not exist or is not published
+	ErrNewsNotFound = errors.New("news not found")
+	// ErrInvalidComment is returned for empty or too long bodies, replies to hidden comments and no-op moderation
+	ErrInvalidComment = errors.New("invalid comment")
+	// ErrBannedWords is returned when a comment contains a word of COMMENT_BANNED_WORDS
+	ErrBannedWords = errors.New("comment contains words that are not allowed")
+	// ErrRateLimited is returned when the user or the IP address posted too many comments
+	ErrRateLimited = errors.New("too many comments, try again later")
+)
+
+// Moderation statuses. Readers only see approved comments.
+const (
+	StatusPending  = "pending"  // Waiting in the moderation queue
+	StatusApproved = "approved" // Shown under the article
+	StatusRejected = "rejected" // Hidden by a moderator
+	StatusSpam     = "spam"     // Hidden as spam
+)
+
+// Comment represents a reader comment as moderators see it
+type Comment struct {
+	ID          uint          `json:"id"`
+	NewsID      uint          `json:"news_id"`
+	NewsTitle   string        `json:"news_title"` // Title in the default locale
+	ParentID    uint          `json:"parent_id"`  // Comment replied to, 0 for none
+	RootID      uint          `json:"root_id"`    // First comment of the thread, 0 for a first comment
+	UserID      uint          `json:"user_id"`
+	Author      string        `json:"author"`
+	Body        string        `json:"body"`
+	Status      string        `json:"status"`
+	IPAddress   string        `json:"ip_address"`
+	Moderations []*Moderation `json:"moderations,omitempty"` // Moderation history, oldest first
+	CreatedAt   string        `json:"created_at"`
+	UpdatedAt   string        `json:"updated_at"`
+}
+
+// CreateComment represents the request to comment on an article or reply to a comment
+type CreateComment struct {
+	Body      string `json:"body" validate:"required"`
+	ParentID  uint   `json:"parent_id"` // Approved comment of the same article to reply to
+	NewsID    uint   `json:"-"`
+	UserID    uint   `json:"-"`
+	IPAddress string `json:"-"`
+}
+
+// ModerateComment represents a moderation action
+type ModerateComment struct {
+	Status      string `json:"status" validate:"required,oneof=pending approved rejected spam"`
+	Reason      string `json:"reason" validate:"max=255"`
+	ModeratorID uint   `json:"-"`
+}
+
+// Moderation represents a recorded moderation action
+type Moderation struct {
+	ID          uint   `json:"id"`
+	ModeratorID uint   `json:"moderator_id"`
+	Moderator   string `json:"moderator"`
+	FromStatus  string `json:"from_status"`
+	ToStatus    string `json:"to_status"`
+	Reason      string `json:"reason"`
+	CreatedAt   string `json:"created_at"`
+}
+
+// ThreadComment represents an approved comment with its approved replies
+type ThreadComment struct {
+	ID        uint             `json:"id"`
+	ParentID  uint             `json:"parent_id"`
+	Author    string           `json:"author"`
+	Body      string           `json:"body"`
+	CreatedAt string           `json:"created_at"`
+	Replies   []*ThreadComment `json:"replies"` // Oldest first
+}
+
+// ThreadResponsePagination represents a page of the threads of an article, newest first
+type ThreadResponsePagination struct {
+	Data       []*ThreadComment  `json:"data"`
+	Pagination entity.Pagination `json:"pagination"`
+}
+
+// ResponseComment represents the moderation queue response
+type ResponseComment struct {
+	ID        uint   `json:"id"`
+	NewsID    uint   `json:"news_id"`
+	NewsTitle string `json:"news_title"`
+	ParentID  uint   `json:"parent_id"`
+	Author    string `json:"author"`
+	Body      string `json:"body"`
+	Status    string `json:"status"`
+	IPAddress string `json:"ip_address"`
+	CreatedAt string `json:"created_at"`
+}
+
+type CommentResponsePagination struct {
+	Data       []*ResponseComment `json:"data"`
+	Pagination entity.Pagination  `json:"pagination"`
+}
@@ -0,0 +1,424 @@
+package persistence
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/JubaerHossain/cn-api/domain/comments/entity"
+	"github.com/JubaerHossain/cn-api/domain/comments/repository"
+	newsEntity "github.com/JubaerHossain/cn-api/domain/news/entity"
+	newsPersistence "github.com/JubaerHossain/cn-api/domain/news/infrastructure/persistence"
+	"github.com/JubaerHossain/cn-api/pkg/locale"
+	utilQuery "github.com/JubaerHossain/cn-api/pkg/utils"
+	"github.com/JubaerHossain/rootx/pkg/core/app"
+	"github.com/JubaerHossain/rootx/pkg/core/cache"
+)
+
+// commentQuery selects a comment as moderators see it. The article title is
+// bound to the first placeholder, the locale.
+const commentQuery = `
+	SELECT comments.id, comments.news_id, COALESCE(news_translations.title, ''), COALESCE(comments.parent_id, 0),
+	       COALESCE(comments.root_id, 0), comments.user_id, COALESCE(users.name, ''), comments.body, comments.status,
+	       comments.ip_address, comments.created_at, comments.updated_at
+	FROM comments
+	LEFT JOIN users ON users.id = comments.user_id
+	LEFT JOIN news_translations ON news_translations.news_id = comments.news_id AND news_translations.locale = ?`
+
+// countApproved stores the number of approved comments of an article on the article
+const countApproved = `
+	UPDATE news
+	SET comment_count = (SELECT COUNT(*) FROM comments WHERE comments.news_id = news.id AND comments.status = ?)
+	WHERE id = ?`
+
+type CommentRepositoryImpl struct {
+	app *app.App
+}
+
+// NewCommentRepository returns a new instance of CommentRepositoryImpl
+func NewCommentRepository(app *app.App) repository.CommentRepository {
+	return &CommentRepositoryImpl{
+		app: app,
+	}
+}
+
+// CacheClear drops the cached threads of an article
+func CacheClear(req *http.Request, cache cache.CacheService, newsID uint) error {
+	_, err := cache.ClearPattern(req.Context(), fmt.Sprintf("comments_%d_*", newsID))
+	return err
+}
+
+// GetComments returns a page of the moderation queue. Pending comments are
+// listed oldest first, the other statuses newest first.
+func (r *CommentRepositoryImpl) GetComments(req *http.Request) (*entity.CommentResponsePagination, error) {
+	ctx := req.Context()
+	query := req.URL.Query()
+
+	status := query.Get("status")
+	switch status {
+	case "":
+		status = entity.StatusPending
+	case entity.StatusPending, entity.StatusApproved, entity.StatusRejected, entity.StatusSpam:
+	default:
+		return nil, fmt.Errorf("%w: unknown status %q", entity.ErrInvalidComment, status)
+	}
+
+	filterQuery := " WHERE comments.status = ?"
+	args := []interface{}{locale.Default(), status}
+	if newsID := query.Get("news_id"); newsID != "" {
+		id, err := strconv.ParseUint(newsID, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid news ID", entity.ErrInvalidComment)
+		}
+		filterQuery += " AND comments.news_id = ?"
+		args = append(args, id)
+	}
+	if search := strings.TrimSpace(query.Get("search")); search != "" {
+		filterQuery += " AND comments.body LIKE ?"
+		args = append(args, "%"+search+"%")
+	}
+
+	// Pagination and limits
+	pagination, limit, offset, err := utilQuery.Paginate(req, r.app, commentQuery, filterQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("pagination error: %w", err)
+	}
+	order := "DESC"
+	if status == entity.StatusPending {
+		order = "ASC"
+	}
+	rows, err := r.app.MDB.QueryContext(ctx, fmt.Sprintf("%s%s ORDER BY comments.id %s LIMIT %d OFFSET %d", commentQuery, filterQuery, order, limit, offset), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query comments: %w", err)
+	}
+	defer rows.Close()
+
+	comments := []*entity.ResponseComment{}
+	for rows.Next() {
+		comment, err := scanComment(rows)
+		if err != nil {
+			return nil, err
+		}
+		comments = append(comments, &entity.ResponseComment{
+			ID:        comment.ID,
+			NewsID:    comment.NewsID,
+			NewsTitle: comment.NewsTitle,
+			ParentID:  comment.ParentID,
+			Author:    comment.Author,
+			Body:      comment.Body,
+			Status:    comment.Status,
+			IPAddress: comment.IPAddress,
+			CreatedAt: comment.CreatedAt,
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return &entity.CommentResponsePagination{
+		Data:       comments,
+		Pagination: pagination,
+	}, nil
+}
+
+// GetCommentByID returns the comment by ID from the database
+func (r *CommentRepositoryImpl) GetCommentByID(commentID uint) (*entity.Comment, error) {
+	comment, err := scanComment(r.app.MDB.QueryRow(commentQuery+" WHERE comments.id = ?", locale.Default(), commentID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, entity.ErrCommentNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return comment, nil
+}
+
+// GetComment returns a comment with its moderation history
+func (r *CommentRepositoryImpl) GetComment(req *http.Request, commentID uint) (*entity.Comment, error) {
+	comment, err := r.GetCommentByID(commentID)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.app.MDB.QueryContext(req.Context(), `
+		SELECT comment_moderations.id, comment_moderations.moderator_id, COALESCE(users.name, ''),
+		       comment_moderations.from_status, comment_moderations.to_status, comment_moderations.reason,
+		       comment_moderations.created_at
+		FROM comment_moderations
+		LEFT JOIN users ON users.id = comment_moderations.moderator_id
+		WHERE comment_moderations.comment_id = ?
+		ORDER BY comment_moderations.id ASC`, comment.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query comment moderations: %w", err)
+	}
+	defer rows.Close()
+
+	comment.Moderations = []*entity.Moderation{}
+	for rows.Next() {
+		var (
+			moderation entity.Moderation
+			createdAt  sql.NullString
+		)
+		if err := rows.Scan(&moderation.ID, &moderation.ModeratorID, &moderation.Moderator, &moderation.FromStatus,
+			&moderation.ToStatus, &moderation.Reason, &createdAt); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		moderation.CreatedAt = createdAt.String
+		comment.Moderations = append(comment.Moderations, &moderation)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return comment, nil
+}
+
+// GetNewsComments returns a page of the approved threads of a published
+// article, newest first, each with its approved replies. A reply to a comment
+// that is not approved is hidden with it.
+func (r *CommentRepositoryImpl) GetNewsComments(req *http.Request, newsID uint) (*entity.ThreadResponsePagination, error) {
+	ctx := req.Context()
+	cacheKey := fmt.Sprintf("comments_%d_%s", newsID, req.URL.Query().Encode()) // Encode query parameters
+
+	// Check cache first
+	if cachedData, errCache := r.app.Cache.Get(ctx, cacheKey); errCache == nil && cachedData != "" {
+		response := &entity.ThreadResponsePagination{}
+		if err := json.Unmarshal([]byte(cachedData), response); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal cached data: %w", err)
+		}
+		return response, nil
+	}
+
+	if err := r.checkNews(req, newsID); err != nil {
+		return nil, err
+	}
+
+	// First comments of the threads
+	baseQuery := `
+		SELECT comments.id, COALESCE(users.name, ''), comments.body, comments.created_at
+		FROM comments
+		LEFT JOIN users ON users.id = comments.user_id`
+	filterQuery := " WHERE comments.news_id = ? AND comments.status = ? AND comments.parent_id IS NULL"
+	args := []interface{}{newsID, entity.StatusApproved}
+
+	pagination, limit, offset, err := utilQuery.Paginate(req, r.app, baseQuery, filterQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("pagination error: %w", err)
+	}
+	rows, err := r.app.MDB.QueryContext(ctx, fmt.Sprintf("%s%s ORDER BY comments.id DESC LIMIT %d OFFSET %d", baseQuery, filterQuery, limit, offset), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query comments: %w", err)
+	}
+	defer rows.Close()
+
+	threads := []*entity.ThreadComment{}
+	shown := map[uint]*entity.ThreadComment{}
+	for rows.Next() {
+		thread := &entity.ThreadComment{Replies: []*entity.ThreadComment{}}
+		var createdAt sql.NullString
+		if err := rows.Scan(&thread.ID, &thread.Author, &thread.Body, &createdAt); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		thread.CreatedAt = createdAt.String
+		threads = append(threads, thread)
+		shown[thread.ID] = thread
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	rows.Close()
+
+	// Replies of the listed threads, a reply always comes after the comment it answers
+	if len(threads) > 0 {
+		placeholders := strings.TrimSuffix(strings.Repeat("?,", len(threads)), ",")
+		replyArgs := []interface{}{entity.StatusApproved}
+		for _, thread := range threads {
+			replyArgs = append(replyArgs, thread.ID)
+		}
+		replies, err := r.app.MDB.QueryContext(ctx, fmt.Sprintf(`
+			SELECT comments.id, comments.parent_id, COALESCE(users.name, ''), comments.body, comments.created_at
+			FROM comments
+			LEFT JOIN users ON users.id = comments.user_id
+			WHERE comments.status = ? AND comments.root_id IN (%s)
+			ORDER BY comments.id ASC`, placeholders), replyArgs...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query replies: %w", err)
+		}
+		defer replies.Close()
+
+		for replies.Next() {
+			reply := &entity.ThreadComment{Replies: []*entity.ThreadComment{}}
+			var createdAt sql.NullString
+			if err := replies.Scan(&reply.ID, &reply.ParentID, &reply.Author, &reply.Body, &createdAt); err != nil {
+				return nil, fmt.Errorf("failed to scan row: %w", err)
+			}
+			parent, ok := shown[reply.ParentID]
+			if !ok {
+				continue
+			}
+			reply.CreatedAt = createdAt.String
+			parent.Replies = append(parent.Replies, reply)
+			shown[reply.ID] = reply
+		}
+		if err := replies.Err(); err != nil {
+			return nil, fmt.Errorf("rows iteration error: %w", err)
+		}
+	}
+
+	response := &entity.ThreadResponsePagination{
+		Data:       threads,
+		Pagination: pagination,
+	}
+
+	// Cache the response
+	jsonData, err := json.Marshal(response)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal response: %w", err)
+	}
+	if err := r.app.Cache.Set(ctx, cacheKey, string(jsonData), time.Duration(r.app.Config.RedisExp)*time.Second); err != nil {
+		return nil, fmt.Errorf("failed to set cache: %w", err)
+	}
+
+	return response, nil
+}
+
+// CreateComment stores a comment as pending. A reply must answer an approved
+// comment of the same article.
+func (r *CommentRepositoryImpl) CreateComment(comment *entity.CreateComment, req *http.Request) (*entity.Comment, error) {
+	ctx := req.Context()
+	if err := r.checkNews(req, comment.NewsID); err != nil {
+		return nil, err
+	}
+
+	var rootID uint
+	if comment.ParentID > 0 {
+		var (
+			newsID, parentRootID uint
+			status               string
+		)
+		err := r.app.MDB.QueryRowContext(ctx, "SELECT news_id, COALESCE(root_id, 0), status FROM comments WHERE id = ?", comment.ParentID).
+			Scan(&newsID, &parentRootID, &status)
+		if errors.Is(err, sql.ErrNoRows) || (err == nil && (newsID != comment.NewsID || status != entity.StatusApproved)) {
+			return nil, fmt.Errorf("%w: parent_id is not an approved comment of the article", entity.ErrInvalidComment)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get parent comment: %w", err)
+		}
+		rootID = parentRootID
+		if rootID == 0 {
+			rootID = comment.ParentID
+		}
+	}
+
+	result, err := r.app.MDB.ExecContext(ctx, `
+		INSERT INTO comments (news_id, parent_id, root_id, user_id, body, status, ip_address, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)`,
+		comment.NewsID, nullableID(comment.ParentID), nullableID(rootID), comment.UserID, comment.Body,
+		entity.StatusPending, comment.IPAddress,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create comment: %w", err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+	return r.GetCommentByID(uint(id))
+}
+
+// ModerateComment changes the status of a comment and records the action. The
+// comment count of the article is recounted when approval changes.
+func (r *CommentRepositoryImpl) ModerateComment(comment *entity.Comment, moderate *entity.ModerateComment, req *http.Request) error {
+	ctx := req.Context()
+	tx, err := r.app.MDB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Lock the comment so concurrent actions record the status they changed
+	var status string
+	err = tx.QueryRowContext(ctx, "SELECT status FROM comments WHERE id = ? FOR UPDATE", comment.ID).Scan(&status)
+	if errors.Is(err, sql.ErrNoRows) {
+		return entity.ErrCommentNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to lock comment: %w", err)
+	}
+	if status == moderate.Status {
+		return fmt.Errorf("%w: comment is already %s", entity.ErrInvalidComment, status)
+	}
+
+	if _, err := tx.ExecContext(ctx, "UPDATE comments SET status = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?", moderate.Status, comment.ID); err != nil {
+		return fmt.Errorf("failed to update comment: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO comment_moderations (comment_id, moderator_id, from_status, to_status, reason, created_at)
+		VALUES (?, ?, ?, ?, ?, CURRENT_TIMESTAMP)`,
+		comment.ID, moderate.ModeratorID, status, moderate.Status, moderate.Reason,
+	); err != nil {
+		return fmt.Errorf("failed to record moderation: %w", err)
+	}
+	recount := status == entity.StatusApproved || moderate.Status == entity.StatusApproved
+	if recount {
+		if _, err := tx.ExecContext(ctx, countApproved, entity.StatusApproved, comment.NewsID); err != nil {
+			return fmt.Errorf("failed to count comments: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	comment.Status = moderate.Status
+
+	// Clear cache, the article lists show the comment count
+	if err := CacheClear(req, r.app.Cache, comment.NewsID); err != nil {
+		return err
+	}
+	if recount {
+		return newsPersistence.CacheClear(req, r.app.Cache)
+	}
+	return nil
+}
+
+// checkNews returns ErrNewsNotFound unless the article is published
+func (r *CommentRepositoryImpl) checkNews(req *http.Request, newsID uint) error {
+	var id uint
+	err := r.app.MDB.QueryRowContext(req.Context(), "SELECT news.id FROM news WHERE news.id = ? AND "+newsEntity.PublishedFilter, newsID).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return entity.ErrNewsNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get news: %w", err)
+	}
+	return nil
+}
+
+// scanComment scans a row of commentQuery
+func scanComment(row interface{ Scan(...interface{}) error }) (*entity.Comment, error) {
+	var (
+		comment              entity.Comment
+		createdAt, updatedAt sql.NullString
+	)
+	if err := row.Scan(&comment.ID, &comment.NewsID, &comment.NewsTitle, &comment.ParentID, &comment.RootID, &comment.UserID,
+		&comment.Author, &comment.Body, &comment.Status, &comment.IPAddress, &createdAt, &updatedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to scan row: %w", err)
+	}
+	comment.CreatedAt = createdAt.String
+	comment.UpdatedAt = updatedAt.String
+	return &comment, nil
+}
+
+// nullableID returns nil for a zero ID so it is stored as NULL
+func nullableID(id uint) interface{} {
+	if id == 0 {
+		return nil
+	}
+	return id
+}
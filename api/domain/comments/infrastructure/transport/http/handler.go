@@ -0,0 +1,186 @@
+package commentHttp
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/JubaerHossain/cn-api/domain/comments/entity"
+	"github.com/JubaerHossain/cn-api/domain/comments/service"
+	"github.com/JubaerHossain/rootx/pkg/core/app"
+	utilQuery "github.com/JubaerHossain/rootx/pkg/query"
+	"github.com/JubaerHossain/rootx/pkg/utils"
+)
+
+// Handler handles API requests
+type Handler struct {
+	App *service.Service
+}
+
+// NewHandler creates a new instance of Handler
+func NewHandler(app *app.App) *Handler {
+	return &Handler{
+		App: service.NewService(app),
+	}
+}
+
+// @Summary Get the comments of an article
+// @Description Get the approved threads of a published article, newest first, each with its approved replies
+// @Tags comments
+// @Accept json
+// @Produce json
+// @Param id path string true "The ID of the article"
+// @Param page query int false "Page number"
+// @Param limit query int false "Number of threads per page"
+// @Success 200 {object} entity.ThreadResponsePagination
+// @Failure 404 {object} map[string]interface{}
+// @Router /news/{id}/comments [get]
+func (h *Handler) GetNewsComments(w http.ResponseWriter, r *http.Request) {
+	comments, err := h.App.GetNewsComments(r)
+	if err != nil {
+		h.writeCommentError(w, err)
+		return
+	}
+	// Write response
+	utils.JsonResponse(w, http.StatusOK, map[string]interface{}{
+		"results": comments,
+	})
+}
+
+// @Summary Comment on an article
+// @Description Post a comment, or a reply to an approved comment, as the authenticated reader. The body is stored as plain text and waits for moderation.
+// @Tags comments
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "The ID of the article"
+// @Param comment body entity.CreateComment true "The comment to post"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 429 {object} map[string]interface{}
+// @Router /news/{id}/comments [post]
+func (h *Handler) CreateComment(w http.ResponseWriter, r *http.Request) {
+	var newComment entity.CreateComment
+
+	pareErr := utilQuery.BodyParse(&newComment, w, r, true) // Parse request body and validate it
+	if pareErr != nil {
+		return
+	}
+
+	comment, err := h.App.CreateComment(r, &newComment)
+	if err != nil {
+		h.writeCommentError(w, err)
+		return
+	}
+
+	// Write response
+	utils.WriteJSONResponse(w, http.StatusCreated, map[string]interface{}{
+		"message": "Comment submitted for moderation",
+		"results": map[string]interface{}{
+			"id":        comment.ID,
+			"parent_id": comment.ParentID,
+			"body":      comment.Body,
+			"status":    comment.Status,
+		},
+	})
+}
+
+// @Summary Get the moderation queue
+// @Description Get the comments of a status, pending by default. Pending comments are listed oldest first, the other statuses newest first.
+// @Tags comments
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param status query string false "pending, approved, rejected or spam"
+// @Param news_id query int false "Only the comments of this article"
+// @Param search query string false "Search the comment bodies"
+// @Param page query int false "Page number"
+// @Param limit query int false "Number of items per page"
+// @Success 200 {object} entity.CommentResponsePagination
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Router /comments [get]
+func (h *Handler) GetComments(w http.ResponseWriter, r *http.Request) {
+	comments, err := h.App.GetComments(r)
+	if err != nil {
+		h.writeCommentError(w, err)
+		return
+	}
+	// Write response
+	utils.JsonResponse(w, http.StatusOK, map[string]interface{}{
+		"results": comments,
+	})
+}
+
+// @Summary Get detailed information about a Comment by ID
+// @Description Get a Comment with its moderation history
+// @Tags comments
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} entity.Comment
+// @Failure 404 {object} map[string]interface{}
+// @Param id path string true "The ID of the Comment"
+// @Failure 403 {object} map[string]interface{}
+// @Router /comments/{id} [get]
+func (h *Handler) GetCommentDetails(w http.ResponseWriter, r *http.Request) {
+	comment, err := h.App.GetCommentDetails(r)
+	if err != nil {
+		h.writeCommentError(w, err)
+		return
+	}
+	// Write response
+	utils.WriteJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"message": "Comment fetched successfully",
+		"results": comment,
+	})
+}
+
+// @Summary Moderate a Comment
+// @Description Approve, reject, mark as spam or send back to the queue. The action is recorded with the moderator.
+// @Tags comments
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Param id path string true "The ID of the Comment"
+// @Param moderation body entity.ModerateComment true "The new status and the reason"
+// @Failure 403 {object} map[string]interface{}
+// @Router /comments/{id}/moderation [post]
+func (h *Handler) ModerateComment(w http.ResponseWriter, r *http.Request) {
+	var moderate entity.ModerateComment
+	pareErr := utilQuery.BodyParse(&moderate, w, r, true) // Parse request body and validate it
+	if pareErr != nil {
+		return
+	}
+
+	comment, err := h.App.ModerateComment(r, &moderate)
+	if err != nil {
+		h.writeCommentError(w, err)
+		return
+	}
+
+	// Write response
+	utils.WriteJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"message": "Comment moderated successfully",
+		"results": comment,
+	})
+}
+
+// writeCommentError maps comment errors to HTTP statuses
+func (h *Handler) writeCommentError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, entity.ErrInvalidComment), errors.Is(err, entity.ErrBannedWords):
+		utils.WriteJSONError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, entity.ErrCommentNotFound), errors.Is(err, entity.ErrNewsNotFound):
+		utils.WriteJSONError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, entity.ErrRateLimited):
+		w.Header().Set("Retry-After", strconv.Itoa(int(h.App.RetryAfter().Seconds())))
+		utils.WriteJSONError(w, http.StatusTooManyRequests, err.Error())
+	default:
+		utils.WriteJSONError(w, http.StatusInternalServerError, err.Error())
+	}
+}
@@ -0,0 +1,38 @@
+package commentHttp
+
+import (
+	"net/http"
+
+	authMiddleware "github.com/JubaerHossain/cn-api/pkg/middleware"
+	"github.com/JubaerHossain/rootx/pkg/core/app"
+	"github.com/JubaerHossain/rootx/pkg/core/middleware"
+)
+
+// CommentRouter registers public routes for API endpoints. Readers comment
+// with their own token.
+func CommentRouter(router *http.ServeMux, application *app.App) http.Handler {
+
+	handler := NewHandler(application)
+
+	router.Handle("GET /news/{id}/comments", middleware.LimiterMiddleware(http.HandlerFunc(handler.GetNewsComments)))
+	router.Handle("POST /news/{id}/comments", middleware.LimiterMiddleware(authMiddleware.AuthMiddleware(application, http.HandlerFunc(handler.CreateComment))))
+
+	return router
+}
+
+// CommentAdminRouter registers comment moderation routes for editors and admins.
+// Readers sign in to comment, so a token alone does not open the queue.
+func CommentAdminRouter(router *http.ServeMux, application *app.App) http.Handler {
+
+	handler := NewHandler(application)
+	protect := func(h http.HandlerFunc) http.Handler {
+		return middleware.LimiterMiddleware(authMiddleware.AuthMiddleware(application,
+			authMiddleware.RoleMiddleware(h, authMiddleware.RoleEditor, authMiddleware.RoleAdmin)))
+	}
+
+	router.Handle("GET /comments", protect(handler.GetComments))
+	router.Handle("GET /comments/{id}", protect(handler.GetCommentDetails))
+	router.Handle("POST /comments/{id}/moderation", protect(handler.ModerateComment))
+
+	return router
+}
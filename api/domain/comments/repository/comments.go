@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"net/http"
+
+	"github.com/JubaerHossain/cn-api/domain/comments/entity"
+)
+
+// CommentRepository defines methods for comment data access
+type CommentRepository interface {
+	GetComments(r *http.Request) (*entity.CommentResponsePagination, error)
+	GetCommentByID(commentID uint) (*entity.Comment, error)
+	GetComment(r *http.Request, commentID uint) (*entity.Comment, error)
+	GetNewsComments(r *http.Request, newsID uint) (*entity.ThreadResponsePagination, error)
+	CreateComment(comment *entity.CreateComment, r *http.Request) (*entity.Comment, error)
+	ModerateComment(comment *entity.Comment, moderate *entity.ModerateComment, r *http.Request) error
+}
@@ -0,0 +1,218 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/JubaerHossain/cn-api/domain/comments/entity"
+	"github.com/JubaerHossain/cn-api/domain/comments/infrastructure/persistence"
+	"github.com/JubaerHossain/cn-api/domain/comments/repository"
+	"github.com/JubaerHossain/cn-api/pkg/config"
+	"github.com/JubaerHossain/cn-api/pkg/middleware"
+	"github.com/JubaerHossain/cn-api/pkg/ratelimit"
+	"github.com/JubaerHossain/cn-api/pkg/redisclient"
+	"github.com/JubaerHossain/cn-api/pkg/utils"
+	"github.com/JubaerHossain/rootx/pkg/core/app"
+	"go.uber.org/zap"
+)
+
+var (
+	commentLimiter     *ratelimit.Limiter
+	commentLimiterOnce sync.Once
+)
+
+type Service struct {
+	app  *app.App
+	repo repository.CommentRepository
+}
+
+func NewService(app *app.App) *Service {
+	repo := persistence.NewCommentRepository(app)
+	return &Service{
+		app:  app,
+		repo: repo,
+	}
+}
+
+// limiter returns the comment rate limiter shared by every handler, on Redis
+// when it is enabled so the limits hold across replicas
+func (s *Service) limiter() *ratelimit.Limiter {
+	commentLimiterOnce.Do(func() {
+		commentLimiter = ratelimit.New(redisclient.Get(s.app.Config), config.GlobalConfig.CommentRateLimit,
+			time.Duration(config.GlobalConfig.CommentRateWindow)*time.Second)
+	})
+	return commentLimiter
+}
+
+// GetComments returns a page of the moderation queue
+func (s *Service) GetComments(r *http.Request) (*entity.CommentResponsePagination, error) {
+	comments, err := s.repo.GetComments(r)
+	if err != nil {
+		s.logError("Error getting comments", err)
+		return nil, err
+	}
+	return comments, nil
+}
+
+func (s *Service) GetCommentByID(r *http.Request) (*entity.Comment, error) {
+	id, err := strconv.ParseUint(r.PathValue("id"), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid comment ID", entity.ErrInvalidComment)
+	}
+	comment, commentErr := s.repo.GetCommentByID(uint(id))
+	if commentErr != nil {
+		s.logError("Error getting comment", commentErr)
+		return nil, commentErr
+	}
+	return comment, nil
+}
+
+// GetCommentDetails retrieves a comment with its moderation history by ID
+func (s *Service) GetCommentDetails(r *http.Request) (*entity.Comment, error) {
+	id, err := strconv.ParseUint(r.PathValue("id"), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid comment ID", entity.ErrInvalidComment)
+	}
+	comment, commentErr := s.repo.GetComment(r, uint(id))
+	if commentErr != nil {
+		s.logError("Error getting comment", commentErr)
+		return nil, commentErr
+	}
+	return comment, nil
+}
+
+// GetNewsComments returns the approved threads of the article of the path
+func (s *Service) GetNewsComments(r *http.Request) (*entity.ThreadResponsePagination, error) {
+	newsID, err := strconv.ParseUint(r.PathValue("id"), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid news ID", entity.ErrInvalidComment)
+	}
+	comments, commentErr := s.repo.GetNewsComments(r, uint(newsID))
+	if commentErr != nil {
+		s.logError("Error getting news comments", commentErr)
+		return nil, commentErr
+	}
+	return comments, nil
+}
+
+// CreateComment posts a comment of the authenticated reader on the article of
+// the path. The body is reduced to plain text and checked against the banned
+// words, then the comment waits for moderation.
+func (s *Service) CreateComment(r *http.Request, comment *entity.CreateComment) (*entity.Comment, error) {
+	newsID, err := strconv.ParseUint(r.PathValue("id"), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid news ID", entity.ErrInvalidComment)
+	}
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		return nil, fmt.Errorf("unauthorized")
+	}
+
+	comment.Body = utils.PlainText(comment.Body)
+	if comment.Body == "" {
+		return nil, fmt.Errorf("%w: body is empty", entity.ErrInvalidComment)
+	}
+	if maxLength := config.GlobalConfig.CommentMaxLength; utf8.RuneCountInString(comment.Body) > maxLength {
+		return nil, fmt.Errorf("%w: body is longer than %d characters", entity.ErrInvalidComment, maxLength)
+	}
+	if containsBannedWord(comment.Body) {
+		return nil, entity.ErrBannedWords
+	}
+
+	comment.NewsID = uint(newsID)
+	comment.UserID = userID
+	comment.IPAddress = utils.ClientIP(r)
+	allowed, err := s.limiter().Allow(r.Context(), fmt.Sprintf("comment:user:%d", userID), "comment:ip:"+comment.IPAddress)
+	if err != nil {
+		s.app.Logger.Error("Error checking comment rate limit", zap.Error(err))
+		return nil, err
+	}
+	if !allowed {
+		return nil, entity.ErrRateLimited
+	}
+
+	created, err := s.repo.CreateComment(comment, r)
+	if err != nil {
+		s.logError("Error creating comment", err)
+		return nil, err
+	}
+	return created, nil
+}
+
+// ModerateComment changes the status of the comment of the path. The action is
+// recorded with the user ID of the moderator's token.
+func (s *Service) ModerateComment(r *http.Request, moderate *entity.ModerateComment) (*entity.Comment, error) {
+	comment, err := s.GetCommentByID(r)
+	if err != nil {
+		return nil, err
+	}
+	claims, ok := middleware.GetClaimsFromContext(r.Context())
+	if !ok {
+		return nil, fmt.Errorf("unauthorized")
+	}
+	sub, ok := claims["sub"].(float64)
+	if !ok || sub <= 0 {
+		return nil, fmt.Errorf("unauthorized")
+	}
+	moderate.ModeratorID = uint(sub)
+	moderate.Reason = utils.StripHTML(moderate.Reason)
+
+	if err := s.repo.ModerateComment(comment, moderate, r); err != nil {
+		s.logError("Error moderating comment", err)
+		return nil, err
+	}
+	return comment, nil
+}
+
+// RetryAfter is the time a rate limited reader waits before commenting again
+func (s *Service) RetryAfter() time.Duration {
+	return s.limiter().Window()
+}
+
+// containsBannedWord reports whether the text holds a word of
+// COMMENT_BANNED_WORDS. Words match whole words in any case, entries with
+// spaces match anywhere.
+func containsBannedWord(text string) bool {
+	text = strings.ToLower(text)
+	var words map[string]bool
+	for _, banned := range strings.Split(config.GlobalConfig.CommentBannedWords, ",") {
+		banned = strings.ToLower(strings.TrimSpace(banned))
+		switch {
+		case banned == "":
+			continue
+		case strings.ContainsAny(banned, " \t"):
+			if strings.Contains(text, banned) {
+				return true
+			}
+		default:
+			if words == nil {
+				words = map[string]bool{}
+				for _, word := range strings.FieldsFunc(text, func(r rune) bool {
+					return !unicode.IsLetter(r) && !unicode.IsDigit(r) && !unicode.Is(unicode.Mn, r) && !unicode.Is(unicode.Mc, r)
+				}) {
+					words[word] = true
+				}
+			}
+			if words[banned] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// logError logs unexpected errors, the sentinel errors are reported to the client
+func (s *Service) logError(message string, err error) {
+	if errors.Is(err, entity.ErrCommentNotFound) || errors.Is(err, entity.ErrNewsNotFound) ||
+		errors.Is(err, entity.ErrInvalidComment) {
+		return
+	}
+	s.app.Logger.Error(message, zap.Error(err))
+}
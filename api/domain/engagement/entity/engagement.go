@@ -0,0 +1,137 @@
+package entity
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	// ErrNewsNotFound is returned when the article does not exist, or is not published for readers
+	ErrNewsNotFound = errors.New("news not found")
+	// ErrPollNotFound is returned when a poll does not exist
+	ErrPollNotFound = errors.New("poll not found")
+	// ErrInvalidPoll is returned for polls with a bad window or choice limit
+	ErrInvalidPoll = errors.New("invalid poll")
+	// ErrInvalidVote is returned for ballots with unknown, repeated or too many options, and unknown reactions
+	ErrInvalidVote = errors.New("invalid vote")
+	// ErrPollClosed is returned when a poll is not open yet or closed already
+	ErrPollClosed = errors.New("poll is not open for voting")
+	// ErrAlreadyVoted is returned when the reader already voted in the poll
+	ErrAlreadyVoted = errors.New("already voted in this poll")
+	// ErrPollHasVotes is returned when changing the options of a poll with ballots
+	ErrPollHasVotes = errors.New("options of a poll with votes cannot change")
+	// ErrNoVoter is returned when a request has neither a user token nor a valid client token
+	ErrNoVoter = errors.New("sign in or send a valid X-Client-Token")
+	// ErrRateLimited is returned when an IP address asked for too many client tokens
+	ErrRateLimited = errors.New("too many client tokens, try again later")
+)
+
+// Tally counter names. Reaction counters are named TallyReaction followed by the reaction key.
+const (
+	TallyOption   = "option"    // Votes of a poll option, by option ID
+	TallyBallot   = "ballot"    // Ballots of a poll, by poll ID
+	TallyReaction = "reaction:" // Reactions to an article, by news ID
+)
+
+// Reaction represents one of the emoji readers react with
+type Reaction struct {
+	Key   string `json:"key"`
+	Emoji string `json:"emoji"`
+}
+
+// Reactions is the fixed set readers choose from, in display order
+var Reactions = []Reaction{
+	{Key: "like", Emoji: "👍"},
+	{Key: "love", Emoji: "❤️"},
+	{Key: "haha", Emoji: "😂"},
+	{Key: "wow", Emoji: "😮"},
+	{Key: "sad", Emoji: "😢"},
+	{Key: "angry", Emoji: "😠"},
+}
+
+// IsReaction reports whether key is one of Reactions
+func IsReaction(key string) bool {
+	for _, reaction := range Reactions {
+		if reaction.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// Voter identifies a reader, "user:<id>" when signed in and "anon:<client token ID>" otherwise
+type Voter struct {
+	Key    string
+	UserID uint // 0 for anonymous readers
+}
+
+// Poll represents a poll of an article with its current totals
+type Poll struct {
+	ID         uint          `json:"id"`
+	NewsID     uint          `json:"news_id"`
+	Question   string        `json:"question"`
+	Multiple   bool          `json:"multiple"`
+	MaxChoices uint          `json:"max_choices"` // Options a ballot may pick when multiple, 0 for any
+	OpensAt    *time.Time    `json:"opens_at"`
+	ClosesAt   *time.Time    `json:"closes_at"`
+	Open       bool          `json:"open"`        // Accepting ballots now
+	VoterCount int64         `json:"voter_count"` // Ballots cast
+	Options    []*PollOption `json:"options"`     // In display order
+	MyVote     []uint        `json:"my_vote"`     // Options the reader picked, null when they did not vote
+	CreatedBy  uint          `json:"created_by"`
+	CreatedAt  time.Time     `json:"created_at"`
+	UpdatedAt  time.Time     `json:"updated_at"`
+}
+
+// IsOpen reports whether the poll accepts ballots at the time
+func (p *Poll) IsOpen(now time.Time) bool {
+	if p.OpensAt != nil && now.Before(*p.OpensAt) {
+		return false
+	}
+	return p.ClosesAt == nil || now.Before(*p.ClosesAt)
+}
+
+// PollOption represents an answer of a poll
+type PollOption struct {
+	ID        uint   `json:"id"`
+	Label     string `json:"label"`
+	Position  uint   `json:"position"`
+	VoteCount int64  `json:"vote_count"`
+}
+
+// PollRequest represents the request to create or update a poll. The options
+// of a poll with votes cannot change.
+type PollRequest struct {
+	Question   string     `json:"question" validate:"required,max=255"`
+	Multiple   bool       `json:"multiple"`
+	MaxChoices uint       `json:"max_choices"` // Only for multiple choice, 0 for any
+	OpensAt    *time.Time `json:"opens_at"`    // Open right away when empty
+	ClosesAt   *time.Time `json:"closes_at"`   // Open until deleted when empty
+	Options    []string   `json:"options" validate:"required,min=2,max=20,dive,required,max=255"`
+}
+
+// VoteRequest represents a ballot, a single option unless the poll is multiple choice
+type VoteRequest struct {
+	OptionIDs []uint `json:"option_ids" validate:"required,min=1"`
+}
+
+// Ballot represents a stored ballot
+type Ballot struct {
+	PollID    uint
+	Voter     Voter
+	OptionIDs []uint
+}
+
+// ReactionRequest represents the reaction of a reader, one of Reactions
+type ReactionRequest struct {
+	Reaction string `json:"reaction" validate:"required"`
+}
+
+// ReactionSummary represents the reaction totals of an article
+type ReactionSummary struct {
+	NewsID    uint             `json:"news_id"`
+	Counts    map[string]int64 `json:"counts"` // Every reaction key, zero included
+	Total     int64            `json:"total"`
+	Mine      string           `json:"mine"` // Reaction of the reader, empty for none
+	Available []Reaction       `json:"available"`
+}
@@ -0,0 +1,541 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/JubaerHossain/cn-api/domain/engagement/entity"
+	"github.com/JubaerHossain/cn-api/domain/engagement/repository"
+	newsEntity "github.com/JubaerHossain/cn-api/domain/news/entity"
+	"github.com/JubaerHossain/cn-api/pkg/tally"
+	"github.com/JubaerHossain/rootx/pkg/core/app"
+)
+
+// pollQuery selects a poll without its options
+const pollQuery = `
+	SELECT id, news_id, question, multiple, max_choices, opens_at, closes_at, voter_count, created_by, created_at, updated_at
+	FROM polls`
+
+type EngagementRepositoryImpl struct {
+	app *app.App
+}
+
+// NewEngagementRepository returns a new instance of EngagementRepositoryImpl
+func NewEngagementRepository(app *app.App) repository.EngagementRepository {
+	return &EngagementRepositoryImpl{
+		app: app,
+	}
+}
+
+// CheckNews returns ErrNewsNotFound unless the article exists and, when
+// published is set, readers can see it
+func (r *EngagementRepositoryImpl) CheckNews(ctx context.Context, newsID uint, published bool) error {
+	query := "SELECT news.id FROM news WHERE news.id = ?"
+	if published {
+		query += " AND " + newsEntity.PublishedFilter
+	}
+	var id uint
+	err := r.app.MDB.QueryRowContext(ctx, query, newsID).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return entity.ErrNewsNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get news: %w", err)
+	}
+	return nil
+}
+
+// GetNewsPolls returns the polls of an article with their options, oldest first
+func (r *EngagementRepositoryImpl) GetNewsPolls(ctx context.Context, newsID uint) ([]*entity.Poll, error) {
+	rows, err := r.app.MDB.QueryContext(ctx, pollQuery+" WHERE news_id = ? ORDER BY id ASC", newsID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query polls: %w", err)
+	}
+	defer rows.Close()
+
+	polls := []*entity.Poll{}
+	for rows.Next() {
+		poll, err := scanPoll(rows)
+		if err != nil {
+			return nil, err
+		}
+		polls = append(polls, poll)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	rows.Close()
+
+	if err := r.loadOptions(ctx, polls...); err != nil {
+		return nil, err
+	}
+	return polls, nil
+}
+
+// GetPoll returns a poll with its options
+func (r *EngagementRepositoryImpl) GetPoll(ctx context.Context, pollID uint) (*entity.Poll, error) {
+	poll, err := scanPoll(r.app.MDB.QueryRowContext(ctx, pollQuery+" WHERE id = ?", pollID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, entity.ErrPollNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := r.loadOptions(ctx, poll); err != nil {
+		return nil, err
+	}
+	return poll, nil
+}
+
+// CreatePoll stores a poll with its options and sets their IDs
+func (r *EngagementRepositoryImpl) CreatePoll(req *http.Request, poll *entity.Poll) error {
+	ctx := req.Context()
+	tx, err := r.app.MDB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO polls (news_id, question, multiple, max_choices, opens_at, closes_at, created_by, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		poll.NewsID, poll.Question, poll.Multiple, poll.MaxChoices, nullableTime(poll.OpensAt), nullableTime(poll.ClosesAt),
+		poll.CreatedBy, poll.CreatedAt.Format(time.DateTime), poll.UpdatedAt.Format(time.DateTime),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create poll: %w", err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	poll.ID = uint(id)
+	if err := insertOptions(ctx, tx, poll); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// UpdatePoll stores the settings of a poll. Options are replaced when not nil,
+// which fails with ErrPollHasVotes once a ballot was cast.
+func (r *EngagementRepositoryImpl) UpdatePoll(req *http.Request, poll *entity.Poll, options []string) error {
+	ctx := req.Context()
+	tx, err := r.app.MDB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Lock the poll so a ballot cannot be cast while its options are replaced
+	var id uint
+	err = tx.QueryRowContext(ctx, "SELECT id FROM polls WHERE id = ? FOR UPDATE", poll.ID).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return entity.ErrPollNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to lock poll: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE polls SET question = ?, multiple = ?, max_choices = ?, opens_at = ?, closes_at = ?, updated_at = ?
+		WHERE id = ?`,
+		poll.Question, poll.Multiple, poll.MaxChoices, nullableTime(poll.OpensAt), nullableTime(poll.ClosesAt),
+		poll.UpdatedAt.Format(time.DateTime), poll.ID,
+	); err != nil {
+		return fmt.Errorf("failed to update poll: %w", err)
+	}
+
+	if options != nil {
+		var ballots int
+		if err := tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM poll_votes WHERE poll_id = ?", poll.ID).Scan(&ballots); err != nil {
+			return fmt.Errorf("failed to count ballots: %w", err)
+		}
+		if ballots > 0 {
+			return entity.ErrPollHasVotes
+		}
+		if _, err := tx.ExecContext(ctx, "DELETE FROM poll_options WHERE poll_id = ?", poll.ID); err != nil {
+			return fmt.Errorf("failed to delete poll options: %w", err)
+		}
+		poll.Options = make([]*entity.PollOption, len(options))
+		for i, label := range options {
+			poll.Options[i] = &entity.PollOption{Label: label, Position: uint(i)}
+		}
+		if err := insertOptions(ctx, tx, poll); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// DeletePoll removes a poll with its options and ballots. Its flushed ballots
+// are taken off the total of the article, pending ones are dropped by the flusher.
+func (r *EngagementRepositoryImpl) DeletePoll(req *http.Request, poll *entity.Poll) error {
+	ctx := req.Context()
+	tx, err := r.app.MDB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE news SET poll_vote_count = poll_vote_count - (SELECT voter_count FROM polls WHERE polls.id = ?)
+		WHERE id = ?`, poll.ID, poll.NewsID); err != nil {
+		return fmt.Errorf("failed to update news poll votes: %w", err)
+	}
+	for _, query := range []string{
+		"DELETE FROM poll_votes WHERE poll_id = ?",
+		"DELETE FROM poll_options WHERE poll_id = ?",
+		"DELETE FROM polls WHERE id = ?",
+	} {
+		if _, err := tx.ExecContext(ctx, query, poll.ID); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// CreateBallot stores a ballot and reports false when the voter already voted
+// in the poll. Options that are not the poll's fail with ErrInvalidVote.
+func (r *EngagementRepositoryImpl) CreateBallot(ctx context.Context, ballot *entity.Ballot) (bool, error) {
+	optionIDs, err := json.Marshal(ballot.OptionIDs)
+	if err != nil {
+		return false, err
+	}
+	tx, err := r.app.MDB.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	// Share the poll lock so its options cannot be replaced until the ballot is in
+	var id uint
+	err = tx.QueryRowContext(ctx, "SELECT id FROM polls WHERE id = ? LOCK IN SHARE MODE", ballot.PollID).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, entity.ErrPollNotFound
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to lock poll: %w", err)
+	}
+	args := []interface{}{ballot.PollID}
+	for _, optionID := range ballot.OptionIDs {
+		args = append(args, optionID)
+	}
+	var found int
+	if err := tx.QueryRowContext(ctx, fmt.Sprintf("SELECT COUNT(*) FROM poll_options WHERE poll_id = ? AND id IN (%s)",
+		placeholders(len(ballot.OptionIDs))), args...).Scan(&found); err != nil {
+		return false, fmt.Errorf("failed to check poll options: %w", err)
+	}
+	if found != len(ballot.OptionIDs) {
+		return false, fmt.Errorf("%w: option_ids are not options of the poll", entity.ErrInvalidVote)
+	}
+
+	// A duplicate leaves the row as it is, MySQL then reports no affected rows
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO poll_votes (poll_id, voter, user_id, option_ids, created_at)
+		VALUES (?, ?, ?, ?, CURRENT_TIMESTAMP)
+		ON DUPLICATE KEY UPDATE id = id`,
+		ballot.PollID, ballot.Voter.Key, nullableID(ballot.Voter.UserID), string(optionIDs),
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to create ballot: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, tx.Commit()
+}
+
+// GetBallots returns the options the voter picked, by poll ID, for the polls they voted in
+func (r *EngagementRepositoryImpl) GetBallots(ctx context.Context, voter string, pollIDs ...uint) (map[uint][]uint, error) {
+	ballots := map[uint][]uint{}
+	if voter == "" || len(pollIDs) == 0 {
+		return ballots, nil
+	}
+	args := []interface{}{voter}
+	for _, id := range pollIDs {
+		args = append(args, id)
+	}
+	rows, err := r.app.MDB.QueryContext(ctx, fmt.Sprintf(
+		"SELECT poll_id, option_ids FROM poll_votes WHERE voter = ? AND poll_id IN (%s)", placeholders(len(pollIDs))), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query ballots: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			pollID    uint
+			optionIDs string
+		)
+		if err := rows.Scan(&pollID, &optionIDs); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		picked := []uint{}
+		if err := json.Unmarshal([]byte(optionIDs), &picked); err != nil {
+			return nil, fmt.Errorf("failed to decode ballot: %w", err)
+		}
+		ballots[pollID] = picked
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return ballots, nil
+}
+
+// GetReactionCounts returns the flushed reaction totals of an article by reaction key
+func (r *EngagementRepositoryImpl) GetReactionCounts(ctx context.Context, newsID uint) (map[string]int64, error) {
+	var reactions sql.NullString
+	err := r.app.MDB.QueryRowContext(ctx, "SELECT reactions FROM news WHERE id = ?", newsID).Scan(&reactions)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, entity.ErrNewsNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reactions: %w", err)
+	}
+	counts := map[string]int64{}
+	if reactions.Valid && reactions.String != "" {
+		if err := json.Unmarshal([]byte(reactions.String), &counts); err != nil {
+			return nil, fmt.Errorf("failed to decode reactions: %w", err)
+		}
+	}
+	return counts, nil
+}
+
+// GetReaction returns the reaction of the voter to an article, empty for none
+func (r *EngagementRepositoryImpl) GetReaction(ctx context.Context, newsID uint, voter string) (string, error) {
+	if voter == "" {
+		return "", nil
+	}
+	var reaction string
+	err := r.app.MDB.QueryRowContext(ctx, "SELECT reaction FROM news_reactions WHERE news_id = ? AND voter = ?", newsID, voter).Scan(&reaction)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get reaction: %w", err)
+	}
+	return reaction, nil
+}
+
+// SetReaction stores the reaction of the voter to an article and returns the
+// one it replaced, empty for none
+func (r *EngagementRepositoryImpl) SetReaction(ctx context.Context, newsID uint, voter, reaction string) (string, error) {
+	tx, err := r.app.MDB.BeginTx(ctx, nil)
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback()
+
+	previous, err := lockReaction(ctx, tx, newsID, voter)
+	if err != nil {
+		return "", err
+	}
+	if previous == reaction {
+		return previous, nil
+	}
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO news_reactions (news_id, voter, reaction, created_at, updated_at)
+		VALUES (?, ?, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+		ON DUPLICATE KEY UPDATE reaction = VALUES(reaction), updated_at = CURRENT_TIMESTAMP`,
+		newsID, voter, reaction,
+	); err != nil {
+		return "", fmt.Errorf("failed to save reaction: %w", err)
+	}
+	return previous, tx.Commit()
+}
+
+// DeleteReaction removes the reaction of the voter to an article and returns it, empty for none
+func (r *EngagementRepositoryImpl) DeleteReaction(ctx context.Context, newsID uint, voter string) (string, error) {
+	tx, err := r.app.MDB.BeginTx(ctx, nil)
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback()
+
+	previous, err := lockReaction(ctx, tx, newsID, voter)
+	if err != nil || previous == "" {
+		return "", err
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM news_reactions WHERE news_id = ? AND voter = ?", newsID, voter); err != nil {
+		return "", fmt.Errorf("failed to delete reaction: %w", err)
+	}
+	return previous, tx.Commit()
+}
+
+// FlushCounts adds a batch of drained vote and reaction changes to the stored
+// totals. Changes of deleted polls, options and articles are dropped.
+func (r *EngagementRepositoryImpl) FlushCounts(ctx context.Context, counts tally.Counts) error {
+	tx, err := r.app.MDB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for id, n := range counts[entity.TallyOption] {
+		if _, err := tx.ExecContext(ctx, "UPDATE poll_options SET vote_count = vote_count + ? WHERE id = ?", n, id); err != nil {
+			return fmt.Errorf("failed to update option votes: %w", err)
+		}
+	}
+	for id, n := range counts[entity.TallyBallot] {
+		if _, err := tx.ExecContext(ctx, "UPDATE polls SET voter_count = voter_count + ? WHERE id = ?", n, id); err != nil {
+			return fmt.Errorf("failed to update poll voters: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE news SET poll_vote_count = poll_vote_count + ?
+			WHERE id = (SELECT news_id FROM polls WHERE polls.id = ?)`, n, id); err != nil {
+			return fmt.Errorf("failed to update news poll votes: %w", err)
+		}
+	}
+	for name, items := range counts {
+		key, ok := strings.CutPrefix(name, entity.TallyReaction)
+		if !ok || !entity.IsReaction(key) {
+			continue
+		}
+		path := "$." + key
+		for id, n := range items {
+			if _, err := tx.ExecContext(ctx, `
+				UPDATE news
+				SET reactions = JSON_SET(COALESCE(reactions, JSON_OBJECT()), ?, COALESCE(JSON_EXTRACT(reactions, ?), 0) + ?),
+				    reaction_count = reaction_count + ?
+				WHERE id = ?`, path, path, n, n, id); err != nil {
+				return fmt.Errorf("failed to update news reactions: %w", err)
+			}
+		}
+	}
+	return tx.Commit()
+}
+
+// loadOptions sets the options of the polls, in display order
+func (r *EngagementRepositoryImpl) loadOptions(ctx context.Context, polls ...*entity.Poll) error {
+	if len(polls) == 0 {
+		return nil
+	}
+	byID := make(map[uint]*entity.Poll, len(polls))
+	args := make([]interface{}, 0, len(polls))
+	for _, poll := range polls {
+		poll.Options = []*entity.PollOption{}
+		byID[poll.ID] = poll
+		args = append(args, poll.ID)
+	}
+	rows, err := r.app.MDB.QueryContext(ctx, fmt.Sprintf(`
+		SELECT id, poll_id, label, position, vote_count FROM poll_options
+		WHERE poll_id IN (%s)
+		ORDER BY poll_id, position, id`, placeholders(len(polls))), args...)
+	if err != nil {
+		return fmt.Errorf("failed to query poll options: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			option entity.PollOption
+			pollID uint
+		)
+		if err := rows.Scan(&option.ID, &pollID, &option.Label, &option.Position, &option.VoteCount); err != nil {
+			return fmt.Errorf("failed to scan row: %w", err)
+		}
+		if poll, ok := byID[pollID]; ok {
+			poll.Options = append(poll.Options, &option)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("rows iteration error: %w", err)
+	}
+	return nil
+}
+
+// insertOptions stores the options of a poll and sets their IDs
+func insertOptions(ctx context.Context, tx *sql.Tx, poll *entity.Poll) error {
+	for _, option := range poll.Options {
+		result, err := tx.ExecContext(ctx, "INSERT INTO poll_options (poll_id, label, position) VALUES (?, ?, ?)",
+			poll.ID, option.Label, option.Position)
+		if err != nil {
+			return fmt.Errorf("failed to create poll option: %w", err)
+		}
+		id, err := result.LastInsertId()
+		if err != nil {
+			return err
+		}
+		option.ID = uint(id)
+	}
+	return nil
+}
+
+// lockReaction returns the reaction of the voter to an article, empty for
+// none, and locks it until the transaction ends
+func lockReaction(ctx context.Context, tx *sql.Tx, newsID uint, voter string) (string, error) {
+	var reaction string
+	err := tx.QueryRowContext(ctx, "SELECT reaction FROM news_reactions WHERE news_id = ? AND voter = ? FOR UPDATE", newsID, voter).Scan(&reaction)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to lock reaction: %w", err)
+	}
+	return reaction, nil
+}
+
+// scanPoll scans a row of pollQuery
+func scanPoll(row interface{ Scan(...interface{}) error }) (*entity.Poll, error) {
+	var (
+		poll                 entity.Poll
+		opensAt, closesAt    sql.NullString
+		createdAt, updatedAt sql.NullString
+	)
+	if err := row.Scan(&poll.ID, &poll.NewsID, &poll.Question, &poll.Multiple, &poll.MaxChoices, &opensAt, &closesAt,
+		&poll.VoterCount, &poll.CreatedBy, &createdAt, &updatedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to scan row: %w", err)
+	}
+	poll.OpensAt = parseNullDateTime(opensAt)
+	poll.ClosesAt = parseNullDateTime(closesAt)
+	if t := parseNullDateTime(createdAt); t != nil {
+		poll.CreatedAt = *t
+	}
+	if t := parseNullDateTime(updatedAt); t != nil {
+		poll.UpdatedAt = *t
+	}
+	return &poll, nil
+}
+
+// placeholders returns n comma separated query placeholders
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?,", n), ",")
+}
+
+// nullableID returns nil for a zero ID so it is stored as NULL
+func nullableID(id uint) interface{} {
+	if id == 0 {
+		return nil
+	}
+	return id
+}
+
+// nullableTime returns nil for a missing time so it is stored as NULL
+func nullableTime(t *time.Time) interface{} {
+	if t == nil || t.IsZero() {
+		return nil
+	}
+	return t.UTC().Format(time.DateTime)
+}
+
+// parseNullDateTime converts a nullable DATETIME column into a *time.Time
+func parseNullDateTime(value sql.NullString) *time.Time {
+	if !value.Valid {
+		return nil
+	}
+	t, err := time.Parse(time.DateTime, value.String)
+	if err != nil {
+		return nil
+	}
+	return &t
+}
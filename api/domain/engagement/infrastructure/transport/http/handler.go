@@ -0,0 +1,304 @@
+package engagementHttp
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/JubaerHossain/cn-api/domain/engagement/entity"
+	"github.com/JubaerHossain/cn-api/domain/engagement/service"
+	"github.com/JubaerHossain/rootx/pkg/core/app"
+	utilQuery "github.com/JubaerHossain/rootx/pkg/query"
+	"github.com/JubaerHossain/rootx/pkg/utils"
+)
+
+// Handler handles API requests
+type Handler struct {
+	App *service.Service
+}
+
+// NewHandler creates a new instance of Handler
+func NewHandler(app *app.App) *Handler {
+	return &Handler{
+		App: service.NewService(app),
+	}
+}
+
+// @Summary Get a client token
+// @Description Get a token that identifies an anonymous reader. Send it in the X-Client-Token header to vote and react without signing in.
+// @Tags engagement
+// @Produce json
+// @Success 201 {object} map[string]interface{}
+// @Failure 429 {object} map[string]interface{}
+// @Router /client-tokens [post]
+func (h *Handler) IssueClientToken(w http.ResponseWriter, r *http.Request) {
+	token, err := h.App.IssueClientToken(r)
+	if err != nil {
+		h.writeEngagementError(w, err)
+		return
+	}
+	// Write response
+	utils.WriteJSONResponse(w, http.StatusCreated, map[string]interface{}{
+		"message": "Client token created successfully",
+		"results": map[string]interface{}{
+			"token": token,
+		},
+	})
+}
+
+// @Summary Get the polls of an article
+// @Description Get the polls of a published article with their totals. my_vote holds the options the reader picked, identified by user token or X-Client-Token.
+// @Tags engagement
+// @Produce json
+// @Param id path string true "The ID of the article"
+// @Param X-Client-Token header string false "Token of an anonymous reader"
+// @Success 200 {array} entity.Poll
+// @Failure 404 {object} map[string]interface{}
+// @Router /news/{id}/polls [get]
+func (h *Handler) GetNewsPolls(w http.ResponseWriter, r *http.Request) {
+	polls, err := h.App.GetNewsPolls(r)
+	if err != nil {
+		h.writeEngagementError(w, err)
+		return
+	}
+	// Write response
+	utils.JsonResponse(w, http.StatusOK, map[string]interface{}{
+		"results": polls,
+	})
+}
+
+// @Summary Vote in a poll
+// @Description Cast the ballot of the reader, once per poll. Signed in readers vote as themselves, anonymous readers by X-Client-Token.
+// @Tags engagement
+// @Accept json
+// @Produce json
+// @Param id path string true "The ID of the poll"
+// @Param X-Client-Token header string false "Token of an anonymous reader"
+// @Param vote body entity.VoteRequest true "The options picked"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /polls/{id}/votes [post]
+func (h *Handler) Vote(w http.ResponseWriter, r *http.Request) {
+	var vote entity.VoteRequest
+	pareErr := utilQuery.BodyParse(&vote, w, r, true) // Parse request body and validate it
+	if pareErr != nil {
+		return
+	}
+
+	poll, err := h.App.Vote(r, &vote)
+	if err != nil {
+		h.writeEngagementError(w, err)
+		return
+	}
+
+	// Write response
+	utils.WriteJSONResponse(w, http.StatusCreated, map[string]interface{}{
+		"message": "Vote counted",
+		"results": poll,
+	})
+}
+
+// @Summary Get the reactions to an article
+// @Description Get the reaction totals of a published article, the reactions available and the reaction of the reader
+// @Tags engagement
+// @Produce json
+// @Param id path string true "The ID of the article"
+// @Param X-Client-Token header string false "Token of an anonymous reader"
+// @Success 200 {object} entity.ReactionSummary
+// @Failure 404 {object} map[string]interface{}
+// @Router /news/{id}/reactions [get]
+func (h *Handler) GetReactions(w http.ResponseWriter, r *http.Request) {
+	summary, err := h.App.GetReactions(r)
+	if err != nil {
+		h.writeEngagementError(w, err)
+		return
+	}
+	// Write response
+	utils.JsonResponse(w, http.StatusOK, map[string]interface{}{
+		"results": summary,
+	})
+}
+
+// @Summary React to an article
+// @Description Set the reaction of the reader, replacing the one they had. One of like, love, haha, wow, sad or angry.
+// @Tags engagement
+// @Accept json
+// @Produce json
+// @Param id path string true "The ID of the article"
+// @Param X-Client-Token header string false "Token of an anonymous reader"
+// @Param reaction body entity.ReactionRequest true "The reaction"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /news/{id}/reactions [post]
+func (h *Handler) React(w http.ResponseWriter, r *http.Request) {
+	var reaction entity.ReactionRequest
+	pareErr := utilQuery.BodyParse(&reaction, w, r, true) // Parse request body and validate it
+	if pareErr != nil {
+		return
+	}
+
+	summary, err := h.App.React(r, &reaction)
+	if err != nil {
+		h.writeEngagementError(w, err)
+		return
+	}
+
+	// Write response
+	utils.WriteJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"message": "Reaction saved",
+		"results": summary,
+	})
+}
+
+// @Summary Remove a reaction
+// @Description Remove the reaction of the reader to an article
+// @Tags engagement
+// @Produce json
+// @Param id path string true "The ID of the article"
+// @Param X-Client-Token header string false "Token of an anonymous reader"
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /news/{id}/reactions [delete]
+func (h *Handler) Unreact(w http.ResponseWriter, r *http.Request) {
+	summary, err := h.App.Unreact(r)
+	if err != nil {
+		h.writeEngagementError(w, err)
+		return
+	}
+	// Write response
+	utils.WriteJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"message": "Reaction removed",
+		"results": summary,
+	})
+}
+
+// @Summary Get the polls of an article
+// @Description Get the polls of an article, whatever its state, with their totals
+// @Tags engagement
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "The ID of the article"
+// @Success 200 {array} entity.Poll
+// @Failure 404 {object} map[string]interface{}
+// @Router /news/{id}/polls [get]
+func (h *Handler) GetPolls(w http.ResponseWriter, r *http.Request) {
+	polls, err := h.App.GetPolls(r)
+	if err != nil {
+		h.writeEngagementError(w, err)
+		return
+	}
+	// Write response
+	utils.JsonResponse(w, http.StatusOK, map[string]interface{}{
+		"results": polls,
+	})
+}
+
+// @Summary Create a poll
+// @Description Attach a single or multiple choice poll to an article. Without opens_at it opens right away, without closes_at it stays open.
+// @Tags engagement
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "The ID of the article"
+// @Param poll body entity.PollRequest true "The poll to create"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /news/{id}/polls [post]
+func (h *Handler) CreatePoll(w http.ResponseWriter, r *http.Request) {
+	var newPoll entity.PollRequest
+	pareErr := utilQuery.BodyParse(&newPoll, w, r, true) // Parse request body and validate it
+	if pareErr != nil {
+		return
+	}
+
+	poll, err := h.App.CreatePoll(r, &newPoll)
+	if err != nil {
+		h.writeEngagementError(w, err)
+		return
+	}
+
+	// Write response
+	utils.WriteJSONResponse(w, http.StatusCreated, map[string]interface{}{
+		"message": "Poll created successfully",
+		"results": poll,
+	})
+}
+
+// @Summary Update a poll
+// @Description Change the question, choice limit and window of a poll. Its options may only change until the first vote.
+// @Tags engagement
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "The ID of the poll"
+// @Param poll body entity.PollRequest true "The poll settings"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /polls/{id} [put]
+func (h *Handler) UpdatePoll(w http.ResponseWriter, r *http.Request) {
+	var updatePoll entity.PollRequest
+	pareErr := utilQuery.BodyParse(&updatePoll, w, r, true) // Parse request body and validate it
+	if pareErr != nil {
+		return
+	}
+
+	poll, err := h.App.UpdatePoll(r, &updatePoll)
+	if err != nil {
+		h.writeEngagementError(w, err)
+		return
+	}
+
+	// Write response
+	utils.WriteJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"message": "Poll updated successfully",
+		"results": poll,
+	})
+}
+
+// @Summary Delete a poll
+// @Description Delete a poll with its votes
+// @Tags engagement
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "The ID of the poll"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /polls/{id} [delete]
+func (h *Handler) DeletePoll(w http.ResponseWriter, r *http.Request) {
+	if err := h.App.DeletePoll(r); err != nil {
+		h.writeEngagementError(w, err)
+		return
+	}
+	// Write response
+	utils.WriteJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"message": "Poll deleted successfully",
+	})
+}
+
+// writeEngagementError maps poll and reaction errors to HTTP statuses
+func (h *Handler) writeEngagementError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, entity.ErrInvalidPoll), errors.Is(err, entity.ErrInvalidVote):
+		utils.WriteJSONError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, entity.ErrNoVoter):
+		utils.WriteJSONError(w, http.StatusUnauthorized, err.Error())
+	case errors.Is(err, entity.ErrNewsNotFound), errors.Is(err, entity.ErrPollNotFound):
+		utils.WriteJSONError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, entity.ErrPollClosed), errors.Is(err, entity.ErrAlreadyVoted), errors.Is(err, entity.ErrPollHasVotes):
+		utils.WriteJSONError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, entity.ErrRateLimited):
+		w.Header().Set("Retry-After", strconv.Itoa(int(h.App.RetryAfter().Seconds())))
+		utils.WriteJSONError(w, http.StatusTooManyRequests, err.Error())
+	default:
+		utils.WriteJSONError(w, http.StatusInternalServerError, err.Error())
+	}
+}
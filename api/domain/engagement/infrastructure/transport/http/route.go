@@ -0,0 +1,45 @@
+package engagementHttp
+
+import (
+	"net/http"
+
+	authMiddleware "github.com/JubaerHossain/cn-api/pkg/middleware"
+	"github.com/JubaerHossain/rootx/pkg/core/app"
+	"github.com/JubaerHossain/rootx/pkg/core/middleware"
+)
+
+// EngagementRouter registers public poll and reaction routes. Readers vote and
+// react with their own token, or anonymously with a client token.
+func EngagementRouter(router *http.ServeMux, application *app.App) http.Handler {
+
+	handler := NewHandler(application)
+	reader := func(h http.HandlerFunc) http.Handler {
+		return middleware.LimiterMiddleware(authMiddleware.OptionalAuthMiddleware(application, h))
+	}
+
+	router.Handle("POST /client-tokens", middleware.LimiterMiddleware(http.HandlerFunc(handler.IssueClientToken)))
+	router.Handle("GET /news/{id}/polls", reader(handler.GetNewsPolls))
+	router.Handle("POST /polls/{id}/votes", reader(handler.Vote))
+	router.Handle("GET /news/{id}/reactions", reader(handler.GetReactions))
+	router.Handle("POST /news/{id}/reactions", reader(handler.React))
+	router.Handle("DELETE /news/{id}/reactions", reader(handler.Unreact))
+
+	return router
+}
+
+// EngagementAdminRouter registers poll management routes for editors and admins
+func EngagementAdminRouter(router *http.ServeMux, application *app.App) http.Handler {
+
+	handler := NewHandler(application)
+	protect := func(h http.HandlerFunc) http.Handler {
+		return middleware.LimiterMiddleware(authMiddleware.AuthMiddleware(application,
+			authMiddleware.RoleMiddleware(h, authMiddleware.RoleEditor, authMiddleware.RoleAdmin)))
+	}
+
+	router.Handle("GET /news/{id}/polls", protect(handler.GetPolls))
+	router.Handle("POST /news/{id}/polls", protect(handler.CreatePoll))
+	router.Handle("PUT /polls/{id}", protect(handler.UpdatePoll))
+	router.Handle("DELETE /polls/{id}", protect(handler.DeletePoll))
+
+	return router
+}
@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/JubaerHossain/cn-api/domain/engagement/entity"
+	"github.com/JubaerHossain/cn-api/pkg/tally"
+)
+
+// EngagementRepository defines methods for poll and reaction data access.
+// Totals are the flushed counts, without the changes still buffered.
+type EngagementRepository interface {
+	CheckNews(ctx context.Context, newsID uint, published bool) error
+	GetNewsPolls(ctx context.Context, newsID uint) ([]*entity.Poll, error)
+	GetPoll(ctx context.Context, pollID uint) (*entity.Poll, error)
+	CreatePoll(r *http.Request, poll *entity.Poll) error
+	UpdatePoll(r *http.Request, poll *entity.Poll, options []string) error
+	DeletePoll(r *http.Request, poll *entity.Poll) error
+	CreateBallot(ctx context.Context, ballot *entity.Ballot) (bool, error)
+	GetBallots(ctx context.Context, voter string, pollIDs ...uint) (map[uint][]uint, error)
+	GetReactionCounts(ctx context.Context, newsID uint) (map[string]int64, error)
+	GetReaction(ctx context.Context, newsID uint, voter string) (string, error)
+	SetReaction(ctx context.Context, newsID uint, voter, reaction string) (string, error)
+	DeleteReaction(ctx context.Context, newsID uint, voter string) (string, error)
+	FlushCounts(ctx context.Context, counts tally.Counts) error
+}
@@ -0,0 +1,528 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/JubaerHossain/cn-api/domain/engagement/entity"
+	"github.com/JubaerHossain/cn-api/domain/engagement/infrastructure/persistence"
+	"github.com/JubaerHossain/cn-api/domain/engagement/repository"
+	"github.com/JubaerHossain/cn-api/pkg/auth"
+	"github.com/JubaerHossain/cn-api/pkg/clienttoken"
+	"github.com/JubaerHossain/cn-api/pkg/config"
+	"github.com/JubaerHossain/cn-api/pkg/middleware"
+	"github.com/JubaerHossain/cn-api/pkg/ratelimit"
+	"github.com/JubaerHossain/cn-api/pkg/redisclient"
+	"github.com/JubaerHossain/cn-api/pkg/tally"
+	"github.com/JubaerHossain/cn-api/pkg/utils"
+	"github.com/JubaerHossain/rootx/pkg/core/app"
+	"go.uber.org/zap"
+)
+
+var (
+	tokenLimiter     *ratelimit.Limiter
+	tokenLimiterOnce sync.Once
+	countTally       *tally.Counter
+	countTallyOnce   sync.Once
+)
+
+type Service struct {
+	app  *app.App
+	repo repository.EngagementRepository
+}
+
+func NewService(app *app.App) *Service {
+	repo := persistence.NewEngagementRepository(app)
+	return &Service{
+		app:  app,
+		repo: repo,
+	}
+}
+
+// limiter returns the client token rate limiter shared by every handler, on
+// Redis when it is enabled so the limits hold across replicas
+func (s *Service) limiter() *ratelimit.Limiter {
+	tokenLimiterOnce.Do(func() {
+		tokenLimiter = ratelimit.New(redisclient.Get(s.app.Config), config.GlobalConfig.ClientTokenLimit, time.Hour)
+	})
+	return tokenLimiter
+}
+
+// counter returns the vote and reaction counter shared by every handler.
+// Changes are buffered in Redis when it is enabled and in memory otherwise.
+func (s *Service) counter() *tally.Counter {
+	countTallyOnce.Do(func() {
+		countTally = tally.New(redisclient.Get(s.app.Config), "tally")
+	})
+	return countTally
+}
+
+// StartTallyFlusher writes buffered votes and reactions to the database every TALLY_FLUSH_INTERVAL
+func (s *Service) StartTallyFlusher() {
+	s.counter().Start(time.Duration(config.GlobalConfig.TallyFlushInterval)*time.Second, s.repo.FlushCounts, s.app.Logger)
+}
+
+// StopTallyFlusher stops the flusher and writes the votes and reactions still buffered
+func (s *Service) StopTallyFlusher(ctx context.Context) error {
+	return s.counter().Stop(ctx, s.repo.FlushCounts)
+}
+
+// RetryAfter is the time a rate limited client waits before asking for a token again
+func (s *Service) RetryAfter() time.Duration {
+	return s.limiter().Window()
+}
+
+// IssueClientToken returns a new token for an anonymous reader, limited per IP address
+func (s *Service) IssueClientToken(r *http.Request) (string, error) {
+	allowed, err := s.limiter().Allow(r.Context(), "client-token:ip:"+utils.ClientIP(r))
+	if err != nil {
+		s.app.Logger.Error("Error checking client token rate limit", zap.Error(err))
+		return "", err
+	}
+	if !allowed {
+		return "", entity.ErrRateLimited
+	}
+	token, err := clienttoken.New(s.tokenSecret())
+	if err != nil {
+		s.app.Logger.Error("Error creating client token", zap.Error(err))
+		return "", err
+	}
+	return token, nil
+}
+
+// GetPolls lists the polls of the article in the path, whatever its state
+func (s *Service) GetPolls(r *http.Request) ([]*entity.Poll, error) {
+	newsID, err := s.newsID(r, false)
+	if err != nil {
+		return nil, err
+	}
+	return s.getPolls(r.Context(), newsID, entity.Voter{})
+}
+
+// GetNewsPolls lists the polls of the published article in the path, with the
+// options the reader picked when they voted
+func (s *Service) GetNewsPolls(r *http.Request) ([]*entity.Poll, error) {
+	newsID, err := s.newsID(r, true)
+	if err != nil {
+		return nil, err
+	}
+	voter, _ := s.voter(r)
+	return s.getPolls(r.Context(), newsID, voter)
+}
+
+// CreatePoll attaches a poll to the article in the path
+func (s *Service) CreatePoll(r *http.Request, request *entity.PollRequest) (*entity.Poll, error) {
+	newsID, err := s.newsID(r, false)
+	if err != nil {
+		return nil, err
+	}
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		return nil, fmt.Errorf("unauthorized")
+	}
+	options, err := validatePoll(request)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	poll := &entity.Poll{
+		NewsID:    newsID,
+		CreatedBy: userID,
+		CreatedAt: now,
+		UpdatedAt: now,
+		Options:   make([]*entity.PollOption, len(options)),
+	}
+	applyPoll(poll, request)
+	for i, label := range options {
+		poll.Options[i] = &entity.PollOption{Label: label, Position: uint(i)}
+	}
+	if err := s.repo.CreatePoll(r, poll); err != nil {
+		s.logError("Error creating poll", err)
+		return nil, err
+	}
+	poll.Open = poll.IsOpen(time.Now())
+	return poll, nil
+}
+
+// UpdatePoll changes the poll in the path. Its options may only change until the first vote.
+func (s *Service) UpdatePoll(r *http.Request, request *entity.PollRequest) (*entity.Poll, error) {
+	poll, err := s.getPoll(r)
+	if err != nil {
+		return nil, err
+	}
+	options, err := validatePoll(request)
+	if err != nil {
+		return nil, err
+	}
+
+	var replace []string
+	if !sameOptions(poll.Options, options) {
+		replace = options
+	}
+	applyPoll(poll, request)
+	poll.UpdatedAt = time.Now().UTC().Truncate(time.Second)
+	if err := s.repo.UpdatePoll(r, poll, replace); err != nil {
+		s.logError("Error updating poll", err)
+		return nil, err
+	}
+	polls, err := s.withTotals(r.Context(), []*entity.Poll{poll}, entity.Voter{})
+	if err != nil {
+		return nil, err
+	}
+	return polls[0], nil
+}
+
+// DeletePoll removes the poll in the path with its votes
+func (s *Service) DeletePoll(r *http.Request) error {
+	poll, err := s.getPoll(r)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.DeletePoll(r, poll); err != nil {
+		s.logError("Error deleting poll", err)
+		return err
+	}
+	return nil
+}
+
+// Vote casts the ballot of the reader in the poll in the path. A reader votes
+// once per poll, as a user when signed in and by client token otherwise.
+func (s *Service) Vote(r *http.Request, request *entity.VoteRequest) (*entity.Poll, error) {
+	ctx := r.Context()
+	voter, err := s.voter(r)
+	if err != nil {
+		return nil, err
+	}
+	poll, err := s.getPoll(r)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.CheckNews(ctx, poll.NewsID, true); err != nil {
+		// Polls of unpublished articles do not exist for readers
+		if errors.Is(err, entity.ErrNewsNotFound) {
+			return nil, entity.ErrPollNotFound
+		}
+		return nil, err
+	}
+	if !poll.IsOpen(time.Now()) {
+		return nil, entity.ErrPollClosed
+	}
+	if err := validateBallot(poll, request.OptionIDs); err != nil {
+		return nil, err
+	}
+
+	created, err := s.repo.CreateBallot(ctx, &entity.Ballot{PollID: poll.ID, Voter: voter, OptionIDs: request.OptionIDs})
+	if err != nil {
+		s.logError("Error creating ballot", err)
+		return nil, err
+	}
+	if !created {
+		return nil, entity.ErrAlreadyVoted
+	}
+	for _, optionID := range request.OptionIDs {
+		s.count(ctx, entity.TallyOption, optionID, 1)
+	}
+	s.count(ctx, entity.TallyBallot, poll.ID, 1)
+
+	polls, err := s.withTotals(ctx, []*entity.Poll{poll}, voter)
+	if err != nil {
+		return nil, err
+	}
+	return polls[0], nil
+}
+
+// GetReactions returns the reaction totals of the published article in the
+// path, with the reaction of the reader when they reacted
+func (s *Service) GetReactions(r *http.Request) (*entity.ReactionSummary, error) {
+	newsID, err := s.newsID(r, true)
+	if err != nil {
+		return nil, err
+	}
+	voter, _ := s.voter(r)
+	return s.reactionSummary(r.Context(), newsID, voter)
+}
+
+// React sets the reaction of the reader to the published article in the path,
+// replacing the one they had
+func (s *Service) React(r *http.Request, request *entity.ReactionRequest) (*entity.ReactionSummary, error) {
+	ctx := r.Context()
+	voter, err := s.voter(r)
+	if err != nil {
+		return nil, err
+	}
+	if !entity.IsReaction(request.Reaction) {
+		return nil, fmt.Errorf("%w: unknown reaction %q", entity.ErrInvalidVote, request.Reaction)
+	}
+	newsID, err := s.newsID(r, true)
+	if err != nil {
+		return nil, err
+	}
+
+	previous, err := s.repo.SetReaction(ctx, newsID, voter.Key, request.Reaction)
+	if err != nil {
+		s.logError("Error saving reaction", err)
+		return nil, err
+	}
+	if previous != request.Reaction {
+		if previous != "" {
+			s.count(ctx, entity.TallyReaction+previous, newsID, -1)
+		}
+		s.count(ctx, entity.TallyReaction+request.Reaction, newsID, 1)
+	}
+	return s.reactionSummary(ctx, newsID, voter)
+}
+
+// Unreact removes the reaction of the reader to the published article in the path
+func (s *Service) Unreact(r *http.Request) (*entity.ReactionSummary, error) {
+	ctx := r.Context()
+	voter, err := s.voter(r)
+	if err != nil {
+		return nil, err
+	}
+	newsID, err := s.newsID(r, true)
+	if err != nil {
+		return nil, err
+	}
+
+	previous, err := s.repo.DeleteReaction(ctx, newsID, voter.Key)
+	if err != nil {
+		s.logError("Error deleting reaction", err)
+		return nil, err
+	}
+	if previous != "" {
+		s.count(ctx, entity.TallyReaction+previous, newsID, -1)
+	}
+	return s.reactionSummary(ctx, newsID, voter)
+}
+
+// getPolls returns the polls of an article with their totals
+func (s *Service) getPolls(ctx context.Context, newsID uint, voter entity.Voter) ([]*entity.Poll, error) {
+	polls, err := s.repo.GetNewsPolls(ctx, newsID)
+	if err != nil {
+		s.logError("Error getting polls", err)
+		return nil, err
+	}
+	return s.withTotals(ctx, polls, voter)
+}
+
+// getPoll returns the poll in the path
+func (s *Service) getPoll(r *http.Request) (*entity.Poll, error) {
+	pollID, err := strconv.ParseUint(r.PathValue("id"), 10, 64)
+	if err != nil {
+		return nil, entity.ErrPollNotFound
+	}
+	poll, err := s.repo.GetPoll(r.Context(), uint(pollID))
+	if err != nil {
+		s.logError("Error getting poll", err)
+		return nil, err
+	}
+	return poll, nil
+}
+
+// withTotals adds the buffered votes to the stored totals of the polls, marks
+// the open ones and sets the ballot of the voter
+func (s *Service) withTotals(ctx context.Context, polls []*entity.Poll, voter entity.Voter) ([]*entity.Poll, error) {
+	pollIDs := make([]uint, 0, len(polls))
+	optionIDs := []uint{}
+	for _, poll := range polls {
+		pollIDs = append(pollIDs, poll.ID)
+		for _, option := range poll.Options {
+			optionIDs = append(optionIDs, option.ID)
+		}
+	}
+	ballots, err := s.counter().Pending(ctx, entity.TallyBallot, pollIDs...)
+	if err != nil {
+		s.app.Logger.Error("Error getting pending ballots", zap.Error(err))
+		return nil, err
+	}
+	votes, err := s.counter().Pending(ctx, entity.TallyOption, optionIDs...)
+	if err != nil {
+		s.app.Logger.Error("Error getting pending votes", zap.Error(err))
+		return nil, err
+	}
+	mine, err := s.repo.GetBallots(ctx, voter.Key, pollIDs...)
+	if err != nil {
+		s.app.Logger.Error("Error getting ballots", zap.Error(err))
+		return nil, err
+	}
+
+	now := time.Now()
+	for _, poll := range polls {
+		poll.Open = poll.IsOpen(now)
+		poll.VoterCount = max(poll.VoterCount+ballots[poll.ID], 0)
+		for _, option := range poll.Options {
+			option.VoteCount = max(option.VoteCount+votes[option.ID], 0)
+		}
+		poll.MyVote = mine[poll.ID]
+	}
+	return polls, nil
+}
+
+// reactionSummary returns the reaction totals of an article, stored and buffered
+func (s *Service) reactionSummary(ctx context.Context, newsID uint, voter entity.Voter) (*entity.ReactionSummary, error) {
+	stored, err := s.repo.GetReactionCounts(ctx, newsID)
+	if err != nil {
+		s.logError("Error getting reactions", err)
+		return nil, err
+	}
+	mine, err := s.repo.GetReaction(ctx, newsID, voter.Key)
+	if err != nil {
+		s.app.Logger.Error("Error getting reaction", zap.Error(err))
+		return nil, err
+	}
+
+	summary := &entity.ReactionSummary{
+		NewsID:    newsID,
+		Counts:    make(map[string]int64, len(entity.Reactions)),
+		Mine:      mine,
+		Available: entity.Reactions,
+	}
+	for _, reaction := range entity.Reactions {
+		pending, err := s.counter().Pending(ctx, entity.TallyReaction+reaction.Key, newsID)
+		if err != nil {
+			s.app.Logger.Error("Error getting pending reactions", zap.Error(err))
+			return nil, err
+		}
+		n := max(stored[reaction.Key]+pending[newsID], 0)
+		summary.Counts[reaction.Key] = n
+		summary.Total += n
+	}
+	return summary, nil
+}
+
+// count buffers a vote or reaction change. A failure is logged and the change
+// lost, the reader's vote or reaction itself is already stored.
+func (s *Service) count(ctx context.Context, name string, id uint, n int64) {
+	if err := s.counter().Add(ctx, name, id, n); err != nil {
+		s.app.Logger.Error("Error counting", zap.String("name", name), zap.Uint("id", id), zap.Error(err))
+	}
+}
+
+// newsID returns the article ID in the path, checking the article exists and,
+// when published is set, that readers can see it
+func (s *Service) newsID(r *http.Request, published bool) (uint, error) {
+	id, err := strconv.ParseUint(r.PathValue("id"), 10, 64)
+	if err != nil {
+		return 0, entity.ErrNewsNotFound
+	}
+	if err := s.repo.CheckNews(r.Context(), uint(id), published); err != nil {
+		s.logError("Error getting news", err)
+		return 0, err
+	}
+	return uint(id), nil
+}
+
+// voter identifies the reader by user token, or else by client token
+func (s *Service) voter(r *http.Request) (entity.Voter, error) {
+	if userID, ok := middleware.GetUserIDFromContext(r.Context()); ok {
+		return entity.Voter{Key: fmt.Sprintf("user:%d", userID), UserID: userID}, nil
+	}
+	if token := r.Header.Get(clienttoken.Header); token != "" {
+		if id, ok := clienttoken.Verify(s.tokenSecret(), token); ok {
+			return entity.Voter{Key: "anon:" + id}, nil
+		}
+	}
+	return entity.Voter{}, entity.ErrNoVoter
+}
+
+// tokenSecret signs client tokens, see auth.SigningKey
+func (s *Service) tokenSecret() []byte {
+	return auth.SigningKey(config.GlobalConfig.ClientTokenSecret, s.app.Config.JwtSecretKey, "client-token")
+}
+
+// validatePoll cleans the request and returns the option labels
+func validatePoll(request *entity.PollRequest) ([]string, error) {
+	request.Question = utils.PlainText(request.Question)
+	if request.Question == "" {
+		return nil, fmt.Errorf("%w: question is empty", entity.ErrInvalidPoll)
+	}
+	options := make([]string, 0, len(request.Options))
+	seen := map[string]bool{}
+	for _, label := range request.Options {
+		label = utils.PlainText(label)
+		if label == "" {
+			return nil, fmt.Errorf("%w: options cannot be empty", entity.ErrInvalidPoll)
+		}
+		if seen[strings.ToLower(label)] {
+			return nil, fmt.Errorf("%w: option %q is repeated", entity.ErrInvalidPoll, label)
+		}
+		seen[strings.ToLower(label)] = true
+		options = append(options, label)
+	}
+	if !request.Multiple {
+		request.MaxChoices = 0
+	}
+	if request.MaxChoices > uint(len(options)) {
+		return nil, fmt.Errorf("%w: max_choices is more than the options", entity.ErrInvalidPoll)
+	}
+	if request.OpensAt != nil && request.ClosesAt != nil && !request.ClosesAt.After(*request.OpensAt) {
+		return nil, fmt.Errorf("%w: closes_at must be after opens_at", entity.ErrInvalidPoll)
+	}
+	return options, nil
+}
+
+// applyPoll copies the settings of the request to the poll
+func applyPoll(poll *entity.Poll, request *entity.PollRequest) {
+	poll.Question = request.Question
+	poll.Multiple = request.Multiple
+	poll.MaxChoices = request.MaxChoices
+	poll.OpensAt = utcTime(request.OpensAt)
+	poll.ClosesAt = utcTime(request.ClosesAt)
+}
+
+// sameOptions reports whether the poll options have the labels, in order
+func sameOptions(options []*entity.PollOption, labels []string) bool {
+	if len(options) != len(labels) {
+		return false
+	}
+	for i, option := range options {
+		if option.Label != labels[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// validateBallot checks the number of options picked and that none repeats.
+// Whether they are options of the poll is checked when the ballot is stored.
+func validateBallot(poll *entity.Poll, optionIDs []uint) error {
+	if !poll.Multiple && len(optionIDs) > 1 {
+		return fmt.Errorf("%w: the poll takes a single option", entity.ErrInvalidVote)
+	}
+	if poll.Multiple && poll.MaxChoices > 0 && uint(len(optionIDs)) > poll.MaxChoices {
+		return fmt.Errorf("%w: the poll takes up to %d options", entity.ErrInvalidVote, poll.MaxChoices)
+	}
+	seen := make(map[uint]bool, len(optionIDs))
+	for _, id := range optionIDs {
+		if seen[id] {
+			return fmt.Errorf("%w: option %d is repeated", entity.ErrInvalidVote, id)
+		}
+		seen[id] = true
+	}
+	return nil
+}
+
+// utcTime returns the time in UTC to the second, nil stays nil
+func utcTime(t *time.Time) *time.Time {
+	if t == nil || t.IsZero() {
+		return nil
+	}
+	utc := t.UTC().Truncate(time.Second)
+	return &utc
+}
+
+// logError logs unexpected errors, the sentinel errors are reported to the client
+func (s *Service) logError(message string, err error) {
+	if errors.Is(err, entity.ErrNewsNotFound) || errors.Is(err, entity.ErrPollNotFound) ||
+		errors.Is(err, entity.ErrInvalidVote) || errors.Is(err, entity.ErrPollHasVotes) {
+		return
+	}
+	s.app.Logger.Error(message, zap.Error(err))
+}
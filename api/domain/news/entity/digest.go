@@ -0,0 +1,24 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/JubaerHossain/cn-api/pkg/ranking"
+)
+
+// DigestQuery selects the articles of a newsletter digest
+type DigestQuery struct {
+	Window      ranking.Window // Ranks the articles of each category
+	Since       time.Time      // Only articles published since
+	Locale      string
+	Categories  []string // Category slugs in display order, every active category when empty
+	PerCategory int
+}
+
+// DigestSection holds the top articles of a category, an article shows in the
+// first section of its categories only
+type DigestSection struct {
+	Category     string        `json:"category"`
+	CategorySlug string        `json:"category_slug"`
+	News         []*ScrollNews `json:"news"`
+}
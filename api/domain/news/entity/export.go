@@ -0,0 +1,38 @@
+package entity
+
+import "time"
+
+// Export formats
+const (
+	ExportNDJSON = "ndjson"
+	ExportCSV    = "csv"
+)
+
+// ExportFilter selects the articles of a bulk export
+type ExportFilter struct {
+	Format   string
+	From     *time.Time // Published on or after, created for articles never published
+	To       *time.Time // Published before, created for articles never published
+	Category string     // Slug of a category the articles are assigned to
+	Locale   string     // Only translations in this locale
+}
+
+// ExportedNews is an article as written by the bulk export, with every translation
+type ExportedNews struct {
+	ID           uint               `json:"id"`
+	Type         string             `json:"type"`
+	StatusID     uint               `json:"status_id"`
+	State        string             `json:"state"`
+	DepartmentID uint               `json:"department_id"`
+	Author       Author             `json:"author"`
+	Categories   []string           `json:"categories"` // Titles
+	PathSmall    string             `json:"path_small"`
+	PathMedium   string             `json:"path_medium"`
+	PathLarge    string             `json:"path_large"`
+	PublishAt    *time.Time         `json:"publish_at"`
+	UnpublishAt  *time.Time         `json:"unpublish_at"`
+	PublishedAt  *time.Time         `json:"published_at"`
+	CreatedAt    time.Time          `json:"created_at"`
+	UpdatedAt    time.Time          `json:"updated_at"`
+	Translations []*NewsTranslation `json:"translations"`
+}
@@ -0,0 +1,66 @@
+package entity
+
+import (
+	"fmt"
+	"time"
+)
+
+// Kinds of imported records, the wxr_imports.kind values
+const (
+	ImportAuthor     = "author"
+	ImportCategory   = "category"
+	ImportPost       = "post"
+	ImportAttachment = "attachment"
+	ImportMedia      = "media" // Attachment or inline image file, keyed by its URL
+)
+
+// ImportOptions are the settings of a WordPress import
+type ImportOptions struct {
+	DryRun   bool   // Report what would be imported, write nothing
+	Locale   string // Of the imported translations, the export language when empty
+	Type     string // news.type of the imported articles
+	RoleID   uint   // users.role of the imported authors
+	AuthorID uint   // Author of posts whose author is not in the export, 0 to skip them
+	Images   bool   // Download attachments and images, store them and link the content to them
+	Site     string // Link of the exported site, resolves relative image URLs
+}
+
+// ImportedNews is an article to import with the dates of its source post
+type ImportedNews struct {
+	News        *News
+	Source      string // GUID of the post
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	PublishedAt *time.Time // Set for published posts
+}
+
+// ImportIssue is an item of the export that was skipped or failed
+type ImportIssue struct {
+	Kind   string `json:"kind"`
+	Source string `json:"source"` // GUID, login or nicename
+	Title  string `json:"title,omitempty"`
+	Reason string `json:"reason"`
+}
+
+// ImportReport sums up an import
+type ImportReport struct {
+	DryRun  bool           `json:"dry_run"`
+	Created map[string]int `json:"created"` // By kind, what a dry run would create
+	Skipped []ImportIssue  `json:"skipped"`
+	Failed  []ImportIssue  `json:"failed"`
+}
+
+// NewImportReport returns an empty report
+func NewImportReport(dryRun bool) *ImportReport {
+	return &ImportReport{DryRun: dryRun, Created: map[string]int{}, Skipped: []ImportIssue{}, Failed: []ImportIssue{}}
+}
+
+// Skip records a skipped item
+func (r *ImportReport) Skip(kind, source, title, reason string) {
+	r.Skipped = append(r.Skipped, ImportIssue{Kind: kind, Source: source, Title: title, Reason: reason})
+}
+
+// Fail records a failed item
+func (r *ImportReport) Fail(kind, source, title string, err error) {
+	r.Failed = append(r.Failed, ImportIssue{Kind: kind, Source: source, Title: title, Reason: fmt.Sprint(err)})
+}
@@ -0,0 +1,71 @@
+package entity
+
+import (
+	"errors"
+	"time"
+)
+
+// TypeLiveBlog is the news.type of live blogs, articles followed by a stream of short entries
+const TypeLiveBlog = "live"
+
+var (
+	// ErrNotLiveBlog is returned when entries are added to an article of another type
+	ErrNotLiveBlog = errors.New("news is not a live blog")
+	// ErrLiveEntryNotFound is returned when an entry does not exist or belongs to another article
+	ErrLiveEntryNotFound = errors.New("live entry not found")
+)
+
+// Media types of a live blog entry
+const (
+	LiveMediaImage = "image"
+	LiveMediaVideo = "video"
+	LiveMediaEmbed = "embed"
+)
+
+// LiveEntryRequest is the body of a new or edited live blog entry
+type LiveEntryRequest struct {
+	Body         string `json:"body" validate:"required,max=10000"`
+	MediaURL     string `json:"media_url" validate:"omitempty,url,max=500"`
+	MediaType    string `json:"media_type" validate:"required_with=MediaURL,omitempty,oneof=image video embed"`
+	MediaCaption string `json:"media_caption" validate:"max=255"`
+	Pinned       bool   `json:"pinned"`
+}
+
+// LiveEntry is a timestamped entry of a live blog
+type LiveEntry struct {
+	ID           uint      `json:"id"`
+	NewsID       uint      `json:"news_id"`
+	Body         string    `json:"body"` // Sanitized HTML
+	MediaURL     string    `json:"media_url"`
+	MediaType    string    `json:"media_type"`
+	MediaCaption string    `json:"media_caption"`
+	Pinned       bool      `json:"pinned"`
+	Author       Author    `json:"author"`
+	UpdatedBy    uint      `json:"updated_by"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// LiveEntryQuery selects a window of the entries of a live blog. After and
+// Before are exclusive entry IDs, when both are zero the latest entries are read.
+// A non-zero Since also reports the entries edited or deleted from then on.
+type LiveEntryQuery struct {
+	After  uint
+	Before uint
+	Since  time.Time
+	Limit  int
+}
+
+// LiveEntriesResponse is a window of the entries of a live blog in ID order.
+// Readers poll with after set to last_id to get the entries added since, and
+// with since set to synced_at to get the entries they hold that changed since.
+type LiveEntriesResponse struct {
+	NewsID   uint         `json:"news_id"`
+	Pinned   []*LiveEntry `json:"pinned"`    // Every pinned entry, newest first
+	Data     []*LiveEntry `json:"data"`      // Oldest first
+	Updated  []*LiveEntry `json:"updated"`   // Entries up to after edited since, oldest change first
+	Deleted  []uint       `json:"deleted"`   // IDs of the entries deleted since
+	LastID   uint         `json:"last_id"`   // Newest entry of the window, after when it is empty
+	HasMore  bool         `json:"has_more"`  // More entries lie beyond the window: newer ones with after, older ones otherwise
+	SyncedAt time.Time    `json:"synced_at"` // Time the response was read, the since of the next poll
+}
@@ -1,61 +1,269 @@
 package entity
 
 import (
+	"errors"
 	"time"
 
 	"github.com/JubaerHossain/rootx/pkg/core/entity"
 )
 
+// ErrNewsNotFound is returned when an article does not exist or is not published
+var ErrNewsNotFound = errors.New("news not found")
+
+// ErrInvalidQuery is returned when request query parameters are missing or malformed
+var ErrInvalidQuery = errors.New("invalid query")
+
 // News represents the news entity
 type News struct {
-	ID        uint      `json:"id"` // Primary key
-	Name      string    `json:"name" validate:"required,min=3,max=100"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
-	Status    bool      `json:"status"`
+	ID               uint               `json:"id"` // Primary key
+	Type             string             `json:"type" validate:"required,max=50"`
+	PathSmall        string             `json:"path_small" validate:"max=255"`
+	PathMedium       string             `json:"path_medium" validate:"max=255"`
+	PathLarge        string             `json:"path_large" validate:"max=255"`
+	PathSmallWebp    string             `json:"path_small_webp"`   // Set by the image upload
+	PathMediumWebp   string             `json:"path_medium_webp"`  // Set by the image upload
+	PathLargeWebp    string             `json:"path_large_webp"`   // Set by the image upload
+	ImagePlaceholder string             `json:"image_placeholder"` // Set by the image upload
+	ImageFiles       []string           `json:"-"`                 // Storage keys of the uploaded image files
+	StatusID         uint               `json:"status_id" validate:"required,gte=1"`
+	PublishStatusID  uint               `json:"publish_status_id"` // Workflow state, always draft on create
+	State            string             `json:"state"`
+	DepartmentID     uint               `json:"department_id"` // Desk the article belongs to, 0 for none
+	PublishAt        *time.Time         `json:"publish_at"`    // When a scheduled article goes live
+	UnpublishAt      *time.Time         `json:"unpublish_at"`  // When a published article comes down
+	CategoryIDs      []uint             `json:"category_ids" validate:"required,min=1,dive,gte=1"`
+	Translations     []*NewsTranslation `json:"translations" validate:"required,min=1,dive"`
+	CreatedBy        uint               `json:"created_by"`
+	UpdatedBy        uint               `json:"updated_by"`
+	CreatedAt        time.Time          `json:"created_at"`
+	UpdatedAt        time.Time          `json:"updated_at"`
+}
+
+// NewsTranslation represents one locale of a news article
+type NewsTranslation struct {
+	Locale          string   `json:"locale" validate:"required,min=2,max=10"`
+	Title           string   `json:"title" validate:"required,min=3,max=191"`
+	Slug            string   `json:"slug" validate:"omitempty,max=191"`
+	SubTitle        string   `json:"sub_title" validate:"max=500"`
+	Excerpt         string   `json:"excerpt"` // Derived on save, the sub title or the start of the content
+	Tags            []string `json:"tags" validate:"dive,max=100"`
+	Content         string   `json:"content" validate:"required"`
+	MetaTitle       string   `json:"meta_title" validate:"max=191"`
+	MetaDescription string   `json:"meta_description" validate:"max=500"`
+	MetaKeywords    []string `json:"meta_keywords" validate:"dive,max=100"`
+}
+
+// NewsTag is a tag as shown with its articles
+type NewsTag struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+	Slug string `json:"slug"`
+}
+
+// TagNewsResponse is a page of the published articles with a tag
+type TagNewsResponse struct {
+	Tag        *NewsTag         `json:"tag"`
+	Locale     string           `json:"locale"` // Negotiated locale of the request
+	Data       []*ScrollNews    `json:"data"`
+	Pagination CursorPagination `json:"pagination"`
+}
+
+// CursorPagination links the neighbouring pages of a list paged with cursors
+type CursorPagination struct {
+	Limit      int     `json:"limit"`
+	NextCursor *string `json:"next_cursor"` // Older articles, null on the last page
+	PrevCursor *string `json:"prev_cursor"` // Newer articles, null on the first page
 }
 
-type Tags struct {
-	ID   uint   `json:"id"` // Primary key
-	Name string `json:"name" validate:"required,min=3,max=100"`
+// NewsListResponse is a page of the latest published articles
+type NewsListResponse struct {
+	Locale     string           `json:"locale"` // Negotiated locale of the request
+	Data       []*ScrollNews    `json:"data"`
+	Pagination CursorPagination `json:"pagination"`
+}
+
+// CategoryNewsResponse is a page of the latest published articles of a category
+type CategoryNewsResponse struct {
+	Category   *NewsCategory    `json:"category"`
+	Locale     string           `json:"locale"` // Negotiated locale of the request
+	Data       []*ScrollNews    `json:"data"`
+	Pagination CursorPagination `json:"pagination"`
 }
 
 type ScrollNews struct {
-	ID           uint     `json:"id"`
-	Title        string   `json:"title"`
-	Slug         string   `json:"slug"`
-	Type         string   `json:"type"`
-	SubTitle     string   `json:"sub_title"`
-	Tags         []string `json:"tags"`
-	Content      string   `json:"content"`
-	MetaTitle    string   `json:"meta_title"`
-	MetaDesc     string   `json:"meta_description"`
-	MetaKeywords []string `json:"meta_keywords"`
-	UpdatedAt    string   `json:"updated_at"`
-	Author       string   `json:"author"`
-	URL          string   `json:"url"`
-	PathSmall    string   `json:"path_small"`
-	PathMedium   string   `json:"path_medium"`
-	PathLarge    string   `json:"path_large"`
-	Loading      string   `json:"loading"`
-	Status       string   `json:"status"`
-	Category     string   `json:"category"`
+	ID             uint             `json:"id"`
+	Locale         string           `json:"locale"` // Locale of the served translation
+	Title          string           `json:"title"`
+	Slug           string           `json:"slug"`
+	Type           string           `json:"type"`
+	SubTitle       string           `json:"sub_title"`
+	Excerpt        string           `json:"excerpt"`
+	Tags           []string         `json:"tags"`
+	Content        string           `json:"content"`
+	MetaTitle      string           `json:"meta_title"`
+	MetaDesc       string           `json:"meta_description"`
+	MetaKeywords   []string         `json:"meta_keywords"`
+	UpdatedAt      string           `json:"updated_at"`
+	CreatedAt      string           `json:"created_at"`
+	PublishedAt    string           `json:"published_at"`
+	Author         string           `json:"author"`
+	URL            string           `json:"url"`
+	PathSmall      string           `json:"path_small"`
+	PathMedium     string           `json:"path_medium"`
+	PathLarge      string           `json:"path_large"`
+	PathSmallWebp  string           `json:"path_small_webp"`
+	PathMediumWebp string           `json:"path_medium_webp"`
+	PathLargeWebp  string           `json:"path_large_webp"`
+	Loading        string           `json:"loading"` // Blur placeholder data URI, or the default loading image
+	Status         string           `json:"status"`
+	Category       string           `json:"category"`
+	CategorySlug   string           `json:"category_slug"`
+	CommentCount   uint             `json:"comment_count"`   // Approved comments
+	Reactions      map[string]int64 `json:"reactions"`       // Totals by reaction key
+	ReactionCount  int64            `json:"reaction_count"`  // Reactions of any kind
+	PollVoteCount  int64            `json:"poll_vote_count"` // Ballots cast in the article's polls
+	SEO            *SEO             `json:"seo,omitempty"`   // With ?include=seo
+}
+
+// Author represents the user who wrote an article
+type Author struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+}
+
+// NewsCategory represents a category an article is assigned to
+type NewsCategory struct {
+	ID    uint   `json:"id"`
+	Title string `json:"title"`
+	Slug  string `json:"slug"`
+}
+
+// ImageRenditions holds the image sizes of an article
+type ImageRenditions struct {
+	Small      string `json:"small"`
+	Medium     string `json:"medium"`
+	Large      string `json:"large"`
+	SmallWebp  string `json:"small_webp"`
+	MediumWebp string `json:"medium_webp"`
+	LargeWebp  string `json:"large_webp"`
+	Loading    string `json:"loading"` // Blur placeholder data URI, or the default loading image
+}
+
+// NewsImage is the set of files generated from an uploaded article image
+type NewsImage struct {
+	PathSmall      string   `json:"path_small"`
+	PathMedium     string   `json:"path_medium"`
+	PathLarge      string   `json:"path_large"`
+	PathSmallWebp  string   `json:"path_small_webp"`
+	PathMediumWebp string   `json:"path_medium_webp"`
+	PathLargeWebp  string   `json:"path_large_webp"`
+	Placeholder    string   `json:"placeholder"` // Data URI of the blurred image
+	Width          int      `json:"width"`       // Of the original
+	Height         int      `json:"height"`
+	Files          []string `json:"-"` // Storage keys of the generated files
+}
+
+// NewsDetails represents a published article with its related articles
+type NewsDetails struct {
+	ID            uint             `json:"id"`
+	Locale        string           `json:"locale"` // Locale of the served translation
+	Title         string           `json:"title"`
+	Slug          string           `json:"slug"`
+	Type          string           `json:"type"`
+	SubTitle      string           `json:"sub_title"`
+	Excerpt       string           `json:"excerpt"`
+	Tags          []string         `json:"tags"`
+	Content       string           `json:"content"`
+	MetaTitle     string           `json:"meta_title"`
+	MetaDesc      string           `json:"meta_description"`
+	MetaKeywords  []string         `json:"meta_keywords"`
+	CreatedAt     string           `json:"created_at"`
+	UpdatedAt     string           `json:"updated_at"`
+	PublishedAt   string           `json:"published_at"`
+	Author        Author           `json:"author"`
+	URL           string           `json:"url"`
+	Images        ImageRenditions  `json:"images"`
+	Categories    []*NewsCategory  `json:"categories"`
+	Related       []*ScrollNews    `json:"related"`
+	Reactions     map[string]int64 `json:"reactions"`       // Totals by reaction key
+	ReactionCount int64            `json:"reaction_count"`  // Reactions of any kind
+	PollVoteCount int64            `json:"poll_vote_count"` // Ballots cast in the article's polls
+	SEO           *SEO             `json:"seo,omitempty"`   // With ?include=seo
+}
+
+// SearchResult represents one ranked article of a search
+type SearchResult struct {
+	ID         uint     `json:"id"`
+	Locale     string   `json:"locale"`
+	Title      string   `json:"title"`
+	Slug       string   `json:"slug"`
+	Type       string   `json:"type"`
+	SubTitle   string   `json:"sub_title"`
+	Tags       []string `json:"tags"`
+	Snippet    string   `json:"snippet"` // HTML escaped, matches wrapped in <mark>
+	URL        string   `json:"url"`
+	PathSmall  string   `json:"path_small"`
+	PathMedium string   `json:"path_medium"`
+	PathLarge  string   `json:"path_large"`
+	CreatedAt  string   `json:"created_at"`
+	Score      float64  `json:"score"`
+}
+
+type SearchResponse struct {
+	Query      string            `json:"query"`
+	Locale     string            `json:"locale"`
+	Data       []*SearchResult   `json:"data"`
+	Pagination entity.Pagination `json:"pagination"`
+}
+
+// Feed formats
+const (
+	FeedRSS  = "rss"
+	FeedAtom = "atom"
+)
+
+// FeedDocument is a rendered XML document, a feed or a sitemap, with its validators
+type FeedDocument struct {
+	ContentType  string    `json:"content_type"`
+	Body         string    `json:"body"`
+	ETag         string    `json:"etag"`
+	LastModified time.Time `json:"last_modified"`
 }
 
 // UpdateNews represents the news update request
 type UpdateNews struct {
-	Name      string    `json:"name" validate:"omitempty,min=3,max=100"`
-	Status    bool      `json:"status"`
-	UpdatedAt time.Time `json:"updated_at"`
+	Type         string             `json:"type" validate:"required,max=50"`
+	PathSmall    string             `json:"path_small" validate:"max=255"`
+	PathMedium   string             `json:"path_medium" validate:"max=255"`
+	PathLarge    string             `json:"path_large" validate:"max=255"`
+	StatusID     uint               `json:"status_id" validate:"required,gte=1"`
+	DepartmentID uint               `json:"department_id"`
+	PublishAt    *time.Time         `json:"publish_at"`
+	UnpublishAt  *time.Time         `json:"unpublish_at"`
+	CategoryIDs  []uint             `json:"category_ids" validate:"required,min=1,dive,gte=1"`
+	Translations []*NewsTranslation `json:"translations" validate:"required,min=1,dive"`
+	UpdatedBy    uint               `json:"updated_by"`
+	UpdatedAt    time.Time          `json:"updated_at"`
+}
+
+// ReplacesImage reports whether the update points the article at other images
+// than the ones it has, which makes the generated variants stale
+func (n *UpdateNews) ReplacesImage(old *News) bool {
+	return old.PathSmall != n.PathSmall || old.PathMedium != n.PathMedium || old.PathLarge != n.PathLarge
 }
 
-// ResponseNews represents the news response
+// ResponseNews represents the news list response
 type ResponseNews struct {
-	ID        uint      `json:"id"`
-	Name      string    `json:"name"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
-	Status    bool      `json:"status"`
+	ID              uint   `json:"id"`
+	Title           string `json:"title"`
+	Slug            string `json:"slug"`
+	Type            string `json:"type"`
+	StatusID        uint   `json:"status_id"`
+	PublishStatusID uint   `json:"publish_status_id"`
+	State           string `json:"state"`
+	DepartmentID    uint   `json:"department_id"`
+	CreatedAt       string `json:"created_at"`
+	UpdatedAt       string `json:"updated_at"`
 }
 
 type NewsResponsePagination struct {
@@ -64,11 +272,32 @@ type NewsResponsePagination struct {
 }
 
 type ScrollNewsResponse struct {
+	Locale     string            `json:"locale"` // Negotiated locale of the request
 	Data       []*ScrollNews     `json:"data"`
 	Pagination entity.Pagination `json:"pagination"`
 }
 
-type ThumbnailNewsResponse struct {
-	Data       []*ScrollNews     `json:"data"`
-	Pagination entity.Pagination `json:"pagination"`
+// PlacementNewsResponse represents the articles served by a homepage placement,
+// pinned articles first
+type PlacementNewsResponse struct {
+	Name   string        `json:"name"`
+	Title  string        `json:"title"`
+	Locale string        `json:"locale"` // Negotiated locale of the request
+	Data   []*ScrollNews `json:"data"`
+}
+
+// Event types of the breaking news stream
+const (
+	LiveEnter  = "enter"  // An article was pinned to a live placement or went live while pinned
+	LiveLeave  = "leave"  // An article left a live placement: unpinned, expired, unpublished or deleted
+	LiveUpdate = "update" // An article pinned to a live placement changed
+	LiveOrder  = "order"  // The pinned articles of a live placement were reordered
+	LiveReset  = "reset"  // Events were missed, the client should reload the placements
+)
+
+// LiveNews is the data of a breaking news stream event. Clients reload the
+// placement from /placements/{name}.
+type LiveNews struct {
+	Placement string `json:"placement"`
+	NewsID    uint   `json:"news_id,omitempty"`
 }
@@ -0,0 +1,44 @@
+package entity
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	// ErrPreviewNotFound is returned for preview links that do not exist or are not signed with the secret
+	ErrPreviewNotFound = errors.New("preview not found")
+	// ErrPreviewExpired is returned for preview links past their expiry or revoked
+	ErrPreviewExpired = errors.New("preview expired")
+)
+
+// CreatePreview is the request to share a revision with a preview link
+type CreatePreview struct {
+	TTL int `json:"ttl" validate:"omitempty,gte=60"` // Seconds the link stays valid, PREVIEW_TTL when empty
+}
+
+// NewsPreviewLink is an issued preview link of a revision
+type NewsPreviewLink struct {
+	ID         uint       `json:"id"`
+	NewsID     uint       `json:"news_id"`
+	RevisionID uint       `json:"revision_id"`
+	Locale     string     `json:"locale"` // Of the revision
+	URL        string     `json:"url"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	RevokedBy  *uint      `json:"revoked_by"`
+	CreatedBy  uint       `json:"created_by"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// Active reports whether the link still opens its preview
+func (l *NewsPreviewLink) Active(now time.Time) bool {
+	return l.RevokedAt == nil && now.Before(l.ExpiresAt)
+}
+
+// NewsPreview is an unpublished revision rendered like a published article
+type NewsPreview struct {
+	RevisionID uint         `json:"revision_id"`
+	ExpiresAt  time.Time    `json:"expires_at"` // Of the link
+	Article    *NewsDetails `json:"article"`
+}
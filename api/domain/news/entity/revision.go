@@ -0,0 +1,74 @@
+package entity
+
+import (
+	"errors"
+	"time"
+
+	"github.com/JubaerHossain/cn-api/pkg/diff"
+)
+
+// ErrRevisionNotFound is returned when a revision does not exist or belongs to another article
+var ErrRevisionNotFound = errors.New("revision not found")
+
+// NewsRevision is an immutable snapshot of one translation, stored on every save
+type NewsRevision struct {
+	ID              uint      `json:"id"`
+	NewsID          uint      `json:"news_id"`
+	Locale          string    `json:"locale"`
+	Number          uint      `json:"number"` // Sequence of the revision within its news and locale
+	Title           string    `json:"title"`
+	Slug            string    `json:"slug"`
+	SubTitle        string    `json:"sub_title"`
+	Tags            []string  `json:"tags"`
+	Content         string    `json:"content"`
+	MetaTitle       string    `json:"meta_title"`
+	MetaDescription string    `json:"meta_description"`
+	MetaKeywords    []string  `json:"meta_keywords"`
+	AuthorID        uint      `json:"author_id"`
+	AuthorName      string    `json:"author_name"`
+	RestoredFrom    *uint     `json:"restored_from"` // Revision this one restored, if any
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// Translation returns the revision's fields as a translation to save
+func (r *NewsRevision) Translation() *NewsTranslation {
+	return &NewsTranslation{
+		Locale:          r.Locale,
+		Title:           r.Title,
+		Slug:            r.Slug,
+		SubTitle:        r.SubTitle,
+		Tags:            r.Tags,
+		Content:         r.Content,
+		MetaTitle:       r.MetaTitle,
+		MetaDescription: r.MetaDescription,
+		MetaKeywords:    r.MetaKeywords,
+	}
+}
+
+// NewsRevisionSummary is a revision in the revision list
+type NewsRevisionSummary struct {
+	ID           uint      `json:"id"`
+	Locale       string    `json:"locale"`
+	Number       uint      `json:"number"`
+	Title        string    `json:"title"`
+	AuthorID     uint      `json:"author_id"`
+	AuthorName   string    `json:"author_name"`
+	RestoredFrom *uint     `json:"restored_from"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// FieldDiff is the change of one field between two revisions. Content changes
+// carry a word-level diff in Ops.
+type FieldDiff struct {
+	Field string      `json:"field"`
+	From  interface{} `json:"from,omitempty"`
+	To    interface{} `json:"to,omitempty"`
+	Ops   []diff.Op   `json:"ops,omitempty"`
+}
+
+// RevisionDiff lists the fields that changed between two revisions
+type RevisionDiff struct {
+	From   *NewsRevisionSummary `json:"from"`
+	To     *NewsRevisionSummary `json:"to"`
+	Fields []*FieldDiff         `json:"fields"`
+}
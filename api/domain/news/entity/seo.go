@@ -0,0 +1,69 @@
+package entity
+
+// IncludeSEO is the include query parameter value that adds SEO metadata to
+// article responses, e.g. ?include=seo
+const IncludeSEO = "seo"
+
+// SEO holds the ready-made metadata of an article page
+type SEO struct {
+	Title       string        `json:"title"`       // meta_title, or the title
+	Description string        `json:"description"` // meta_description, or the excerpt
+	Keywords    []string      `json:"keywords"`    // meta_keywords, or the tags
+	Canonical   string        `json:"canonical"`
+	JSONLD      []interface{} `json:"json_ld"`    // NewsArticle and BreadcrumbList, each for a <script type="application/ld+json">
+	OpenGraph   []MetaTag     `json:"open_graph"` // <meta property="og:…"> and article:… tags
+	Twitter     []MetaTag     `json:"twitter"`    // <meta name="twitter:…"> tags
+}
+
+// MetaTag is a <meta> tag. OpenGraph tags set Property, Twitter tags set Name.
+type MetaTag struct {
+	Property string `json:"property,omitempty"`
+	Name     string `json:"name,omitempty"`
+	Content  string `json:"content"`
+}
+
+// NewsArticleLD is a schema.org NewsArticle
+type NewsArticleLD struct {
+	Context          string         `json:"@context"`
+	Type             string         `json:"@type"`
+	MainEntityOfPage ThingLD        `json:"mainEntityOfPage"`
+	Headline         string         `json:"headline"`
+	Description      string         `json:"description,omitempty"`
+	Image            []string       `json:"image,omitempty"` // Largest rendition first
+	DatePublished    string         `json:"datePublished,omitempty"`
+	DateModified     string         `json:"dateModified,omitempty"`
+	Author           []ThingLD      `json:"author,omitempty"`
+	Publisher        OrganizationLD `json:"publisher"`
+	ArticleSection   []string       `json:"articleSection,omitempty"`
+	Keywords         []string       `json:"keywords,omitempty"`
+	InLanguage       string         `json:"inLanguage,omitempty"`
+}
+
+// ThingLD is a schema.org item referenced by name or by URL
+type ThingLD struct {
+	Type string `json:"@type"`
+	ID   string `json:"@id,omitempty"`
+	Name string `json:"name,omitempty"`
+}
+
+// OrganizationLD is the schema.org Organization publishing the articles
+type OrganizationLD struct {
+	Type string `json:"@type"`
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+// BreadcrumbListLD is a schema.org BreadcrumbList
+type BreadcrumbListLD struct {
+	Context         string       `json:"@context"`
+	Type            string       `json:"@type"`
+	ItemListElement []ListItemLD `json:"itemListElement"`
+}
+
+// ListItemLD is a step of a BreadcrumbList, positions start at 1
+type ListItemLD struct {
+	Type     string `json:"@type"`
+	Position int    `json:"position"`
+	Name     string `json:"name"`
+	Item     string `json:"item"`
+}
@@ -0,0 +1,165 @@
+package entity
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrTransitionNotAllowed is returned when the article's current state has no transition to the requested one
+var ErrTransitionNotAllowed = errors.New("transition not allowed")
+
+// ErrTransitionForbidden is returned when the user's role may not perform the transition
+var ErrTransitionForbidden = errors.New("transition forbidden for role")
+
+// ErrNotOwnArticle is returned when a reporter changes an article they did not
+// write, or one that has left draft and review
+var ErrNotOwnArticle = errors.New("reporters may only change their own draft or in review articles")
+
+// ErrCommentRequired is returned when a transition requires a comment and none was given
+var ErrCommentRequired = errors.New("comment required")
+
+// StatusActive is the status_id of records that are not soft disabled
+const StatusActive uint = 1
+
+// State is an editorial workflow state stored in news.publish_status_id
+type State uint
+
+// Workflow states. Published keeps the value 9 used by existing rows.
+const (
+	StateDraft       State = 1
+	StateInReview    State = 2
+	StateApproved    State = 3
+	StateScheduled   State = 4
+	StateUnpublished State = 5
+	StateArchived    State = 6
+	StatePublished   State = 9
+)
+
+// PublishedFilter restricts a query on the news table to the articles readers can see
+var PublishedFilter = fmt.Sprintf("news.status_id = %d AND news.publish_status_id = %d", StatusActive, StatePublished)
+
+var stateNames = map[State]string{
+	StateDraft:       "draft",
+	StateInReview:    "in_review",
+	StateApproved:    "approved",
+	StateScheduled:   "scheduled",
+	StatePublished:   "published",
+	StateUnpublished: "unpublished",
+	StateArchived:    "archived",
+}
+
+// String returns the API name of the state
+func (s State) String() string {
+	if name, ok := stateNames[s]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+// ParseState returns the state with the given API name
+func ParseState(name string) (State, bool) {
+	for state, stateName := range stateNames {
+		if stateName == name {
+			return state, true
+		}
+	}
+	return 0, false
+}
+
+// Role is a workflow role, mapped from JWT role IDs through WORKFLOW_ROLES
+type Role string
+
+const (
+	RoleReporter Role = "reporter"
+	RoleEditor   Role = "editor"
+	RoleAdmin    Role = "admin"
+
+	// RoleScheduler records transitions made by the publishing scheduler
+	RoleScheduler Role = "scheduler"
+)
+
+// Transition is an allowed move between two states
+type Transition struct {
+	From            State
+	To              State
+	Roles           []Role
+	CommentRequired bool
+}
+
+// Transitions lists every allowed workflow move
+var Transitions = []Transition{
+	{From: StateDraft, To: StateInReview, Roles: []Role{RoleReporter, RoleEditor, RoleAdmin}},
+	{From: StateInReview, To: StateDraft, Roles: []Role{RoleEditor, RoleAdmin}, CommentRequired: true},
+	{From: StateInReview, To: StateApproved, Roles: []Role{RoleEditor, RoleAdmin}},
+	{From: StateApproved, To: StateInReview, Roles: []Role{RoleEditor, RoleAdmin}, CommentRequired: true},
+	{From: StateApproved, To: StateScheduled, Roles: []Role{RoleEditor, RoleAdmin}},
+	{From: StateApproved, To: StatePublished, Roles: []Role{RoleEditor, RoleAdmin}},
+	{From: StateScheduled, To: StateApproved, Roles: []Role{RoleEditor, RoleAdmin}},
+	{From: StateScheduled, To: StatePublished, Roles: []Role{RoleEditor, RoleAdmin}},
+	{From: StatePublished, To: StateUnpublished, Roles: []Role{RoleEditor, RoleAdmin}, CommentRequired: true},
+	{From: StateUnpublished, To: StatePublished, Roles: []Role{RoleEditor, RoleAdmin}},
+	{From: StateUnpublished, To: StateDraft, Roles: []Role{RoleEditor, RoleAdmin}},
+	{From: StateDraft, To: StateArchived, Roles: []Role{RoleAdmin}, CommentRequired: true},
+	{From: StateUnpublished, To: StateArchived, Roles: []Role{RoleAdmin}, CommentRequired: true},
+	{From: StateArchived, To: StateDraft, Roles: []Role{RoleAdmin}, CommentRequired: true},
+}
+
+// FindTransition checks that role may move an article from one state to another with the given comment
+func FindTransition(from, to State, role Role, comment string) (*Transition, error) {
+	for i := range Transitions {
+		transition := &Transitions[i]
+		if transition.From != from || transition.To != to {
+			continue
+		}
+		if !transition.Allows(role) {
+			return nil, ErrTransitionForbidden
+		}
+		if transition.CommentRequired && comment == "" {
+			return nil, ErrCommentRequired
+		}
+		return transition, nil
+	}
+	return nil, ErrTransitionNotAllowed
+}
+
+// Allows reports whether the role may perform the transition
+func (t *Transition) Allows(role Role) bool {
+	for _, allowed := range t.Roles {
+		if allowed == role {
+			return true
+		}
+	}
+	return false
+}
+
+// DeskStates returns the states a role can move articles out of, the role's desk
+func DeskStates(role Role) []State {
+	seen := map[State]bool{}
+	var states []State
+	for _, transition := range Transitions {
+		if transition.Allows(role) && !seen[transition.From] {
+			seen[transition.From] = true
+			states = append(states, transition.From)
+		}
+	}
+	return states
+}
+
+// NewsTransition is the request to move an article to another state
+type NewsTransition struct {
+	State   string `json:"state" validate:"required"`
+	Comment string `json:"comment" validate:"max=1000"`
+}
+
+// NewsTransitionHistory is one recorded workflow transition
+type NewsTransitionHistory struct {
+	ID        uint      `json:"id"`
+	NewsID    uint      `json:"news_id"`
+	From      string    `json:"from"`
+	To        string    `json:"to"`
+	Comment   string    `json:"comment"`
+	UserID    uint      `json:"user_id"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,97 @@
+package persistence
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/JubaerHossain/cn-api/domain/news/entity"
+	"github.com/JubaerHossain/cn-api/pkg/content"
+)
+
+// contentBatchSize is the number of translations cleaned per transaction by SanitizeContent
+const contentBatchSize = 200
+
+// prepareContent runs the content pipeline on translations about to be saved:
+// the HTML is sanitized and the excerpt derived. Content that sanitizes to
+// nothing is refused.
+func (r *NewsRepositoryImpl) prepareContent(translations ...*entity.NewsTranslation) error {
+	for _, translation := range translations {
+		translation.Content = content.Sanitize(translation.Content, r.app.Config.Domain)
+		if strings.TrimSpace(translation.Content) == "" {
+			return fmt.Errorf("%w: content of locale %q is empty once sanitized", entity.ErrInvalidQuery, translation.Locale)
+		}
+		translation.Excerpt = content.Excerpt(translation.SubTitle, translation.Content)
+	}
+	return nil
+}
+
+// SanitizeContent runs the content pipeline on every stored translation and
+// returns the number that changed. Revisions keep what was saved at the time.
+func (r *NewsRepositoryImpl) SanitizeContent(ctx context.Context) (int, error) {
+	changed := 0
+	lastID := uint(0)
+	for {
+		rows, err := r.app.MDB.QueryContext(ctx, `
+			SELECT id, COALESCE(sub_title, ''), excerpt, COALESCE(content, '') FROM news_translations
+			WHERE id > ? ORDER BY id ASC LIMIT ?`, lastID, contentBatchSize)
+		if err != nil {
+			return changed, fmt.Errorf("failed to query translations: %w", err)
+		}
+		type translationContent struct {
+			id               uint
+			content, excerpt string
+		}
+		var (
+			batch []translationContent
+			read  int
+		)
+		for rows.Next() {
+			var subTitle, excerpt, html string
+			if err := rows.Scan(&lastID, &subTitle, &excerpt, &html); err != nil {
+				rows.Close()
+				return changed, fmt.Errorf("failed to scan row: %w", err)
+			}
+			read++
+			sanitized := content.Sanitize(html, r.app.Config.Domain)
+			derived := content.Excerpt(subTitle, sanitized)
+			if sanitized != html || derived != excerpt {
+				batch = append(batch, translationContent{id: lastID, content: sanitized, excerpt: derived})
+			}
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return changed, fmt.Errorf("rows iteration error: %w", err)
+		}
+		if read == 0 {
+			break
+		}
+		if len(batch) == 0 {
+			continue
+		}
+
+		tx, err := r.app.MDB.BeginTx(ctx, nil)
+		if err != nil {
+			return changed, err
+		}
+		for _, item := range batch {
+			if _, err := tx.ExecContext(ctx, "UPDATE news_translations SET content = ?, excerpt = ? WHERE id = ?", item.content, item.excerpt, item.id); err != nil {
+				tx.Rollback()
+				return changed, fmt.Errorf("failed to update translation %d: %w", item.id, err)
+			}
+		}
+		if err := tx.Commit(); err != nil {
+			return changed, err
+		}
+		changed += len(batch)
+	}
+
+	if changed > 0 {
+		// CacheClear only uses the request for its context
+		if err := CacheClear((&http.Request{}).WithContext(ctx), r.app.Cache); err != nil {
+			return changed, err
+		}
+	}
+	return changed, nil
+}
@@ -0,0 +1,301 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/JubaerHossain/cn-api/domain/news/entity"
+	"github.com/JubaerHossain/cn-api/pkg/locale"
+)
+
+// relatedLimit is the number of related articles returned with an article
+const relatedLimit = 5
+
+// GetNewsBySlug returns a published article by its slug. When the slug is an old
+// one the article's current slug is returned instead so the caller can redirect.
+func (r *NewsRepositoryImpl) GetNewsBySlug(req *http.Request, slug string) (*entity.NewsDetails, string, error) {
+	ctx := req.Context()
+	chain := locale.Chain(locale.Negotiate(req))
+	cacheKey := fmt.Sprintf("get_news_detail_%s_%s", slug, strings.Join(chain, "-"))
+
+	// Check cache first
+	if cachedData, errCache := r.app.Cache.Get(ctx, cacheKey); errCache == nil && cachedData != "" {
+		news := &entity.NewsDetails{}
+		if err := json.Unmarshal([]byte(cachedData), news); err != nil {
+			return nil, "", fmt.Errorf("failed to unmarshal cached data: %w", err)
+		}
+		return news, "", nil
+	}
+
+	// Resolve the slug, preferring translations along the requested locale chain
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(chain)), ",")
+	args := []interface{}{slug}
+	for i := 0; i < 2; i++ {
+		for _, l := range chain {
+			args = append(args, l)
+		}
+	}
+	var (
+		newsID     uint
+		slugLocale string
+	)
+	err := r.app.MDB.QueryRowContext(ctx, fmt.Sprintf(`
+		SELECT news_id, locale FROM news_translations
+		WHERE slug = ?
+		ORDER BY FIELD(locale, %[1]s) = 0, FIELD(locale, %[1]s)
+		LIMIT 1`, placeholders), args...).Scan(&newsID, &slugLocale)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, r.currentSlug(req, slug), entity.ErrNewsNotFound
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to resolve slug: %w", err)
+	}
+
+	news, err := r.getPublishedNews(req, newsID, slugLocale)
+	if err != nil {
+		return nil, "", err
+	}
+
+	related, err := r.getRelatedNews(req, news, chain)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get related news: %w", err)
+	}
+	news.Related = related
+
+	// Cache the response
+	jsonData, err := json.Marshal(news)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to marshal response: %w", err)
+	}
+	cacheDuration := time.Duration(r.app.Config.RedisExp) * time.Second
+	if err := r.app.Cache.Set(ctx, cacheKey, string(jsonData), cacheDuration); err != nil {
+		return nil, "", fmt.Errorf("failed to set cache: %w", err)
+	}
+
+	return news, "", nil
+}
+
+// currentSlug looks up an old slug in news_slug_redirects and returns the current
+// slug of the published article it belonged to, or an empty string
+func (r *NewsRepositoryImpl) currentSlug(req *http.Request, oldSlug string) string {
+	var slug string
+	err := r.app.MDB.QueryRowContext(req.Context(), `
+		SELECT news_translations.slug
+		FROM news_slug_redirects
+		JOIN news ON news.id = news_slug_redirects.news_id
+		JOIN news_translations ON news_translations.news_id = news.id AND news_translations.locale = news_slug_redirects.locale
+		WHERE news_slug_redirects.slug = ? AND `+entity.PublishedFilter+`
+		LIMIT 1`, oldSlug).Scan(&slug)
+	if err != nil {
+		return ""
+	}
+	return slug
+}
+
+// getPublishedNews loads a published article in the given locale with its author and categories
+func (r *NewsRepositoryImpl) getPublishedNews(req *http.Request, newsID uint, newsLocale string) (*entity.NewsDetails, error) {
+	ctx := req.Context()
+	var (
+		news          entity.NewsDetails
+		meta_keywords sql.NullString
+		placeholder   string
+		reactions     sql.NullString
+	)
+	err := r.app.MDB.QueryRowContext(ctx, `
+		SELECT
+		    news.id,
+		    news_translations.locale,
+		    news_translations.title,
+		    news_translations.slug,
+		    news.type,
+		    COALESCE(news_translations.sub_title, ''),
+		    news_translations.excerpt,
+		    COALESCE(news_translations.content, ''),
+		    COALESCE(news_translations.meta_title, ''),
+		    COALESCE(news_translations.meta_description, ''),
+		    news_translations.meta_keywords,
+		    news.created_at,
+		    news.updated_at,
+		    COALESCE(news.published_at, news.created_at),
+		    users.id,
+		    users.name,
+		    COALESCE(news.path_small, ''),
+		    COALESCE(news.path_medium, ''),
+		    COALESCE(news.path_large, ''),
+		    COALESCE(news.path_small_webp, ''),
+		    COALESCE(news.path_medium_webp, ''),
+		    COALESCE(news.path_large_webp, ''),
+		    COALESCE(news.image_placeholder, ''),
+		    news.reactions,
+		    news.reaction_count,
+		    news.poll_vote_count
+		FROM news
+		JOIN news_translations ON news.id = news_translations.news_id
+		JOIN users ON news.created_by = users.id
+		WHERE news.id = ? AND news_translations.locale = ? AND `+entity.PublishedFilter,
+		newsID, newsLocale,
+	).Scan(
+		&news.ID,
+		&news.Locale,
+		&news.Title,
+		&news.Slug,
+		&news.Type,
+		&news.SubTitle,
+		&news.Excerpt,
+		&news.Content,
+		&news.MetaTitle,
+		&news.MetaDesc,
+		&meta_keywords,
+		&news.CreatedAt,
+		&news.UpdatedAt,
+		&news.PublishedAt,
+		&news.Author.ID,
+		&news.Author.Name,
+		&news.Images.Small,
+		&news.Images.Medium,
+		&news.Images.Large,
+		&news.Images.SmallWebp,
+		&news.Images.MediumWebp,
+		&news.Images.LargeWebp,
+		&placeholder,
+		&reactions,
+		&news.ReactionCount,
+		&news.PollVoteCount,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, entity.ErrNewsNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get news: %w", err)
+	}
+	if err := unmarshalList(meta_keywords, &news.MetaKeywords); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal meta keywords: %w", err)
+	}
+	if err := unmarshalCounts(reactions.String, &news.Reactions); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal reactions: %w", err)
+	}
+	tags, err := r.tagsOf(ctx, []uint{news.ID})
+	if err != nil {
+		return nil, err
+	}
+	news.Tags = tags[news.ID][news.Locale]
+	if news.Tags == nil {
+		news.Tags = []string{}
+	}
+	news.URL = fmt.Sprintf("%s/news/%s", r.app.Config.Domain, news.Slug)
+	news.Images.Loading = r.loadingImage(placeholder)
+
+	if news.Categories, err = r.newsCategories(ctx, newsID); err != nil {
+		return nil, err
+	}
+	return &news, nil
+}
+
+// newsCategories loads the categories of an article in their display order
+func (r *NewsRepositoryImpl) newsCategories(ctx context.Context, newsID uint) ([]*entity.NewsCategory, error) {
+	rows, err := r.app.MDB.QueryContext(ctx, `
+		SELECT news_categories.id, COALESCE(news_categories.title, ''), news_categories.slug
+		FROM assign_categories
+		JOIN news_categories ON assign_categories.news_category_id = news_categories.id
+		WHERE assign_categories.news_id = ?
+		ORDER BY news_categories.order ASC`, newsID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query categories: %w", err)
+	}
+	defer rows.Close()
+
+	categories := []*entity.NewsCategory{}
+	for rows.Next() {
+		var category entity.NewsCategory
+		if err := rows.Scan(&category.ID, &category.Title, &category.Slug); err != nil {
+			return nil, fmt.Errorf("failed to scan category: %w", err)
+		}
+		categories = append(categories, &category)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return categories, nil
+}
+
+// getRelatedNews ranks other published articles by the number of shared
+// categories (weighted double) and shared tags
+func (r *NewsRepositoryImpl) getRelatedNews(req *http.Request, news *entity.NewsDetails, chain []string) ([]*entity.ScrollNews, error) {
+	var (
+		terms []string
+		args  []interface{}
+	)
+	if len(news.Categories) > 0 {
+		placeholders := strings.TrimSuffix(strings.Repeat("?,", len(news.Categories)), ",")
+		terms = append(terms, fmt.Sprintf(
+			"2 * (SELECT COUNT(*) FROM assign_categories ac WHERE ac.news_id = news.id AND ac.news_category_id IN (%s))", placeholders))
+		for _, category := range news.Categories {
+			args = append(args, category.ID)
+		}
+	}
+	if len(news.Tags) > 0 {
+		terms = append(terms, `(
+			SELECT COUNT(DISTINCT nt.tag_id) FROM news_tags nt
+			JOIN news_tags own ON own.tag_id = nt.tag_id AND own.news_id = ? AND own.locale = ?
+			WHERE nt.news_id = news.id)`)
+		args = append(args, news.ID, news.Locale)
+	}
+	if len(terms) == 0 {
+		return []*entity.ScrollNews{}, nil
+	}
+
+	query := fmt.Sprintf(`
+		SELECT news.id, %s AS score
+		FROM news
+		JOIN news_translations ON news.id = news_translations.news_id AND news_translations.locale = ?
+		WHERE news.id <> ? AND %s
+		HAVING score > 0
+		ORDER BY score DESC, news.id DESC
+		LIMIT ?`, strings.Join(terms, " + "), entity.PublishedFilter)
+	args = append(args, news.Locale, news.ID, relatedLimit)
+
+	rows, err := r.app.MDB.QueryContext(req.Context(), query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	scores := map[uint]int{}
+	var ids []uint
+	for rows.Next() {
+		var (
+			id    uint
+			score int
+		)
+		if err := rows.Scan(&id, &score); err != nil {
+			return nil, err
+		}
+		scores[id] = score
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return []*entity.ScrollNews{}, nil
+	}
+
+	related, err := r.newsByIDs(req.Context(), chain, "", ids)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(related, func(i, j int) bool {
+		if scores[related[i].ID] != scores[related[j].ID] {
+			return scores[related[i].ID] > scores[related[j].ID]
+		}
+		return related[i].ID > related[j].ID
+	})
+	return related, nil
+}
@@ -0,0 +1,109 @@
+package persistence
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/JubaerHossain/cn-api/domain/news/entity"
+	"github.com/JubaerHossain/cn-api/pkg/locale"
+	"github.com/JubaerHossain/cn-api/pkg/ranking"
+)
+
+// GetDigest returns the top articles of each category published since the
+// query time. Articles are ranked by their views in the query window, and the
+// latest articles fill the sections the ranking leaves short. Categories
+// without articles are left out.
+func (r *NewsRepositoryImpl) GetDigest(ctx context.Context, query *entity.DigestQuery) ([]*entity.DigestSection, error) {
+	categories, err := r.digestCategories(ctx, query.Categories)
+	if err != nil {
+		return nil, err
+	}
+
+	locales := locale.Chain(query.Locale)
+	publishedSince := query.Since.UTC().Format(time.DateTime)
+	seen := map[uint]bool{}
+	sections := []*entity.DigestSection{}
+	for _, category := range categories {
+		ids, err := r.rankings().Top(ctx, query.Window, fmt.Sprintf("category:%d", category.ID), query.PerCategory+rankingSlack,
+			func(ctx context.Context, since time.Time) ([]ranking.Bucket, error) {
+				return r.viewBuckets(ctx, since, category.ID)
+			})
+		if err != nil {
+			return nil, err
+		}
+
+		where := fmt.Sprintf(" AND %s AND news_categories.id = ? AND COALESCE(news.published_at, news.created_at) >= ?", entity.PublishedFilter)
+		var ranked []*entity.ScrollNews
+		if len(ids) > 0 {
+			args := []interface{}{category.ID, publishedSince}
+			for _, id := range ids {
+				args = append(args, id)
+			}
+			list, err := r.newsList(ctx, locales, where+fmt.Sprintf(" AND news.id IN (%s)", strings.TrimSuffix(strings.Repeat("?,", len(ids)), ",")),
+				uint(len(ids)), args...)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get news list: %w", err)
+			}
+			ranked = rankNewsList(list, ids, len(ids))
+		}
+		// The latest articles fill in for articles nobody read yet
+		latest, err := r.newsList(ctx, locales, where, uint(query.PerCategory+len(ranked)), category.ID, publishedSince)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get news list: %w", err)
+		}
+
+		section := &entity.DigestSection{Category: category.Title, CategorySlug: category.Slug, News: []*entity.ScrollNews{}}
+		for _, news := range append(ranked, latest...) {
+			if len(section.News) == query.PerCategory {
+				break
+			}
+			if seen[news.ID] {
+				continue
+			}
+			seen[news.ID] = true
+			section.News = append(section.News, news)
+		}
+		if len(section.News) > 0 {
+			sections = append(sections, section)
+		}
+	}
+	return sections, nil
+}
+
+// digestCategories returns the active categories with the slugs, in their
+// order, or every active category in display order when slugs is empty
+func (r *NewsRepositoryImpl) digestCategories(ctx context.Context, slugs []string) ([]*entity.NewsCategory, error) {
+	query := "SELECT id, COALESCE(title, ''), slug FROM news_categories WHERE status_id = ?"
+	args := []interface{}{entity.StatusActive}
+	if len(slugs) > 0 {
+		placeholders := strings.TrimSuffix(strings.Repeat("?,", len(slugs)), ",")
+		query += fmt.Sprintf(" AND slug IN (%[1]s) ORDER BY FIELD(slug, %[1]s)", placeholders)
+		for i := 0; i < 2; i++ {
+			for _, slug := range slugs {
+				args = append(args, slug)
+			}
+		}
+	} else {
+		query += " ORDER BY `order` ASC, id ASC"
+	}
+	rows, err := r.app.MDB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query categories: %w", err)
+	}
+	defer rows.Close()
+
+	categories := []*entity.NewsCategory{}
+	for rows.Next() {
+		var category entity.NewsCategory
+		if err := rows.Scan(&category.ID, &category.Title, &category.Slug); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		categories = append(categories, &category)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return categories, nil
+}
@@ -0,0 +1,123 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/JubaerHossain/cn-api/domain/news/entity"
+)
+
+// ExportNews reads the articles selected by the filter in ID order and passes
+// them one by one to fn. Rows are read from a single cursor as fn consumes
+// them, so only the current article is held in memory. Reading stops at the
+// first error of fn or when the context is cancelled.
+func (r *NewsRepositoryImpl) ExportNews(ctx context.Context, filter *entity.ExportFilter, fn func(*entity.ExportedNews) error) error {
+	var (
+		filters []string
+		args    []interface{}
+	)
+	if filter.From != nil {
+		filters = append(filters, "COALESCE(news.published_at, news.created_at) >= ?")
+		args = append(args, nullableTime(filter.From))
+	}
+	if filter.To != nil {
+		filters = append(filters, "COALESCE(news.published_at, news.created_at) < ?")
+		args = append(args, nullableTime(filter.To))
+	}
+	if filter.Category != "" {
+		filters = append(filters, `EXISTS (
+			SELECT 1 FROM assign_categories
+			JOIN news_categories ON news_categories.id = assign_categories.news_category_id
+			WHERE assign_categories.news_id = news.id AND news_categories.slug = ?)`)
+		args = append(args, filter.Category)
+	}
+	if filter.Locale != "" {
+		filters = append(filters, "news_translations.locale = ?")
+		args = append(args, filter.Locale)
+	}
+	filterQuery := ""
+	if len(filters) > 0 {
+		filterQuery = " WHERE " + strings.Join(filters, " AND ")
+	}
+
+	// Translations of an article come together, they are grouped as they are read
+	rows, err := r.app.MDB.QueryContext(ctx, `
+		SELECT news.id, news.type, news.status_id, COALESCE(news.publish_status_id, 0), COALESCE(news.department_id, 0),
+		       COALESCE(users.id, 0), COALESCE(users.name, ''),
+		       (SELECT JSON_ARRAYAGG(news_categories.title)
+		        FROM assign_categories
+		        JOIN news_categories ON news_categories.id = assign_categories.news_category_id
+		        WHERE assign_categories.news_id = news.id),
+		       COALESCE(news.path_small, ''), COALESCE(news.path_medium, ''), COALESCE(news.path_large, ''),
+		       news.publish_at, news.unpublish_at, news.published_at, news.created_at, news.updated_at,
+		       news_translations.locale, news_translations.title, news_translations.slug,
+		       COALESCE(news_translations.sub_title, ''), news_translations.excerpt, COALESCE(news_translations.content, ''),
+		       news_translations.tags, COALESCE(news_translations.meta_title, ''),
+		       COALESCE(news_translations.meta_description, ''), news_translations.meta_keywords
+		FROM news
+		JOIN news_translations ON news_translations.news_id = news.id
+		LEFT JOIN users ON users.id = news.created_by`+filterQuery+`
+		ORDER BY news.id ASC, news_translations.locale ASC`, args...)
+	if err != nil {
+		return fmt.Errorf("failed to query export: %w", err)
+	}
+	defer rows.Close()
+
+	var current *entity.ExportedNews
+	for rows.Next() {
+		var (
+			news                                                      entity.ExportedNews
+			translation                                               entity.NewsTranslation
+			categories, tags, metaKeywords                            sql.NullString
+			publishAt, unpublishAt, publishedAt, createdAt, updatedAt sql.NullString
+			publishStatusID                                           uint
+		)
+		if err := rows.Scan(&news.ID, &news.Type, &news.StatusID, &publishStatusID, &news.DepartmentID,
+			&news.Author.ID, &news.Author.Name, &categories,
+			&news.PathSmall, &news.PathMedium, &news.PathLarge,
+			&publishAt, &unpublishAt, &publishedAt, &createdAt, &updatedAt,
+			&translation.Locale, &translation.Title, &translation.Slug,
+			&translation.SubTitle, &translation.Excerpt, &translation.Content,
+			&tags, &translation.MetaTitle, &translation.MetaDescription, &metaKeywords,
+		); err != nil {
+			return fmt.Errorf("failed to scan export: %w", err)
+		}
+		if err := unmarshalList(tags, &translation.Tags); err != nil {
+			return fmt.Errorf("failed to unmarshal tags: %w", err)
+		}
+		if err := unmarshalList(metaKeywords, &translation.MetaKeywords); err != nil {
+			return fmt.Errorf("failed to unmarshal meta keywords: %w", err)
+		}
+
+		if current != nil && current.ID == news.ID {
+			current.Translations = append(current.Translations, &translation)
+			continue
+		}
+		if current != nil {
+			if err := fn(current); err != nil {
+				return err
+			}
+		}
+
+		if err := unmarshalList(categories, &news.Categories); err != nil {
+			return fmt.Errorf("failed to unmarshal categories: %w", err)
+		}
+		news.State = entity.State(publishStatusID).String()
+		news.PublishAt = parseNullDateTime(publishAt)
+		news.UnpublishAt = parseNullDateTime(unpublishAt)
+		news.PublishedAt = parseNullDateTime(publishedAt)
+		news.CreatedAt = parseDateTime(createdAt)
+		news.UpdatedAt = parseDateTime(updatedAt)
+		news.Translations = []*entity.NewsTranslation{&translation}
+		current = &news
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("rows iteration error: %w", err)
+	}
+	if current != nil {
+		return fn(current)
+	}
+	return nil
+}
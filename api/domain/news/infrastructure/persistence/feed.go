@@ -0,0 +1,187 @@
+package persistence
+
+import (
+	"crypto/sha1"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/JubaerHossain/cn-api/domain/news/entity"
+	"github.com/JubaerHossain/cn-api/pkg/feed"
+	"github.com/JubaerHossain/cn-api/pkg/locale"
+)
+
+// feedSize is the number of articles in a feed
+const feedSize = 50
+
+// GetFeed returns the rendered feed of the latest published articles, limited to
+// one category when categorySlug is set
+func (r *NewsRepositoryImpl) GetFeed(req *http.Request, format, categorySlug string) (*entity.FeedDocument, error) {
+	ctx := req.Context()
+	feedLocale := locale.Negotiate(req)
+	cacheKey := fmt.Sprintf("feed_%s_%s_%s", format, categorySlug, feedLocale)
+
+	// Check cache first
+	if cachedData, errCache := r.app.Cache.Get(ctx, cacheKey); errCache == nil && cachedData != "" {
+		document := &entity.FeedDocument{}
+		if err := json.Unmarshal([]byte(cachedData), document); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal cached data: %w", err)
+		}
+		return document, nil
+	}
+
+	// RequestURI still carries the router prefix that StripPrefix removed from URL.Path
+	domain := strings.TrimSuffix(r.app.Config.Domain, "/")
+	selfPath, _, _ := strings.Cut(req.RequestURI, "?")
+	f := &feed.Feed{
+		Title:       "Latest news",
+		Link:        domain,
+		SelfLink:    domain + selfPath,
+		Description: "The latest published articles",
+		Language:    feedLocale,
+	}
+
+	where := " AND " + entity.PublishedFilter
+	var args []interface{}
+	if categorySlug != "" {
+		var title sql.NullString
+		err := r.app.MDB.QueryRowContext(ctx, "SELECT title FROM news_categories WHERE slug = ? AND status_id = ?", categorySlug, entity.StatusActive).Scan(&title)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, entity.ErrNewsNotFound
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get category: %w", err)
+		}
+		f.Title = fmt.Sprintf("%s news", title.String)
+		f.Description = fmt.Sprintf("The latest published articles in %s", title.String)
+		where += ` AND news.id IN (
+		    SELECT ac.news_id FROM assign_categories ac
+		    JOIN news_categories nc ON nc.id = ac.news_category_id
+		    WHERE nc.slug = ?)`
+		args = append(args, categorySlug)
+	}
+
+	// The latest articles are picked by publication date, then loaded with
+	// every category they are listed in
+	rows, err := r.app.MDB.QueryContext(ctx, fmt.Sprintf(`
+		SELECT news.id FROM news
+		WHERE news.published_at IS NOT NULL %s
+		ORDER BY news.published_at DESC, news.id DESC
+		LIMIT ?`, where), append(args, feedSize)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get feed articles: %w", err)
+	}
+	defer rows.Close()
+	var ids []interface{}
+	for rows.Next() {
+		var id uint
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	var list []*entity.ScrollNews
+	if len(ids) > 0 {
+		placeholders := strings.TrimSuffix(strings.Repeat("?,", len(ids)), ",")
+		list, err = r.GetNewsList(req, locale.Chain(feedLocale), fmt.Sprintf(" AND news.id IN (%s)", placeholders), 0, ids...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get news list: %w", err)
+		}
+	}
+
+	// GetNewsList returns one row per category, merge them per article
+	items := map[uint]*feed.Item{}
+	for _, news := range list {
+		if item, ok := items[news.ID]; ok {
+			item.Categories = append(item.Categories, news.Category)
+			continue
+		}
+		item := &feed.Item{
+			Title:       news.Title,
+			Link:        news.URL,
+			GUID:        news.URL,
+			Description: news.Excerpt,
+			Author:      news.Author,
+			Categories:  []string{news.Category},
+			Published:   parseDateTime(sql.NullString{String: news.PublishedAt, Valid: true}),
+			Updated:     parseDateTime(sql.NullString{String: news.UpdatedAt, Valid: true}),
+		}
+		if news.PathLarge != "" {
+			imageURL := r.assetURL(news.PathLarge)
+			item.Media = &feed.Media{URL: imageURL, Type: imageType(imageURL), Medium: "image"}
+		}
+		if item.Updated.After(f.Updated) {
+			f.Updated = item.Updated
+		}
+		items[news.ID] = item
+	}
+	for _, id := range ids {
+		if item, ok := items[id.(uint)]; ok {
+			f.Items = append(f.Items, item)
+		}
+	}
+	if f.Updated.IsZero() {
+		f.Updated = time.Now().UTC()
+	}
+
+	var body []byte
+	document := &entity.FeedDocument{LastModified: f.Updated.UTC().Truncate(time.Second)}
+	switch format {
+	case entity.FeedAtom:
+		body, err = feed.Atom(f)
+		document.ContentType = "application/atom+xml; charset=utf-8"
+	default:
+		body, err = feed.RSS(f)
+		document.ContentType = "application/rss+xml; charset=utf-8"
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to render feed: %w", err)
+	}
+	sum := sha1.Sum(body)
+	document.Body = string(body)
+	document.ETag = fmt.Sprintf(`"%s"`, hex.EncodeToString(sum[:10]))
+
+	// Cache the response
+	jsonData, err := json.Marshal(document)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal response: %w", err)
+	}
+	cacheDuration := time.Duration(r.app.Config.RedisExp) * time.Second
+	if err := r.app.Cache.Set(ctx, cacheKey, string(jsonData), cacheDuration); err != nil {
+		return nil, fmt.Errorf("failed to set cache: %w", err)
+	}
+
+	return document, nil
+}
+
+// assetURL turns a stored file path into an absolute URL
+func (r *NewsRepositoryImpl) assetURL(p string) string {
+	return AssetURL(r.app.Config.Domain, p)
+}
+
+// AssetURL turns a stored file path into an absolute URL on the domain
+func AssetURL(domain, p string) string {
+	if strings.HasPrefix(p, "http://") || strings.HasPrefix(p, "https://") {
+		return p
+	}
+	return fmt.Sprintf("%s/uploads/%s", strings.TrimSuffix(domain, "/"), strings.TrimPrefix(p, "/"))
+}
+
+// imageType guesses the MIME type of an image from its extension
+func imageType(url string) string {
+	if t := mime.TypeByExtension(strings.ToLower(path.Ext(url))); t != "" {
+		return t
+	}
+	return "image/jpeg"
+}
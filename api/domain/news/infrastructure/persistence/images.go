@@ -0,0 +1,70 @@
+package persistence
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/JubaerHossain/cn-api/domain/news/entity"
+)
+
+// SetNewsImage points the article at the generated image files. Returns the
+// storage keys of the files it used before, for the caller to delete.
+func (r *NewsRepositoryImpl) SetNewsImage(newsID uint, image *entity.NewsImage, req *http.Request) ([]string, error) {
+	ctx := req.Context()
+	tx, err := r.app.MDB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var previous sql.NullString
+	err = tx.QueryRowContext(ctx, "SELECT image_files FROM news WHERE id = ? FOR UPDATE", newsID).Scan(&previous)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, entity.ErrNewsNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get news: %w", err)
+	}
+	var oldFiles []string
+	if err := unmarshalList(previous, &oldFiles); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal image files: %w", err)
+	}
+
+	files, err := json.Marshal(image.Files)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal image files: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE news
+		SET path_small = ?, path_medium = ?, path_large = ?,
+		    path_small_webp = ?, path_medium_webp = ?, path_large_webp = ?,
+		    image_placeholder = ?, image_files = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ?`,
+		image.PathSmall, image.PathMedium, image.PathLarge,
+		image.PathSmallWebp, image.PathMediumWebp, image.PathLargeWebp,
+		image.Placeholder, string(files), newsID,
+	); err != nil {
+		return nil, fmt.Errorf("failed to update news image: %w", err)
+	}
+	if err := markSitemapsStale(req, tx, newsID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	// Clear cache
+	return oldFiles, CacheClear(req, r.app.Cache)
+}
+
+// loadingImage returns the blur placeholder of an article, or the default loading image
+func (r *NewsRepositoryImpl) loadingImage(placeholder string) string {
+	if placeholder != "" {
+		return placeholder
+	}
+	return fmt.Sprintf("%s/uploads/%s", r.app.Config.Domain, "default/loading.png")
+}
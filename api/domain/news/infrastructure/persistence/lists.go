@@ -0,0 +1,188 @@
+package persistence
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/JubaerHossain/cn-api/domain/news/entity"
+	"github.com/JubaerHossain/cn-api/pkg/auth"
+	"github.com/JubaerHossain/cn-api/pkg/config"
+	"github.com/JubaerHossain/cn-api/pkg/cursor"
+	"github.com/JubaerHossain/cn-api/pkg/locale"
+)
+
+// GetLatestNews returns a page of the published articles, newest first
+func (r *NewsRepositoryImpl) GetLatestNews(req *http.Request, token string, limit int) (*entity.NewsListResponse, error) {
+	ctx := req.Context()
+	requested := locale.Negotiate(req)
+	cacheKey := fmt.Sprintf("news_list_%s_%d_%s", requested, limit, token)
+
+	// Check cache first
+	if cachedData, errCache := r.app.Cache.Get(ctx, cacheKey); errCache == nil && cachedData != "" {
+		response := &entity.NewsListResponse{}
+		if err := json.Unmarshal([]byte(cachedData), response); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal cached data: %w", err)
+		}
+		return response, nil
+	}
+
+	newsList, pagination, err := r.publishedPage(req, requested, "", "", nil, token, limit)
+	if err != nil {
+		return nil, err
+	}
+	response := &entity.NewsListResponse{
+		Locale:     requested,
+		Data:       newsList,
+		Pagination: pagination,
+	}
+
+	// Cache the response
+	if err := r.cacheResponse(req, cacheKey, response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// GetCategoryNews returns a page of the published articles of a category, newest first
+func (r *NewsRepositoryImpl) GetCategoryNews(req *http.Request, slug, token string, limit int) (*entity.CategoryNewsResponse, error) {
+	ctx := req.Context()
+	requested := locale.Negotiate(req)
+	cacheKey := fmt.Sprintf("category_news_%s_%s_%d_%s", slug, requested, limit, token)
+
+	// Check cache first
+	if cachedData, errCache := r.app.Cache.Get(ctx, cacheKey); errCache == nil && cachedData != "" {
+		response := &entity.CategoryNewsResponse{}
+		if err := json.Unmarshal([]byte(cachedData), response); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal cached data: %w", err)
+		}
+		return response, nil
+	}
+
+	var category entity.NewsCategory
+	err := r.app.MDB.QueryRowContext(ctx, "SELECT id, COALESCE(title, ''), slug FROM news_categories WHERE slug = ? AND status_id = ?", slug, entity.StatusActive).
+		Scan(&category.ID, &category.Title, &category.Slug)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, entity.ErrNewsNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get category: %w", err)
+	}
+
+	newsList, pagination, err := r.publishedPage(req, requested,
+		" JOIN assign_categories ON assign_categories.news_id = news.id",
+		" AND assign_categories.news_category_id = ?", []interface{}{category.ID},
+		token, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	response := &entity.CategoryNewsResponse{
+		Category:   &category,
+		Locale:     requested,
+		Data:       newsList,
+		Pagination: pagination,
+	}
+
+	// Cache the response
+	if err := r.cacheResponse(req, cacheKey, response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// publishedPage returns the page of published articles after the cursor token,
+// or the first page when it is empty. join and where narrow the articles, args
+// are bound to the placeholders in where. Articles are ordered by publication
+// date then ID, so the pages stay put when articles are published.
+func (r *NewsRepositoryImpl) publishedPage(req *http.Request, requested, join, where string, args []interface{}, token string, limit int) ([]*entity.ScrollNews, entity.CursorPagination, error) {
+	ctx := req.Context()
+	pagination := entity.CursorPagination{Limit: limit}
+	secret := r.cursorSecret()
+
+	var after *cursor.Cursor
+	if token != "" {
+		c, err := cursor.Decode(secret, token)
+		if err != nil {
+			return nil, pagination, fmt.Errorf("%w: %v", entity.ErrInvalidQuery, err)
+		}
+		after = &c
+	}
+
+	// A previous page is read towards newer articles, then turned around
+	order, position := "DESC", ""
+	queryArgs := append([]interface{}{}, args...)
+	if after != nil {
+		at := after.Time.Format("2006-01-02 15:04:05")
+		compare := "<"
+		if after.Direction == cursor.Prev {
+			order, compare = "ASC", ">"
+		}
+		position = fmt.Sprintf(" AND (news.published_at %[1]s ? OR (news.published_at = ? AND news.id %[1]s ?))", compare)
+		queryArgs = append(queryArgs, at, at, after.ID)
+	}
+	rows, err := r.app.MDB.QueryContext(ctx, fmt.Sprintf(`
+		SELECT DISTINCT news.id, news.published_at
+		FROM news%s
+		WHERE %s AND news.published_at IS NOT NULL%s%s
+		ORDER BY news.published_at %s, news.id %s
+		LIMIT %d`, join, entity.PublishedFilter, where, position, order, order, limit+1), queryArgs...)
+	if err != nil {
+		return nil, pagination, fmt.Errorf("failed to query news page: %w", err)
+	}
+	defer rows.Close()
+
+	var items []cursor.Item
+	for rows.Next() {
+		var (
+			item        cursor.Item
+			publishedAt sql.NullString
+		)
+		if err := rows.Scan(&item.ID, &publishedAt); err != nil {
+			return nil, pagination, fmt.Errorf("failed to scan row: %w", err)
+		}
+		item.Time = parseDateTime(publishedAt)
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, pagination, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	items, next, prev := cursor.Page(secret, after, items, limit)
+	if next != "" {
+		pagination.NextCursor = &next
+	}
+	if prev != "" {
+		pagination.PrevCursor = &prev
+	}
+
+	ids := make([]uint, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.ID)
+	}
+	newsList, err := r.newsByIDs(req.Context(), locale.Chain(requested), "", ids)
+	if err != nil {
+		return nil, pagination, fmt.Errorf("failed to get news list: %w", err)
+	}
+	return newsList, pagination, nil
+}
+
+// cursorSecret signs the list cursors, see auth.SigningKey
+func (r *NewsRepositoryImpl) cursorSecret() []byte {
+	return auth.SigningKey(config.GlobalConfig.CursorSecret, r.app.Config.JwtSecretKey, "cursor")
+}
+
+// cacheResponse caches a JSON response for the configured duration
+func (r *NewsRepositoryImpl) cacheResponse(req *http.Request, cacheKey string, response interface{}) error {
+	jsonData, err := json.Marshal(response)
+	if err != nil {
+		return fmt.Errorf("failed to marshal response: %w", err)
+	}
+	if err := r.app.Cache.Set(req.Context(), cacheKey, string(jsonData), time.Duration(r.app.Config.RedisExp)*time.Second); err != nil {
+		return fmt.Errorf("failed to set cache: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,353 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/JubaerHossain/cn-api/domain/news/entity"
+	"github.com/JubaerHossain/cn-api/pkg/content"
+)
+
+// liveEntryColumns are the news_live_entries columns scanned by scanLiveEntry
+const liveEntryColumns = `
+	news_live_entries.id, news_live_entries.news_id, news_live_entries.body,
+	COALESCE(news_live_entries.media_url, ''), COALESCE(news_live_entries.media_type, ''),
+	COALESCE(news_live_entries.media_caption, ''), news_live_entries.pinned,
+	news_live_entries.created_by, COALESCE(users.name, ''), news_live_entries.updated_by,
+	news_live_entries.created_at, news_live_entries.updated_at`
+
+// CreateLiveEntry appends an entry to a live blog and sets its ID
+func (r *NewsRepositoryImpl) CreateLiveEntry(req *http.Request, entry *entity.LiveEntry) error {
+	ctx := req.Context()
+	if err := r.prepareLiveEntry(entry); err != nil {
+		return err
+	}
+	tx, err := r.app.MDB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO news_live_entries (news_id, body, media_url, media_type, media_caption, pinned,
+		                               created_by, updated_by, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		entry.NewsID, entry.Body, nullableString(entry.MediaURL), nullableString(entry.MediaType), nullableString(entry.MediaCaption),
+		entry.Pinned, entry.Author.ID, entry.UpdatedBy, nullableTime(&entry.CreatedAt), nullableTime(&entry.UpdatedAt))
+	if err != nil {
+		return fmt.Errorf("failed to insert live entry: %w", err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get live entry ID: %w", err)
+	}
+	entry.ID = uint(id)
+	if err := touchNews(ctx, tx, entry.NewsID, entry.UpdatedAt); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	return r.clearLiveEntries(ctx)
+}
+
+// UpdateLiveEntry saves an edited entry
+func (r *NewsRepositoryImpl) UpdateLiveEntry(req *http.Request, entry *entity.LiveEntry) error {
+	ctx := req.Context()
+	if err := r.prepareLiveEntry(entry); err != nil {
+		return err
+	}
+	tx, err := r.app.MDB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE news_live_entries
+		SET body = ?, media_url = ?, media_type = ?, media_caption = ?, pinned = ?, updated_by = ?, updated_at = ?
+		WHERE id = ? AND news_id = ? AND deleted_at IS NULL`,
+		entry.Body, nullableString(entry.MediaURL), nullableString(entry.MediaType), nullableString(entry.MediaCaption),
+		entry.Pinned, entry.UpdatedBy, nullableTime(&entry.UpdatedAt), entry.ID, entry.NewsID,
+	); err != nil {
+		return fmt.Errorf("failed to update live entry: %w", err)
+	}
+	if err := touchNews(ctx, tx, entry.NewsID, entry.UpdatedAt); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	return r.clearLiveEntries(ctx)
+}
+
+// DeleteLiveEntry removes an entry of a live blog, leaving a tombstone that
+// tells polling readers to drop it
+func (r *NewsRepositoryImpl) DeleteLiveEntry(req *http.Request, newsID, entryID uint) error {
+	ctx := req.Context()
+	tx, err := r.app.MDB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := time.Now().UTC()
+	result, err := tx.ExecContext(ctx, `
+		UPDATE news_live_entries SET deleted_at = ?, updated_at = ?
+		WHERE id = ? AND news_id = ? AND deleted_at IS NULL`,
+		nullableTime(&now), nullableTime(&now), entryID, newsID)
+	if err != nil {
+		return fmt.Errorf("failed to delete live entry: %w", err)
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return entity.ErrLiveEntryNotFound
+	}
+	if err := touchNews(ctx, tx, newsID, now); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	return r.clearLiveEntries(ctx)
+}
+
+// GetLiveEntry returns an entry of a live blog by ID
+func (r *NewsRepositoryImpl) GetLiveEntry(req *http.Request, newsID, entryID uint) (*entity.LiveEntry, error) {
+	row := r.app.MDB.QueryRowContext(req.Context(), `
+		SELECT `+liveEntryColumns+`
+		FROM news_live_entries
+		LEFT JOIN users ON users.id = news_live_entries.created_by
+		WHERE news_live_entries.id = ? AND news_live_entries.news_id = ? AND news_live_entries.deleted_at IS NULL`, entryID, newsID)
+	entry, err := scanLiveEntry(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, entity.ErrLiveEntryNotFound
+	}
+	return entry, err
+}
+
+// GetLiveEntries returns a window of the entries of a live blog with its pinned
+// entries, and the entries edited or deleted since the query asks for
+func (r *NewsRepositoryImpl) GetLiveEntries(req *http.Request, newsID uint, query entity.LiveEntryQuery) (*entity.LiveEntriesResponse, error) {
+	ctx := req.Context()
+	// Changes are compared with >= since, so a change made while the response
+	// is read is reported again by the next poll rather than lost
+	response := &entity.LiveEntriesResponse{
+		NewsID:   newsID,
+		Updated:  []*entity.LiveEntry{},
+		Deleted:  []uint{},
+		LastID:   query.After,
+		SyncedAt: time.Now().UTC().Truncate(time.Second),
+	}
+
+	pinned, err := r.queryLiveEntries(ctx, `
+		WHERE news_live_entries.news_id = ? AND news_live_entries.pinned = TRUE
+		AND news_live_entries.deleted_at IS NULL
+		ORDER BY news_live_entries.id DESC`, newsID)
+	if err != nil {
+		return nil, err
+	}
+	response.Pinned = pinned
+
+	// One more entry than the limit tells whether the window has more beyond it
+	var entries []*entity.LiveEntry
+	switch {
+	case query.After > 0:
+		entries, err = r.queryLiveEntries(ctx, `
+			WHERE news_live_entries.news_id = ? AND news_live_entries.id > ? AND news_live_entries.deleted_at IS NULL
+			ORDER BY news_live_entries.id ASC LIMIT ?`, newsID, query.After, query.Limit+1)
+	case query.Before > 0:
+		entries, err = r.queryLiveEntries(ctx, `
+			WHERE news_live_entries.news_id = ? AND news_live_entries.id < ? AND news_live_entries.deleted_at IS NULL
+			ORDER BY news_live_entries.id DESC LIMIT ?`, newsID, query.Before, query.Limit+1)
+	default:
+		entries, err = r.queryLiveEntries(ctx, `
+			WHERE news_live_entries.news_id = ? AND news_live_entries.deleted_at IS NULL
+			ORDER BY news_live_entries.id DESC LIMIT ?`, newsID, query.Limit+1)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) > query.Limit {
+		response.HasMore = true
+		entries = entries[:query.Limit]
+	}
+	if query.After == 0 {
+		// Read newest first, served oldest first
+		for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+			entries[i], entries[j] = entries[j], entries[i]
+		}
+	}
+	response.Data = entries
+	if len(entries) > 0 {
+		response.LastID = entries[len(entries)-1].ID
+	}
+
+	if !query.Since.IsZero() {
+		if err := r.liveEntryChanges(ctx, newsID, query, response); err != nil {
+			return nil, err
+		}
+	}
+	return response, nil
+}
+
+// liveEntryChanges fills the entries edited and deleted since the query's
+// Since. Edits are limited to the entries up to After, the ones the reader
+// holds; newer entries reach it through the window itself.
+func (r *NewsRepositoryImpl) liveEntryChanges(ctx context.Context, newsID uint, query entity.LiveEntryQuery, response *entity.LiveEntriesResponse) error {
+	since := nullableTime(&query.Since)
+	clauses := `
+		WHERE news_live_entries.news_id = ? AND news_live_entries.deleted_at IS NULL
+		AND news_live_entries.updated_at >= ?`
+	args := []interface{}{newsID, since}
+	if query.After > 0 {
+		clauses += " AND news_live_entries.id <= ?"
+		args = append(args, query.After)
+	}
+	updated, err := r.queryLiveEntries(ctx, clauses+`
+		ORDER BY news_live_entries.updated_at ASC, news_live_entries.id ASC`, args...)
+	if err != nil {
+		return err
+	}
+	response.Updated = updated
+
+	rows, err := r.app.MDB.QueryContext(ctx, `
+		SELECT id FROM news_live_entries
+		WHERE news_id = ? AND deleted_at >= ?
+		ORDER BY deleted_at ASC, id ASC`, newsID, since)
+	if err != nil {
+		return fmt.Errorf("failed to query deleted live entries: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id uint
+		if err := rows.Scan(&id); err != nil {
+			return fmt.Errorf("failed to scan deleted live entry: %w", err)
+		}
+		response.Deleted = append(response.Deleted, id)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("rows iteration error: %w", err)
+	}
+	return nil
+}
+
+// GetLiveBlogEntries returns a window of the entries of the published live
+// blog with the slug, cached until an entry changes
+func (r *NewsRepositoryImpl) GetLiveBlogEntries(req *http.Request, slug string, query entity.LiveEntryQuery) (*entity.LiveEntriesResponse, error) {
+	ctx := req.Context()
+	var since int64
+	if !query.Since.IsZero() {
+		since = query.Since.Unix()
+	}
+	cacheKey := fmt.Sprintf("live_entries_%s_%d_%d_%d_%d", slug, query.After, query.Before, since, query.Limit)
+	if cachedData, errCache := r.app.Cache.Get(ctx, cacheKey); errCache == nil && cachedData != "" {
+		response := &entity.LiveEntriesResponse{}
+		if err := json.Unmarshal([]byte(cachedData), response); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal cached data: %w", err)
+		}
+		return response, nil
+	}
+
+	var newsID uint
+	err := r.app.MDB.QueryRowContext(ctx, `
+		SELECT news.id
+		FROM news_translations
+		JOIN news ON news.id = news_translations.news_id
+		WHERE news_translations.slug = ? AND news.type = ? AND `+entity.PublishedFilter+`
+		LIMIT 1`, slug, entity.TypeLiveBlog).Scan(&newsID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, entity.ErrNewsNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve slug: %w", err)
+	}
+
+	response, err := r.GetLiveEntries(req, newsID, query)
+	if err != nil {
+		return nil, err
+	}
+
+	// Cache the response
+	jsonData, err := json.Marshal(response)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal response: %w", err)
+	}
+	cacheDuration := time.Duration(r.app.Config.RedisExp) * time.Second
+	if err := r.app.Cache.Set(ctx, cacheKey, string(jsonData), cacheDuration); err != nil {
+		return nil, fmt.Errorf("failed to set cache: %w", err)
+	}
+	return response, nil
+}
+
+// queryLiveEntries reads the entries matching the WHERE and ORDER clauses
+func (r *NewsRepositoryImpl) queryLiveEntries(ctx context.Context, clauses string, args ...interface{}) ([]*entity.LiveEntry, error) {
+	rows, err := r.app.MDB.QueryContext(ctx, `
+		SELECT `+liveEntryColumns+`
+		FROM news_live_entries
+		LEFT JOIN users ON users.id = news_live_entries.created_by
+		`+clauses, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query live entries: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []*entity.LiveEntry{}
+	for rows.Next() {
+		entry, err := scanLiveEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return entries, nil
+}
+
+// prepareLiveEntry sanitizes the body of an entry like article content
+func (r *NewsRepositoryImpl) prepareLiveEntry(entry *entity.LiveEntry) error {
+	entry.Body = content.Sanitize(entry.Body, r.app.Config.Domain)
+	if strings.TrimSpace(entry.Body) == "" {
+		return fmt.Errorf("%w: body is empty once sanitized", entity.ErrInvalidQuery)
+	}
+	return nil
+}
+
+// touchNews moves the modification time of a live blog to its latest entry change
+func touchNews(ctx context.Context, tx *sql.Tx, newsID uint, updatedAt time.Time) error {
+	if _, err := tx.ExecContext(ctx, "UPDATE news SET updated_at = ? WHERE id = ?", nullableTime(&updatedAt), newsID); err != nil {
+		return fmt.Errorf("failed to update news: %w", err)
+	}
+	return nil
+}
+
+// clearLiveEntries drops the cached entries of every live blog
+func (r *NewsRepositoryImpl) clearLiveEntries(ctx context.Context) error {
+	_, err := r.app.Cache.ClearPattern(ctx, "live_entries_*")
+	return err
+}
+
+// scanLiveEntry reads a row selected with liveEntryColumns
+func scanLiveEntry(row interface{ Scan(...interface{}) error }) (*entity.LiveEntry, error) {
+	var (
+		entry                entity.LiveEntry
+		createdAt, updatedAt sql.NullString
+	)
+	if err := row.Scan(&entry.ID, &entry.NewsID, &entry.Body, &entry.MediaURL, &entry.MediaType, &entry.MediaCaption,
+		&entry.Pinned, &entry.Author.ID, &entry.Author.Name, &entry.UpdatedBy, &createdAt, &updatedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to scan live entry: %w", err)
+	}
+	entry.CreatedAt = parseDateTime(createdAt)
+	entry.UpdatedAt = parseDateTime(updatedAt)
+	return &entry, nil
+}
@@ -2,7 +2,9 @@ package persistence
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strings"
@@ -10,10 +12,11 @@ import (
 
 	"github.com/JubaerHossain/cn-api/domain/news/entity"
 	"github.com/JubaerHossain/cn-api/domain/news/repository"
+	"github.com/JubaerHossain/cn-api/pkg/locale"
+	utilQuery "github.com/JubaerHossain/cn-api/pkg/utils"
 	"github.com/JubaerHossain/rootx/pkg/core/app"
 	"github.com/JubaerHossain/rootx/pkg/core/cache"
 	"github.com/JubaerHossain/rootx/pkg/core/config"
-	utilQuery "github.com/JubaerHossain/rootx/pkg/query"
 )
 
 type NewsRepositoryImpl struct {
@@ -32,15 +35,50 @@ func CacheClear(req *http.Request, cache cache.CacheService) error {
 	if _, err := cache.ClearPattern(ctx, "get_all_newss_*"); err != nil {
 		return err
 	}
-	if _, err := cache.ClearPattern(ctx, "get_breaking_scrolling_news*"); err != nil {
+	if _, err := cache.ClearPattern(ctx, "get_news_detail_*"); err != nil {
+		return err
+	}
+	if _, err := cache.ClearPattern(ctx, "search_news_*"); err != nil {
+		return err
+	}
+	if _, err := cache.ClearPattern(ctx, "ranking_*"); err != nil {
+		return err
+	}
+	if _, err := cache.ClearPattern(ctx, "news_list_*"); err != nil {
+		return err
+	}
+	if _, err := cache.ClearPattern(ctx, "category_news_*"); err != nil {
+		return err
+	}
+	if _, err := cache.ClearPattern(ctx, "tag_news_*"); err != nil {
+		return err
+	}
+	// Any article may be pinned to or backfill a placement
+	if _, err := cache.ClearPattern(ctx, "placement_*"); err != nil {
+		return err
+	}
+	// Saving an article can create tags
+	if _, err := cache.ClearPattern(ctx, "get_all_tags_*"); err != nil {
+		return err
+	}
+	if _, err := cache.ClearPattern(ctx, "tag_autocomplete_*"); err != nil {
+		return err
+	}
+	if _, err := cache.ClearPattern(ctx, "feed_*"); err != nil {
+		return err
+	}
+	if _, err := cache.ClearPattern(ctx, "sitemap_*"); err != nil {
+		return err
+	}
+	// Unpublishing or retyping a live blog hides its entries
+	if _, err := cache.ClearPattern(ctx, "live_entries_*"); err != nil {
 		return err
 	}
 	return nil
 }
 
-// GetAllNewss returns all newss from the database
+// GetNewses returns all news from the database
 func (r *NewsRepositoryImpl) GetNewses(req *http.Request) (*entity.NewsResponsePagination, error) {
-	// Implement logic to get all newss
 	ctx := req.Context()
 	cacheKey := fmt.Sprintf("get_all_newss_%s", req.URL.Query().Encode()) // Encode query parameters
 	if cachedData, errCache := r.app.Cache.Get(ctx, cacheKey); errCache == nil && cachedData != "" {
@@ -51,20 +89,58 @@ func (r *NewsRepositoryImpl) GetNewses(req *http.Request) (*entity.NewsResponseP
 		return newss, nil
 	}
 
-	baseQuery := "SELECT id, name, status, created_at FROM news" // Example SQL query
+	queryValues := req.URL.Query()
+	listLocale := queryValues.Get("locale")
+	if listLocale == "" {
+		listLocale = locale.Default()
+	}
+
+	baseQuery := `
+	SELECT news.id, news_translations.title, news_translations.slug, news.type, news.status_id, news.publish_status_id,
+	       COALESCE(news.department_id, 0), news.created_at, news.updated_at
+	FROM news
+	JOIN news_translations ON news.id = news_translations.news_id AND news_translations.locale = ?`
+	args := []interface{}{listLocale}
 
 	// Apply filters from query parameters
-	queryValues := req.URL.Query()
 	var filters []string
 
 	// Filter by search query
 	if search := queryValues.Get("search"); search != "" {
-		filters = append(filters, fmt.Sprintf("name ILIKE '%%%s%%'", search))
+		filters = append(filters, "news_translations.title LIKE ?")
+		args = append(args, "%"+search+"%")
 	}
 
 	// Filter by status
 	if status := queryValues.Get("status"); status != "" {
-		filters = append(filters, fmt.Sprintf("status = %s", status))
+		filters = append(filters, "news.status_id = ?")
+		args = append(args, status)
+	}
+
+	// Filter by type
+	if newsType := queryValues.Get("type"); newsType != "" {
+		filters = append(filters, "news.type = ?")
+		args = append(args, newsType)
+	}
+
+	// Filter by workflow states, a comma separated list of state names
+	if states := queryValues.Get("state"); states != "" {
+		var placeholders []string
+		for _, name := range strings.Split(states, ",") {
+			state, ok := entity.ParseState(strings.TrimSpace(name))
+			if !ok {
+				return nil, fmt.Errorf("%w: unknown state %q", entity.ErrInvalidQuery, name)
+			}
+			placeholders = append(placeholders, "?")
+			args = append(args, state)
+		}
+		filters = append(filters, fmt.Sprintf("news.publish_status_id IN (%s)", strings.Join(placeholders, ",")))
+	}
+
+	// Filter by desk
+	if department := queryValues.Get("department_id"); department != "" {
+		filters = append(filters, "news.department_id = ?")
+		args = append(args, department)
 	}
 
 	// Apply filters to query
@@ -74,13 +150,13 @@ func (r *NewsRepositoryImpl) GetNewses(req *http.Request) (*entity.NewsResponseP
 	}
 
 	// sort by
-	sortBy := " ORDER BY id DESC"
-	if sort := queryValues.Get("sort"); sort != "" {
-		sortBy = fmt.Sprintf(" ORDER BY id %s", sort)
+	sortBy := " ORDER BY news.id DESC"
+	if sort := strings.ToUpper(queryValues.Get("sort")); sort == "ASC" || sort == "DESC" {
+		sortBy = fmt.Sprintf(" ORDER BY news.id %s", sort)
 	}
 
 	// Pagination and limits
-	pagination, limit, offset, err := utilQuery.Paginate(req, r.app, baseQuery, filterQuery)
+	pagination, limit, offset, err := utilQuery.Paginate(req, r.app, baseQuery, filterQuery, args...)
 	if err != nil {
 		return nil, fmt.Errorf("pagination error: %w", err)
 	}
@@ -88,15 +164,7 @@ func (r *NewsRepositoryImpl) GetNewses(req *http.Request) (*entity.NewsResponseP
 	// Apply pagination to query
 	query := fmt.Sprintf("%s%s%s LIMIT %d OFFSET %d", baseQuery, filterQuery, sortBy, limit, offset)
 
-	// Get database connection from pool
-	conn, err := r.app.DB.Acquire(ctx)
-	if err != nil {
-		return nil, err
-	}
-	defer conn.Release()
-
-	// Perform the query
-	rows, err := conn.Query(ctx, query)
+	rows, err := r.app.MDB.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -106,10 +174,11 @@ func (r *NewsRepositoryImpl) GetNewses(req *http.Request) (*entity.NewsResponseP
 	newss := []*entity.ResponseNews{}
 	for rows.Next() {
 		var news entity.ResponseNews
-		err := rows.Scan(&news.ID, &news.Name, &news.Status, &news.CreatedAt)
+		err := rows.Scan(&news.ID, &news.Title, &news.Slug, &news.Type, &news.StatusID, &news.PublishStatusID, &news.DepartmentID, &news.CreatedAt, &news.UpdatedAt)
 		if err != nil {
 			return nil, err
 		}
+		news.State = entity.State(news.PublishStatusID).String()
 		newss = append(newss, &news)
 	}
 
@@ -134,234 +203,477 @@ func (r *NewsRepositoryImpl) GetNewses(req *http.Request) (*entity.NewsResponseP
 	return &response, nil
 }
 
-// GetNewsByID returns a news by ID from the database
+// GetNewsByID returns the news row by ID from the database
 func (r *NewsRepositoryImpl) GetNewsByID(newsID uint) (*entity.News, error) {
-	// Implement logic to get news by ID
 	news := &entity.News{}
-	if err := r.app.DB.QueryRow(context.Background(), "SELECT id, name, status FROM news WHERE id = $1", newsID).Scan(&news.ID, &news.Name, &news.Status); err != nil {
+	var createdAt, updatedAt, publishAt, unpublishAt, imageFiles sql.NullString
+	query := `
+		SELECT id, type, COALESCE(path_small, ''), COALESCE(path_medium, ''), COALESCE(path_large, ''),
+		       COALESCE(path_small_webp, ''), COALESCE(path_medium_webp, ''), COALESCE(path_large_webp, ''),
+		       COALESCE(image_placeholder, ''), image_files, status_id,
+		       COALESCE(publish_status_id, 0), COALESCE(department_id, 0), publish_at, unpublish_at,
+		       COALESCE(created_by, 0), COALESCE(updated_by, 0), created_at, updated_at
+		FROM news WHERE id = ?`
+	if err := r.app.MDB.QueryRow(query, newsID).Scan(
+		&news.ID, &news.Type, &news.PathSmall, &news.PathMedium, &news.PathLarge,
+		&news.PathSmallWebp, &news.PathMediumWebp, &news.PathLargeWebp, &news.ImagePlaceholder, &imageFiles,
+		&news.StatusID, &news.PublishStatusID, &news.DepartmentID,
+		&publishAt, &unpublishAt, &news.CreatedBy, &news.UpdatedBy, &createdAt, &updatedAt,
+	); err != nil {
 		return nil, fmt.Errorf("news not found")
 	}
+	if err := unmarshalList(imageFiles, &news.ImageFiles); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal image files: %w", err)
+	}
+	news.State = entity.State(news.PublishStatusID).String()
+	news.PublishAt = parseNullDateTime(publishAt)
+	news.UnpublishAt = parseNullDateTime(unpublishAt)
+	news.CreatedAt = parseDateTime(createdAt)
+	news.UpdatedAt = parseDateTime(updatedAt)
 	return news, nil
 }
 
-// GetNews returns a news by ID from the database
-func (r *NewsRepositoryImpl) GetNews(newsID uint) (*entity.ResponseNews, error) {
-	// Implement logic to get news by ID
-	resNews := &entity.ResponseNews{}
-	query := "SELECT id, name, status FROM news WHERE id = $1"
-	if err := r.app.DB.QueryRow(context.Background(), query, newsID).Scan(&resNews.ID, &resNews.Name, &resNews.Status); err != nil {
-		return nil, fmt.Errorf("news not found")
+// GetNews returns a news with its translations and categories by ID from the database
+func (r *NewsRepositoryImpl) GetNews(newsID uint) (*entity.News, error) {
+	news, err := r.GetNewsByID(newsID)
+	if err != nil {
+		return nil, err
 	}
-	return resNews, nil
-}
 
-func (r *NewsRepositoryImpl) GetNewsDetails(newsID uint) (*entity.ResponseNews, error) {
-	// Implement logic to get news details by ID
-	resNews := &entity.ResponseNews{}
-	err := r.app.DB.QueryRow(context.Background(), `
-		SELECT u.id, u.name, u.status
-		FROM news u
-		WHERE u.id = $1
-	`, newsID).Scan(&resNews.ID, &resNews.Name, &resNews.Status)
+	rows, err := r.app.MDB.Query(`
+		SELECT locale, title, slug, COALESCE(sub_title, ''), excerpt, COALESCE(content, ''),
+		       COALESCE(meta_title, ''), COALESCE(meta_description, ''), meta_keywords
+		FROM news_translations WHERE news_id = ? ORDER BY id ASC`, newsID)
 	if err != nil {
-		return nil, fmt.Errorf("news not found")
+		return nil, fmt.Errorf("failed to query translations: %w", err)
 	}
-	return resNews, nil
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			translation   entity.NewsTranslation
+			meta_keywords sql.NullString
+		)
+		if err := rows.Scan(&translation.Locale, &translation.Title, &translation.Slug, &translation.SubTitle,
+			&translation.Excerpt, &translation.Content, &translation.MetaTitle, &translation.MetaDescription, &meta_keywords); err != nil {
+			return nil, fmt.Errorf("failed to scan translation: %w", err)
+		}
+		if err := unmarshalList(meta_keywords, &translation.MetaKeywords); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal meta keywords: %w", err)
+		}
+		news.Translations = append(news.Translations, &translation)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	tags, err := r.tagsOf(context.Background(), []uint{newsID})
+	if err != nil {
+		return nil, err
+	}
+	for _, translation := range news.Translations {
+		translation.Tags = tags[newsID][translation.Locale]
+		if translation.Tags == nil {
+			translation.Tags = []string{}
+		}
+	}
+
+	categoryRows, err := r.app.MDB.Query("SELECT news_category_id FROM assign_categories WHERE news_id = ?", newsID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query categories: %w", err)
+	}
+	defer categoryRows.Close()
+
+	for categoryRows.Next() {
+		var categoryID uint
+		if err := categoryRows.Scan(&categoryID); err != nil {
+			return nil, fmt.Errorf("failed to scan category: %w", err)
+		}
+		news.CategoryIDs = append(news.CategoryIDs, categoryID)
+	}
+	if err := categoryRows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return news, nil
 }
 
+// CreateNews inserts the news, its translations and category assignments in one transaction
 func (r *NewsRepositoryImpl) CreateNews(news *entity.News, req *http.Request) error {
-	// Begin a transaction
-	tx, err := r.app.DB.Begin(context.Background())
+	ctx := req.Context()
+	if err := r.prepareContent(news.Translations...); err != nil {
+		return err
+	}
+	tx, err := r.app.MDB.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
-	defer func() {
-		if r := recover(); r != nil {
-			// Recover from panic and rollback the transaction
-			tx.Rollback(context.Background())
-		} else if err := tx.Commit(context.Background()); err != nil {
-			// Commit the transaction if no error occurred, otherwise rollback
-			tx.Rollback(context.Background())
-		}
-	}()
+	defer tx.Rollback()
 
-	// Create the news within the transaction
-	_, err = tx.Exec(context.Background(), `
-		INSERT INTO news (name, status, created_at, updated_at) VALUES ($1, TRUE, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
-	`, news.Name)
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO news (type, path_small, path_medium, path_large, status_id, publish_status_id, department_id, publish_at, unpublish_at,
+		                  created_by, updated_by, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)`,
+		news.Type, news.PathSmall, news.PathMedium, news.PathLarge, news.StatusID, news.PublishStatusID, nullableID(news.DepartmentID),
+		nullableTime(news.PublishAt), nullableTime(news.UnpublishAt),
+		news.CreatedBy, news.CreatedBy,
+	)
 	if err != nil {
-		tx.Rollback(context.Background())
+		return fmt.Errorf("failed to insert news: %w", err)
+	}
+	newsID, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get news id: %w", err)
+	}
+	news.ID = uint(newsID)
+
+	if err := saveTranslations(req, tx, news.ID, news.Translations, news.CreatedBy); err != nil {
+		return err
+	}
+	if err := assignCategories(req, tx, news.ID, news.CategoryIDs); err != nil {
+		return err
+	}
+	if err := markSitemapsStale(req, tx, news.ID); err != nil {
 		return err
 	}
 
-	// Clear cache
-	if err := CacheClear(req, r.app.Cache); err != nil {
-		tx.Rollback(context.Background())
+	if err := tx.Commit(); err != nil {
 		return err
 	}
 
-	return nil
+	// Clear cache
+	return CacheClear(req, r.app.Cache)
 }
 
+// UpdateNews replaces the news fields, translations and category assignments in one transaction
 func (r *NewsRepositoryImpl) UpdateNews(oldNews *entity.News, news *entity.UpdateNews, req *http.Request) error {
-	tx, err := r.app.DB.Begin(context.Background())
+	ctx := req.Context()
+	if err := r.prepareContent(news.Translations...); err != nil {
+		return err
+	}
+	tx, err := r.app.MDB.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
-	defer func() {
-		if r := recover(); r != nil {
-			tx.Rollback(context.Background())
-		} else if err := tx.Commit(context.Background()); err != nil {
-			tx.Rollback(context.Background())
-		}
-	}()
+	defer tx.Rollback()
 
-	query := `
+	if _, err := tx.ExecContext(ctx, `
 		UPDATE news
-		SET name = $1, status = $2
-		WHERE id = $3
-		RETURNING id, name, status
-	`
-	row := tx.QueryRow(context.Background(), query, news.Name, news.Status, oldNews.ID)
-	updateNews := &entity.News{}
-	err = row.Scan(&updateNews.ID, &updateNews.Name, &updateNews.Status)
-	if err != nil {
-		tx.Rollback(context.Background())
+		SET type = ?, path_small = ?, path_medium = ?, path_large = ?, status_id = ?, department_id = ?, publish_at = ?, unpublish_at = ?,
+		    updated_by = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ?`,
+		news.Type, news.PathSmall, news.PathMedium, news.PathLarge, news.StatusID, nullableID(news.DepartmentID),
+		nullableTime(news.PublishAt), nullableTime(news.UnpublishAt),
+		news.UpdatedBy, oldNews.ID,
+	); err != nil {
+		return fmt.Errorf("failed to update news: %w", err)
+	}
+	if news.ReplacesImage(oldNews) {
+		// The generated variants belong to the uploaded image, not to the new paths
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE news SET path_small_webp = NULL, path_medium_webp = NULL, path_large_webp = NULL,
+			                image_placeholder = NULL, image_files = NULL
+			WHERE id = ?`, oldNews.ID); err != nil {
+			return fmt.Errorf("failed to clear image variants: %w", err)
+		}
+	}
+
+	if err := saveTranslations(req, tx, oldNews.ID, news.Translations, news.UpdatedBy); err != nil {
 		return err
 	}
 
-	// Clear cache
-	if err := CacheClear(req, r.app.Cache); err != nil {
-		tx.Rollback(context.Background())
+	// Replace the category assignments
+	if _, err := tx.ExecContext(ctx, "DELETE FROM assign_categories WHERE news_id = ?", oldNews.ID); err != nil {
+		return fmt.Errorf("failed to clear categories: %w", err)
+	}
+	if err := assignCategories(req, tx, oldNews.ID, news.CategoryIDs); err != nil {
+		return err
+	}
+	if err := markSitemapsStale(req, tx, oldNews.ID); err != nil {
 		return err
 	}
 
-	return nil
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	// Clear cache
+	return CacheClear(req, r.app.Cache)
 }
 
+// DeleteNews removes the news with its translations, category assignments,
+// view stats and import records in one transaction
 func (r *NewsRepositoryImpl) DeleteNews(news *entity.News, req *http.Request) error {
-	tx, err := r.app.DB.Begin(context.Background())
+	ctx := req.Context()
+	tx, err := r.app.MDB.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
-	defer func() {
-		if r := recover(); r != nil {
-			tx.Rollback(context.Background())
-		} else if err := tx.Commit(context.Background()); err != nil {
-			tx.Rollback(context.Background())
+	defer tx.Rollback()
+
+	for _, query := range []string{
+		"DELETE FROM news_slug_redirects WHERE news_id = ?",
+		"DELETE FROM news_workflow_transitions WHERE news_id = ?",
+		"DELETE FROM news_previews WHERE news_id = ?",
+		"DELETE FROM news_live_entries WHERE news_id = ?",
+		"DELETE FROM news_revisions WHERE news_id = ?",
+		"DELETE FROM assign_categories WHERE news_id = ?",
+		"DELETE FROM news_tags WHERE news_id = ?",
+		"DELETE FROM placement_items WHERE news_id = ?",
+		"DELETE FROM comment_moderations WHERE comment_id IN (SELECT id FROM comments WHERE news_id = ?)",
+		"DELETE FROM comments WHERE news_id = ?",
+		"DELETE FROM poll_votes WHERE poll_id IN (SELECT id FROM polls WHERE news_id = ?)",
+		"DELETE FROM poll_options WHERE poll_id IN (SELECT id FROM polls WHERE news_id = ?)",
+		"DELETE FROM polls WHERE news_id = ?",
+		"DELETE FROM news_reactions WHERE news_id = ?",
+		"DELETE FROM news_view_stats WHERE news_id = ?",
+		// Media rows point at stored files shared between posts and stay
+		fmt.Sprintf("DELETE FROM wxr_imports WHERE kind IN ('%s', '%s') AND target_id = ?", entity.ImportPost, entity.ImportAttachment),
+		"DELETE FROM news_translations WHERE news_id = ?",
+		"DELETE FROM news WHERE id = ?",
+	} {
+		if _, err := tx.ExecContext(ctx, query, news.ID); err != nil {
+			return err
 		}
-	}()
-
-	query := "DELETE FROM news WHERE id = $1"
-	if _, err := tx.Exec(context.Background(), query, news.ID); err != nil {
-		tx.Rollback(context.Background())
+	}
+	if err := markSitemapsStale(req, tx, news.ID); err != nil {
 		return err
 	}
 
-	// Clear cache
-	if err := CacheClear(req, r.app.Cache); err != nil {
-		tx.Rollback(context.Background())
+	if err := tx.Commit(); err != nil {
 		return err
 	}
 
-	return nil
+	// Clear cache
+	return CacheClear(req, r.app.Cache)
 }
 
-// GetBreakingScrollingNews returns breaking and scrolling news
-func (r *NewsRepositoryImpl) GetBreakingScrollingNews(req *http.Request) (*entity.ScrollNewsResponse, error) {
+// saveTranslations upserts one news_translations row per locale, records a
+// revision of each, and removes locales that are no longer present in the request
+func saveTranslations(req *http.Request, tx *sql.Tx, newsID uint, translations []*entity.NewsTranslation, authorID uint) error {
 	ctx := req.Context()
-	cacheKey := fmt.Sprintf("get_breaking_scrolling_news_%s", req.URL.Query().Encode()) // Encode query parameters
+	locales := make([]string, 0, len(translations))
+	seen := make(map[string]bool, len(translations))
+	for _, translation := range translations {
+		if seen[translation.Locale] {
+			return fmt.Errorf("duplicate translation for locale %q", translation.Locale)
+		}
+		seen[translation.Locale] = true
+		locales = append(locales, translation.Locale)
 
-	// Check cache first
-	if cachedData, errCache := r.app.Cache.Get(ctx, cacheKey); errCache == nil && cachedData != "" {
-		news := &entity.ScrollNewsResponse{}
-		if err := json.Unmarshal([]byte(cachedData), news); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal cached data: %w", err)
+		if err := saveTranslation(req, tx, newsID, translation, authorID, nil); err != nil {
+			return err
 		}
-		return news, nil
 	}
 
-	var filters []string
-	filters = append(filters, "news.status_id = 1")
-	filters = append(filters, "news.publish_status_id = 9")
-	filters = append(filters, "news.breaking_scroll_news = 1")
-
-	// Apply filters to query
-	filterQuery := ""
-	if len(filters) > 0 {
-		filterQuery = " AND " + strings.Join(filters, " AND ")
+	// Drop translations for locales that were removed
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(locales)), ",")
+	args := []interface{}{newsID}
+	for _, locale := range locales {
+		args = append(args, locale)
 	}
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf("DELETE FROM news_tags WHERE news_id = ? AND locale NOT IN (%s)", placeholders), args...); err != nil {
+		return fmt.Errorf("failed to remove stale tags: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf("DELETE FROM news_translations WHERE news_id = ? AND locale NOT IN (%s)", placeholders), args...); err != nil {
+		return fmt.Errorf("failed to remove stale translations: %w", err)
+	}
+	return nil
+}
 
-
-	// Get news list
-	newsList, err := r.GetNewsList(req, filterQuery, 3)
+// saveTranslation upserts the news_translations row of one locale and stores it
+// as a new revision. restoredFrom is the revision being restored, if any.
+func saveTranslation(req *http.Request, tx *sql.Tx, newsID uint, translation *entity.NewsTranslation, authorID uint, restoredFrom *uint) error {
+	ctx := req.Context()
+	slug, err := uniqueSlug(req, tx, newsID, translation)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get news list: %w", err)
+		return err
 	}
+	translation.Slug = slug
 
-	// Construct the response
-	response := &entity.ScrollNewsResponse{
-		Data: newsList,
+	// news_translations.tags keeps a copy of the tag names for the search index
+	translation.Tags, err = syncTags(ctx, tx, newsID, translation.Locale, translation.Tags)
+	if err != nil {
+		return err
 	}
-
-	// Cache the response
-	jsonData, err := json.Marshal(response)
+	tags, err := marshalList(translation.Tags)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal response: %w", err)
+		return fmt.Errorf("failed to marshal tags: %w", err)
+	}
+	metaKeywords, err := marshalList(translation.MetaKeywords)
+	if err != nil {
+		return fmt.Errorf("failed to marshal meta keywords: %w", err)
+	}
+
+	// The slug now belongs to this article, it must no longer redirect elsewhere
+	if _, err := tx.ExecContext(ctx, "DELETE FROM news_slug_redirects WHERE locale = ? AND slug = ?", translation.Locale, translation.Slug); err != nil {
+		return fmt.Errorf("failed to clear slug redirect: %w", err)
+	}
+
+	var (
+		translationID uint
+		oldSlug       string
+	)
+	err = tx.QueryRowContext(ctx, "SELECT id, slug FROM news_translations WHERE news_id = ? AND locale = ?", newsID, translation.Locale).Scan(&translationID, &oldSlug)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO news_translations (news_id, locale, title, slug, sub_title, excerpt, tags, content, meta_title, meta_description, meta_keywords, created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)`,
+			newsID, translation.Locale, translation.Title, translation.Slug, translation.SubTitle, translation.Excerpt, tags,
+			translation.Content, translation.MetaTitle, translation.MetaDescription, metaKeywords,
+		)
+	case err == nil:
+		_, err = tx.ExecContext(ctx, `
+			UPDATE news_translations
+			SET title = ?, slug = ?, sub_title = ?, excerpt = ?, tags = ?, content = ?, meta_title = ?, meta_description = ?, meta_keywords = ?, updated_at = CURRENT_TIMESTAMP
+			WHERE id = ?`,
+			translation.Title, translation.Slug, translation.SubTitle, translation.Excerpt, tags,
+			translation.Content, translation.MetaTitle, translation.MetaDescription, metaKeywords, translationID,
+		)
+		// Keep the previous slug so old links redirect permanently
+		if err == nil && oldSlug != "" && oldSlug != translation.Slug {
+			_, err = tx.ExecContext(ctx, `
+				INSERT INTO news_slug_redirects (news_id, locale, slug, created_at) VALUES (?, ?, ?, CURRENT_TIMESTAMP)
+				ON DUPLICATE KEY UPDATE news_id = VALUES(news_id)`,
+				newsID, translation.Locale, oldSlug,
+			)
+		}
 	}
-	cacheDuration := time.Duration(r.app.Config.RedisExp) * time.Second
-	if err := r.app.Cache.Set(ctx, cacheKey, string(jsonData), cacheDuration); err != nil {
-		return nil, fmt.Errorf("failed to set cache: %w", err)
+	if err != nil {
+		return fmt.Errorf("failed to save %s translation: %w", translation.Locale, err)
 	}
 
-	return response, nil
+	// The translation row is locked by the upsert above, so revision numbers cannot race
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO news_revisions (news_id, locale, number, title, slug, sub_title, tags, content, meta_title,
+		                            meta_description, meta_keywords, author_id, restored_from, created_at)
+		SELECT ?, ?, COALESCE(MAX(number), 0) + 1, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP
+		FROM news_revisions WHERE news_id = ? AND locale = ?`,
+		newsID, translation.Locale, translation.Title, translation.Slug, translation.SubTitle, tags,
+		translation.Content, translation.MetaTitle, translation.MetaDescription, metaKeywords, authorID, restoredFrom,
+		newsID, translation.Locale,
+	); err != nil {
+		return fmt.Errorf("failed to record %s revision: %w", translation.Locale, err)
+	}
+	return nil
 }
 
-// GetBreakingThumbnailNews returns thumbnail news
-func (r *NewsRepositoryImpl) GetBreakingThumbnailNews(req *http.Request) (*entity.ThumbnailNewsResponse, error) {
-	ctx := req.Context()
-	cacheKey := fmt.Sprintf("get_breaking_thumbnail_news_%s", req.URL.Query().Encode()) // Encode query parameters
+// uniqueSlug returns the requested slug (or one derived from the title) that is
+// not yet used by another article in the same locale
+func uniqueSlug(req *http.Request, tx *sql.Tx, newsID uint, translation *entity.NewsTranslation) (string, error) {
+	base := utilQuery.Slugify(translation.Slug)
+	if base == "" {
+		base = utilQuery.Slugify(translation.Title)
+	}
+	if base == "" {
+		return "", fmt.Errorf("could not derive a slug for locale %q", translation.Locale)
+	}
+
+	slug := base
+	for i := 2; ; i++ {
+		var count int
+		if err := tx.QueryRowContext(req.Context(),
+			"SELECT COUNT(*) FROM news_translations WHERE locale = ? AND slug = ? AND news_id <> ?",
+			translation.Locale, slug, newsID,
+		).Scan(&count); err != nil {
+			return "", fmt.Errorf("failed to check slug: %w", err)
+		}
+		if count == 0 {
+			return slug, nil
+		}
+		slug = fmt.Sprintf("%s-%d", base, i)
+	}
+}
 
-	// Check cache first
-	if cachedData, errCache := r.app.Cache.Get(ctx, cacheKey); errCache == nil && cachedData != "" {
-		news := &entity.ThumbnailNewsResponse{}
-		if err := json.Unmarshal([]byte(cachedData), news); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal cached data: %w", err)
+// assignCategories links the news to the given categories
+func assignCategories(req *http.Request, tx *sql.Tx, newsID uint, categoryIDs []uint) error {
+	seen := make(map[uint]bool, len(categoryIDs))
+	for _, categoryID := range categoryIDs {
+		if seen[categoryID] {
+			continue
+		}
+		seen[categoryID] = true
+		if _, err := tx.ExecContext(req.Context(),
+			"INSERT INTO assign_categories (news_id, news_category_id) VALUES (?, ?)", newsID, categoryID,
+		); err != nil {
+			return fmt.Errorf("failed to assign category %d: %w", categoryID, err)
 		}
-		return news, nil
 	}
+	return nil
+}
 
-	var filters []string
-	filters = append(filters, "news.status_id = 1")
-	filters = append(filters, "news.publish_status_id = 9")
-	filters = append(filters, "news.breaking_thumb_news = 1")
+// marshalList encodes a string list the way news_translations stores it
+func marshalList(list []string) (string, error) {
+	if list == nil {
+		list = []string{}
+	}
+	data, err := json.Marshal(list)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
 
-	// Apply filters to query
-	filterQuery := ""
-	if len(filters) > 0 {
-		filterQuery = " AND " + strings.Join(filters, " AND ")
+// nullableID stores a zero foreign key as NULL
+func nullableID(id uint) interface{} {
+	if id == 0 {
+		return nil
 	}
+	return id
+}
 
-	// Get news list
-	newsList, err := r.GetNewsList(req, filterQuery, 3)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get news list: %w", err)
+// nullableString stores an empty string as NULL
+func nullableString(value string) interface{} {
+	if value == "" {
+		return nil
 	}
+	return value
+}
 
-	// Construct the response
-	response := &entity.ThumbnailNewsResponse{
-		Data: newsList,
+// nullableTime stores a time as a UTC DATETIME, or NULL when unset
+func nullableTime(t *time.Time) interface{} {
+	if t == nil || t.IsZero() {
+		return nil
 	}
+	return t.UTC().Format(time.DateTime)
+}
 
-	// Cache the response
-	jsonData, err := json.Marshal(response)
+// parseNullDateTime converts a nullable DATETIME column into a *time.Time
+func parseNullDateTime(value sql.NullString) *time.Time {
+	if !value.Valid {
+		return nil
+	}
+	t := parseDateTime(value)
+	return &t
+}
+
+// parseDateTime converts a MySQL DATETIME column into time.Time, returning the zero time for NULL
+func parseDateTime(value sql.NullString) time.Time {
+	if !value.Valid {
+		return time.Time{}
+	}
+	t, err := time.Parse(time.DateTime, value.String)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal response: %w", err)
+		return time.Time{}
 	}
-	cacheDuration := time.Duration(r.app.Config.RedisExp) * time.Second
-	if err := r.app.Cache.Set(ctx, cacheKey, string(jsonData), cacheDuration); err != nil {
-		return nil, fmt.Errorf("failed to set cache: %w", err)
+	return t
+}
+
+// unmarshalCounts decodes a JSON object of counts, treating NULL and empty values as no counts
+func unmarshalCounts(value string, counts *map[string]int64) error {
+	*counts = map[string]int64{}
+	if value == "" {
+		return nil
 	}
+	return json.Unmarshal([]byte(value), counts)
+}
 
-	return response, nil
+// unmarshalList decodes a JSON string list column, treating NULL and empty values as an empty list
+func unmarshalList(value sql.NullString, list *[]string) error {
+	if !value.Valid || value.String == "" {
+		*list = []string{}
+		return nil
+	}
+	return json.Unmarshal([]byte(value.String), list)
 }
@@ -0,0 +1,284 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/JubaerHossain/cn-api/domain/news/entity"
+	"github.com/JubaerHossain/cn-api/pkg/locale"
+)
+
+// Backfill rules of a placement, see the placements domain
+const (
+	backfillRecent   = "recent"
+	backfillCategory = "category"
+)
+
+// GetPlacementNews returns the articles of a placement: its pinned articles that
+// are published and have not expired, in their order, then the articles of its
+// backfill rule up to the size of the placement
+func (r *NewsRepositoryImpl) GetPlacementNews(req *http.Request, name string) (*entity.PlacementNewsResponse, error) {
+	ctx := req.Context()
+	requested := locale.Negotiate(req)
+	// Placement names never hold an underscore, so placement_<name>_* only matches this placement
+	cacheKey := fmt.Sprintf("placement_%s_%s", name, requested)
+
+	// Check cache first
+	if cachedData, errCache := r.app.Cache.Get(ctx, cacheKey); errCache == nil && cachedData != "" {
+		response := &entity.PlacementNewsResponse{}
+		if err := json.Unmarshal([]byte(cachedData), response); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal cached data: %w", err)
+		}
+		return response, nil
+	}
+
+	var (
+		placementID, maxItems, categoryID uint
+		title, backfill                   string
+	)
+	err := r.app.MDB.QueryRowContext(ctx, "SELECT id, title, max_items, backfill, COALESCE(backfill_category_id, 0) FROM placements WHERE name = ?", name).
+		Scan(&placementID, &title, &maxItems, &backfill, &categoryID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, entity.ErrNewsNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get placement: %w", err)
+	}
+
+	// Pinned articles
+	rows, err := r.app.MDB.QueryContext(ctx, fmt.Sprintf(`
+		SELECT placement_items.news_id, placement_items.expires_at
+		FROM placement_items
+		JOIN news ON news.id = placement_items.news_id
+		WHERE placement_items.placement_id = ? AND %s
+		  AND (placement_items.expires_at IS NULL OR placement_items.expires_at > UTC_TIMESTAMP())
+		ORDER BY placement_items.position ASC, placement_items.news_id DESC
+		LIMIT ?`, entity.PublishedFilter), placementID, maxItems)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query placement items: %w", err)
+	}
+	defer rows.Close()
+
+	var (
+		ids       []uint
+		expiresAt time.Time // Earliest expiry of the served pins
+	)
+	for rows.Next() {
+		var (
+			id      uint
+			expires sql.NullString
+		)
+		if err := rows.Scan(&id, &expires); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		if t := parseDateTime(expires); !t.IsZero() && (expiresAt.IsZero() || t.Before(expiresAt)) {
+			expiresAt = t
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	// Backfill the remaining places
+	if remaining := int(maxItems) - len(ids); remaining > 0 && (backfill == backfillRecent || backfill == backfillCategory) {
+		backfilled, err := r.backfillNews(req, backfill, categoryID, ids, remaining)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, backfilled...)
+	}
+
+	newsList, err := r.newsByIDs(req.Context(), locale.Chain(requested), " AND "+entity.PublishedFilter, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get news list: %w", err)
+	}
+
+	// Construct the response
+	response := &entity.PlacementNewsResponse{
+		Name:   name,
+		Title:  title,
+		Locale: requested,
+		Data:   newsList,
+	}
+
+	// Cache the response, no longer than the first pin expires
+	jsonData, err := json.Marshal(response)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal response: %w", err)
+	}
+	cacheDuration := time.Duration(r.app.Config.RedisExp) * time.Second
+	if !expiresAt.IsZero() {
+		if untilExpiry := time.Until(expiresAt); untilExpiry < cacheDuration {
+			cacheDuration = max(untilExpiry, time.Second)
+		}
+	}
+	if err := r.app.Cache.Set(ctx, cacheKey, string(jsonData), cacheDuration); err != nil {
+		return nil, fmt.Errorf("failed to set cache: %w", err)
+	}
+
+	return response, nil
+}
+
+// backfillNews returns the latest published articles, of the category for the
+// category rule, leaving out the pinned ones
+func (r *NewsRepositoryImpl) backfillNews(req *http.Request, backfill string, categoryID uint, pinned []uint, limit int) ([]uint, error) {
+	join, where := "", ""
+	var args []interface{}
+	if backfill == backfillCategory {
+		join = " JOIN assign_categories ON assign_categories.news_id = news.id"
+		where = " AND assign_categories.news_category_id = ?"
+		args = append(args, categoryID)
+	}
+	if len(pinned) > 0 {
+		where += fmt.Sprintf(" AND news.id NOT IN (%s)", strings.TrimSuffix(strings.Repeat("?,", len(pinned)), ","))
+		for _, id := range pinned {
+			args = append(args, id)
+		}
+	}
+	args = append(args, limit)
+
+	rows, err := r.app.MDB.QueryContext(req.Context(), fmt.Sprintf(`
+		SELECT DISTINCT news.id, news.published_at
+		FROM news%s
+		WHERE %s AND news.published_at IS NOT NULL%s
+		ORDER BY news.published_at DESC, news.id DESC
+		LIMIT ?`, join, entity.PublishedFilter, where), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query backfill news: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []uint
+	for rows.Next() {
+		var (
+			id          uint
+			publishedAt sql.NullString
+		)
+		if err := rows.Scan(&id, &publishedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return ids, nil
+}
+
+// GetNewsPlacements returns the placements among names the article is pinned
+// to. With publishedOnly none are returned unless the article is live.
+func (r *NewsRepositoryImpl) GetNewsPlacements(ctx context.Context, newsID uint, names []string, publishedOnly bool) ([]string, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+	args := []interface{}{newsID}
+	for _, name := range names {
+		args = append(args, name)
+	}
+	where := ""
+	if publishedOnly {
+		where = " AND " + entity.PublishedFilter
+	}
+	rows, err := r.app.MDB.QueryContext(ctx, fmt.Sprintf(`
+		SELECT placements.name
+		FROM placement_items
+		JOIN placements ON placements.id = placement_items.placement_id
+		JOIN news ON news.id = placement_items.news_id
+		WHERE placement_items.news_id = ? AND placements.name IN (%s)%s`,
+		strings.TrimSuffix(strings.Repeat("?,", len(names)), ","), where), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query news placements: %w", err)
+	}
+	defer rows.Close()
+
+	var placements []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		placements = append(placements, name)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return placements, nil
+}
+
+// IsNewsPublished reports whether readers can see the article
+func (r *NewsRepositoryImpl) IsNewsPublished(ctx context.Context, newsID uint) (bool, error) {
+	var count int
+	if err := r.app.MDB.QueryRowContext(ctx, "SELECT COUNT(*) FROM news WHERE id = ? AND "+entity.PublishedFilter, newsID).Scan(&count); err != nil {
+		return false, fmt.Errorf("failed to check news state: %w", err)
+	}
+	return count > 0, nil
+}
+
+// ExpirePlacementItems removes the pins that have expired and returns them
+func (r *NewsRepositoryImpl) ExpirePlacementItems(ctx context.Context) ([]entity.LiveNews, error) {
+	tx, err := r.app.MDB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT placements.name, placement_items.placement_id, placement_items.news_id
+		FROM placement_items
+		JOIN placements ON placements.id = placement_items.placement_id
+		WHERE placement_items.expires_at <= UTC_TIMESTAMP()
+		FOR UPDATE`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query expired placement items: %w", err)
+	}
+	defer rows.Close()
+
+	var (
+		expired []entity.LiveNews
+		ids     [][2]uint
+	)
+	for rows.Next() {
+		var (
+			item        entity.LiveNews
+			placementID uint
+		)
+		if err := rows.Scan(&item.Placement, &placementID, &item.NewsID); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		expired = append(expired, item)
+		ids = append(ids, [2]uint{placementID, item.NewsID})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	rows.Close()
+
+	for _, id := range ids {
+		if _, err := tx.ExecContext(ctx, "DELETE FROM placement_items WHERE placement_id = ? AND news_id = ?", id[0], id[1]); err != nil {
+			return nil, fmt.Errorf("failed to delete expired placement item: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	// Clear cache of the placements the pins left
+	cleared := map[string]bool{}
+	for _, item := range expired {
+		if cleared[item.Placement] {
+			continue
+		}
+		cleared[item.Placement] = true
+		if _, err := r.app.Cache.ClearPattern(ctx, "placement_"+item.Placement+"_*"); err != nil {
+			return nil, err
+		}
+	}
+	return expired, nil
+}
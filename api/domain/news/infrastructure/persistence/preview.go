@@ -0,0 +1,191 @@
+package persistence
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/JubaerHossain/cn-api/domain/news/entity"
+	"github.com/JubaerHossain/cn-api/pkg/content"
+)
+
+// previewColumns are the news_previews columns scanned by scanPreview
+const previewColumns = `
+	news_previews.id, news_previews.news_id, news_previews.revision_id, news_revisions.locale,
+	news_previews.expires_at, news_previews.revoked_at, news_previews.revoked_by,
+	news_previews.created_by, news_previews.created_at`
+
+// CreatePreview stores a preview link and sets its ID
+func (r *NewsRepositoryImpl) CreatePreview(req *http.Request, link *entity.NewsPreviewLink) error {
+	result, err := r.app.MDB.ExecContext(req.Context(), `
+		INSERT INTO news_previews (news_id, revision_id, expires_at, created_by, created_at)
+		VALUES (?, ?, ?, ?, ?)`,
+		link.NewsID, link.RevisionID, nullableTime(&link.ExpiresAt), link.CreatedBy, nullableTime(&link.CreatedAt))
+	if err != nil {
+		return fmt.Errorf("failed to insert preview: %w", err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get preview ID: %w", err)
+	}
+	link.ID = uint(id)
+	return nil
+}
+
+// GetPreviews lists the preview links of an article, newest first
+func (r *NewsRepositoryImpl) GetPreviews(req *http.Request, newsID uint) ([]*entity.NewsPreviewLink, error) {
+	rows, err := r.app.MDB.QueryContext(req.Context(), `
+		SELECT `+previewColumns+`
+		FROM news_previews
+		JOIN news_revisions ON news_revisions.id = news_previews.revision_id
+		WHERE news_previews.news_id = ?
+		ORDER BY news_previews.id DESC`, newsID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query previews: %w", err)
+	}
+	defer rows.Close()
+
+	links := []*entity.NewsPreviewLink{}
+	for rows.Next() {
+		link, err := scanPreview(rows)
+		if err != nil {
+			return nil, err
+		}
+		links = append(links, link)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return links, nil
+}
+
+// GetPreview returns a preview link by ID
+func (r *NewsRepositoryImpl) GetPreview(req *http.Request, previewID uint) (*entity.NewsPreviewLink, error) {
+	row := r.app.MDB.QueryRowContext(req.Context(), `
+		SELECT `+previewColumns+`
+		FROM news_previews
+		JOIN news_revisions ON news_revisions.id = news_previews.revision_id
+		WHERE news_previews.id = ?`, previewID)
+	link, err := scanPreview(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, entity.ErrPreviewNotFound
+	}
+	return link, err
+}
+
+// RevokePreview ends a preview link before its expiry. Revoking twice keeps the first revocation.
+func (r *NewsRepositoryImpl) RevokePreview(req *http.Request, link *entity.NewsPreviewLink, userID uint) error {
+	now := time.Now().UTC().Truncate(time.Second)
+	result, err := r.app.MDB.ExecContext(req.Context(), `
+		UPDATE news_previews SET revoked_at = ?, revoked_by = ?
+		WHERE id = ? AND revoked_at IS NULL`, nullableTime(&now), userID, link.ID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke preview: %w", err)
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected > 0 {
+		link.RevokedAt = &now
+		link.RevokedBy = &userID
+	}
+	return nil
+}
+
+// GetPreviewNews renders the revision of a preview link as an article, with
+// the current image and categories of the article. Nothing here is cached.
+func (r *NewsRepositoryImpl) GetPreviewNews(req *http.Request, link *entity.NewsPreviewLink) (*entity.NewsDetails, error) {
+	ctx := req.Context()
+	revision, err := r.GetNewsRevision(req, link.NewsID, link.RevisionID)
+	if errors.Is(err, entity.ErrRevisionNotFound) {
+		return nil, entity.ErrPreviewNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		news        entity.NewsDetails
+		publishedAt sql.NullString
+		placeholder string
+	)
+	err = r.app.MDB.QueryRowContext(ctx, `
+		SELECT
+		    news.id,
+		    news.type,
+		    news.created_at,
+		    news.published_at,
+		    COALESCE(news.path_small, ''),
+		    COALESCE(news.path_medium, ''),
+		    COALESCE(news.path_large, ''),
+		    COALESCE(news.path_small_webp, ''),
+		    COALESCE(news.path_medium_webp, ''),
+		    COALESCE(news.path_large_webp, ''),
+		    COALESCE(news.image_placeholder, '')
+		FROM news
+		WHERE news.id = ?`, link.NewsID,
+	).Scan(
+		&news.ID,
+		&news.Type,
+		&news.CreatedAt,
+		&publishedAt,
+		&news.Images.Small,
+		&news.Images.Medium,
+		&news.Images.Large,
+		&news.Images.SmallWebp,
+		&news.Images.MediumWebp,
+		&news.Images.LargeWebp,
+		&placeholder,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, entity.ErrPreviewNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get news: %w", err)
+	}
+
+	// Revisions saved before the content pipeline existed may hold unsafe markup
+	news.Content = content.Sanitize(revision.Content, r.app.Config.Domain)
+	news.Locale = revision.Locale
+	news.Title = revision.Title
+	news.Slug = revision.Slug
+	news.SubTitle = revision.SubTitle
+	news.Excerpt = content.Excerpt(revision.SubTitle, news.Content)
+	news.Tags = revision.Tags
+	if news.Tags == nil {
+		news.Tags = []string{}
+	}
+	news.MetaTitle = revision.MetaTitle
+	news.MetaDesc = revision.MetaDescription
+	news.MetaKeywords = revision.MetaKeywords
+	news.UpdatedAt = revision.CreatedAt.Format(time.DateTime)
+	news.PublishedAt = publishedAt.String // Empty until the article is first published
+	news.Author = entity.Author{ID: revision.AuthorID, Name: revision.AuthorName}
+	news.URL = fmt.Sprintf("%s/news/%s", r.app.Config.Domain, news.Slug)
+	news.Images.Loading = r.loadingImage(placeholder)
+	news.Related = []*entity.ScrollNews{}
+	if news.Categories, err = r.newsCategories(ctx, link.NewsID); err != nil {
+		return nil, err
+	}
+	return &news, nil
+}
+
+// scanPreview reads a row of previewColumns
+func scanPreview(row interface{ Scan(...interface{}) error }) (*entity.NewsPreviewLink, error) {
+	var (
+		link                            entity.NewsPreviewLink
+		expiresAt, revokedAt, createdAt sql.NullString
+		revokedBy                       sql.NullInt64
+	)
+	if err := row.Scan(&link.ID, &link.NewsID, &link.RevisionID, &link.Locale,
+		&expiresAt, &revokedAt, &revokedBy, &link.CreatedBy, &createdAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to scan preview: %w", err)
+	}
+	link.ExpiresAt = parseDateTime(expiresAt)
+	link.RevokedAt = parseNullDateTime(revokedAt)
+	link.RevokedBy = nullableUint(revokedBy)
+	link.CreatedAt = parseDateTime(createdAt)
+	return &link, nil
+}
@@ -1,50 +1,114 @@
 package persistence
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/JubaerHossain/cn-api/domain/news/entity"
 )
 
-func (r *NewsRepositoryImpl) GetNewsList(req *http.Request, where string, limit uint) ([]*entity.ScrollNews, error) {
-	ctx := req.Context()
+// GetNewsList returns published list items. For every article the first
+// translation found along the locale chain is served, once per category of the
+// article; args are bound to the placeholders in where. A limit of 0 returns
+// every matching row.
+func (r *NewsRepositoryImpl) GetNewsList(req *http.Request, locales []string, where string, limit uint, args ...interface{}) ([]*entity.ScrollNews, error) {
+	return r.newsList(req.Context(), locales, where, limit, args...)
+}
+
+// newsByIDs returns the published list items of the given articles in the order
+// of ids, one per article however many categories it is listed in. Articles
+// that are missing or filtered out by where are skipped.
+func (r *NewsRepositoryImpl) newsByIDs(ctx context.Context, locales []string, where string, ids []uint, args ...interface{}) ([]*entity.ScrollNews, error) {
+	if len(ids) == 0 {
+		return []*entity.ScrollNews{}, nil
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(ids)), ",")
+	queryArgs := make([]interface{}, 0, len(args)+len(ids))
+	queryArgs = append(queryArgs, args...)
+	for _, id := range ids {
+		queryArgs = append(queryArgs, id)
+	}
+	// No row limit: the rows per article depend on its categories, ids bounds the result
+	list, err := r.newsList(ctx, locales, fmt.Sprintf("%s AND news.id IN (%s)", where, placeholders), 0, queryArgs...)
+	if err != nil {
+		return nil, err
+	}
+	return rankNewsList(list, ids, len(ids)), nil
+}
+
+// newsList is GetNewsList for callers without a request
+func (r *NewsRepositoryImpl) newsList(ctx context.Context, locales []string, where string, limit uint, args ...interface{}) ([]*entity.ScrollNews, error) {
 
 	// Base SQL query
 	baseQuery := `
 	SELECT DISTINCT 
 	    news.id, 
+	    news_translations.locale, 
 	    news_translations.title, 
 	    news_translations.slug, 
 	    news.type, 
 	    news_translations.sub_title, 
-	    news_translations.tags, 
+	    news_translations.excerpt,
 	    news_translations.content, 
 	    news_translations.meta_title, 
 	    news_translations.meta_description, 
 	    news_translations.meta_keywords, 
 	    news.updated_at, 
+	    news.created_at, 
+	    COALESCE(news.published_at, news.created_at),
 	    users.name as author, 
 	    news.path_small, 
 	    news.path_medium, 
 	    news.path_large, 
+	    COALESCE(news.path_small_webp, ''),
+	    COALESCE(news.path_medium_webp, ''),
+	    COALESCE(news.path_large_webp, ''),
+	    COALESCE(news.image_placeholder, ''),
 	    news.status_id, 
-	    news_categories.title
+	    news_categories.title,
+	    news_categories.slug,
+	    news.comment_count,
+	    COALESCE(CAST(news.reactions AS CHAR), ''),
+	    news.reaction_count,
+	    news.poll_vote_count
 	FROM news
 	JOIN news_translations ON news.id = news_translations.news_id
 	JOIN assign_categories ON news.id = assign_categories.news_id
 	JOIN news_categories ON assign_categories.news_category_id = news_categories.id
 	JOIN users ON news.created_by = users.id
-	WHERE news_translations.locale = 'en' %s
+	WHERE news_translations.locale = (
+	    SELECT nt.locale FROM news_translations nt
+	    WHERE nt.news_id = news.id AND nt.locale IN (%[1]s)
+	    ORDER BY FIELD(nt.locale, %[1]s)
+	    LIMIT 1
+	) %[2]s
 	ORDER BY news.id DESC
-	LIMIT ?;
+	%[3]s;
 	`
 
-	// Combine base query with where clause
-	query := fmt.Sprintf(baseQuery, where)
+	// Combine base query with the locale chain and where clause
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(locales)), ",")
+	limitClause := ""
+	if limit > 0 {
+		limitClause = "LIMIT ?"
+	}
+	query := fmt.Sprintf(baseQuery, placeholders, where, limitClause)
 
-	rows, err := r.app.MDB.QueryContext(ctx, query, limit)
+	queryArgs := make([]interface{}, 0, 2*len(locales)+len(args)+1)
+	for i := 0; i < 2; i++ {
+		for _, l := range locales {
+			queryArgs = append(queryArgs, l)
+		}
+	}
+	queryArgs = append(queryArgs, args...)
+	if limit > 0 {
+		queryArgs = append(queryArgs, limit)
+	}
+
+	rows, err := r.app.MDB.QueryContext(ctx, query, queryArgs...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to execute query: %w", err)
 	}
@@ -54,39 +118,52 @@ func (r *NewsRepositoryImpl) GetNewsList(req *http.Request, where string, limit
 	var newsList []*entity.ScrollNews
 	for rows.Next() {
 		var (
-			news   entity.ScrollNews
-			tags   string
+			news          entity.ScrollNews
 			meta_keywords string
+			placeholder   string
+			reactions     string
 		)
 		if err := rows.Scan(
 			&news.ID,
+			&news.Locale,
 			&news.Title,
 			&news.Slug,
 			&news.Type,
 			&news.SubTitle,
-			&tags,
+			&news.Excerpt,
 			&news.Content,
 			&news.MetaTitle,
 			&news.MetaDesc,
 			&meta_keywords,
 			&news.UpdatedAt,
+			&news.CreatedAt,
+			&news.PublishedAt,
 			&news.Author,
 			&news.PathSmall,
 			&news.PathMedium,
 			&news.PathLarge,
+			&news.PathSmallWebp,
+			&news.PathMediumWebp,
+			&news.PathLargeWebp,
+			&placeholder,
 			&news.Status,
 			&news.Category,
+			&news.CategorySlug,
+			&news.CommentCount,
+			&reactions,
+			&news.ReactionCount,
+			&news.PollVoteCount,
 		); err != nil {
 			return nil, fmt.Errorf("failed to scan row: %w", err)
 		}
-		if err := json.Unmarshal([]byte(tags), &news.Tags); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal tags: %w", err)
+		if err := unmarshalCounts(reactions, &news.Reactions); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal reactions: %w", err)
 		}
 		if err := json.Unmarshal([]byte(meta_keywords), &news.MetaKeywords); err != nil {
 			return nil, fmt.Errorf("failed to unmarshal meta keywords: %w", err)
 		}
 		news.URL = fmt.Sprintf("%s/news/%s", r.app.Config.Domain, news.Slug)
-		news.Loading = fmt.Sprintf("%s/uploads/%s", r.app.Config.Domain, "default/loading.png")
+		news.Loading = r.loadingImage(placeholder)
 		newsList = append(newsList, &news)
 	}
 
@@ -94,5 +171,21 @@ func (r *NewsRepositoryImpl) GetNewsList(req *http.Request, where string, limit
 		return nil, fmt.Errorf("rows iteration error: %w", err)
 	}
 
+	// Tags of all listed articles are loaded in one query
+	ids := make([]uint, 0, len(newsList))
+	for _, news := range newsList {
+		ids = append(ids, news.ID)
+	}
+	tags, err := r.tagsOf(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, news := range newsList {
+		news.Tags = tags[news.ID][news.Locale]
+		if news.Tags == nil {
+			news.Tags = []string{}
+		}
+	}
+
 	return newsList, nil
 }
@@ -0,0 +1,208 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/JubaerHossain/cn-api/domain/news/entity"
+	"github.com/JubaerHossain/cn-api/pkg/config"
+	"github.com/JubaerHossain/cn-api/pkg/locale"
+	"github.com/JubaerHossain/cn-api/pkg/ranking"
+	"github.com/JubaerHossain/cn-api/pkg/redisclient"
+)
+
+const (
+	// rankingSlack is the number of extra ranked IDs read to make up for articles
+	// that were unpublished since the ranking was built
+	rankingSlack = 10
+	// rankingCacheDuration is how long a ranked list is served from the cache
+	rankingCacheDuration = time.Minute
+)
+
+var (
+	ranker     *ranking.Ranker
+	rankerOnce sync.Once
+)
+
+// rankings returns the ranker shared by every repository. Rankings are kept in
+// Redis when it is enabled, and in memory otherwise.
+func (r *NewsRepositoryImpl) rankings() *ranking.Ranker {
+	rankerOnce.Do(func() {
+		var store ranking.Store = ranking.NewMemoryStore()
+		if client := redisclient.Get(r.app.Config); client != nil {
+			store = ranking.NewRedisStore(client)
+		}
+		ranker = ranking.New(store, time.Duration(config.GlobalConfig.RankingRefresh)*time.Second)
+	})
+	return ranker
+}
+
+// GetRanking returns the published articles ranked highest in the window, limited
+// to one category when categorySlug is set
+func (r *NewsRepositoryImpl) GetRanking(req *http.Request, window ranking.Window, categorySlug string, limit int) (*entity.ScrollNewsResponse, error) {
+	ctx := req.Context()
+	requested := locale.Negotiate(req)
+	cacheKey := fmt.Sprintf("ranking_%s_%s_%s_%d", window.Name, categorySlug, requested, limit)
+
+	// Check cache first
+	if cachedData, errCache := r.app.Cache.Get(ctx, cacheKey); errCache == nil && cachedData != "" {
+		response := &entity.ScrollNewsResponse{}
+		if err := json.Unmarshal([]byte(cachedData), response); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal cached data: %w", err)
+		}
+		return response, nil
+	}
+
+	scope, categoryID := "all", uint(0)
+	if categorySlug != "" {
+		err := r.app.MDB.QueryRowContext(ctx, "SELECT id FROM news_categories WHERE slug = ? AND status_id = ?", categorySlug, entity.StatusActive).Scan(&categoryID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, entity.ErrNewsNotFound
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get category: %w", err)
+		}
+		scope = fmt.Sprintf("category:%d", categoryID)
+	}
+
+	ids, err := r.rankings().Top(ctx, window, scope, limit+rankingSlack, func(ctx context.Context, since time.Time) ([]ranking.Bucket, error) {
+		return r.viewBuckets(ctx, since, categoryID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	newsList, err := r.newsByIDs(ctx, locale.Chain(requested), " AND "+entity.PublishedFilter, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get news list: %w", err)
+	}
+	if len(newsList) > limit {
+		newsList = newsList[:limit]
+	}
+
+	// Construct the response
+	response := &entity.ScrollNewsResponse{
+		Locale: requested,
+		Data:   newsList,
+	}
+
+	// Cache the response, rankings move too fast for the usual cache duration
+	jsonData, err := json.Marshal(response)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal response: %w", err)
+	}
+	if err := r.app.Cache.Set(ctx, cacheKey, string(jsonData), rankingCacheDuration); err != nil {
+		return nil, fmt.Errorf("failed to set cache: %w", err)
+	}
+
+	return response, nil
+}
+
+// rankNewsList orders the articles as in ids, keeping one row per article. An
+// article listed in several categories comes back once per category.
+func rankNewsList(list []*entity.ScrollNews, ids []uint, limit int) []*entity.ScrollNews {
+	byID := make(map[uint]*entity.ScrollNews, len(list))
+	for _, news := range list {
+		if _, ok := byID[news.ID]; !ok {
+			byID[news.ID] = news
+		}
+	}
+	ranked := make([]*entity.ScrollNews, 0, limit)
+	for _, id := range ids {
+		if news, ok := byID[id]; ok && len(ranked) < limit {
+			ranked = append(ranked, news)
+		}
+	}
+	return ranked
+}
+
+// viewBuckets returns the hourly views of published articles since the given time,
+// limited to one category when categoryID is set
+func (r *NewsRepositoryImpl) viewBuckets(ctx context.Context, since time.Time, categoryID uint) ([]ranking.Bucket, error) {
+	query := `
+		SELECT news_view_stats.news_id, news_view_stats.bucket, news_view_stats.views
+		FROM news_view_stats
+		JOIN news ON news.id = news_view_stats.news_id`
+	var args []interface{}
+	if categoryID != 0 {
+		query += " JOIN assign_categories ON assign_categories.news_id = news.id AND assign_categories.news_category_id = ?"
+		args = append(args, categoryID)
+	}
+	query += " WHERE " + entity.PublishedFilter + " AND news_view_stats.bucket >= ?"
+	args = append(args, since.UTC().Truncate(time.Hour).Format("2006-01-02 15:04:05"))
+
+	rows, err := r.app.MDB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query view stats: %w", err)
+	}
+	defer rows.Close()
+
+	var buckets []ranking.Bucket
+	for rows.Next() {
+		var (
+			bucket ranking.Bucket
+			start  sql.NullString
+		)
+		if err := rows.Scan(&bucket.ID, &start, &bucket.Views); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		bucket.Start = parseDateTime(start)
+		buckets = append(buckets, bucket)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return buckets, nil
+}
+
+// recordRankings adds flushed article views to the built rankings of the site
+// and of the articles' categories
+func (r *NewsRepositoryImpl) recordRankings(ctx context.Context, news map[uint]int64) error {
+	if len(news) == 0 {
+		return nil
+	}
+	ranker := r.rankings()
+	if err := ranker.Record(ctx, "all", news); err != nil {
+		return err
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(news)), ",")
+	args := make([]interface{}, 0, len(news))
+	for id := range news {
+		args = append(args, id)
+	}
+	rows, err := r.app.MDB.QueryContext(ctx, fmt.Sprintf("SELECT news_id, news_category_id FROM assign_categories WHERE news_id IN (%s)", placeholders), args...)
+	if err != nil {
+		return fmt.Errorf("failed to query news categories: %w", err)
+	}
+	defer rows.Close()
+
+	byCategory := map[uint]map[uint]int64{}
+	for rows.Next() {
+		var newsID, categoryID uint
+		if err := rows.Scan(&newsID, &categoryID); err != nil {
+			return fmt.Errorf("failed to scan row: %w", err)
+		}
+		if byCategory[categoryID] == nil {
+			byCategory[categoryID] = map[uint]int64{}
+		}
+		byCategory[categoryID][newsID] = news[newsID]
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	for categoryID, views := range byCategory {
+		if err := ranker.Record(ctx, fmt.Sprintf("category:%d", categoryID), views); err != nil {
+			return err
+		}
+	}
+	return nil
+}
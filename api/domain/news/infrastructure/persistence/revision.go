@@ -0,0 +1,135 @@
+package persistence
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/JubaerHossain/cn-api/domain/news/entity"
+)
+
+// GetNewsRevisions lists the revisions of an article, newest first, optionally for one locale
+func (r *NewsRepositoryImpl) GetNewsRevisions(req *http.Request, newsID uint, revisionLocale string) ([]*entity.NewsRevisionSummary, error) {
+	query := `
+		SELECT news_revisions.id, news_revisions.locale, news_revisions.number, news_revisions.title,
+		       news_revisions.author_id, COALESCE(users.name, ''), news_revisions.restored_from, news_revisions.created_at
+		FROM news_revisions
+		LEFT JOIN users ON users.id = news_revisions.author_id
+		WHERE news_revisions.news_id = ?`
+	args := []interface{}{newsID}
+	if revisionLocale != "" {
+		query += " AND news_revisions.locale = ?"
+		args = append(args, revisionLocale)
+	}
+	query += " ORDER BY news_revisions.id DESC"
+
+	rows, err := r.app.MDB.QueryContext(req.Context(), query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query revisions: %w", err)
+	}
+	defer rows.Close()
+
+	revisions := []*entity.NewsRevisionSummary{}
+	for rows.Next() {
+		var (
+			revision     entity.NewsRevisionSummary
+			restoredFrom sql.NullInt64
+			createdAt    sql.NullString
+		)
+		if err := rows.Scan(&revision.ID, &revision.Locale, &revision.Number, &revision.Title,
+			&revision.AuthorID, &revision.AuthorName, &restoredFrom, &createdAt); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		revision.RestoredFrom = nullableUint(restoredFrom)
+		revision.CreatedAt = parseDateTime(createdAt)
+		revisions = append(revisions, &revision)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return revisions, nil
+}
+
+// GetNewsRevision returns the full snapshot of a revision of the article
+func (r *NewsRepositoryImpl) GetNewsRevision(req *http.Request, newsID, revisionID uint) (*entity.NewsRevision, error) {
+	var (
+		revision           entity.NewsRevision
+		tags, metaKeywords sql.NullString
+		restoredFrom       sql.NullInt64
+		createdAt          sql.NullString
+	)
+	err := r.app.MDB.QueryRowContext(req.Context(), `
+		SELECT news_revisions.id, news_revisions.news_id, news_revisions.locale, news_revisions.number,
+		       news_revisions.title, news_revisions.slug, COALESCE(news_revisions.sub_title, ''), news_revisions.tags,
+		       COALESCE(news_revisions.content, ''), COALESCE(news_revisions.meta_title, ''),
+		       COALESCE(news_revisions.meta_description, ''), news_revisions.meta_keywords,
+		       news_revisions.author_id, COALESCE(users.name, ''), news_revisions.restored_from, news_revisions.created_at
+		FROM news_revisions
+		LEFT JOIN users ON users.id = news_revisions.author_id
+		WHERE news_revisions.id = ? AND news_revisions.news_id = ?`, revisionID, newsID,
+	).Scan(
+		&revision.ID, &revision.NewsID, &revision.Locale, &revision.Number,
+		&revision.Title, &revision.Slug, &revision.SubTitle, &tags,
+		&revision.Content, &revision.MetaTitle,
+		&revision.MetaDescription, &metaKeywords,
+		&revision.AuthorID, &revision.AuthorName, &restoredFrom, &createdAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, entity.ErrRevisionNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get revision: %w", err)
+	}
+	if err := unmarshalList(tags, &revision.Tags); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal tags: %w", err)
+	}
+	if err := unmarshalList(metaKeywords, &revision.MetaKeywords); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal meta keywords: %w", err)
+	}
+	revision.RestoredFrom = nullableUint(restoredFrom)
+	revision.CreatedAt = parseDateTime(createdAt)
+	return &revision, nil
+}
+
+// RestoreNewsRevision writes the revision back to its translation. The restore is
+// itself stored as a new revision pointing at the restored one, history is never rewritten.
+func (r *NewsRepositoryImpl) RestoreNewsRevision(news *entity.News, revision *entity.NewsRevision, authorID uint, req *http.Request) error {
+	ctx := req.Context()
+	// Revisions saved before the content pipeline existed may hold unsafe markup
+	translation := revision.Translation()
+	if err := r.prepareContent(translation); err != nil {
+		return err
+	}
+	tx, err := r.app.MDB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := saveTranslation(req, tx, news.ID, translation, authorID, &revision.ID); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, "UPDATE news SET updated_by = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?", authorID, news.ID); err != nil {
+		return fmt.Errorf("failed to update news: %w", err)
+	}
+	if err := markSitemapsStale(req, tx, news.ID); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	// Clear cache
+	return CacheClear(req, r.app.Cache)
+}
+
+// nullableUint converts a nullable integer column into a *uint
+func nullableUint(value sql.NullInt64) *uint {
+	if !value.Valid {
+		return nil
+	}
+	v := uint(value.Int64)
+	return &v
+}
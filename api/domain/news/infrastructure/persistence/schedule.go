@@ -0,0 +1,111 @@
+package persistence
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/JubaerHossain/cn-api/domain/news/entity"
+)
+
+// scheduleBatchSize caps the articles promoted or demoted in one scheduler run
+const scheduleBatchSize = 100
+
+// PublishScheduledNews publishes scheduled articles whose publish_at has passed
+// and unpublishes published articles whose unpublish_at has passed. Each article
+// moves in its own transaction with a workflow history row. Returns the IDs of
+// the articles published and unpublished, also when it fails part way.
+func (r *NewsRepositoryImpl) PublishScheduledNews(ctx context.Context) ([]uint, []uint, error) {
+	// CacheClear and the transaction helpers only use the request for its context
+	req := (&http.Request{}).WithContext(ctx)
+
+	publish := &entity.Transition{From: entity.StateScheduled, To: entity.StatePublished}
+	published, err := r.applySchedule(req, publish, "publish_at", "Scheduled publish", "")
+	if err != nil {
+		return published, nil, err
+	}
+
+	// Clear unpublish_at so a later manual republish is not taken down again at once
+	unpublish := &entity.Transition{From: entity.StatePublished, To: entity.StateUnpublished}
+	unpublished, err := r.applySchedule(req, unpublish, "unpublish_at", "Scheduled unpublish", ", unpublish_at = NULL")
+	if err != nil {
+		return published, unpublished, err
+	}
+
+	if len(published)+len(unpublished) > 0 {
+		if err := CacheClear(req, r.app.Cache); err != nil {
+			return published, unpublished, err
+		}
+	}
+	return published, unpublished, nil
+}
+
+// applySchedule moves the articles in transition.From whose dueColumn has passed
+// to transition.To and returns the IDs of those it moved
+func (r *NewsRepositoryImpl) applySchedule(req *http.Request, transition *entity.Transition, dueColumn, comment, extraSet string) ([]uint, error) {
+	ctx := req.Context()
+	rows, err := r.app.MDB.QueryContext(ctx, fmt.Sprintf(`
+		SELECT id FROM news
+		WHERE publish_status_id = ? AND %s IS NOT NULL AND %s <= UTC_TIMESTAMP()
+		ORDER BY %s ASC
+		LIMIT %d`, dueColumn, dueColumn, dueColumn, scheduleBatchSize), transition.From)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query due news: %w", err)
+	}
+	var ids []uint
+	for rows.Next() {
+		var id uint
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	history := &entity.NewsTransitionHistory{Comment: comment, Role: string(entity.RoleScheduler)}
+	var moved []uint
+	for _, id := range ids {
+		ok, err := r.applyScheduledTransition(req, id, transition, history, extraSet)
+		if err != nil {
+			return moved, err
+		}
+		if ok {
+			moved = append(moved, id)
+		}
+	}
+	return moved, nil
+}
+
+// applyScheduledTransition moves one article unless an editor changed its state first
+func (r *NewsRepositoryImpl) applyScheduledTransition(req *http.Request, newsID uint, transition *entity.Transition, history *entity.NewsTransitionHistory, extraSet string) (bool, error) {
+	ctx := req.Context()
+	tx, err := r.app.MDB.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, fmt.Sprintf(`
+		UPDATE news SET publish_status_id = ?, updated_at = CURRENT_TIMESTAMP%s%s
+		WHERE id = ? AND publish_status_id = ?`, publishedAtSet(transition), extraSet),
+		transition.To, newsID, transition.From,
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to update state of news %d: %w", newsID, err)
+	}
+	if affected, err := result.RowsAffected(); err != nil || affected == 0 {
+		return false, err
+	}
+
+	if err := recordTransition(req, tx, newsID, transition, history); err != nil {
+		return false, err
+	}
+	if err := markSitemapsStale(req, tx, newsID); err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
+}
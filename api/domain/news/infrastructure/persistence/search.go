@@ -0,0 +1,216 @@
+package persistence
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/JubaerHossain/cn-api/domain/news/entity"
+	"github.com/JubaerHossain/cn-api/pkg/locale"
+	utilQuery "github.com/JubaerHossain/cn-api/pkg/utils"
+)
+
+// snippetWidth is the length in runes of a highlighted search snippet
+const snippetWidth = 200
+
+// recencyHalfLifeDays controls how quickly older articles lose rank: an article
+// this many days old scores half of an equally relevant article published now
+const recencyHalfLifeDays = 30
+
+// rowScanner is implemented by both *sql.Rows and pgx.Rows
+type rowScanner interface {
+	Next() bool
+	Scan(dest ...interface{}) error
+	Err() error
+}
+
+// SearchNews runs a ranked full-text search over published translations. MySQL
+// uses the FULLTEXT index through app.MDB, Postgres the search_vector column
+// through app.DB.
+func (r *NewsRepositoryImpl) SearchNews(req *http.Request) (*entity.SearchResponse, error) {
+	ctx := req.Context()
+	queryValues := req.URL.Query()
+
+	search := strings.TrimSpace(queryValues.Get("q"))
+	if search == "" {
+		return nil, fmt.Errorf("%w: q is required", entity.ErrInvalidQuery)
+	}
+	searchLocale := locale.Negotiate(req)
+
+	cacheKey := fmt.Sprintf("search_news_%s_%s", searchLocale, queryValues.Encode())
+	if cachedData, errCache := r.app.Cache.Get(ctx, cacheKey); errCache == nil && cachedData != "" {
+		response := &entity.SearchResponse{}
+		if err := json.Unmarshal([]byte(cachedData), response); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal cached data: %w", err)
+		}
+		return response, nil
+	}
+
+	isMySQL := r.app.Config.DBType == "mysql"
+	var args []interface{}
+	// bind adds an argument and returns its driver specific placeholder
+	bind := func(value interface{}) string {
+		args = append(args, value)
+		if isMySQL {
+			return "?"
+		}
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	// Articles date from publication, imported and legacy rows without one from creation
+	publishedAt := "COALESCE(news.published_at, news.created_at)"
+	var match, relevance, age, createdAt string
+	if isMySQL {
+		// Positional placeholders: one for the score in SELECT, one for the WHERE match
+		relevance = fmt.Sprintf("MATCH(nt.title, nt.sub_title, nt.content, nt.tags) AGAINST (%s IN NATURAL LANGUAGE MODE)", bind(search))
+		match = fmt.Sprintf("MATCH(nt.title, nt.sub_title, nt.content, nt.tags) AGAINST (%s IN NATURAL LANGUAGE MODE)", bind(search))
+		age = "TIMESTAMPDIFF(SECOND, " + publishedAt + ", NOW()) / 86400"
+		createdAt = "DATE_FORMAT(" + publishedAt + ", '%Y-%m-%d %H:%i:%s')"
+	} else {
+		tsQuery := fmt.Sprintf("websearch_to_tsquery('simple', %s)", bind(search))
+		match = "nt.search_vector @@ " + tsQuery
+		relevance = "ts_rank_cd(nt.search_vector, " + tsQuery + ")"
+		age = "EXTRACT(EPOCH FROM (NOW() - " + publishedAt + ")) / 86400"
+		createdAt = "to_char(" + publishedAt + ", 'YYYY-MM-DD HH24:MI:SS')"
+	}
+	// Relevance decays with age so recent articles outrank equally relevant old ones
+	score := fmt.Sprintf("(%s) / (1 + GREATEST(%s, 0) / %d)", relevance, age, recencyHalfLifeDays)
+
+	baseQuery := fmt.Sprintf(`
+	SELECT
+	    news.id,
+	    nt.locale,
+	    nt.title,
+	    nt.slug,
+	    news.type,
+	    COALESCE(nt.sub_title, ''),
+	    COALESCE(CAST(nt.tags AS CHAR), ''),
+	    COALESCE(nt.content, ''),
+	    COALESCE(news.path_small, ''),
+	    COALESCE(news.path_medium, ''),
+	    COALESCE(news.path_large, ''),
+	    %s AS created_at,
+	    %s AS score
+	FROM news
+	JOIN news_translations nt ON news.id = nt.news_id`, createdAt, score)
+	if !isMySQL {
+		baseQuery = strings.Replace(baseQuery, "CAST(nt.tags AS CHAR)", "nt.tags::text", 1)
+	}
+
+	filters := []string{
+		match,
+		entity.PublishedFilter,
+		"nt.locale = " + bind(searchLocale),
+	}
+
+	if category := queryValues.Get("category"); category != "" {
+		filters = append(filters, fmt.Sprintf(`EXISTS (
+		SELECT 1 FROM assign_categories ac
+		JOIN news_categories nc ON nc.id = ac.news_category_id
+		WHERE ac.news_id = news.id AND nc.slug = %s)`, bind(category)))
+	}
+	if newsType := queryValues.Get("type"); newsType != "" {
+		filters = append(filters, "news.type = "+bind(newsType))
+	}
+	if from := queryValues.Get("from"); from != "" {
+		date, err := time.Parse(time.DateOnly, from)
+		if err != nil {
+			return nil, fmt.Errorf("%w: from must be a YYYY-MM-DD date", entity.ErrInvalidQuery)
+		}
+		filters = append(filters, publishedAt+" >= "+bind(date))
+	}
+	if to := queryValues.Get("to"); to != "" {
+		date, err := time.Parse(time.DateOnly, to)
+		if err != nil {
+			return nil, fmt.Errorf("%w: to must be a YYYY-MM-DD date", entity.ErrInvalidQuery)
+		}
+		// The end date is inclusive
+		filters = append(filters, publishedAt+" < "+bind(date.AddDate(0, 0, 1)))
+	}
+
+	filterQuery := " WHERE " + strings.Join(filters, " AND ")
+
+	// Pagination and limits
+	pagination, limit, offset, err := utilQuery.Paginate(req, r.app, baseQuery, filterQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("pagination error: %w", err)
+	}
+	query := fmt.Sprintf("%s%s ORDER BY score DESC, news.id DESC LIMIT %d OFFSET %d", baseQuery, filterQuery, limit, offset)
+
+	var rows rowScanner
+	if isMySQL {
+		mysqlRows, err := r.app.MDB.QueryContext(ctx, query, args...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to execute search: %w", err)
+		}
+		defer mysqlRows.Close()
+		rows = mysqlRows
+	} else {
+		pgRows, err := r.app.DB.Query(ctx, query, args...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to execute search: %w", err)
+		}
+		defer pgRows.Close()
+		rows = pgRows
+	}
+
+	terms := utilQuery.SearchTerms(search)
+	results := []*entity.SearchResult{}
+	for rows.Next() {
+		var (
+			result  entity.SearchResult
+			tags    string
+			content string
+		)
+		if err := rows.Scan(
+			&result.ID,
+			&result.Locale,
+			&result.Title,
+			&result.Slug,
+			&result.Type,
+			&result.SubTitle,
+			&tags,
+			&content,
+			&result.PathSmall,
+			&result.PathMedium,
+			&result.PathLarge,
+			&result.CreatedAt,
+			&result.Score,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		result.Tags = []string{}
+		if tags != "" {
+			if err := json.Unmarshal([]byte(tags), &result.Tags); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal tags: %w", err)
+			}
+		}
+		result.Snippet = utilQuery.Highlight(utilQuery.StripHTML(content), terms, snippetWidth)
+		result.URL = fmt.Sprintf("%s/news/%s", r.app.Config.Domain, result.Slug)
+		results = append(results, &result)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	response := &entity.SearchResponse{
+		Query:      search,
+		Locale:     searchLocale,
+		Data:       results,
+		Pagination: pagination,
+	}
+
+	// Cache the response
+	jsonData, err := json.Marshal(response)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal response: %w", err)
+	}
+	cacheDuration := time.Duration(r.app.Config.RedisExp) * time.Second
+	if err := r.app.Cache.Set(ctx, cacheKey, string(jsonData), cacheDuration); err != nil {
+		return nil, fmt.Errorf("failed to set cache: %w", err)
+	}
+
+	return response, nil
+}
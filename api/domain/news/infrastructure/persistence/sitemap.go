@@ -0,0 +1,407 @@
+package persistence
+
+import (
+	"context"
+	"crypto/sha1"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/JubaerHossain/cn-api/domain/news/entity"
+	"github.com/JubaerHossain/cn-api/pkg/config"
+	"github.com/JubaerHossain/cn-api/pkg/locale"
+	"github.com/JubaerHossain/cn-api/pkg/sitemap"
+)
+
+const (
+	// maxSitemapChunk is the most news ids covered by one child sitemap
+	maxSitemapChunk = 10000
+
+	// googleNewsWindow is how far back the Google News sitemap reaches
+	googleNewsWindow = 48 * time.Hour
+	// googleNewsLimit is the maximum number of URLs Google accepts in a news sitemap
+	googleNewsLimit = 1000
+
+	// Stored sitemaps that depend on the clock or on categories are also rebuilt after these ages
+	googleNewsMaxAge = 10 * time.Minute
+	categoryMaxAge   = time.Hour
+)
+
+// Names of the stored sitemaps, also used as file names under /sitemaps/
+const (
+	sitemapGoogleNews = "google-news"
+	sitemapCategories = "categories"
+	sitemapNewsPrefix = "news-"
+)
+
+// sitemapChunkSize is the number of news ids covered by one child sitemap. Every
+// translation in a supported locale is its own URL, so chunks shrink as locales
+// are added and a full chunk stays under the 50,000 URL limit.
+func sitemapChunkSize() int {
+	return max(1, min(maxSitemapChunk, sitemap.MaxURLs/max(1, len(locale.Supported()))))
+}
+
+// newsSitemapName is the stored name of a news chunk. It carries the chunk size,
+// so files built before the supported locales changed are not served for the
+// new id ranges.
+func newsSitemapName(chunk int) string {
+	return fmt.Sprintf("%s%d/%d", sitemapNewsPrefix, chunk, sitemapChunkSize())
+}
+
+// GetSitemapIndex returns the sitemap index listing every child sitemap
+func (r *NewsRepositoryImpl) GetSitemapIndex(req *http.Request) (*entity.FeedDocument, error) {
+	ctx := req.Context()
+	cacheKey := "sitemap_index"
+
+	// Check cache first
+	if cachedData, errCache := r.app.Cache.Get(ctx, cacheKey); errCache == nil && cachedData != "" {
+		document := &entity.FeedDocument{}
+		if err := json.Unmarshal([]byte(cachedData), document); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal cached data: %w", err)
+		}
+		return document, nil
+	}
+
+	base := sitemapBaseURL(r.app.Config.Domain, req)
+	var sitemaps []sitemap.Sitemap
+	var updated time.Time
+
+	rows, err := r.app.MDB.QueryContext(ctx, fmt.Sprintf(`
+		SELECT FLOOR((news.id - 1) / %d) + 1 AS chunk, MAX(news.updated_at)
+		FROM news
+		WHERE %s
+		GROUP BY chunk
+		ORDER BY chunk ASC`, sitemapChunkSize(), entity.PublishedFilter))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query sitemap chunks: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			chunk        int
+			lastModified sql.NullString
+		)
+		if err := rows.Scan(&chunk, &lastModified); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		modified := parseDateTime(lastModified)
+		if modified.After(updated) {
+			updated = modified
+		}
+		sitemaps = append(sitemaps, sitemap.Sitemap{
+			Loc:          fmt.Sprintf("%s/sitemaps/%s%d.xml", base, sitemapNewsPrefix, chunk),
+			LastModified: modified,
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	sitemaps = append(sitemaps,
+		sitemap.Sitemap{Loc: fmt.Sprintf("%s/sitemaps/%s.xml", base, sitemapCategories), LastModified: updated},
+		sitemap.Sitemap{Loc: fmt.Sprintf("%s/sitemaps/%s.xml", base, sitemapGoogleNews), LastModified: updated},
+	)
+
+	body, err := sitemap.Index(sitemaps)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render sitemap index: %w", err)
+	}
+	document := newSitemapDocument(body, updated)
+
+	// Cache the response
+	jsonData, err := json.Marshal(document)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal response: %w", err)
+	}
+	cacheDuration := time.Duration(r.app.Config.RedisExp) * time.Second
+	if err := r.app.Cache.Set(ctx, cacheKey, string(jsonData), cacheDuration); err != nil {
+		return nil, fmt.Errorf("failed to set cache: %w", err)
+	}
+
+	return document, nil
+}
+
+// GetSitemap returns a child sitemap by file name: news-N.xml, categories.xml or
+// google-news.xml. Files are stored in the sitemaps table and only rebuilt when a
+// publish marked them stale or they outlived their maximum age.
+func (r *NewsRepositoryImpl) GetSitemap(req *http.Request, file string) (*entity.FeedDocument, error) {
+	name, ok := strings.CutSuffix(file, ".xml")
+	if !ok {
+		return nil, entity.ErrNewsNotFound
+	}
+
+	switch {
+	case name == sitemapGoogleNews:
+		return r.storedSitemap(req.Context(), name, googleNewsMaxAge, r.buildGoogleNewsSitemap)
+	case name == sitemapCategories:
+		return r.storedSitemap(req.Context(), name, categoryMaxAge, r.buildCategorySitemap)
+	case strings.HasPrefix(name, sitemapNewsPrefix):
+		chunk, err := strconv.Atoi(strings.TrimPrefix(name, sitemapNewsPrefix))
+		if err != nil || chunk < 1 || strconv.Itoa(chunk) != strings.TrimPrefix(name, sitemapNewsPrefix) {
+			return nil, entity.ErrNewsNotFound
+		}
+		return r.storedSitemap(req.Context(), newsSitemapName(chunk), 0, func(ctx context.Context) ([]byte, int, time.Time, error) {
+			return r.buildNewsSitemap(ctx, chunk)
+		})
+	}
+	return nil, entity.ErrNewsNotFound
+}
+
+// storedSitemap returns the stored sitemap when it is fresh, otherwise it builds and
+// stores it. The version column guards against a publish that happens while the
+// file is being built: the file is then saved but stays stale.
+func (r *NewsRepositoryImpl) storedSitemap(ctx context.Context, name string, maxAge time.Duration, build func(ctx context.Context) ([]byte, int, time.Time, error)) (*entity.FeedDocument, error) {
+	var (
+		body         string
+		lastModified sql.NullString
+		expired      bool
+		version      uint64
+	)
+	// maxAge 0 disables expiry, the file is only rebuilt when marked stale
+	err := r.app.MDB.QueryRowContext(ctx, `
+		SELECT body, last_modified, version,
+		       stale = 1 OR generated_at IS NULL OR (? > 0 AND generated_at < NOW() - INTERVAL ? SECOND)
+		FROM sitemaps WHERE name = ?`,
+		int(maxAge.Seconds()), int(maxAge.Seconds()), name,
+	).Scan(&body, &lastModified, &version, &expired)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("failed to get stored sitemap: %w", err)
+	}
+	if err == nil && !expired {
+		return newSitemapDocument([]byte(body), parseDateTime(lastModified)), nil
+	}
+
+	rendered, urlCount, modified, err := build(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if urlCount == 0 && strings.HasPrefix(name, sitemapNewsPrefix) {
+		// The chunk has no published articles, do not store empty files for unknown chunks
+		return nil, entity.ErrNewsNotFound
+	}
+
+	var modifiedArg interface{}
+	if !modified.IsZero() {
+		modifiedArg = modified.Format(time.DateTime)
+	}
+	if _, err := r.app.MDB.ExecContext(ctx, `
+		INSERT INTO sitemaps (name, body, url_count, last_modified, stale, version, generated_at)
+		VALUES (?, ?, ?, ?, 0, 0, NOW())
+		ON DUPLICATE KEY UPDATE
+		    stale = IF(version = ?, 0, 1),
+		    body = VALUES(body),
+		    url_count = VALUES(url_count),
+		    last_modified = VALUES(last_modified),
+		    generated_at = VALUES(generated_at)`,
+		name, string(rendered), urlCount, modifiedArg, version,
+	); err != nil {
+		return nil, fmt.Errorf("failed to store sitemap: %w", err)
+	}
+
+	return newSitemapDocument(rendered, modified), nil
+}
+
+// buildNewsSitemap renders every published translation in a supported locale
+// whose news id falls in the chunk
+func (r *NewsRepositoryImpl) buildNewsSitemap(ctx context.Context, chunk int) ([]byte, int, time.Time, error) {
+	size := sitemapChunkSize()
+	locales := locale.Supported()
+	args := []interface{}{(chunk-1)*size + 1, chunk * size}
+	for _, l := range locales {
+		args = append(args, l)
+	}
+	rows, err := r.app.MDB.QueryContext(ctx, fmt.Sprintf(`
+		SELECT nt.slug, nt.title, COALESCE(news.path_large, ''), GREATEST(news.updated_at, nt.updated_at)
+		FROM news
+		JOIN news_translations nt ON news.id = nt.news_id
+		WHERE news.id BETWEEN ? AND ? AND nt.locale IN (%s) AND %s
+		ORDER BY news.id ASC, nt.locale ASC`, strings.TrimSuffix(strings.Repeat("?,", len(locales)), ","), entity.PublishedFilter),
+		args...,
+	)
+	if err != nil {
+		return nil, 0, time.Time{}, fmt.Errorf("failed to query sitemap news: %w", err)
+	}
+	defer rows.Close()
+
+	var (
+		urls    []sitemap.URL
+		updated time.Time
+	)
+	for rows.Next() {
+		var (
+			slug, title, image string
+			lastModified       sql.NullString
+		)
+		if err := rows.Scan(&slug, &title, &image, &lastModified); err != nil {
+			return nil, 0, time.Time{}, fmt.Errorf("failed to scan row: %w", err)
+		}
+		u := sitemap.URL{
+			Loc:          fmt.Sprintf("%s/news/%s", r.app.Config.Domain, slug),
+			LastModified: parseDateTime(lastModified),
+		}
+		if image != "" {
+			u.Images = []sitemap.Image{{Loc: r.assetURL(image), Caption: title}}
+		}
+		if u.LastModified.After(updated) {
+			updated = u.LastModified
+		}
+		urls = append(urls, u)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, time.Time{}, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	body, err := sitemap.URLSet(urls)
+	if err != nil {
+		return nil, 0, time.Time{}, fmt.Errorf("failed to render news sitemap %d: %w", chunk, err)
+	}
+	return body, len(urls), updated, nil
+}
+
+// buildGoogleNewsSitemap renders the translations published within the Google News window
+func (r *NewsRepositoryImpl) buildGoogleNewsSitemap(ctx context.Context) ([]byte, int, time.Time, error) {
+	rows, err := r.app.MDB.QueryContext(ctx, fmt.Sprintf(`
+		SELECT nt.locale, nt.slug, nt.title, COALESCE(news.path_large, ''), news.published_at, GREATEST(news.updated_at, nt.updated_at)
+		FROM news
+		JOIN news_translations nt ON news.id = nt.news_id
+		WHERE news.published_at >= UTC_TIMESTAMP() - INTERVAL %d SECOND AND %s
+		ORDER BY news.published_at DESC, news.id DESC
+		LIMIT %d`, int(googleNewsWindow.Seconds()), entity.PublishedFilter, googleNewsLimit))
+	if err != nil {
+		return nil, 0, time.Time{}, fmt.Errorf("failed to query google news: %w", err)
+	}
+	defer rows.Close()
+
+	var (
+		urls    []sitemap.URL
+		updated time.Time
+	)
+	for rows.Next() {
+		var (
+			newsLocale, slug, title, image string
+			createdAt, lastModified        sql.NullString
+		)
+		if err := rows.Scan(&newsLocale, &slug, &title, &image, &createdAt, &lastModified); err != nil {
+			return nil, 0, time.Time{}, fmt.Errorf("failed to scan row: %w", err)
+		}
+		u := sitemap.URL{
+			Loc:          fmt.Sprintf("%s/news/%s", r.app.Config.Domain, slug),
+			LastModified: parseDateTime(lastModified),
+			News: &sitemap.News{
+				PublicationName: config.GlobalConfig.SiteName,
+				Language:        newsLocale,
+				PublicationDate: parseDateTime(createdAt),
+				Title:           title,
+			},
+		}
+		if image != "" {
+			u.Images = []sitemap.Image{{Loc: r.assetURL(image), Caption: title}}
+		}
+		if u.LastModified.After(updated) {
+			updated = u.LastModified
+		}
+		urls = append(urls, u)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, time.Time{}, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	body, err := sitemap.URLSet(urls)
+	if err != nil {
+		return nil, 0, time.Time{}, fmt.Errorf("failed to render google news sitemap: %w", err)
+	}
+	if updated.IsZero() {
+		updated = time.Now().UTC()
+	}
+	return body, len(urls), updated, nil
+}
+
+// buildCategorySitemap renders the active categories, dated by their latest published article
+func (r *NewsRepositoryImpl) buildCategorySitemap(ctx context.Context) ([]byte, int, time.Time, error) {
+	rows, err := r.app.MDB.QueryContext(ctx, fmt.Sprintf(`
+		SELECT nc.slug, MAX(news.updated_at)
+		FROM news_categories nc
+		LEFT JOIN assign_categories ac ON ac.news_category_id = nc.id
+		LEFT JOIN news ON news.id = ac.news_id AND %s
+		WHERE nc.status_id = %d
+		GROUP BY nc.id, nc.slug, nc.order
+		ORDER BY nc.order ASC
+		LIMIT %d`, entity.PublishedFilter, entity.StatusActive, sitemap.MaxURLs))
+	if err != nil {
+		return nil, 0, time.Time{}, fmt.Errorf("failed to query sitemap categories: %w", err)
+	}
+	defer rows.Close()
+
+	var (
+		urls    []sitemap.URL
+		updated time.Time
+	)
+	for rows.Next() {
+		var (
+			slug         string
+			lastModified sql.NullString
+		)
+		if err := rows.Scan(&slug, &lastModified); err != nil {
+			return nil, 0, time.Time{}, fmt.Errorf("failed to scan row: %w", err)
+		}
+		u := sitemap.URL{
+			Loc:          fmt.Sprintf("%s/category/%s", r.app.Config.Domain, slug),
+			LastModified: parseDateTime(lastModified),
+		}
+		if u.LastModified.After(updated) {
+			updated = u.LastModified
+		}
+		urls = append(urls, u)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, time.Time{}, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	body, err := sitemap.URLSet(urls)
+	if err != nil {
+		return nil, 0, time.Time{}, fmt.Errorf("failed to render category sitemap: %w", err)
+	}
+	return body, len(urls), updated, nil
+}
+
+// markSitemapsStale flags the sitemaps that can contain the article so that only
+// they are rebuilt on their next request
+func markSitemapsStale(req *http.Request, tx *sql.Tx, newsID uint) error {
+	chunk := (int(newsID)-1)/sitemapChunkSize() + 1
+	for _, name := range []string{newsSitemapName(chunk), sitemapGoogleNews, sitemapCategories} {
+		if _, err := tx.ExecContext(req.Context(), `
+			INSERT INTO sitemaps (name, body, stale, version) VALUES (?, '', 1, 1)
+			ON DUPLICATE KEY UPDATE stale = 1, version = version + 1`, name); err != nil {
+			return fmt.Errorf("failed to mark sitemap %s stale: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// sitemapBaseURL returns the absolute URL of the router the request came through.
+// RequestURI still carries the prefix that StripPrefix removed from URL.Path.
+func sitemapBaseURL(domain string, req *http.Request) string {
+	requestPath, _, _ := strings.Cut(req.RequestURI, "?")
+	return strings.TrimSuffix(domain, "/") + strings.TrimSuffix(requestPath, req.URL.Path)
+}
+
+// newSitemapDocument wraps a rendered sitemap with its validators
+func newSitemapDocument(body []byte, lastModified time.Time) *entity.FeedDocument {
+	if lastModified.IsZero() {
+		lastModified = time.Now().UTC()
+	}
+	sum := sha1.Sum(body)
+	return &entity.FeedDocument{
+		ContentType:  "application/xml; charset=utf-8",
+		Body:         string(body),
+		ETag:         fmt.Sprintf(`"%s"`, hex.EncodeToString(sum[:10])),
+		LastModified: lastModified.UTC().Truncate(time.Second),
+	}
+}
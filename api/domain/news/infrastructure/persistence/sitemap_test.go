@@ -0,0 +1,42 @@
+package persistence
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/JubaerHossain/cn-api/pkg/config"
+	"github.com/JubaerHossain/cn-api/pkg/sitemap"
+)
+
+func TestSitemapChunkSize(t *testing.T) {
+	tests := []struct {
+		name    string
+		locales int
+		want    int
+	}{
+		{name: "one locale", locales: 1, want: maxSitemapChunk},
+		{name: "five locales", locales: 5, want: maxSitemapChunk},
+		{name: "six locales", locales: 6, want: 8333},
+		{name: "many locales", locales: 40, want: 1250},
+		{name: "more locales than URLs", locales: sitemap.MaxURLs + 1, want: 1},
+	}
+	saved := config.GlobalConfig.SupportedLocales
+	defer func() { config.GlobalConfig.SupportedLocales = saved }()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			locales := make([]string, tt.locales)
+			for i := range locales {
+				locales[i] = fmt.Sprintf("l%d", i)
+			}
+			config.GlobalConfig.SupportedLocales = strings.Join(locales, ",")
+			got := sitemapChunkSize()
+			if got != tt.want {
+				t.Errorf("sitemapChunkSize() = %d, want %d", got, tt.want)
+			}
+			if got*tt.locales > sitemap.MaxURLs && got > 1 {
+				t.Errorf("a full chunk has %d URLs, over the limit", got*tt.locales)
+			}
+		})
+	}
+}
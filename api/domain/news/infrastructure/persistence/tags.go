@@ -0,0 +1,242 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/JubaerHossain/cn-api/domain/news/entity"
+	"github.com/JubaerHossain/cn-api/pkg/locale"
+	utilQuery "github.com/JubaerHossain/cn-api/pkg/utils"
+)
+
+// tagBackfillBatchSize is the number of translations converted per transaction by BackfillTags
+const tagBackfillBatchSize = 200
+
+// syncTags replaces the tags of a translation, creating the tags that do not exist
+// yet. Names are matched on their slug, so the stored spelling of an existing tag
+// wins; the resulting names are returned in order.
+func syncTags(ctx context.Context, tx *sql.Tx, newsID uint, tagLocale string, names []string) ([]string, error) {
+	if _, err := tx.ExecContext(ctx, "DELETE FROM news_tags WHERE news_id = ? AND locale = ?", newsID, tagLocale); err != nil {
+		return nil, fmt.Errorf("failed to clear tags: %w", err)
+	}
+
+	var slugs []string
+	bySlug := map[string]string{}
+	for _, name := range names {
+		name = strings.Join(strings.Fields(name), " ")
+		slug := utilQuery.Slugify(name)
+		if slug == "" || bySlug[slug] != "" {
+			continue
+		}
+		bySlug[slug] = name
+		slugs = append(slugs, slug)
+	}
+	if len(slugs) == 0 {
+		return []string{}, nil
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(slugs)), ",")
+	values := strings.TrimSuffix(strings.Repeat("(?, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP),", len(slugs)), ",")
+	args := make([]interface{}, 0, 2*len(slugs))
+	slugArgs := make([]interface{}, 0, len(slugs))
+	for _, slug := range slugs {
+		args = append(args, bySlug[slug], slug)
+		slugArgs = append(slugArgs, slug)
+	}
+	if _, err := tx.ExecContext(ctx, "INSERT INTO tags (name, slug, created_at, updated_at) VALUES "+values+" ON DUPLICATE KEY UPDATE id = id", args...); err != nil {
+		return nil, fmt.Errorf("failed to create tags: %w", err)
+	}
+
+	rows, err := tx.QueryContext(ctx, fmt.Sprintf("SELECT id, name, slug FROM tags WHERE slug IN (%s)", placeholders), slugArgs...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tags: %w", err)
+	}
+	ids := map[string]uint{}
+	for rows.Next() {
+		var (
+			id         uint
+			name, slug string
+		)
+		if err := rows.Scan(&id, &name, &slug); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		ids[slug] = id
+		bySlug[slug] = name
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	values = strings.TrimSuffix(strings.Repeat("(?, ?, ?, ?),", len(slugs)), ",")
+	args = args[:0]
+	tags := make([]string, 0, len(slugs))
+	for position, slug := range slugs {
+		args = append(args, newsID, tagLocale, ids[slug], position)
+		tags = append(tags, bySlug[slug])
+	}
+	if _, err := tx.ExecContext(ctx, "INSERT INTO news_tags (news_id, locale, tag_id, position) VALUES "+values, args...); err != nil {
+		return nil, fmt.Errorf("failed to assign tags: %w", err)
+	}
+	return tags, nil
+}
+
+// tagsOf returns the tag names of the articles' translations by news ID and locale
+func (r *NewsRepositoryImpl) tagsOf(ctx context.Context, newsIDs []uint) (map[uint]map[string][]string, error) {
+	tags := map[uint]map[string][]string{}
+	if len(newsIDs) == 0 {
+		return tags, nil
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(newsIDs)), ",")
+	args := make([]interface{}, 0, len(newsIDs))
+	for _, id := range newsIDs {
+		args = append(args, id)
+	}
+	rows, err := r.app.MDB.QueryContext(ctx, fmt.Sprintf(`
+		SELECT news_tags.news_id, news_tags.locale, tags.name
+		FROM news_tags
+		JOIN tags ON tags.id = news_tags.tag_id
+		WHERE news_tags.news_id IN (%s)
+		ORDER BY news_tags.news_id, news_tags.locale, news_tags.position`, placeholders), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tags: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			newsID          uint
+			tagLocale, name string
+		)
+		if err := rows.Scan(&newsID, &tagLocale, &name); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		if tags[newsID] == nil {
+			tags[newsID] = map[string][]string{}
+		}
+		tags[newsID][tagLocale] = append(tags[newsID][tagLocale], name)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return tags, nil
+}
+
+// GetTagNews returns a page of the published articles with the tag, newest first
+func (r *NewsRepositoryImpl) GetTagNews(req *http.Request, slug, token string, limit int) (*entity.TagNewsResponse, error) {
+	ctx := req.Context()
+	requested := locale.Negotiate(req)
+	cacheKey := fmt.Sprintf("tag_news_%s_%s_%d_%s", slug, requested, limit, token)
+
+	// Check cache first
+	if cachedData, errCache := r.app.Cache.Get(ctx, cacheKey); errCache == nil && cachedData != "" {
+		response := &entity.TagNewsResponse{}
+		if err := json.Unmarshal([]byte(cachedData), response); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal cached data: %w", err)
+		}
+		return response, nil
+	}
+
+	var tag entity.NewsTag
+	err := r.app.MDB.QueryRowContext(ctx, "SELECT id, name, slug FROM tags WHERE slug = ?", slug).Scan(&tag.ID, &tag.Name, &tag.Slug)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, entity.ErrNewsNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tag: %w", err)
+	}
+
+	newsList, pagination, err := r.publishedPage(req, requested,
+		" JOIN news_tags ON news_tags.news_id = news.id",
+		" AND news_tags.tag_id = ?", []interface{}{tag.ID},
+		token, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	response := &entity.TagNewsResponse{
+		Tag:        &tag,
+		Locale:     requested,
+		Data:       newsList,
+		Pagination: pagination,
+	}
+
+	// Cache the response
+	if err := r.cacheResponse(req, cacheKey, response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// BackfillTags converts the JSON tag arrays of every translation into tag rows.
+// It can be run again safely, each run replaces the rows of every translation.
+// Returns the number of translations converted.
+func (r *NewsRepositoryImpl) BackfillTags(ctx context.Context) (int, error) {
+	converted := 0
+	lastID := uint(0)
+	for {
+		rows, err := r.app.MDB.QueryContext(ctx, `
+			SELECT id, news_id, locale, tags FROM news_translations
+			WHERE id > ? ORDER BY id ASC LIMIT ?`, lastID, tagBackfillBatchSize)
+		if err != nil {
+			return converted, fmt.Errorf("failed to query translations: %w", err)
+		}
+		type translationTags struct {
+			newsID uint
+			locale string
+			tags   []string
+		}
+		var batch []translationTags
+		for rows.Next() {
+			var (
+				item translationTags
+				tags sql.NullString
+			)
+			if err := rows.Scan(&lastID, &item.newsID, &item.locale, &tags); err != nil {
+				rows.Close()
+				return converted, fmt.Errorf("failed to scan row: %w", err)
+			}
+			if err := unmarshalList(tags, &item.tags); err != nil {
+				rows.Close()
+				return converted, fmt.Errorf("failed to unmarshal tags of translation %d: %w", lastID, err)
+			}
+			batch = append(batch, item)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return converted, fmt.Errorf("rows iteration error: %w", err)
+		}
+		if len(batch) == 0 {
+			break
+		}
+
+		tx, err := r.app.MDB.BeginTx(ctx, nil)
+		if err != nil {
+			return converted, err
+		}
+		for _, item := range batch {
+			if _, err := syncTags(ctx, tx, item.newsID, item.locale, item.tags); err != nil {
+				tx.Rollback()
+				return converted, fmt.Errorf("failed to convert tags of news %d (%s): %w", item.newsID, item.locale, err)
+			}
+		}
+		if err := tx.Commit(); err != nil {
+			return converted, err
+		}
+		converted += len(batch)
+	}
+
+	if converted > 0 {
+		// CacheClear only uses the request for its context
+		if err := CacheClear((&http.Request{}).WithContext(ctx), r.app.Cache); err != nil {
+			return converted, err
+		}
+	}
+	return converted, nil
+}
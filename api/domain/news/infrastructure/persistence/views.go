@@ -0,0 +1,117 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// viewStatsBatchSize caps the rows of one hourly statistics insert
+const viewStatsBatchSize = 500
+
+// FlushViews adds buffered view counts to the articles and categories, and to
+// the hourly article statistics and rankings used by the trending and most-read
+// lists. Views of items that no longer exist are dropped.
+func (r *NewsRepositoryImpl) FlushViews(ctx context.Context, news, categories map[uint]int64) error {
+	tx, err := r.app.MDB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	news, err = existingIDs(ctx, tx, "news", news)
+	if err != nil {
+		return err
+	}
+	categories, err = existingIDs(ctx, tx, "news_categories", categories)
+	if err != nil {
+		return err
+	}
+
+	for id, views := range news {
+		if _, err := tx.ExecContext(ctx, "UPDATE news SET view_count = view_count + ? WHERE id = ?", views, id); err != nil {
+			return fmt.Errorf("failed to update news views: %w", err)
+		}
+	}
+	for id, views := range categories {
+		if _, err := tx.ExecContext(ctx, "UPDATE news_categories SET view_count = view_count + ? WHERE id = ?", views, id); err != nil {
+			return fmt.Errorf("failed to update category views: %w", err)
+		}
+	}
+
+	// Views are attributed to the hour they were flushed in
+	bucket := time.Now().UTC().Truncate(time.Hour).Format("2006-01-02 15:04:05")
+	placeholders := make([]string, 0, viewStatsBatchSize)
+	args := make([]interface{}, 0, viewStatsBatchSize*3)
+	insertStats := func() error {
+		if len(placeholders) == 0 {
+			return nil
+		}
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO news_view_stats (news_id, bucket, views) VALUES `+strings.Join(placeholders, ", ")+`
+			ON DUPLICATE KEY UPDATE views = views + VALUES(views)`, args...)
+		placeholders, args = placeholders[:0], args[:0]
+		if err != nil {
+			return fmt.Errorf("failed to insert view stats: %w", err)
+		}
+		return nil
+	}
+	for id, views := range news {
+		placeholders = append(placeholders, "(?, ?, ?)")
+		args = append(args, id, bucket, views)
+		if len(placeholders) == viewStatsBatchSize {
+			if err := insertStats(); err != nil {
+				return err
+			}
+		}
+	}
+	if err := insertStats(); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	// The views are stored, so a ranking failure must not make the caller retry them.
+	// Rankings catch up when they are next rebuilt.
+	if err := r.recordRankings(ctx, news); err != nil {
+		r.app.Logger.Error("Error recording rankings", zap.Error(err))
+	}
+	return nil
+}
+
+// existingIDs keeps the counts of the IDs that still exist in table
+func existingIDs(ctx context.Context, tx *sql.Tx, table string, counts map[uint]int64) (map[uint]int64, error) {
+	if len(counts) == 0 {
+		return counts, nil
+	}
+	placeholders := make([]string, 0, len(counts))
+	args := make([]interface{}, 0, len(counts))
+	for id := range counts {
+		placeholders = append(placeholders, "?")
+		args = append(args, id)
+	}
+	rows, err := tx.QueryContext(ctx, fmt.Sprintf("SELECT id FROM %s WHERE id IN (%s)", table, strings.Join(placeholders, ", ")), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	existing := make(map[uint]int64, len(counts))
+	for rows.Next() {
+		var id uint
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		existing[id] = counts[id]
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return existing, nil
+}
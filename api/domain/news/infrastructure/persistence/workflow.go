@@ -0,0 +1,106 @@
+package persistence
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+
+	"github.com/JubaerHossain/cn-api/domain/news/entity"
+)
+
+// TransitionNews moves the article to the transition's target state and records
+// the move in news_workflow_transitions in one transaction. The update only
+// applies while the article is still in the transition's source state, so two
+// editors acting at once cannot both succeed.
+func (r *NewsRepositoryImpl) TransitionNews(news *entity.News, transition *entity.Transition, history *entity.NewsTransitionHistory, req *http.Request) error {
+	ctx := req.Context()
+	tx, err := r.app.MDB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE news SET publish_status_id = ?, updated_by = ?, updated_at = CURRENT_TIMESTAMP`+publishedAtSet(transition)+`
+		WHERE id = ? AND publish_status_id = ?`,
+		transition.To, history.UserID, news.ID, transition.From,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update state: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to update state: %w", err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("%w: the article is no longer %s", entity.ErrTransitionNotAllowed, transition.From)
+	}
+
+	if err := recordTransition(req, tx, news.ID, transition, history); err != nil {
+		return err
+	}
+	if err := markSitemapsStale(req, tx, news.ID); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	// Clear cache
+	return CacheClear(req, r.app.Cache)
+}
+
+// publishedAtSet stamps the first publication of an article. A republished
+// article keeps its date, so it does not jump back to the top of the lists.
+func publishedAtSet(transition *entity.Transition) string {
+	if transition.To == entity.StatePublished {
+		return ", published_at = COALESCE(published_at, UTC_TIMESTAMP())"
+	}
+	return ""
+}
+
+// recordTransition inserts a news_workflow_transitions row
+func recordTransition(req *http.Request, tx *sql.Tx, newsID uint, transition *entity.Transition, history *entity.NewsTransitionHistory) error {
+	if _, err := tx.ExecContext(req.Context(), `
+		INSERT INTO news_workflow_transitions (news_id, from_status_id, to_status_id, comment, user_id, role, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP)`,
+		newsID, transition.From, transition.To, history.Comment, history.UserID, history.Role,
+	); err != nil {
+		return fmt.Errorf("failed to record transition: %w", err)
+	}
+	return nil
+}
+
+// GetNewsTransitions returns the workflow history of an article, oldest first
+func (r *NewsRepositoryImpl) GetNewsTransitions(req *http.Request, newsID uint) ([]*entity.NewsTransitionHistory, error) {
+	rows, err := r.app.MDB.QueryContext(req.Context(), `
+		SELECT id, news_id, from_status_id, to_status_id, COALESCE(comment, ''), user_id, role, created_at
+		FROM news_workflow_transitions
+		WHERE news_id = ?
+		ORDER BY id ASC`, newsID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query transitions: %w", err)
+	}
+	defer rows.Close()
+
+	transitions := []*entity.NewsTransitionHistory{}
+	for rows.Next() {
+		var (
+			history   entity.NewsTransitionHistory
+			from, to  entity.State
+			createdAt sql.NullString
+		)
+		if err := rows.Scan(&history.ID, &history.NewsID, &from, &to, &history.Comment, &history.UserID, &history.Role, &createdAt); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		history.From = from.String()
+		history.To = to.String()
+		history.CreatedAt = parseDateTime(createdAt)
+		transitions = append(transitions, &history)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return transitions, nil
+}
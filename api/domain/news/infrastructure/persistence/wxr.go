@@ -0,0 +1,227 @@
+package persistence
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha1"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/JubaerHossain/cn-api/domain/news/entity"
+	rootxQuery "github.com/JubaerHossain/rootx/pkg/query"
+)
+
+// ImportedID returns the record created for a source by an earlier import, 0 when there is none
+func (r *NewsRepositoryImpl) ImportedID(ctx context.Context, kind, source string) (uint, error) {
+	var targetID uint
+	err := r.app.MDB.QueryRowContext(ctx,
+		"SELECT target_id FROM wxr_imports WHERE kind = ? AND source_hash = ?", kind, sourceHash(source),
+	).Scan(&targetID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get imported %s: %w", kind, err)
+	}
+	return targetID, nil
+}
+
+// RecordImport remembers the record created for a source
+func (r *NewsRepositoryImpl) RecordImport(ctx context.Context, kind, source string, targetID uint) error {
+	tx, err := r.app.MDB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err := recordImport(ctx, tx, kind, source, targetID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// ImportedURL returns the URL a file of the source was stored at, empty when
+// it was not imported
+func (r *NewsRepositoryImpl) ImportedURL(ctx context.Context, kind, source string) (string, error) {
+	var targetURL sql.NullString
+	err := r.app.MDB.QueryRowContext(ctx,
+		"SELECT target_url FROM wxr_imports WHERE kind = ? AND source_hash = ?", kind, sourceHash(source),
+	).Scan(&targetURL)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get imported %s: %w", kind, err)
+	}
+	return targetURL.String, nil
+}
+
+// RecordImportedURL remembers the URL a file of the source was stored at
+func (r *NewsRepositoryImpl) RecordImportedURL(ctx context.Context, kind, source, targetURL string) error {
+	if _, err := r.app.MDB.ExecContext(ctx, `
+		INSERT INTO wxr_imports (kind, source_hash, source, target_id, target_url, created_at)
+		VALUES (?, ?, ?, 0, ?, CURRENT_TIMESTAMP)
+		ON DUPLICATE KEY UPDATE target_url = VALUES(target_url)`,
+		kind, sourceHash(source), truncateSource(source), targetURL,
+	); err != nil {
+		return fmt.Errorf("failed to record imported %s: %w", kind, err)
+	}
+	return nil
+}
+
+// ImportAuthor returns the user with the author's email, creating it when
+// there is none. Created users get an unusable password and sign in after a reset.
+func (r *NewsRepositoryImpl) ImportAuthor(ctx context.Context, login, name, email string, roleID uint) (uint, error) {
+	if email == "" {
+		email = login + "@wordpress.invalid"
+	}
+	tx, err := r.app.MDB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var userID uint
+	err = tx.QueryRowContext(ctx, "SELECT id FROM users WHERE email = ?", email).Scan(&userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		secret := make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return 0, err
+		}
+		password, err := rootxQuery.HashPassword(hex.EncodeToString(secret))
+		if err != nil {
+			return 0, fmt.Errorf("failed to hash password: %w", err)
+		}
+		result, err := tx.ExecContext(ctx, `
+			INSERT INTO users (name, email, password, role, status, created_at, updated_at)
+			VALUES (?, ?, ?, ?, TRUE, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)`, name, email, password, roleID)
+		if err != nil {
+			return 0, fmt.Errorf("failed to insert user: %w", err)
+		}
+		id, err := result.LastInsertId()
+		if err != nil {
+			return 0, fmt.Errorf("failed to get user id: %w", err)
+		}
+		userID = uint(id)
+	} else if err != nil {
+		return 0, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if err := recordImport(ctx, tx, entity.ImportAuthor, login, userID); err != nil {
+		return 0, err
+	}
+	return userID, tx.Commit()
+}
+
+// ImportCategory returns the category with the slug, creating it under the
+// parent when there is none
+func (r *NewsRepositoryImpl) ImportCategory(ctx context.Context, slug, title string, parentID uint) (uint, error) {
+	tx, err := r.app.MDB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var categoryID uint
+	err = tx.QueryRowContext(ctx, "SELECT id FROM news_categories WHERE slug = ?", slug).Scan(&categoryID)
+	if errors.Is(err, sql.ErrNoRows) {
+		result, err := tx.ExecContext(ctx, `
+			INSERT INTO news_categories (title, slug, parent_id, status_id, created_at, updated_at)
+			VALUES (?, ?, ?, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)`, title, slug, nullableID(parentID), entity.StatusActive)
+		if err != nil {
+			return 0, fmt.Errorf("failed to insert category: %w", err)
+		}
+		id, err := result.LastInsertId()
+		if err != nil {
+			return 0, fmt.Errorf("failed to get category id: %w", err)
+		}
+		categoryID = uint(id)
+	} else if err != nil {
+		return 0, fmt.Errorf("failed to get category: %w", err)
+	}
+
+	if err := recordImport(ctx, tx, entity.ImportCategory, slug, categoryID); err != nil {
+		return 0, err
+	}
+	return categoryID, tx.Commit()
+}
+
+// ImportNews creates an article like CreateNews, keeping the dates of its
+// source post. The article and its import record are written together.
+func (r *NewsRepositoryImpl) ImportNews(ctx context.Context, imported *entity.ImportedNews) error {
+	news := imported.News
+	if err := r.prepareContent(news.Translations...); err != nil {
+		return err
+	}
+	// The helpers shared with the API only use the request for its context
+	req := (&http.Request{}).WithContext(ctx)
+	tx, err := r.app.MDB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO news (type, status_id, publish_status_id, publish_at, published_at,
+		                  created_by, updated_by, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		news.Type, news.StatusID, news.PublishStatusID, nullableTime(news.PublishAt), nullableTime(imported.PublishedAt),
+		news.CreatedBy, news.CreatedBy, nullableTime(&imported.CreatedAt), nullableTime(&imported.UpdatedAt),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert news: %w", err)
+	}
+	newsID, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get news id: %w", err)
+	}
+	news.ID = uint(newsID)
+
+	if err := saveTranslations(req, tx, news.ID, news.Translations, news.CreatedBy); err != nil {
+		return err
+	}
+	if err := assignCategories(req, tx, news.ID, news.CategoryIDs); err != nil {
+		return err
+	}
+	if err := markSitemapsStale(req, tx, news.ID); err != nil {
+		return err
+	}
+	if err := recordImport(ctx, tx, entity.ImportPost, imported.Source, news.ID); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	// Clear cache
+	return CacheClear(req, r.app.Cache)
+}
+
+// recordImport writes an import record in the transaction
+func recordImport(ctx context.Context, tx *sql.Tx, kind, source string, targetID uint) error {
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO wxr_imports (kind, source_hash, source, target_id, created_at) VALUES (?, ?, ?, ?, CURRENT_TIMESTAMP)`,
+		kind, sourceHash(source), truncateSource(source), targetID,
+	); err != nil {
+		return fmt.Errorf("failed to record imported %s: %w", kind, err)
+	}
+	return nil
+}
+
+// sourceHash keys an import record, GUIDs are too long to index
+func sourceHash(source string) string {
+	sum := sha1.Sum([]byte(strings.TrimSpace(source)))
+	return hex.EncodeToString(sum[:])
+}
+
+// truncateSource keeps the readable copy of a source within its column
+func truncateSource(source string) string {
+	if len(source) <= 1000 {
+		return source
+	}
+	return strings.ToValidUTF8(source[:1000], "")
+}
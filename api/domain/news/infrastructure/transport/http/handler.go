@@ -1,10 +1,18 @@
 package newsHttp
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/JubaerHossain/cn-api/domain/news/entity"
 	"github.com/JubaerHossain/cn-api/domain/news/service"
+	"github.com/JubaerHossain/cn-api/pkg/live"
+	"github.com/JubaerHossain/cn-api/pkg/views"
 	"github.com/JubaerHossain/rootx/pkg/core/app"
 	utilQuery "github.com/JubaerHossain/rootx/pkg/query"
 	"github.com/JubaerHossain/rootx/pkg/utils"
@@ -28,12 +36,25 @@ func NewHandler(app *app.App) *Handler {
 // @Accept json
 // @Produce json
 // @Security ApiKeyAuth
+// @Param page query int false "Page number"
+// @Param limit query int false "Number of items per page"
+// @Param search query string false "Search by title"
+// @Param status query int false "Filter by status ID"
+// @Param type query string false "Filter by news type"
+// @Param locale query string false "Locale of the listed title"
+// @Param sort query string false "Sort by ID (asc or desc)"
+// @Param state query string false "Comma separated workflow states"
+// @Param department_id query int false "Filter by desk"
 // @Success 200 {object} entity.NewsResponsePagination
 // @Router /news [get]
 func (h *Handler) GetNewses(w http.ResponseWriter, r *http.Request) {
 	// Implement GetNewses handler
 	news, err := h.App.GetNewses(r)
 	if err != nil {
+		if errors.Is(err, entity.ErrInvalidQuery) {
+			utils.WriteJSONError(w, http.StatusBadRequest, err.Error())
+			return
+		}
 		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to fetch news")
 		return
 	}
@@ -64,6 +85,10 @@ func (h *Handler) CreateNews(w http.ResponseWriter, r *http.Request) {
 	// Call the CreateNews function to create the role
 	err := h.App.CreateNews(&newNews, r)
 	if err != nil {
+		if errors.Is(err, entity.ErrInvalidQuery) {
+			utils.WriteJSONError(w, http.StatusBadRequest, err.Error())
+			return
+		}
 		utils.WriteJSONError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
@@ -74,7 +99,6 @@ func (h *Handler) CreateNews(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-
 func (h *Handler) GetNewsByID(w http.ResponseWriter, r *http.Request) {
 	news, err := h.App.GetNewsByID(r)
 	if err != nil {
@@ -95,9 +119,9 @@ func (h *Handler) GetNewsByID(w http.ResponseWriter, r *http.Request) {
 // @Accept json
 // @Produce json
 // @Security ApiKeyAuth
-// @Success 200 {object} entity.ResponseNews
+// @Success 200 {object} entity.News
 // @Param id path string true "The ID of the News"
-// @Router /news/{id}/details [get]
+// @Router /news/{id} [get]
 func (h *Handler) GetNewsDetails(w http.ResponseWriter, r *http.Request) {
 	news, err := h.App.GetNewsDetails(r)
 	if err != nil {
@@ -121,6 +145,7 @@ func (h *Handler) GetNewsDetails(w http.ResponseWriter, r *http.Request) {
 // @Success 200 {object} map[string]interface{} "News updated successfully"
 // @Param id path string true "The ID of the News"
 // @Param news body entity.UpdateNews true "Updated News object"
+// @Failure 403 {object} map[string]interface{}
 // @Router /news/{id} [put]
 func (h *Handler) UpdateNews(w http.ResponseWriter, r *http.Request) {
 	// Implement UpdateNews handler
@@ -133,7 +158,14 @@ func (h *Handler) UpdateNews(w http.ResponseWriter, r *http.Request) {
 	// Call the CreateNews function to create the news
 	err := h.App.UpdateNews(r, &updateNews)
 	if err != nil {
-		utils.WriteJSONError(w, http.StatusInternalServerError, err.Error())
+		switch {
+		case errors.Is(err, entity.ErrInvalidQuery):
+			utils.WriteJSONError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, entity.ErrNotOwnArticle):
+			utils.WriteJSONError(w, http.StatusForbidden, err.Error())
+		default:
+			utils.WriteJSONError(w, http.StatusInternalServerError, err.Error())
+		}
 		return
 	}
 
@@ -143,6 +175,42 @@ func (h *Handler) UpdateNews(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// @Summary Upload the image of a News
+// @Description Upload the original image of a News. The small, medium and large renditions, their WebP variants and a blur placeholder are generated from it, without its EXIF metadata, and replace the current image.
+// @Tags news
+// @Accept mpfd
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "The ID of the News"
+// @Param image formData file true "JPEG, PNG, GIF or WebP original"
+// @Success 200 {object} entity.NewsImage
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /news/{id}/image [post]
+func (h *Handler) UploadNewsImage(w http.ResponseWriter, r *http.Request) {
+	// Leave room for the multipart envelope around the file
+	r.Body = http.MaxBytesReader(w, r.Body, h.App.MaxImageUpload()+1<<20)
+
+	image, err := h.App.UploadNewsImage(r)
+	if err != nil {
+		switch {
+		case errors.Is(err, entity.ErrInvalidQuery):
+			utils.WriteJSONError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, entity.ErrNewsNotFound):
+			utils.WriteJSONError(w, http.StatusNotFound, "News not found")
+		default:
+			utils.WriteJSONError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	// Write response
+	utils.WriteJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"message": "Image uploaded successfully",
+		"results": image,
+	})
+}
+
 // @Summary Delete a News
 // @Description Delete a News
 // @Tags news
@@ -165,40 +233,1032 @@ func (h *Handler) DeleteNews(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-// @Summary Get all scroll news
-// @Description Get details of all scroll news
+// @Summary Export news
+// @Description Stream every article with its translations and category titles, in ID order. NDJSON writes an article per line, CSV a row per translation with list values joined by "|".
+// @Tags news
+// @Produce json
+// @Produce text/csv
+// @Security ApiKeyAuth
+// @Param format query string false "ndjson (default) or csv"
+// @Param from query string false "Published on or after this YYYY-MM-DD date, created for articles never published"
+// @Param to query string false "Published on or before this YYYY-MM-DD date, created for articles never published"
+// @Param category query string false "Slug of a category"
+// @Param locale query string false "Only translations in this locale"
+// @Success 200 {string} string "Export file"
+// @Failure 400 {object} map[string]interface{}
+// @Router /news/export [get]
+func (h *Handler) ExportNews(w http.ResponseWriter, r *http.Request) {
+	filter, err := h.App.ExportFilter(r)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	contentType := "application/x-ndjson; charset=utf-8"
+	if filter.Format == entity.ExportCSV {
+		contentType = "text/csv; charset=utf-8"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="news-%s.%s"`, time.Now().UTC().Format("20060102-150405"), filter.Format))
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("X-Accel-Buffering", "no") // Keep proxies from buffering the download
+
+	export := &exportWriter{ResponseWriter: w}
+	if err := h.App.ExportNews(r.Context(), filter, export); err != nil {
+		if !export.started {
+			w.Header().Del("Content-Disposition")
+			utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to export news")
+			return
+		}
+		// The status is sent, aborting tells the client the download is incomplete
+		panic(http.ErrAbortHandler)
+	}
+}
+
+// exportWriter records whether an export has started writing its response
+type exportWriter struct {
+	http.ResponseWriter
+	started bool
+}
+
+func (w *exportWriter) Write(p []byte) (int, error) {
+	w.started = true
+	return w.ResponseWriter.Write(p)
+}
+
+// @Summary Move a News through the editorial workflow
+// @Description Transition a News to another workflow state. Allowed moves depend on the current state and the caller's role; some require a comment.
 // @Tags news
 // @Accept json
 // @Produce json
 // @Security ApiKeyAuth
-// @Success 200 {object} entity.ScrollNewsResponse
-// @Router /public/v1/breaking-scrolling-news [get]
-func (h *Handler) GetBreakingScrollingNews(w http.ResponseWriter, r *http.Request) {
-	// Implement GetBreakingScrollingNews handler
-	news, err := h.App.GetBreakingScrollingNews(r)
+// @Param id path string true "The ID of the News"
+// @Param transition body entity.NewsTransition true "Target state and comment"
+// @Success 200 {object} map[string]interface{} "News moved successfully"
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /news/{id}/transitions [post]
+func (h *Handler) TransitionNews(w http.ResponseWriter, r *http.Request) {
+	var transition entity.NewsTransition
+	pareErr := utilQuery.BodyParse(&transition, w, r, true) // Parse request body and validate it
+	if pareErr != nil {
+		return
+	}
+
+	news, err := h.App.TransitionNews(r, &transition)
 	if err != nil {
-		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to fetch news")
+		switch {
+		case errors.Is(err, entity.ErrInvalidQuery), errors.Is(err, entity.ErrCommentRequired):
+			utils.WriteJSONError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, entity.ErrTransitionForbidden), errors.Is(err, entity.ErrNotOwnArticle):
+			utils.WriteJSONError(w, http.StatusForbidden, err.Error())
+		case errors.Is(err, entity.ErrTransitionNotAllowed):
+			utils.WriteJSONError(w, http.StatusConflict, err.Error())
+		default:
+			utils.WriteJSONError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	// Write response
+	utils.WriteJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"message": "News moved successfully",
+		"results": news,
+	})
+}
+
+// @Summary Workflow history of a News
+// @Description List the workflow transitions of a News, oldest first
+// @Tags news
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "The ID of the News"
+// @Success 200 {array} entity.NewsTransitionHistory
+// @Router /news/{id}/transitions [get]
+func (h *Handler) GetNewsTransitions(w http.ResponseWriter, r *http.Request) {
+	transitions, err := h.App.GetNewsTransitions(r)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	// Write response
+	utils.WriteJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"message": "News transitions fetched successfully",
+		"results": transitions,
+	})
+}
+
+// @Summary Editor's desk
+// @Description List the News waiting on the caller's desk: by default every state the caller's role can move articles out of
+// @Tags news
+// @Produce json
+// @Security ApiKeyAuth
+// @Param state query string false "Comma separated workflow states"
+// @Param department_id query int false "Desk (department) ID"
+// @Param locale query string false "Locale of the listed title"
+// @Param page query int false "Page number"
+// @Param limit query int false "Number of items per page"
+// @Success 200 {object} entity.NewsResponsePagination
+// @Failure 403 {object} map[string]interface{}
+// @Router /news/desk [get]
+func (h *Handler) GetDesk(w http.ResponseWriter, r *http.Request) {
+	news, err := h.App.GetDesk(r)
+	if err != nil {
+		switch {
+		case errors.Is(err, entity.ErrInvalidQuery):
+			utils.WriteJSONError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, entity.ErrTransitionForbidden):
+			utils.WriteJSONError(w, http.StatusForbidden, "No workflow role")
+		default:
+			utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to fetch news")
+		}
+		return
+	}
+	// Write response
+	utils.JsonResponse(w, http.StatusOK, map[string]interface{}{
+		"results": news,
+	})
+}
+
+// @Summary Revisions of a News
+// @Description List the revisions of a News, newest first
+// @Tags news
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "The ID of the News"
+// @Param locale query string false "Only revisions of this locale"
+// @Success 200 {array} entity.NewsRevisionSummary
+// @Router /news/{id}/revisions [get]
+func (h *Handler) GetNewsRevisions(w http.ResponseWriter, r *http.Request) {
+	revisions, err := h.App.GetNewsRevisions(r)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	// Write response
+	utils.WriteJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"message": "News revisions fetched successfully",
+		"results": revisions,
+	})
+}
+
+// @Summary A revision of a News
+// @Description Get the full field snapshot of a revision
+// @Tags news
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "The ID of the News"
+// @Param revision path string true "The ID of the revision"
+// @Success 200 {object} entity.NewsRevision
+// @Failure 404 {object} map[string]interface{}
+// @Router /news/{id}/revisions/{revision} [get]
+func (h *Handler) GetNewsRevision(w http.ResponseWriter, r *http.Request) {
+	revision, err := h.App.GetNewsRevision(r)
+	if err != nil {
+		writeRevisionError(w, err)
+		return
+	}
+	// Write response
+	utils.WriteJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"message": "News revision fetched successfully",
+		"results": revision,
+	})
+}
+
+// @Summary Diff two revisions of a News
+// @Description Field-level diff between two revisions, with a word-level diff of the content
+// @Tags news
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "The ID of the News"
+// @Param from query int true "The ID of the older revision"
+// @Param to query int true "The ID of the newer revision"
+// @Success 200 {object} entity.RevisionDiff
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /news/{id}/revisions/diff [get]
+func (h *Handler) DiffNewsRevisions(w http.ResponseWriter, r *http.Request) {
+	revisionDiff, err := h.App.DiffNewsRevisions(r)
+	if err != nil {
+		writeRevisionError(w, err)
+		return
+	}
+	// Write response
+	utils.WriteJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"message": "News revisions compared successfully",
+		"results": revisionDiff,
+	})
+}
+
+// @Summary Restore a revision of a News
+// @Description Write a revision back to its translation, recorded as a new revision
+// @Tags news
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "The ID of the News"
+// @Param revision path string true "The ID of the revision"
+// @Success 200 {object} map[string]interface{} "Revision restored successfully"
+// @Failure 404 {object} map[string]interface{}
+// @Router /news/{id}/revisions/{revision}/restore [post]
+func (h *Handler) RestoreNewsRevision(w http.ResponseWriter, r *http.Request) {
+	if err := h.App.RestoreNewsRevision(r); err != nil {
+		writeRevisionError(w, err)
+		return
+	}
+	// Write response
+	utils.WriteJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"message": "Revision restored successfully",
+	})
+}
+
+// writeRevisionError maps revision errors to status codes
+func writeRevisionError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, entity.ErrInvalidQuery):
+		utils.WriteJSONError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, entity.ErrRevisionNotFound):
+		utils.WriteJSONError(w, http.StatusNotFound, "Revision not found")
+	default:
+		utils.WriteJSONError(w, http.StatusInternalServerError, err.Error())
+	}
+}
+
+// @Summary Share a revision with a preview link
+// @Description Issue a signed, time-limited URL of the public preview of a revision, for readers without an account
+// @Tags news
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "The ID of the News"
+// @Param revision path string true "The ID of the revision"
+// @Param preview body entity.CreatePreview false "Seconds the link stays valid"
+// @Success 201 {object} entity.NewsPreviewLink
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /news/{id}/revisions/{revision}/previews [post]
+func (h *Handler) CreatePreview(w http.ResponseWriter, r *http.Request) {
+	var preview entity.CreatePreview
+	if r.ContentLength != 0 {
+		pareErr := utilQuery.BodyParse(&preview, w, r, true) // Parse request body and validate it
+		if pareErr != nil {
+			return
+		}
+	}
+
+	link, err := h.App.CreatePreview(r, &preview)
+	if err != nil {
+		writePreviewError(w, err)
+		return
+	}
+	// Write response
+	utils.WriteJSONResponse(w, http.StatusCreated, map[string]interface{}{
+		"message": "Preview link created successfully",
+		"results": link,
+	})
+}
+
+// @Summary Preview links of a News
+// @Description List the preview links of a News, newest first, revoked and expired ones included
+// @Tags news
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "The ID of the News"
+// @Success 200 {array} entity.NewsPreviewLink
+// @Router /news/{id}/previews [get]
+func (h *Handler) GetPreviews(w http.ResponseWriter, r *http.Request) {
+	links, err := h.App.GetPreviews(r)
+	if err != nil {
+		writePreviewError(w, err)
+		return
+	}
+	// Write response
+	utils.WriteJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"message": "Preview links fetched successfully",
+		"results": links,
+	})
+}
+
+// @Summary Revoke a preview link
+// @Description End a preview link before its expiry
+// @Tags news
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "The ID of the News"
+// @Param preview path string true "The ID of the preview link"
+// @Success 200 {object} entity.NewsPreviewLink
+// @Failure 404 {object} map[string]interface{}
+// @Router /news/{id}/previews/{preview} [delete]
+func (h *Handler) RevokePreview(w http.ResponseWriter, r *http.Request) {
+	link, err := h.App.RevokePreview(r)
+	if err != nil {
+		writePreviewError(w, err)
+		return
+	}
+	// Write response
+	utils.WriteJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"message": "Preview link revoked successfully",
+		"results": link,
+	})
+}
+
+// @Summary Preview of an unpublished revision
+// @Description Render the revision of a preview link like a published article. Responses are never cached.
+// @Tags news
+// @Produce json
+// @Param preview path string true "The ID of the preview link"
+// @Param expires query int true "Expiry of the link, unix seconds"
+// @Param signature query string true "Signature of the link"
+// @Success 200 {object} entity.NewsPreview
+// @Failure 404 {object} map[string]interface{}
+// @Failure 410 {object} map[string]interface{}
+// @Router /public/v1/previews/{preview} [get]
+func (h *Handler) GetPreview(w http.ResponseWriter, r *http.Request) {
+	// Drafts must not stay in shared caches, nor in search engines
+	w.Header().Set("Cache-Control", "private, no-store, max-age=0")
+	w.Header().Set("Pragma", "no-cache")
+	w.Header().Set("X-Robots-Tag", "noindex, nofollow")
+
+	news, err := h.App.GetPreview(r)
+	if err != nil {
+		writePreviewError(w, err)
+		return
+	}
+	w.Header().Set("Content-Language", news.Article.Locale)
+	// Write response
+	utils.JsonResponse(w, http.StatusOK, news)
+}
+
+// writePreviewError maps preview errors to status codes
+func writePreviewError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, entity.ErrInvalidQuery):
+		utils.WriteJSONError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, entity.ErrNewsNotFound):
+		utils.WriteJSONError(w, http.StatusNotFound, "News not found")
+	case errors.Is(err, entity.ErrRevisionNotFound):
+		utils.WriteJSONError(w, http.StatusNotFound, "Revision not found")
+	case errors.Is(err, entity.ErrPreviewNotFound):
+		utils.WriteJSONError(w, http.StatusNotFound, "Preview not found")
+	case errors.Is(err, entity.ErrPreviewExpired):
+		utils.WriteJSONError(w, http.StatusGone, "Preview link has expired or was revoked")
+	default:
+		utils.WriteJSONError(w, http.StatusInternalServerError, err.Error())
+	}
+}
+
+// @Summary Entries of a live blog
+// @Description List a window of the entries of a live blog, whatever its state, oldest first, with every pinned entry
+// @Tags news
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "The ID of the News"
+// @Param after query int false "Entries after this entry ID"
+// @Param before query int false "Entries before this entry ID"
+// @Param since query string false "RFC 3339 time, also report the entries edited or deleted from then on"
+// @Param limit query int false "Number of entries"
+// @Success 200 {object} entity.LiveEntriesResponse
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /news/{id}/entries [get]
+func (h *Handler) GetLiveEntries(w http.ResponseWriter, r *http.Request) {
+	entries, err := h.App.GetLiveEntries(r)
+	if err != nil {
+		writeLiveEntryError(w, err)
+		return
+	}
+	// Write response
+	utils.JsonResponse(w, http.StatusOK, map[string]interface{}{
+		"results": entries,
+	})
+}
+
+// @Summary Add an entry to a live blog
+// @Description Append a timestamped entry to a News of type live. The body is sanitized like article content.
+// @Tags news
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "The ID of the News"
+// @Param entry body entity.LiveEntryRequest true "The entry"
+// @Success 201 {object} entity.LiveEntry
+// @Failure 400 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /news/{id}/entries [post]
+func (h *Handler) CreateLiveEntry(w http.ResponseWriter, r *http.Request) {
+	var request entity.LiveEntryRequest
+	pareErr := utilQuery.BodyParse(&request, w, r, true) // Parse request body and validate it
+	if pareErr != nil {
+		return
+	}
+
+	entry, err := h.App.CreateLiveEntry(r, &request)
+	if err != nil {
+		writeLiveEntryError(w, err)
 		return
 	}
 	// Write response
-	utils.JsonResponse(w, http.StatusOK,news)
+	utils.WriteJSONResponse(w, http.StatusCreated, map[string]interface{}{
+		"message": "Live entry created successfully",
+		"results": entry,
+	})
 }
 
-// @Summary Get all thumbnail news
-// @Description Get details of all thumbnail news
+// @Summary Edit an entry of a live blog
+// @Description Replace the body, media and pinned flag of a live blog entry. It keeps its place in the stream.
 // @Tags news
 // @Accept json
 // @Produce json
 // @Security ApiKeyAuth
-// @Success 200 {object} entity.ThumbnailNewsResponse
+// @Param id path string true "The ID of the News"
+// @Param entry path string true "The ID of the entry"
+// @Param body body entity.LiveEntryRequest true "The entry"
+// @Success 200 {object} entity.LiveEntry
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /news/{id}/entries/{entry} [put]
+func (h *Handler) UpdateLiveEntry(w http.ResponseWriter, r *http.Request) {
+	var request entity.LiveEntryRequest
+	pareErr := utilQuery.BodyParse(&request, w, r, true) // Parse request body and validate it
+	if pareErr != nil {
+		return
+	}
+
+	entry, err := h.App.UpdateLiveEntry(r, &request)
+	if err != nil {
+		writeLiveEntryError(w, err)
+		return
+	}
+	// Write response
+	utils.WriteJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"message": "Live entry updated successfully",
+		"results": entry,
+	})
+}
+
+// @Summary Delete an entry of a live blog
+// @Description Delete an entry of a live blog
+// @Tags news
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "The ID of the News"
+// @Param entry path string true "The ID of the entry"
+// @Success 200 {object} map[string]interface{} "Live entry deleted successfully"
+// @Failure 404 {object} map[string]interface{}
+// @Router /news/{id}/entries/{entry} [delete]
+func (h *Handler) DeleteLiveEntry(w http.ResponseWriter, r *http.Request) {
+	if err := h.App.DeleteLiveEntry(r); err != nil {
+		writeLiveEntryError(w, err)
+		return
+	}
+	// Write response
+	utils.WriteJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"message": "Live entry deleted successfully",
+	})
+}
+
+// @Summary Entries of a published live blog
+// @Description Entries of a published live blog, oldest first, with every pinned entry. Without after or before the latest entries are returned; poll with after set to the last_id and since set to the synced_at of the previous response to get only the entries added since, with the entries edited (updated) and the IDs of the entries deleted (deleted) since.
+// @Tags news
+// @Produce json
+// @Param slug path string true "The slug of the article"
+// @Param after query int false "Entries after this entry ID"
+// @Param before query int false "Entries before this entry ID, to page back"
+// @Param since query string false "RFC 3339 time, also report the entries edited or deleted from then on"
+// @Param limit query int false "Number of entries"
+// @Success 200 {object} entity.LiveEntriesResponse
+// @Failure 404 {object} map[string]interface{}
+// @Router /public/v1/news/{slug}/entries [get]
+func (h *Handler) GetLiveBlogEntries(w http.ResponseWriter, r *http.Request) {
+	entries, err := h.App.GetLiveBlogEntries(r)
+	if err != nil {
+		writeLiveEntryError(w, err)
+		return
+	}
+	// Write response
+	utils.JsonResponse(w, http.StatusOK, entries)
+}
+
+// writeLiveEntryError maps live blog errors to status codes
+func writeLiveEntryError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, entity.ErrInvalidQuery):
+		utils.WriteJSONError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, entity.ErrNewsNotFound):
+		utils.WriteJSONError(w, http.StatusNotFound, "News not found")
+	case errors.Is(err, entity.ErrLiveEntryNotFound):
+		utils.WriteJSONError(w, http.StatusNotFound, "Live entry not found")
+	case errors.Is(err, entity.ErrNotLiveBlog):
+		utils.WriteJSONError(w, http.StatusConflict, "News is not a live blog")
+	default:
+		utils.WriteJSONError(w, http.StatusInternalServerError, err.Error())
+	}
+}
+
+// @Summary Articles of a placement
+// @Description Articles of a homepage placement: its pinned articles in their order, then the articles of its backfill rule
+// @Tags news
+// @Accept json
+// @Produce json
+// @Param name path string true "The name of the placement"
+// @Param locale query string false "Locale, overrides Accept-Language"
+// @Param Accept-Language header string false "Preferred locales"
+// @Param include query string false "seo to add JSON-LD, OpenGraph and Twitter card metadata"
+// @Success 200 {object} entity.PlacementNewsResponse
+// @Failure 404 {object} map[string]interface{}
+// @Router /public/v1/placements/{name} [get]
+func (h *Handler) GetPlacementNews(w http.ResponseWriter, r *http.Request) {
+	news, err := h.App.GetPlacementNews(r)
+	if err != nil {
+		writeListError(w, err, "Placement not found")
+		return
+	}
+	w.Header().Set("Content-Language", news.Locale)
+	w.Header().Add("Vary", "Accept-Language")
+	// Write response
+	utils.JsonResponse(w, http.StatusOK, news)
+}
+
+// @Summary Breaking scrolling news
+// @Description Articles of the breaking-scroll placement, kept for clients of the former breaking flags
+// @Tags news
+// @Accept json
+// @Produce json
+// @Param locale query string false "Locale, overrides Accept-Language"
+// @Param Accept-Language header string false "Preferred locales"
+// @Success 200 {object} entity.PlacementNewsResponse
+// @Router /public/v1/breaking-scrolling-news [get]
+func (h *Handler) GetBreakingScrollingNews(w http.ResponseWriter, r *http.Request) {
+	r.SetPathValue("name", "breaking-scroll")
+	h.GetPlacementNews(w, r)
+}
+
+// @Summary Breaking thumbnail news
+// @Description Articles of the breaking-thumb placement, kept for clients of the former breaking flags
+// @Tags news
+// @Accept json
+// @Produce json
+// @Param locale query string false "Locale, overrides Accept-Language"
+// @Param Accept-Language header string false "Preferred locales"
+// @Success 200 {object} entity.PlacementNewsResponse
 // @Router /public/v1/breaking-thumbnail-news [get]
 func (h *Handler) GetBreakingThumbnailNews(w http.ResponseWriter, r *http.Request) {
-	// Implement GetBreakingThumbnailNews handler
-	news, err := h.App.GetBreakingThumbnailNews(r)
+	r.SetPathValue("name", "breaking-thumb")
+	h.GetPlacementNews(w, r)
+}
+
+// @Summary Get a published article by slug
+// @Description Get a published article with its categories, author, images and related articles. Old slugs redirect permanently to the current one.
+// @Tags news
+// @Accept json
+// @Produce json
+// @Param slug path string true "The slug of the article"
+// @Param locale query string false "Locale, overrides Accept-Language"
+// @Param include query string false "seo to add JSON-LD, OpenGraph and Twitter card metadata"
+// @Success 200 {object} entity.NewsDetails
+// @Success 301 "Slug has changed, see Location"
+// @Failure 404 {object} map[string]interface{}
+// @Router /public/v1/news/{slug} [get]
+func (h *Handler) GetNewsBySlug(w http.ResponseWriter, r *http.Request) {
+	news, currentSlug, err := h.App.GetNewsBySlug(r)
 	if err != nil {
+		if errors.Is(err, entity.ErrNewsNotFound) {
+			if currentSlug != "" {
+				// Relative to the request path so the public prefix is kept
+				location := url.PathEscape(currentSlug)
+				if r.URL.RawQuery != "" {
+					location += "?" + r.URL.RawQuery
+				}
+				w.Header().Set("Location", location)
+				w.WriteHeader(http.StatusMovedPermanently)
+				return
+			}
+			utils.WriteJSONError(w, http.StatusNotFound, "News not found")
+			return
+		}
 		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to fetch news")
 		return
 	}
+	// Pages served from a CDN never reach here, they report views through the hit endpoint
+	h.App.RecordView(r, views.KindNews, news.ID)
+	w.Header().Set("Content-Language", news.Locale)
+	// Write response
+	utils.JsonResponse(w, http.StatusOK, news)
+}
+
+// @Summary Record an article view
+// @Description Count a view of an article. Bots and repeated views of a client within the dedupe window are ignored.
+// @Tags news
+// @Param id path int true "News ID"
+// @Success 204 "Accepted"
+// @Failure 400 {object} map[string]interface{}
+// @Router /public/v1/hits/news/{id} [post]
+func (h *Handler) RecordNewsHit(w http.ResponseWriter, r *http.Request) {
+	h.recordHit(w, r, views.KindNews)
+}
+
+// @Summary Record a category view
+// @Description Count a view of a category page. Bots and repeated views of a client within the dedupe window are ignored.
+// @Tags news
+// @Param id path int true "Category ID"
+// @Success 204 "Accepted"
+// @Failure 400 {object} map[string]interface{}
+// @Router /public/v1/hits/categories/{id} [post]
+func (h *Handler) RecordCategoryHit(w http.ResponseWriter, r *http.Request) {
+	h.recordHit(w, r, views.KindCategory)
+}
+
+// recordHit counts a view of the item in the path
+func (h *Handler) recordHit(w http.ResponseWriter, r *http.Request, kind string) {
+	if err := h.App.RecordHit(r, kind); err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	// Hits are never cached, whether or not the view was counted
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// @Summary Search published articles
+// @Description Full-text search over article titles, sub titles, content and tags, ranked by relevance and recency
+// @Tags news
+// @Accept json
+// @Produce json
+// @Param q query string true "Search query"
+// @Param locale query string false "Locale, overrides Accept-Language"
+// @Param category query string false "Category slug"
+// @Param type query string false "News type"
+// @Param from query string false "Published on or after (YYYY-MM-DD)"
+// @Param to query string false "Published on or before (YYYY-MM-DD)"
+// @Param page query int false "Page number"
+// @Param limit query int false "Number of items per page"
+// @Success 200 {object} entity.SearchResponse
+// @Failure 400 {object} map[string]interface{}
+// @Router /public/v1/search [get]
+func (h *Handler) SearchNews(w http.ResponseWriter, r *http.Request) {
+	results, err := h.App.SearchNews(r)
+	if err != nil {
+		if errors.Is(err, entity.ErrInvalidQuery) {
+			utils.WriteJSONError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to search news")
+		return
+	}
+	w.Header().Set("Content-Language", results.Locale)
 	// Write response
-	utils.JsonResponse(w, http.StatusOK,news)
+	utils.JsonResponse(w, http.StatusOK, results)
+}
+
+// @Summary Latest articles
+// @Description Published articles, newest first. Pass the next_cursor or prev_cursor of a page as cursor to get the older or newer page.
+// @Tags news
+// @Produce json
+// @Param cursor query string false "next_cursor or prev_cursor of a previous page"
+// @Param limit query int false "Number of items per page (default 10, max 50)"
+// @Param locale query string false "Locale, overrides Accept-Language"
+// @Param include query string false "seo to add JSON-LD, OpenGraph and Twitter card metadata"
+// @Success 200 {object} entity.NewsListResponse
+// @Failure 400 {object} map[string]interface{}
+// @Router /public/v1/news [get]
+func (h *Handler) GetLatestNews(w http.ResponseWriter, r *http.Request) {
+	news, err := h.App.GetLatestNews(r)
+	if err != nil {
+		writeListError(w, err, "")
+		return
+	}
+	w.Header().Set("Content-Language", news.Locale)
+	w.Header().Add("Vary", "Accept-Language")
+	// Write response
+	utils.JsonResponse(w, http.StatusOK, news)
+}
+
+// @Summary Articles of a category
+// @Description Published articles of a category, newest first. Pass the next_cursor or prev_cursor of a page as cursor to get the older or newer page.
+// @Tags news
+// @Produce json
+// @Param slug path string true "Category slug"
+// @Param cursor query string false "next_cursor or prev_cursor of a previous page"
+// @Param limit query int false "Number of items per page (default 10, max 50)"
+// @Param locale query string false "Locale, overrides Accept-Language"
+// @Param include query string false "seo to add JSON-LD, OpenGraph and Twitter card metadata"
+// @Success 200 {object} entity.CategoryNewsResponse
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /public/v1/categories/{slug}/news [get]
+func (h *Handler) GetCategoryNews(w http.ResponseWriter, r *http.Request) {
+	news, err := h.App.GetCategoryNews(r)
+	if err != nil {
+		writeListError(w, err, "Category not found")
+		return
+	}
+	w.Header().Set("Content-Language", news.Locale)
+	w.Header().Add("Vary", "Accept-Language")
+	// Write response
+	utils.JsonResponse(w, http.StatusOK, news)
+}
+
+// @Summary Articles with a tag
+// @Description Published articles with a tag, newest first. Pass the next_cursor or prev_cursor of a page as cursor to get the older or newer page.
+// @Tags news
+// @Produce json
+// @Param slug path string true "Tag slug"
+// @Param cursor query string false "next_cursor or prev_cursor of a previous page"
+// @Param limit query int false "Number of items per page (default 10, max 50)"
+// @Param locale query string false "Locale, overrides Accept-Language"
+// @Param include query string false "seo to add JSON-LD, OpenGraph and Twitter card metadata"
+// @Success 200 {object} entity.TagNewsResponse
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /public/v1/tags/{slug} [get]
+func (h *Handler) GetTagNews(w http.ResponseWriter, r *http.Request) {
+	news, err := h.App.GetTagNews(r)
+	if err != nil {
+		writeListError(w, err, "Tag not found")
+		return
+	}
+	w.Header().Set("Content-Language", news.Locale)
+	w.Header().Add("Vary", "Accept-Language")
+	// Write response
+	utils.JsonResponse(w, http.StatusOK, news)
+}
+
+// writeListError maps the errors of a cursor paginated list to HTTP statuses
+func writeListError(w http.ResponseWriter, err error, notFound string) {
+	switch {
+	case errors.Is(err, entity.ErrInvalidQuery):
+		utils.WriteJSONError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, entity.ErrNewsNotFound) && notFound != "":
+		utils.WriteJSONError(w, http.StatusNotFound, notFound)
+	default:
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to fetch news")
+	}
+}
+
+// @Summary Trending articles
+// @Description Published articles ranked by recent views, with older views decaying quickly
+// @Tags news
+// @Produce json
+// @Param limit query int false "Number of articles, 10 by default and 50 at most"
+// @Param locale query string false "Locale, overrides Accept-Language"
+// @Param include query string false "seo to add JSON-LD, OpenGraph and Twitter card metadata"
+// @Success 200 {object} entity.ScrollNewsResponse
+// @Failure 400 {object} map[string]interface{}
+// @Router /public/v1/trending [get]
+func (h *Handler) GetTrending(w http.ResponseWriter, r *http.Request) {
+	news, err := h.App.GetTrending(r)
+	h.writeRanking(w, news, err)
+}
+
+// @Summary Trending articles of a category
+// @Description Published articles of a category ranked by recent views
+// @Tags news
+// @Produce json
+// @Param slug path string true "Category slug"
+// @Param limit query int false "Number of articles, 10 by default and 50 at most"
+// @Param locale query string false "Locale, overrides Accept-Language"
+// @Param include query string false "seo to add JSON-LD, OpenGraph and Twitter card metadata"
+// @Success 200 {object} entity.ScrollNewsResponse
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /public/v1/trending/category/{slug} [get]
+func (h *Handler) GetCategoryTrending(w http.ResponseWriter, r *http.Request) {
+	news, err := h.App.GetTrending(r)
+	h.writeRanking(w, news, err)
+}
+
+// @Summary Most-read articles
+// @Description Published articles ranked by views in a sliding window
+// @Tags news
+// @Produce json
+// @Param window query string false "24h, 7d or 30d, 24h by default"
+// @Param limit query int false "Number of articles, 10 by default and 50 at most"
+// @Param locale query string false "Locale, overrides Accept-Language"
+// @Param include query string false "seo to add JSON-LD, OpenGraph and Twitter card metadata"
+// @Success 200 {object} entity.ScrollNewsResponse
+// @Failure 400 {object} map[string]interface{}
+// @Router /public/v1/most-read [get]
+func (h *Handler) GetMostRead(w http.ResponseWriter, r *http.Request) {
+	news, err := h.App.GetMostRead(r)
+	h.writeRanking(w, news, err)
+}
+
+// @Summary Most-read articles of a category
+// @Description Published articles of a category ranked by views in a sliding window
+// @Tags news
+// @Produce json
+// @Param slug path string true "Category slug"
+// @Param window query string false "24h, 7d or 30d, 24h by default"
+// @Param limit query int false "Number of articles, 10 by default and 50 at most"
+// @Param locale query string false "Locale, overrides Accept-Language"
+// @Param include query string false "seo to add JSON-LD, OpenGraph and Twitter card metadata"
+// @Success 200 {object} entity.ScrollNewsResponse
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /public/v1/most-read/category/{slug} [get]
+func (h *Handler) GetCategoryMostRead(w http.ResponseWriter, r *http.Request) {
+	news, err := h.App.GetMostRead(r)
+	h.writeRanking(w, news, err)
+}
+
+// writeRanking writes a ranked list or the error that prevented it
+func (h *Handler) writeRanking(w http.ResponseWriter, news *entity.ScrollNewsResponse, err error) {
+	switch {
+	case err == nil:
+		w.Header().Set("Content-Language", news.Locale)
+		w.Header().Add("Vary", "Accept-Language")
+		utils.JsonResponse(w, http.StatusOK, news)
+	case errors.Is(err, entity.ErrInvalidQuery):
+		utils.WriteJSONError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, entity.ErrNewsNotFound):
+		utils.WriteJSONError(w, http.StatusNotFound, "Category not found")
+	default:
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to fetch news")
+	}
+}
+
+// @Summary Latest articles as RSS 2.0
+// @Description RSS feed of the latest published articles
+// @Tags feeds
+// @Produce xml
+// @Param locale query string false "Locale, overrides Accept-Language"
+// @Success 200 {string} string "RSS document"
+// @Success 304 "Not modified"
+// @Router /public/v1/feeds/latest.rss [get]
+func (h *Handler) GetLatestRSS(w http.ResponseWriter, r *http.Request) {
+	h.writeFeed(w, r, entity.FeedRSS, "")
+}
+
+// @Summary Latest articles as Atom
+// @Description Atom feed of the latest published articles
+// @Tags feeds
+// @Produce xml
+// @Param locale query string false "Locale, overrides Accept-Language"
+// @Success 200 {string} string "Atom document"
+// @Success 304 "Not modified"
+// @Router /public/v1/feeds/latest.atom [get]
+func (h *Handler) GetLatestAtom(w http.ResponseWriter, r *http.Request) {
+	h.writeFeed(w, r, entity.FeedAtom, "")
+}
+
+// @Summary Category articles as RSS 2.0
+// @Description RSS feed of the latest published articles of a category
+// @Tags feeds
+// @Produce xml
+// @Param slug path string true "Category slug followed by .rss"
+// @Param locale query string false "Locale, overrides Accept-Language"
+// @Success 200 {string} string "RSS document"
+// @Success 304 "Not modified"
+// @Failure 404 {object} map[string]interface{}
+// @Router /public/v1/feeds/category/{slug}.rss [get]
+func (h *Handler) GetCategoryRSS(w http.ResponseWriter, r *http.Request) {
+	// ServeMux wildcards match whole segments, so the extension is checked here
+	slug, ok := strings.CutSuffix(r.PathValue("file"), ".rss")
+	if !ok || slug == "" {
+		utils.WriteJSONError(w, http.StatusNotFound, "Feed not found")
+		return
+	}
+	h.writeFeed(w, r, entity.FeedRSS, slug)
+}
+
+// writeFeed writes a feed honouring If-None-Match and If-Modified-Since
+func (h *Handler) writeFeed(w http.ResponseWriter, r *http.Request, format, categorySlug string) {
+	document, err := h.App.GetFeed(r, format, categorySlug)
+	if err != nil {
+		if errors.Is(err, entity.ErrNewsNotFound) {
+			utils.WriteJSONError(w, http.StatusNotFound, "Feed not found")
+			return
+		}
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to fetch feed")
+		return
+	}
+	w.Header().Add("Vary", "Accept-Language")
+	writeDocument(w, r, document)
+}
+
+// @Summary Sitemap index
+// @Description Sitemap index listing the article, category and Google News sitemaps
+// @Tags sitemaps
+// @Produce xml
+// @Success 200 {string} string "Sitemap index"
+// @Success 304 "Not modified"
+// @Router /public/v1/sitemap.xml [get]
+func (h *Handler) GetSitemapIndex(w http.ResponseWriter, r *http.Request) {
+	document, err := h.App.GetSitemapIndex(r)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to fetch sitemap")
+		return
+	}
+	writeDocument(w, r, document)
+}
+
+// @Summary Child sitemap
+// @Description Article sitemap (news-N.xml) with image entries, categories.xml or the Google News google-news.xml
+// @Tags sitemaps
+// @Produce xml
+// @Param file path string true "Sitemap file name"
+// @Success 200 {string} string "Sitemap"
+// @Success 304 "Not modified"
+// @Failure 404 {object} map[string]interface{}
+// @Router /public/v1/sitemaps/{file} [get]
+func (h *Handler) GetSitemap(w http.ResponseWriter, r *http.Request) {
+	document, err := h.App.GetSitemap(r)
+	if err != nil {
+		if errors.Is(err, entity.ErrNewsNotFound) {
+			utils.WriteJSONError(w, http.StatusNotFound, "Sitemap not found")
+			return
+		}
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to fetch sitemap")
+		return
+	}
+	writeDocument(w, r, document)
+}
+
+// writeDocument writes a rendered XML document honouring If-None-Match and If-Modified-Since
+func writeDocument(w http.ResponseWriter, r *http.Request, document *entity.FeedDocument) {
+	w.Header().Set("ETag", document.ETag)
+	w.Header().Set("Last-Modified", document.LastModified.UTC().Format(http.TimeFormat))
+	w.Header().Set("Cache-Control", "public, max-age=300")
+
+	if match := r.Header.Get("If-None-Match"); match != "" {
+		if match == document.ETag || match == "*" {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+	} else if since, err := http.ParseTime(r.Header.Get("If-Modified-Since")); err == nil && !document.LastModified.After(since) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", document.ContentType)
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(document.Body))
+}
+
+// @Summary Breaking news stream
+// @Description Server-Sent Events pushed when an article enters or leaves a breaking placement, or changes while pinned to one. Events carry the placement name and article ID; clients reload the placement. A reset event means events were missed. Reconnecting clients resume with the Last-Event-ID header or the last_event_id query parameter.
+// @Tags news
+// @Produce text/event-stream
+// @Param Last-Event-ID header string false "ID of the last event received"
+// @Param last_event_id query string false "ID of the last event received, for clients that cannot set headers"
+// @Success 200 {object} entity.LiveNews
+// @Failure 503 {object} map[string]interface{}
+// @Router /public/v1/stream/breaking [get]
+func (h *Handler) StreamBreakingNews(w http.ResponseWriter, r *http.Request) {
+	lastID := r.Header.Get("Last-Event-ID")
+	if lastID == "" {
+		lastID = r.URL.Query().Get("last_event_id")
+	}
+	after, _ := strconv.ParseUint(lastID, 10, 64)
+
+	sub, missed, complete, err := h.App.SubscribeLive(after)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusServiceUnavailable, "Stream is unavailable")
+		return
+	}
+	defer h.App.UnsubscribeLive(sub)
+
+	flusher := http.NewResponseController(w)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no") // Keep proxies from buffering the stream
+	w.WriteHeader(http.StatusOK)
+
+	// Clients wait a few seconds before reconnecting, to another replica during a deploy
+	fmt.Fprint(w, "retry: 3000\n\n")
+	if !complete {
+		fmt.Fprintf(w, "event: %s\ndata: {}\n\n", entity.LiveReset)
+	}
+	for _, event := range missed {
+		writeEvent(w, event)
+	}
+	if err := flusher.Flush(); err != nil {
+		return
+	}
+
+	heartbeat := time.NewTicker(h.App.LiveHeartbeat())
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case event, ok := <-sub.C:
+			// The subscription ends when the client lags behind or the server shuts down
+			if !ok {
+				return
+			}
+			writeEvent(w, event)
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": heartbeat\n\n")
+		}
+		if err := flusher.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// writeEvent writes an event in the Server-Sent Events format
+func writeEvent(w http.ResponseWriter, event live.Event) {
+	fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, event.Data)
 }
@@ -2,24 +2,102 @@ package newsHttp
 
 import (
 	"net/http"
+
+	authMiddleware "github.com/JubaerHossain/cn-api/pkg/middleware"
 	"github.com/JubaerHossain/rootx/pkg/core/app"
-    "github.com/JubaerHossain/rootx/pkg/core/middleware"
+	"github.com/JubaerHossain/rootx/pkg/core/middleware"
 )
 
-// NewsRouter registers routes for API endpoints
+// NewsRouter registers public routes for API endpoints
 func NewsRouter(router *http.ServeMux, application *app.App) http.Handler {
 
-	
 	handler := NewHandler(application)
 	// Register news routes
 
-	router.Handle("GET /news", middleware.LimiterMiddleware(http.HandlerFunc(handler.GetNewses)))
-
-
+	router.Handle("GET /news", middleware.LimiterMiddleware(http.HandlerFunc(handler.GetLatestNews)))
+	router.Handle("GET /news/{slug}", middleware.LimiterMiddleware(http.HandlerFunc(handler.GetNewsBySlug)))
+	router.Handle("GET /news/{slug}/entries", middleware.LimiterMiddleware(http.HandlerFunc(handler.GetLiveBlogEntries)))
+	router.Handle("GET /categories/{slug}/news", middleware.LimiterMiddleware(http.HandlerFunc(handler.GetCategoryNews)))
+	router.Handle("GET /search", middleware.LimiterMiddleware(http.HandlerFunc(handler.SearchNews)))
+	router.Handle("GET /tags/{slug}", middleware.LimiterMiddleware(http.HandlerFunc(handler.GetTagNews)))
+	router.Handle("GET /placements/{name}", middleware.LimiterMiddleware(http.HandlerFunc(handler.GetPlacementNews)))
 	router.Handle("GET /breaking-scrolling-news", middleware.LimiterMiddleware(http.HandlerFunc(handler.GetBreakingScrollingNews)))
 	router.Handle("GET /breaking-thumbnail-news", middleware.LimiterMiddleware(http.HandlerFunc(handler.GetBreakingThumbnailNews)))
-	
-   
+	router.Handle("GET /previews/{preview}", middleware.LimiterMiddleware(http.HandlerFunc(handler.GetPreview)))
+
+	router.Handle("GET /trending", middleware.LimiterMiddleware(http.HandlerFunc(handler.GetTrending)))
+	router.Handle("GET /trending/category/{slug}", middleware.LimiterMiddleware(http.HandlerFunc(handler.GetCategoryTrending)))
+	router.Handle("GET /most-read", middleware.LimiterMiddleware(http.HandlerFunc(handler.GetMostRead)))
+	router.Handle("GET /most-read/category/{slug}", middleware.LimiterMiddleware(http.HandlerFunc(handler.GetCategoryMostRead)))
+
+	router.Handle("GET /feeds/latest.rss", middleware.LimiterMiddleware(http.HandlerFunc(handler.GetLatestRSS)))
+	router.Handle("GET /feeds/latest.atom", middleware.LimiterMiddleware(http.HandlerFunc(handler.GetLatestAtom)))
+	router.Handle("GET /feeds/category/{file}", middleware.LimiterMiddleware(http.HandlerFunc(handler.GetCategoryRSS)))
+
+	router.Handle("GET /sitemap.xml", middleware.LimiterMiddleware(http.HandlerFunc(handler.GetSitemapIndex)))
+	router.Handle("GET /sitemaps/{file}", middleware.LimiterMiddleware(http.HandlerFunc(handler.GetSitemap)))
+
+	router.Handle("POST /hits/news/{id}", middleware.LimiterMiddleware(http.HandlerFunc(handler.RecordNewsHit)))
+	router.Handle("POST /hits/categories/{id}", middleware.LimiterMiddleware(http.HandlerFunc(handler.RecordCategoryHit)))
+
+	return router
+}
+
+// NewsAdminRouter registers authenticated news management routes. Reporters
+// write and submit articles; deleting, exporting, restoring and previews are
+// left to editors and admins.
+func NewsAdminRouter(router *http.ServeMux, application *app.App) http.Handler {
+
+	handler := NewHandler(application)
+	newsroom := func(h http.HandlerFunc) http.Handler {
+		return middleware.LimiterMiddleware(authMiddleware.AuthMiddleware(application,
+			authMiddleware.RoleMiddleware(h, authMiddleware.RoleReporter, authMiddleware.RoleEditor, authMiddleware.RoleAdmin)))
+	}
+	protect := func(h http.HandlerFunc) http.Handler {
+		return middleware.LimiterMiddleware(authMiddleware.AuthMiddleware(application,
+			authMiddleware.RoleMiddleware(h, authMiddleware.RoleEditor, authMiddleware.RoleAdmin)))
+	}
+
+	router.Handle("GET /news", newsroom(handler.GetNewses))
+	router.Handle("POST /news", newsroom(handler.CreateNews))
+	router.Handle("GET /news/{id}", newsroom(handler.GetNewsDetails))
+	router.Handle("PUT /news/{id}", newsroom(handler.UpdateNews))
+	router.Handle("DELETE /news/{id}", protect(handler.DeleteNews))
+	router.Handle("POST /news/{id}/image", protect(handler.UploadNewsImage))
+	router.Handle("GET /news/export", protect(handler.ExportNews))
+
+	// Editorial workflow
+	router.Handle("GET /news/desk", newsroom(handler.GetDesk))
+	router.Handle("GET /news/{id}/transitions", newsroom(handler.GetNewsTransitions))
+	router.Handle("POST /news/{id}/transitions", newsroom(handler.TransitionNews))
+
+	// Revision history
+	router.Handle("GET /news/{id}/revisions", newsroom(handler.GetNewsRevisions))
+	router.Handle("GET /news/{id}/revisions/diff", newsroom(handler.DiffNewsRevisions))
+	router.Handle("GET /news/{id}/revisions/{revision}", newsroom(handler.GetNewsRevision))
+	router.Handle("POST /news/{id}/revisions/{revision}/restore", protect(handler.RestoreNewsRevision))
+
+	// Live blog entries
+	router.Handle("GET /news/{id}/entries", newsroom(handler.GetLiveEntries))
+	router.Handle("POST /news/{id}/entries", newsroom(handler.CreateLiveEntry))
+	router.Handle("PUT /news/{id}/entries/{entry}", newsroom(handler.UpdateLiveEntry))
+	router.Handle("DELETE /news/{id}/entries/{entry}", newsroom(handler.DeleteLiveEntry))
+
+	// Preview links
+	router.Handle("POST /news/{id}/revisions/{revision}/previews", protect(handler.CreatePreview))
+	router.Handle("GET /news/{id}/previews", protect(handler.GetPreviews))
+	router.Handle("DELETE /news/{id}/previews/{preview}", protect(handler.RevokePreview))
+
+	return router
+}
+
+// NewsStreamRouter registers the public event streams. They are served outside
+// the request metrics middleware, whose response writer cannot flush.
+func NewsStreamRouter(router *http.ServeMux, application *app.App) http.Handler {
+
+	handler := NewHandler(application)
+
+	router.Handle("GET /stream/breaking", middleware.LimiterMiddleware(http.HandlerFunc(handler.StreamBreakingNews)))
 
 	return router
 }
@@ -1,21 +1,63 @@
 package repository
 
 import (
+	"context"
 	"net/http"
 
 	"github.com/JubaerHossain/cn-api/domain/news/entity"
+	"github.com/JubaerHossain/cn-api/pkg/ranking"
 )
 
-
 // NewsRepository defines methods for news data access
 type NewsRepository interface {
 	GetNewses(r *http.Request) (*entity.NewsResponsePagination, error)
 	GetNewsByID(newsID uint) (*entity.News, error)
-	GetNews(newsID uint) (*entity.ResponseNews, error)
-	CreateNews(news *entity.News, r *http.Request)  error
+	GetNews(newsID uint) (*entity.News, error)
+	CreateNews(news *entity.News, r *http.Request) error
 	UpdateNews(oldNews *entity.News, news *entity.UpdateNews, r *http.Request) error
 	DeleteNews(news *entity.News, r *http.Request) error
+	SetNewsImage(newsID uint, image *entity.NewsImage, r *http.Request) ([]string, error)
+	TransitionNews(news *entity.News, transition *entity.Transition, history *entity.NewsTransitionHistory, r *http.Request) error
+	GetNewsTransitions(r *http.Request, newsID uint) ([]*entity.NewsTransitionHistory, error)
+	PublishScheduledNews(ctx context.Context) ([]uint, []uint, error)
+	GetNewsRevisions(r *http.Request, newsID uint, locale string) ([]*entity.NewsRevisionSummary, error)
+	GetNewsRevision(r *http.Request, newsID, revisionID uint) (*entity.NewsRevision, error)
+	RestoreNewsRevision(news *entity.News, revision *entity.NewsRevision, authorID uint, r *http.Request) error
+	CreatePreview(r *http.Request, link *entity.NewsPreviewLink) error
+	GetPreviews(r *http.Request, newsID uint) ([]*entity.NewsPreviewLink, error)
+	GetPreview(r *http.Request, previewID uint) (*entity.NewsPreviewLink, error)
+	RevokePreview(r *http.Request, link *entity.NewsPreviewLink, userID uint) error
+	GetPreviewNews(r *http.Request, link *entity.NewsPreviewLink) (*entity.NewsDetails, error)
+	CreateLiveEntry(r *http.Request, entry *entity.LiveEntry) error
+	UpdateLiveEntry(r *http.Request, entry *entity.LiveEntry) error
+	DeleteLiveEntry(r *http.Request, newsID, entryID uint) error
+	GetLiveEntry(r *http.Request, newsID, entryID uint) (*entity.LiveEntry, error)
+	GetLiveEntries(r *http.Request, newsID uint, query entity.LiveEntryQuery) (*entity.LiveEntriesResponse, error)
+	FlushViews(ctx context.Context, news, categories map[uint]int64) error
+	BackfillTags(ctx context.Context) (int, error)
+	SanitizeContent(ctx context.Context) (int, error)
+	ImportedID(ctx context.Context, kind, source string) (uint, error)
+	RecordImport(ctx context.Context, kind, source string, targetID uint) error
+	ImportedURL(ctx context.Context, kind, source string) (string, error)
+	RecordImportedURL(ctx context.Context, kind, source, targetURL string) error
+	ImportAuthor(ctx context.Context, login, name, email string, roleID uint) (uint, error)
+	ImportCategory(ctx context.Context, slug, title string, parentID uint) (uint, error)
+	ImportNews(ctx context.Context, imported *entity.ImportedNews) error
+	ExportNews(ctx context.Context, filter *entity.ExportFilter, fn func(*entity.ExportedNews) error) error
 
-	GetBreakingScrollingNews(r *http.Request) (*entity.ScrollNewsResponse, error)
-	GetBreakingThumbnailNews(r *http.Request) (*entity.ThumbnailNewsResponse, error)
-}
\ No newline at end of file
+	GetNewsBySlug(r *http.Request, slug string) (*entity.NewsDetails, string, error)
+	SearchNews(r *http.Request) (*entity.SearchResponse, error)
+	GetLatestNews(r *http.Request, cursor string, limit int) (*entity.NewsListResponse, error)
+	GetCategoryNews(r *http.Request, slug, cursor string, limit int) (*entity.CategoryNewsResponse, error)
+	GetTagNews(r *http.Request, slug, cursor string, limit int) (*entity.TagNewsResponse, error)
+	GetLiveBlogEntries(r *http.Request, slug string, query entity.LiveEntryQuery) (*entity.LiveEntriesResponse, error)
+	GetFeed(r *http.Request, format, categorySlug string) (*entity.FeedDocument, error)
+	GetSitemapIndex(r *http.Request) (*entity.FeedDocument, error)
+	GetSitemap(r *http.Request, file string) (*entity.FeedDocument, error)
+	GetPlacementNews(r *http.Request, name string) (*entity.PlacementNewsResponse, error)
+	GetNewsPlacements(ctx context.Context, newsID uint, names []string, publishedOnly bool) ([]string, error)
+	IsNewsPublished(ctx context.Context, newsID uint) (bool, error)
+	ExpirePlacementItems(ctx context.Context) ([]entity.LiveNews, error)
+	GetRanking(r *http.Request, window ranking.Window, categorySlug string, limit int) (*entity.ScrollNewsResponse, error)
+	GetDigest(ctx context.Context, query *entity.DigestQuery) ([]*entity.DigestSection, error)
+}
@@ -0,0 +1,180 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/JubaerHossain/cn-api/domain/news/entity"
+	"go.uber.org/zap"
+)
+
+// exportListSeparator joins the list values of a CSV cell
+const exportListSeparator = "|"
+
+// exportColumns is the header of the CSV export, one row per translation
+var exportColumns = []string{
+	"id", "locale", "type", "status_id", "state", "department_id", "author_id", "author", "categories",
+	"title", "slug", "sub_title", "excerpt", "content", "tags", "meta_title", "meta_description", "meta_keywords",
+	"path_small", "path_medium", "path_large", "publish_at", "unpublish_at", "published_at", "created_at", "updated_at",
+}
+
+// ExportFilter reads the format, from, to, category and locale query parameters of a bulk export
+func (s *Service) ExportFilter(r *http.Request) (*entity.ExportFilter, error) {
+	queryValues := r.URL.Query()
+	filter := &entity.ExportFilter{
+		Format:   queryValues.Get("format"),
+		Category: queryValues.Get("category"),
+		Locale:   queryValues.Get("locale"),
+	}
+	switch filter.Format {
+	case "":
+		filter.Format = entity.ExportNDJSON
+	case entity.ExportNDJSON, entity.ExportCSV:
+	default:
+		return nil, fmt.Errorf("%w: format must be ndjson or csv", entity.ErrInvalidQuery)
+	}
+	if from := queryValues.Get("from"); from != "" {
+		date, err := time.Parse(time.DateOnly, from)
+		if err != nil {
+			return nil, fmt.Errorf("%w: from must be a YYYY-MM-DD date", entity.ErrInvalidQuery)
+		}
+		filter.From = &date
+	}
+	if to := queryValues.Get("to"); to != "" {
+		date, err := time.Parse(time.DateOnly, to)
+		if err != nil {
+			return nil, fmt.Errorf("%w: to must be a YYYY-MM-DD date", entity.ErrInvalidQuery)
+		}
+		// The end date is inclusive
+		date = date.AddDate(0, 0, 1)
+		filter.To = &date
+	}
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		return nil, fmt.Errorf("%w: from must not be after to", entity.ErrInvalidQuery)
+	}
+	return filter, nil
+}
+
+// ExportNews writes the articles selected by the filter to w as they are read
+// from the database. An error after the first article leaves w truncated.
+func (s *Service) ExportNews(ctx context.Context, filter *entity.ExportFilter, w io.Writer) error {
+	var exporter newsExporter
+	if filter.Format == entity.ExportCSV {
+		exporter = &csvExporter{writer: csv.NewWriter(w)}
+	} else {
+		encoder := json.NewEncoder(w)
+		encoder.SetEscapeHTML(false) // Keep the article HTML readable
+		exporter = &ndjsonExporter{encoder: encoder}
+	}
+
+	err := s.repo.ExportNews(ctx, filter, exporter.Write)
+	if err == nil {
+		err = exporter.Close()
+	}
+	if err != nil {
+		// A client that goes away cancels the export, it is not an error of ours
+		if !errors.Is(err, context.Canceled) && ctx.Err() == nil {
+			s.app.Logger.Error("Error exporting news", zap.Error(err))
+		}
+		return err
+	}
+	return nil
+}
+
+// newsExporter writes exported articles in one format
+type newsExporter interface {
+	Write(news *entity.ExportedNews) error
+	Close() error
+}
+
+// ndjsonExporter writes an article per line
+type ndjsonExporter struct {
+	encoder *json.Encoder
+}
+
+func (e *ndjsonExporter) Write(news *entity.ExportedNews) error {
+	return e.encoder.Encode(news)
+}
+
+func (e *ndjsonExporter) Close() error {
+	return nil
+}
+
+// csvExporter writes a row per translation. The header is written with the
+// first row so that a failing query leaves the response untouched.
+type csvExporter struct {
+	writer *csv.Writer
+	header bool
+}
+
+func (e *csvExporter) Write(news *entity.ExportedNews) error {
+	if err := e.writeHeader(); err != nil {
+		return err
+	}
+	for _, translation := range news.Translations {
+		if err := e.writer.Write([]string{
+			strconv.FormatUint(uint64(news.ID), 10),
+			translation.Locale,
+			news.Type,
+			strconv.FormatUint(uint64(news.StatusID), 10),
+			news.State,
+			strconv.FormatUint(uint64(news.DepartmentID), 10),
+			strconv.FormatUint(uint64(news.Author.ID), 10),
+			news.Author.Name,
+			strings.Join(news.Categories, exportListSeparator),
+			translation.Title,
+			translation.Slug,
+			translation.SubTitle,
+			translation.Excerpt,
+			translation.Content,
+			strings.Join(translation.Tags, exportListSeparator),
+			translation.MetaTitle,
+			translation.MetaDescription,
+			strings.Join(translation.MetaKeywords, exportListSeparator),
+			news.PathSmall,
+			news.PathMedium,
+			news.PathLarge,
+			exportTime(news.PublishAt),
+			exportTime(news.UnpublishAt),
+			exportTime(news.PublishedAt),
+			exportTime(&news.CreatedAt),
+			exportTime(&news.UpdatedAt),
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *csvExporter) Close() error {
+	if err := e.writeHeader(); err != nil {
+		return err
+	}
+	e.writer.Flush()
+	return e.writer.Error()
+}
+
+// writeHeader writes the header row once
+func (e *csvExporter) writeHeader() error {
+	if e.header {
+		return nil
+	}
+	e.header = true
+	return e.writer.Write(exportColumns)
+}
+
+// exportTime formats a time of the CSV export, empty when it is not set
+func exportTime(t *time.Time) string {
+	if t == nil || t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
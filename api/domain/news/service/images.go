@@ -0,0 +1,173 @@
+package service
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/JubaerHossain/cn-api/domain/news/entity"
+	"github.com/JubaerHossain/cn-api/pkg/config"
+	"github.com/JubaerHossain/cn-api/pkg/imaging"
+	"go.uber.org/zap"
+)
+
+// imageField is the multipart form field of the uploaded original
+const imageField = "image"
+
+var (
+	imageDisk     imaging.Disk
+	imageDiskErr  error
+	imageDiskOnce sync.Once
+)
+
+// disk returns the storage selected by STORAGE_DISK
+func (s *Service) disk() (imaging.Disk, error) {
+	imageDiskOnce.Do(func() {
+		imageDisk, imageDiskErr = imaging.NewDisk(s.app.Config)
+	})
+	return imageDisk, imageDiskErr
+}
+
+// MaxImageUpload is the largest accepted original in bytes
+func (s *Service) MaxImageUpload() int64 {
+	return int64(config.GlobalConfig.ImageMaxUpload) << 20
+}
+
+// UploadNewsImage generates the renditions of the uploaded image, stores them
+// and points the article of the path at them. The files of the image it
+// replaces are deleted.
+func (s *Service) UploadNewsImage(r *http.Request) (*entity.NewsImage, error) {
+	news, err := s.GetNewsByID(r)
+	if err != nil {
+		return nil, err
+	}
+	file, _, err := r.FormFile(imageField)
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return nil, fmt.Errorf("%w: image is larger than %d MB", entity.ErrInvalidQuery, config.GlobalConfig.ImageMaxUpload)
+		}
+		return nil, fmt.Errorf("%w: %s file is required", entity.ErrInvalidQuery, imageField)
+	}
+	defer file.Close()
+
+	image, err := s.storeNewsImage(r, news, file)
+	if err != nil {
+		return nil, err
+	}
+	s.notifyNews(r.Context(), entity.LiveUpdate, news.ID, nil)
+	return image, nil
+}
+
+// storeNewsImage generates the renditions of an original, stores them and
+// points the article at them. The files of the image it replaces are deleted.
+func (s *Service) storeNewsImage(r *http.Request, news *entity.News, file io.Reader) (*entity.NewsImage, error) {
+	sizes, err := s.imageSizes()
+	if err != nil {
+		return nil, err
+	}
+	disk, err := s.disk()
+	if err != nil {
+		s.app.Logger.Error("Error opening image storage", zap.Error(err))
+		return nil, err
+	}
+
+	// Keys change with the content, so cached copies of a replaced image never linger
+	hash := sha1.New()
+	result, err := imaging.Process(io.TeeReader(file, hash), sizes, config.GlobalConfig.ImageQuality)
+	if err != nil {
+		if errors.Is(err, imaging.ErrUnsupportedImage) {
+			return nil, fmt.Errorf("%w: %v", entity.ErrInvalidQuery, err)
+		}
+		s.app.Logger.Error("Error processing image", zap.Uint("news_id", news.ID), zap.Error(err))
+		return nil, err
+	}
+	folder := fmt.Sprintf("news/%d/%s", news.ID, hex.EncodeToString(hash.Sum(nil))[:16])
+
+	image := &entity.NewsImage{
+		Placeholder: result.Placeholder,
+		Width:       result.Width,
+		Height:      result.Height,
+	}
+	files := []imaging.File{result.Original}
+	for _, size := range sizes {
+		files = append(files, result.Renditions[size.Name], result.WebP[size.Name])
+	}
+	urls := make(map[string]string, len(files))
+	for _, f := range files {
+		key := folder + "/" + f.Name
+		url, err := disk.Put(r.Context(), key, f)
+		if err != nil {
+			s.app.Logger.Error("Error storing image", zap.String("key", key), zap.Error(err))
+			s.deleteImageFiles(image.Files, news.ImageFiles)
+			return nil, err
+		}
+		image.Files = append(image.Files, key)
+		urls[f.Name] = url
+	}
+	image.PathSmall = urls[result.Renditions["small"].Name]
+	image.PathMedium = urls[result.Renditions["medium"].Name]
+	image.PathLarge = urls[result.Renditions["large"].Name]
+	image.PathSmallWebp = urls[result.WebP["small"].Name]
+	image.PathMediumWebp = urls[result.WebP["medium"].Name]
+	image.PathLargeWebp = urls[result.WebP["large"].Name]
+
+	oldFiles, err := s.repo.SetNewsImage(news.ID, image, r)
+	if err != nil {
+		s.app.Logger.Error("Error saving news image", zap.Uint("news_id", news.ID), zap.Error(err))
+		s.deleteImageFiles(image.Files, news.ImageFiles)
+		return nil, err
+	}
+	// Uploading the same original again reuses its keys, those files must stay
+	s.deleteImageFiles(oldFiles, image.Files)
+	return image, nil
+}
+
+// imageSizes returns the configured rendition widths. The three renditions
+// stored on an article are required.
+func (s *Service) imageSizes() ([]imaging.Size, error) {
+	sizes, err := imaging.ParseSizes(config.GlobalConfig.ImageSizes)
+	if err != nil {
+		return nil, fmt.Errorf("IMAGE_SIZES: %w", err)
+	}
+	names := map[string]bool{}
+	for _, size := range sizes {
+		names[size.Name] = true
+	}
+	for _, name := range []string{"small", "medium", "large"} {
+		if !names[name] {
+			return nil, fmt.Errorf("IMAGE_SIZES: %s width is missing", name)
+		}
+	}
+	return sizes, nil
+}
+
+// deleteImageFiles removes stored image files, except the keys in keep. Failures
+// are logged only: the article no longer refers to the files.
+func (s *Service) deleteImageFiles(keys, keep []string) {
+	if len(keys) == 0 {
+		return
+	}
+	disk, err := s.disk()
+	if err != nil {
+		s.app.Logger.Error("Error opening image storage", zap.Error(err))
+		return
+	}
+	kept := make(map[string]bool, len(keep))
+	for _, key := range keep {
+		kept[key] = true
+	}
+	for _, key := range keys {
+		if kept[key] {
+			continue
+		}
+		if err := disk.Delete(context.Background(), key); err != nil {
+			s.app.Logger.Error("Error deleting image file", zap.String("key", key), zap.Error(err))
+		}
+	}
+}
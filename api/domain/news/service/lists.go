@@ -0,0 +1,81 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/JubaerHossain/cn-api/domain/news/entity"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultPageLimit = 10
+	maxPageLimit     = 50
+)
+
+// GetLatestNews retrieves a page of the latest published articles. The cursor
+// query parameter takes the next_cursor or prev_cursor of a previous page.
+func (s *Service) GetLatestNews(r *http.Request) (*entity.NewsListResponse, error) {
+	limit, err := pageLimit(r)
+	if err != nil {
+		return nil, err
+	}
+	news, newsErr := s.repo.GetLatestNews(r, r.URL.Query().Get("cursor"), limit)
+	if newsErr != nil {
+		if !errors.Is(newsErr, entity.ErrInvalidQuery) {
+			s.app.Logger.Error("Error getting latest news", zap.Error(newsErr))
+		}
+		return nil, newsErr
+	}
+	s.attachListSEO(r, news.Data)
+	return news, nil
+}
+
+// GetCategoryNews retrieves a page of the latest published articles of the category in the path
+func (s *Service) GetCategoryNews(r *http.Request) (*entity.CategoryNewsResponse, error) {
+	limit, err := pageLimit(r)
+	if err != nil {
+		return nil, err
+	}
+	news, newsErr := s.repo.GetCategoryNews(r, r.PathValue("slug"), r.URL.Query().Get("cursor"), limit)
+	if newsErr != nil {
+		if !errors.Is(newsErr, entity.ErrInvalidQuery) && !errors.Is(newsErr, entity.ErrNewsNotFound) {
+			s.app.Logger.Error("Error getting category news", zap.Error(newsErr))
+		}
+		return nil, newsErr
+	}
+	s.attachListSEO(r, news.Data)
+	return news, nil
+}
+
+// GetTagNews retrieves a page of the latest published articles with the tag in the path
+func (s *Service) GetTagNews(r *http.Request) (*entity.TagNewsResponse, error) {
+	limit, err := pageLimit(r)
+	if err != nil {
+		return nil, err
+	}
+	news, tagErr := s.repo.GetTagNews(r, r.PathValue("slug"), r.URL.Query().Get("cursor"), limit)
+	if tagErr != nil {
+		if !errors.Is(tagErr, entity.ErrInvalidQuery) && !errors.Is(tagErr, entity.ErrNewsNotFound) {
+			s.app.Logger.Error("Error getting tag news", zap.Error(tagErr))
+		}
+		return nil, tagErr
+	}
+	s.attachListSEO(r, news.Data)
+	return news, nil
+}
+
+// pageLimit reads the limit query parameter of a cursor paginated list
+func pageLimit(r *http.Request) (int, error) {
+	value := r.URL.Query().Get("limit")
+	if value == "" {
+		return defaultPageLimit, nil
+	}
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit < 1 || limit > maxPageLimit {
+		return 0, fmt.Errorf("%w: limit must be between 1 and %d", entity.ErrInvalidQuery, maxPageLimit)
+	}
+	return limit, nil
+}
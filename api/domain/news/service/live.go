@@ -0,0 +1,153 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/JubaerHossain/cn-api/domain/news/entity"
+	"github.com/JubaerHossain/cn-api/pkg/config"
+	"github.com/JubaerHossain/cn-api/pkg/live"
+	"github.com/JubaerHossain/cn-api/pkg/redisclient"
+	"go.uber.org/zap"
+)
+
+// liveChannel is the Redis pub/sub channel of the breaking news stream
+const liveChannel = "live_breaking"
+
+var (
+	liveBroker     *live.Broker
+	liveBrokerOnce sync.Once
+)
+
+// broker returns the breaking news broker shared by every handler. Events go
+// through Redis pub/sub when it is enabled, so every replica pushes them, and
+// stay in the process otherwise.
+func (s *Service) broker() *live.Broker {
+	liveBrokerOnce.Do(func() {
+		liveBroker = live.New(redisclient.Get(s.app.Config), liveChannel, config.GlobalConfig.LiveReplay, s.app.Logger)
+		liveBroker.Start()
+	})
+	return liveBroker
+}
+
+// SubscribeLive subscribes to the breaking news stream, see live.Broker.Subscribe
+func (s *Service) SubscribeLive(lastID uint64) (*live.Subscription, []live.Event, bool, error) {
+	return s.broker().Subscribe(lastID)
+}
+
+// UnsubscribeLive ends a breaking news stream subscription
+func (s *Service) UnsubscribeLive(sub *live.Subscription) {
+	s.broker().Unsubscribe(sub)
+}
+
+// StopLiveEvents disconnects the breaking news streams so the server can shut down
+func (s *Service) StopLiveEvents() {
+	s.broker().Close()
+}
+
+// IsLivePlacement reports whether the placement is pushed on the breaking news stream
+func IsLivePlacement(name string) bool {
+	for _, placement := range livePlacements() {
+		if placement == name {
+			return true
+		}
+	}
+	return false
+}
+
+// NotifyPlacement pushes an event about a live placement. Other placements are
+// ignored, and so are articles readers cannot see, so drafts pinned ahead of
+// time do not reach the stream.
+func (s *Service) NotifyPlacement(ctx context.Context, eventType, placement string, newsID uint) {
+	if !IsLivePlacement(placement) {
+		return
+	}
+	if newsID != 0 {
+		published, err := s.repo.IsNewsPublished(ctx, newsID)
+		if err != nil {
+			s.app.Logger.Error("Error checking news state", zap.Uint("news_id", newsID), zap.Error(err))
+			return
+		}
+		if !published {
+			return
+		}
+	}
+	s.publishLive(ctx, eventType, entity.LiveNews{Placement: placement, NewsID: newsID})
+}
+
+// notifyNews pushes an event for every live placement the article is pinned to.
+// placements are looked up when nil, and only for published articles; callers
+// taking an article down look them up first.
+func (s *Service) notifyNews(ctx context.Context, eventType string, newsID uint, placements []string) {
+	if placements == nil {
+		var err error
+		if placements, err = s.newsPlacements(ctx, newsID, true); err != nil {
+			return
+		}
+	}
+	for _, placement := range placements {
+		s.publishLive(ctx, eventType, entity.LiveNews{Placement: placement, NewsID: newsID})
+	}
+}
+
+// notifyTransition pushes a workflow move: an article that goes live enters its
+// live placements and one taken down leaves them. Moves between states readers
+// cannot see are not pushed.
+func (s *Service) notifyTransition(ctx context.Context, newsID uint, from, to entity.State) {
+	switch {
+	case to == entity.StatePublished:
+		s.notifyNews(ctx, entity.LiveEnter, newsID, nil)
+	case from == entity.StatePublished:
+		if placements, err := s.newsPlacements(ctx, newsID, false); err == nil && len(placements) > 0 {
+			s.notifyNews(ctx, entity.LiveLeave, newsID, placements)
+		}
+	}
+}
+
+// newsPlacements returns the live placements the article is pinned to, none
+// for an unpublished article when publishedOnly is set
+func (s *Service) newsPlacements(ctx context.Context, newsID uint, publishedOnly bool) ([]string, error) {
+	placements, err := s.repo.GetNewsPlacements(ctx, newsID, livePlacements(), publishedOnly)
+	if err != nil {
+		s.app.Logger.Error("Error getting news placements", zap.Uint("news_id", newsID), zap.Error(err))
+		return nil, err
+	}
+	return placements, nil
+}
+
+// expireLivePins removes the expired pins and pushes their departure
+func (s *Service) expireLivePins(ctx context.Context) error {
+	expired, err := s.repo.ExpirePlacementItems(ctx)
+	if err != nil {
+		return err
+	}
+	for _, item := range expired {
+		s.NotifyPlacement(ctx, entity.LiveLeave, item.Placement, item.NewsID)
+	}
+	return nil
+}
+
+// publishLive publishes an event, failures are logged only: the change itself succeeded
+func (s *Service) publishLive(ctx context.Context, eventType string, data entity.LiveNews) {
+	if err := s.broker().Publish(ctx, eventType, data); err != nil {
+		s.app.Logger.Error("Error publishing live event", zap.String("type", eventType), zap.String("placement", data.Placement), zap.Error(err))
+	}
+}
+
+// livePlacements returns the placements listed in LIVE_PLACEMENTS
+func livePlacements() []string {
+	var names []string
+	for _, name := range strings.Split(config.GlobalConfig.LivePlacements, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// LiveHeartbeat is the time between heartbeats of an idle stream
+func (s *Service) LiveHeartbeat() time.Duration {
+	return time.Duration(config.GlobalConfig.LiveHeartbeat) * time.Second
+}
@@ -0,0 +1,203 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/JubaerHossain/cn-api/domain/news/entity"
+	"github.com/JubaerHossain/cn-api/pkg/middleware"
+	"go.uber.org/zap"
+)
+
+// CreateLiveEntry appends an entry to the live blog in the path
+func (s *Service) CreateLiveEntry(r *http.Request, request *entity.LiveEntryRequest) (*entity.LiveEntry, error) {
+	news, err := s.getLiveBlog(r)
+	if err != nil {
+		return nil, err
+	}
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		return nil, fmt.Errorf("unauthorized")
+	}
+	if err := validateLiveMedia(request); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	entry := &entity.LiveEntry{
+		NewsID:       news.ID,
+		Body:         request.Body,
+		MediaURL:     request.MediaURL,
+		MediaType:    request.MediaType,
+		MediaCaption: request.MediaCaption,
+		Pinned:       request.Pinned,
+		Author:       entity.Author{ID: userID},
+		UpdatedBy:    userID,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	if err := s.repo.CreateLiveEntry(r, entry); err != nil {
+		s.app.Logger.Error("Error creating live entry", zap.Error(err))
+		return nil, err
+	}
+	s.notifyNews(r.Context(), entity.LiveUpdate, news.ID, nil)
+	return s.repo.GetLiveEntry(r, news.ID, entry.ID)
+}
+
+// UpdateLiveEntry edits the entry in the path, its ID and time stay
+func (s *Service) UpdateLiveEntry(r *http.Request, request *entity.LiveEntryRequest) (*entity.LiveEntry, error) {
+	entry, err := s.getLiveEntry(r)
+	if err != nil {
+		return nil, err
+	}
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		return nil, fmt.Errorf("unauthorized")
+	}
+	if err := validateLiveMedia(request); err != nil {
+		return nil, err
+	}
+
+	entry.Body = request.Body
+	entry.MediaURL = request.MediaURL
+	entry.MediaType = request.MediaType
+	entry.MediaCaption = request.MediaCaption
+	entry.Pinned = request.Pinned
+	entry.UpdatedBy = userID
+	entry.UpdatedAt = time.Now().UTC().Truncate(time.Second)
+	if err := s.repo.UpdateLiveEntry(r, entry); err != nil {
+		s.app.Logger.Error("Error updating live entry", zap.Error(err))
+		return nil, err
+	}
+	s.notifyNews(r.Context(), entity.LiveUpdate, entry.NewsID, nil)
+	return entry, nil
+}
+
+// DeleteLiveEntry removes the entry in the path
+func (s *Service) DeleteLiveEntry(r *http.Request) error {
+	entry, err := s.getLiveEntry(r)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.DeleteLiveEntry(r, entry.NewsID, entry.ID); err != nil {
+		s.app.Logger.Error("Error deleting live entry", zap.Error(err))
+		return err
+	}
+	s.notifyNews(r.Context(), entity.LiveUpdate, entry.NewsID, nil)
+	return nil
+}
+
+// GetLiveEntries lists the entries of the live blog in the path, whatever its state.
+// The after, before and limit query parameters select the window, since adds
+// the entries edited or deleted from then on.
+func (s *Service) GetLiveEntries(r *http.Request) (*entity.LiveEntriesResponse, error) {
+	news, err := s.getLiveBlog(r)
+	if err != nil {
+		return nil, err
+	}
+	query, err := liveEntryQuery(r)
+	if err != nil {
+		return nil, err
+	}
+	entries, entriesErr := s.repo.GetLiveEntries(r, news.ID, query)
+	if entriesErr != nil {
+		s.app.Logger.Error("Error getting live entries", zap.Error(entriesErr))
+		return nil, entriesErr
+	}
+	return entries, nil
+}
+
+// GetLiveBlogEntries retrieves the entries of the published live blog with the
+// slug in the path. Readers poll with after set to the last_id and since set
+// to the synced_at they got.
+func (s *Service) GetLiveBlogEntries(r *http.Request) (*entity.LiveEntriesResponse, error) {
+	query, err := liveEntryQuery(r)
+	if err != nil {
+		return nil, err
+	}
+	entries, entriesErr := s.repo.GetLiveBlogEntries(r, r.PathValue("slug"), query)
+	if entriesErr != nil {
+		if !errors.Is(entriesErr, entity.ErrNewsNotFound) {
+			s.app.Logger.Error("Error getting live blog entries", zap.Error(entriesErr))
+		}
+		return nil, entriesErr
+	}
+	return entries, nil
+}
+
+// getLiveBlog returns the article in the path, which must be a live blog
+func (s *Service) getLiveBlog(r *http.Request) (*entity.News, error) {
+	news, err := s.GetNewsByID(r)
+	if err != nil {
+		return nil, err
+	}
+	if news.Type != entity.TypeLiveBlog {
+		return nil, entity.ErrNotLiveBlog
+	}
+	return news, nil
+}
+
+// getLiveEntry returns the entry in the path of the live blog in the path
+func (s *Service) getLiveEntry(r *http.Request) (*entity.LiveEntry, error) {
+	news, err := s.getLiveBlog(r)
+	if err != nil {
+		return nil, err
+	}
+	entryID, err := strconv.ParseUint(r.PathValue("entry"), 10, 64)
+	if err != nil {
+		return nil, entity.ErrLiveEntryNotFound
+	}
+	return s.repo.GetLiveEntry(r, news.ID, uint(entryID))
+}
+
+// liveEntryQuery reads the after, before, since and limit query parameters of an entry list
+func liveEntryQuery(r *http.Request) (entity.LiveEntryQuery, error) {
+	var query entity.LiveEntryQuery
+	queryValues := r.URL.Query()
+	for name, target := range map[string]*uint{"after": &query.After, "before": &query.Before} {
+		if value := queryValues.Get(name); value != "" {
+			id, err := strconv.ParseUint(value, 10, 64)
+			if err != nil {
+				return query, fmt.Errorf("%w: %s must be an entry ID", entity.ErrInvalidQuery, name)
+			}
+			*target = uint(id)
+		}
+	}
+	if query.After > 0 && query.Before > 0 {
+		return query, fmt.Errorf("%w: after and before cannot be combined", entity.ErrInvalidQuery)
+	}
+	if value := queryValues.Get("since"); value != "" {
+		since, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return query, fmt.Errorf("%w: since must be an RFC 3339 time", entity.ErrInvalidQuery)
+		}
+		if query.Before > 0 {
+			return query, fmt.Errorf("%w: since and before cannot be combined", entity.ErrInvalidQuery)
+		}
+		query.Since = since.UTC()
+	}
+	limit, err := pageLimit(r)
+	if err != nil {
+		return query, err
+	}
+	query.Limit = limit
+	return query, nil
+}
+
+// validateLiveMedia only accepts web URLs as the media of an entry
+func validateLiveMedia(request *entity.LiveEntryRequest) error {
+	if request.MediaURL == "" {
+		// Type and caption mean nothing without media
+		request.MediaType, request.MediaCaption = "", ""
+		return nil
+	}
+	u, err := url.Parse(request.MediaURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%w: media_url must be an http or https URL", entity.ErrInvalidQuery)
+	}
+	return nil
+}
@@ -1,6 +1,8 @@
 package service
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -8,6 +10,7 @@ import (
 	"github.com/JubaerHossain/cn-api/domain/news/entity"
 	"github.com/JubaerHossain/cn-api/domain/news/infrastructure/persistence"
 	"github.com/JubaerHossain/cn-api/domain/news/repository"
+	"github.com/JubaerHossain/cn-api/pkg/middleware"
 	"github.com/JubaerHossain/rootx/pkg/core/app"
 	"go.uber.org/zap"
 )
@@ -29,21 +32,31 @@ func (s *Service) GetNewses(r *http.Request) (*entity.NewsResponsePagination, er
 	// Call repository to get all news
 	news, newsErr := s.repo.GetNewses(r)
 	if newsErr != nil {
-		s.app.Logger.Error("Error getting news", zap.Error(newsErr))
+		if !errors.Is(newsErr, entity.ErrInvalidQuery) {
+			s.app.Logger.Error("Error getting news", zap.Error(newsErr))
+		}
 		return nil, newsErr
 	}
 	return news, nil
 }
 
-
-
 // CreateNews creates a new news
-func (s *Service) CreateNews(news *entity.News, r *http.Request)  error {
-	// Add any validation or business logic here before creating the news
-    if err := s.repo.CreateNews(news, r); err != nil {
+func (s *Service) CreateNews(news *entity.News, r *http.Request) error {
+	// The author is always the authenticated user
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		return fmt.Errorf("unauthorized")
+	}
+	news.CreatedBy = userID
+	if err := validateSchedule(news.PublishAt, news.UnpublishAt); err != nil {
+		return err
+	}
+	// Articles always enter the workflow as drafts
+	news.PublishStatusID = uint(entity.StateDraft)
+	if err := s.repo.CreateNews(news, r); err != nil {
 		s.app.Logger.Error("Error creating news", zap.Error(err))
-        return err
-    }
+		return err
+	}
 	return nil
 }
 
@@ -61,7 +74,7 @@ func (s *Service) GetNewsByID(r *http.Request) (*entity.News, error) {
 }
 
 // GetNewsDetails retrieves a news by ID
-func (s *Service) GetNewsDetails(r *http.Request) (*entity.ResponseNews, error) {
+func (s *Service) GetNewsDetails(r *http.Request) (*entity.News, error) {
 	id, err := strconv.ParseUint(r.PathValue("id"), 10, 64)
 	if err != nil {
 		return nil, fmt.Errorf("invalid news ID")
@@ -75,19 +88,37 @@ func (s *Service) GetNewsDetails(r *http.Request) (*entity.ResponseNews, error)
 }
 
 // UpdateNews updates an existing news
-func (s *Service) UpdateNews(r *http.Request, news *entity.UpdateNews)  error {
+func (s *Service) UpdateNews(r *http.Request, news *entity.UpdateNews) error {
 	// Call repository to update news
 	oldNews, err := s.GetNewsByID(r)
 	if err != nil {
 		return err
 	}
 
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		return fmt.Errorf("unauthorized")
+	}
+	if role, ok := workflowRole(r); ok {
+		if err := checkReporterAccess(role, oldNews, userID); err != nil {
+			return err
+		}
+	}
+	news.UpdatedBy = userID
+	if err := validateSchedule(news.PublishAt, news.UnpublishAt); err != nil {
+		return err
+	}
+
 	err2 := s.repo.UpdateNews(oldNews, news, r)
 	if err2 != nil {
 		s.app.Logger.Error("Error updating news", zap.Error(err2))
 		return err2
 	}
-	return  nil
+	if news.ReplacesImage(oldNews) {
+		s.deleteImageFiles(oldNews.ImageFiles, nil)
+	}
+	s.notifyNews(r.Context(), entity.LiveUpdate, oldNews.ID, nil)
+	return nil
 }
 
 // DeleteNews deletes a news by ID
@@ -97,34 +128,111 @@ func (s *Service) DeleteNews(r *http.Request) error {
 	if err != nil {
 		return err
 	}
+	// Deleting the article unpins it
+	placements, _ := s.newsPlacements(r.Context(), news.ID, true)
 
 	err2 := s.repo.DeleteNews(news, r)
 	if err2 != nil {
 		s.app.Logger.Error("Error deleting news", zap.Error(err2))
 		return err2
 	}
+	s.deleteImageFiles(news.ImageFiles, nil)
+	if len(placements) > 0 {
+		s.notifyNews(r.Context(), entity.LiveLeave, news.ID, placements)
+	}
 
 	return nil
 }
 
-// GetBreakingScrollingNews retrieves breaking and scrolling news
-func (s *Service) GetBreakingScrollingNews(r *http.Request) (*entity.ScrollNewsResponse, error) {
-	// Call repository to get breaking and scrolling news
-	news, newsErr := s.repo.GetBreakingScrollingNews(r)
+// GetPlacementNews retrieves the articles served by the placement of the path
+func (s *Service) GetPlacementNews(r *http.Request) (*entity.PlacementNewsResponse, error) {
+	name := r.PathValue("name")
+	if name == "" {
+		return nil, entity.ErrNewsNotFound
+	}
+	news, newsErr := s.repo.GetPlacementNews(r, name)
 	if newsErr != nil {
-		s.app.Logger.Error("Error getting breaking and scrolling news", zap.Error(newsErr))
+		if !errors.Is(newsErr, entity.ErrNewsNotFound) {
+			s.app.Logger.Error("Error getting placement news", zap.Error(newsErr))
+		}
 		return nil, newsErr
 	}
+	s.attachListSEO(r, news.Data)
 	return news, nil
 }
 
-// GetThumbnailNews retrieves thumbnail news
-func (s *Service) GetBreakingThumbnailNews(r *http.Request) (*entity.ThumbnailNewsResponse, error) {
-	// Call repository to get thumbnail news
-	news, newsErr := s.repo.GetBreakingThumbnailNews(r)
+// GetNewsBySlug retrieves a published article by slug. A non-empty slug is
+// returned together with ErrNewsNotFound when the requested slug has moved.
+func (s *Service) GetNewsBySlug(r *http.Request) (*entity.NewsDetails, string, error) {
+	slug := r.PathValue("slug")
+	if slug == "" {
+		return nil, "", entity.ErrNewsNotFound
+	}
+	news, currentSlug, newsErr := s.repo.GetNewsBySlug(r, slug)
 	if newsErr != nil {
-		s.app.Logger.Error("Error getting thumbnail news", zap.Error(newsErr))
-		return nil, newsErr
+		if !errors.Is(newsErr, entity.ErrNewsNotFound) {
+			s.app.Logger.Error("Error getting news by slug", zap.Error(newsErr))
+		}
+		return nil, currentSlug, newsErr
 	}
-	return news, nil
+	s.attachDetailsSEO(r, news)
+	return news, "", nil
+}
+
+// SearchNews runs a full-text search over published articles
+func (s *Service) SearchNews(r *http.Request) (*entity.SearchResponse, error) {
+	results, searchErr := s.repo.SearchNews(r)
+	if searchErr != nil {
+		if !errors.Is(searchErr, entity.ErrInvalidQuery) {
+			s.app.Logger.Error("Error searching news", zap.Error(searchErr))
+		}
+		return nil, searchErr
+	}
+	return results, nil
+}
+
+// BackfillTags converts the JSON tag arrays of existing translations into tag
+// rows. It backs the one-off tags-backfill command.
+func (s *Service) BackfillTags(ctx context.Context) (int, error) {
+	return s.repo.BackfillTags(ctx)
+}
+
+// SanitizeContent runs the content pipeline on the stored translations, saved
+// before it existed. It backs the one-off content-sanitize command.
+func (s *Service) SanitizeContent(ctx context.Context) (int, error) {
+	return s.repo.SanitizeContent(ctx)
+}
+
+// GetFeed retrieves the rendered feed of the latest articles, optionally for one category
+func (s *Service) GetFeed(r *http.Request, format, categorySlug string) (*entity.FeedDocument, error) {
+	document, feedErr := s.repo.GetFeed(r, format, categorySlug)
+	if feedErr != nil {
+		if !errors.Is(feedErr, entity.ErrNewsNotFound) {
+			s.app.Logger.Error("Error getting feed", zap.Error(feedErr))
+		}
+		return nil, feedErr
+	}
+	return document, nil
+}
+
+// GetSitemapIndex retrieves the sitemap index
+func (s *Service) GetSitemapIndex(r *http.Request) (*entity.FeedDocument, error) {
+	document, sitemapErr := s.repo.GetSitemapIndex(r)
+	if sitemapErr != nil {
+		s.app.Logger.Error("Error getting sitemap index", zap.Error(sitemapErr))
+		return nil, sitemapErr
+	}
+	return document, nil
+}
+
+// GetSitemap retrieves a child sitemap by its file name
+func (s *Service) GetSitemap(r *http.Request) (*entity.FeedDocument, error) {
+	document, sitemapErr := s.repo.GetSitemap(r, r.PathValue("file"))
+	if sitemapErr != nil {
+		if !errors.Is(sitemapErr, entity.ErrNewsNotFound) {
+			s.app.Logger.Error("Error getting sitemap", zap.Error(sitemapErr))
+		}
+		return nil, sitemapErr
+	}
+	return document, nil
 }
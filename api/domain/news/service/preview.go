@@ -0,0 +1,165 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/JubaerHossain/cn-api/domain/news/entity"
+	"github.com/JubaerHossain/cn-api/pkg/auth"
+	"github.com/JubaerHossain/cn-api/pkg/config"
+	"github.com/JubaerHossain/cn-api/pkg/middleware"
+	"github.com/JubaerHossain/cn-api/pkg/preview"
+	"go.uber.org/zap"
+)
+
+// CreatePreview issues a signed preview link of the revision in the path
+func (s *Service) CreatePreview(r *http.Request, request *entity.CreatePreview) (*entity.NewsPreviewLink, error) {
+	news, err := s.GetNewsByID(r)
+	if err != nil {
+		return nil, err
+	}
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		return nil, fmt.Errorf("unauthorized")
+	}
+	revision, err := s.getRevision(r, news.ID, r.PathValue("revision"))
+	if err != nil {
+		return nil, err
+	}
+	ttl := request.TTL
+	if ttl == 0 {
+		ttl = config.GlobalConfig.PreviewTTL
+	}
+	if ttl > config.GlobalConfig.PreviewMaxTTL {
+		return nil, fmt.Errorf("%w: ttl must be at most %d seconds", entity.ErrInvalidQuery, config.GlobalConfig.PreviewMaxTTL)
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	link := &entity.NewsPreviewLink{
+		NewsID:     news.ID,
+		RevisionID: revision.ID,
+		Locale:     revision.Locale,
+		ExpiresAt:  now.Add(time.Duration(ttl) * time.Second),
+		CreatedBy:  userID,
+		CreatedAt:  now,
+	}
+	if err := s.repo.CreatePreview(r, link); err != nil {
+		s.app.Logger.Error("Error creating preview", zap.Error(err))
+		return nil, err
+	}
+	link.URL = s.previewURL(link)
+	return link, nil
+}
+
+// GetPreviews lists the preview links of the article in the path, revoked and
+// expired ones included. Signatures are only shown when a link is issued.
+func (s *Service) GetPreviews(r *http.Request) ([]*entity.NewsPreviewLink, error) {
+	news, err := s.GetNewsByID(r)
+	if err != nil {
+		return nil, err
+	}
+	links, linkErr := s.repo.GetPreviews(r, news.ID)
+	if linkErr != nil {
+		s.app.Logger.Error("Error getting previews", zap.Error(linkErr))
+		return nil, linkErr
+	}
+	return links, nil
+}
+
+// RevokePreview ends the preview link in the path, its URL stops working at once
+func (s *Service) RevokePreview(r *http.Request) (*entity.NewsPreviewLink, error) {
+	news, err := s.GetNewsByID(r)
+	if err != nil {
+		return nil, err
+	}
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		return nil, fmt.Errorf("unauthorized")
+	}
+	link, err := s.getPreview(r)
+	if err != nil {
+		return nil, err
+	}
+	if link.NewsID != news.ID {
+		return nil, entity.ErrPreviewNotFound
+	}
+	if err := s.repo.RevokePreview(r, link, userID); err != nil {
+		s.app.Logger.Error("Error revoking preview", zap.Error(err))
+		return nil, err
+	}
+	return link, nil
+}
+
+// GetPreview renders the draft of the preview link in the path when its
+// expires and signature query parameters are valid
+func (s *Service) GetPreview(r *http.Request) (*entity.NewsPreview, error) {
+	link, err := s.getPreview(r)
+	if err != nil {
+		return nil, err
+	}
+	queryValues := r.URL.Query()
+	expires, err := strconv.ParseInt(queryValues.Get("expires"), 10, 64)
+	if err != nil || expires != link.ExpiresAt.Unix() ||
+		!preview.Verify(s.previewSecret(), previewLink(link), queryValues.Get("signature")) {
+		return nil, entity.ErrPreviewNotFound
+	}
+	if !link.Active(time.Now()) {
+		return nil, entity.ErrPreviewExpired
+	}
+
+	news, newsErr := s.repo.GetPreviewNews(r, link)
+	if newsErr != nil {
+		if !errors.Is(newsErr, entity.ErrPreviewNotFound) {
+			s.app.Logger.Error("Error getting preview news", zap.Error(newsErr))
+		}
+		return nil, newsErr
+	}
+	return &entity.NewsPreview{
+		RevisionID: link.RevisionID,
+		ExpiresAt:  link.ExpiresAt,
+		Article:    news,
+	}, nil
+}
+
+// getPreview loads the preview link of the preview path value
+func (s *Service) getPreview(r *http.Request) (*entity.NewsPreviewLink, error) {
+	id, err := strconv.ParseUint(r.PathValue("preview"), 10, 64)
+	if err != nil {
+		return nil, entity.ErrPreviewNotFound
+	}
+	link, linkErr := s.repo.GetPreview(r, uint(id))
+	if linkErr != nil {
+		if !errors.Is(linkErr, entity.ErrPreviewNotFound) {
+			s.app.Logger.Error("Error getting preview", zap.Error(linkErr))
+		}
+		return nil, linkErr
+	}
+	return link, nil
+}
+
+// previewURL returns the signed URL of a preview link
+func (s *Service) previewURL(link *entity.NewsPreviewLink) string {
+	base := config.GlobalConfig.PreviewURL
+	if base == "" {
+		base = strings.TrimSuffix(s.app.Config.Domain, "/") + "/api/public/v1/previews"
+	}
+	query := url.Values{}
+	query.Set("expires", strconv.FormatInt(link.ExpiresAt.Unix(), 10))
+	query.Set("signature", preview.Sign(s.previewSecret(), previewLink(link)))
+	return fmt.Sprintf("%s/%d?%s", strings.TrimSuffix(base, "/"), link.ID, query.Encode())
+}
+
+// previewSecret signs the preview links, see auth.SigningKey
+func (s *Service) previewSecret() []byte {
+	return auth.SigningKey(config.GlobalConfig.PreviewSecret, s.app.Config.JwtSecretKey, "preview")
+}
+
+// previewLink returns what the signature of a preview link covers
+func previewLink(link *entity.NewsPreviewLink) preview.Link {
+	return preview.Link{ID: link.ID, RevisionID: link.RevisionID, ExpiresAt: link.ExpiresAt}
+}
@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/JubaerHossain/cn-api/domain/news/entity"
+	"github.com/JubaerHossain/cn-api/pkg/ranking"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultRankingLimit = 10
+	maxRankingLimit     = 50
+)
+
+// GetTrending retrieves the articles trending now, in the category of the path if any
+func (s *Service) GetTrending(r *http.Request) (*entity.ScrollNewsResponse, error) {
+	return s.getRanking(r, ranking.Trending)
+}
+
+// GetMostRead retrieves the most-read articles in the window query parameter
+// (24h, 7d or 30d, 24h by default), in the category of the path if any
+func (s *Service) GetMostRead(r *http.Request) (*entity.ScrollNewsResponse, error) {
+	name := r.URL.Query().Get("window")
+	if name == "" {
+		name = "24h"
+	}
+	window, ok := ranking.MostRead[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: window must be 24h, 7d or 30d", entity.ErrInvalidQuery)
+	}
+	return s.getRanking(r, window)
+}
+
+// getRanking retrieves the articles ranked highest in the window
+func (s *Service) getRanking(r *http.Request, window ranking.Window) (*entity.ScrollNewsResponse, error) {
+	limit := defaultRankingLimit
+	if value := r.URL.Query().Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > maxRankingLimit {
+			return nil, fmt.Errorf("%w: limit must be between 1 and %d", entity.ErrInvalidQuery, maxRankingLimit)
+		}
+		limit = parsed
+	}
+	news, rankingErr := s.repo.GetRanking(r, window, r.PathValue("slug"), limit)
+	if rankingErr != nil {
+		if !errors.Is(rankingErr, entity.ErrNewsNotFound) {
+			s.app.Logger.Error("Error getting ranking", zap.String("window", window.Name), zap.Error(rankingErr))
+		}
+		return nil, rankingErr
+	}
+	s.attachListSEO(r, news.Data)
+	return news, nil
+}
+
+// GetDigest retrieves the top articles of each category for a newsletter digest
+func (s *Service) GetDigest(ctx context.Context, query *entity.DigestQuery) ([]*entity.DigestSection, error) {
+	sections, err := s.repo.GetDigest(ctx, query)
+	if err != nil {
+		s.app.Logger.Error("Error getting digest", zap.String("window", query.Window.Name), zap.Error(err))
+		return nil, err
+	}
+	return sections, nil
+}
@@ -0,0 +1,139 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/JubaerHossain/cn-api/domain/news/entity"
+	"github.com/JubaerHossain/cn-api/pkg/diff"
+	"github.com/JubaerHossain/cn-api/pkg/middleware"
+	"go.uber.org/zap"
+)
+
+// GetNewsRevisions lists the revisions of an article, filtered by the locale query parameter
+func (s *Service) GetNewsRevisions(r *http.Request) ([]*entity.NewsRevisionSummary, error) {
+	news, err := s.GetNewsByID(r)
+	if err != nil {
+		return nil, err
+	}
+	revisions, revisionErr := s.repo.GetNewsRevisions(r, news.ID, r.URL.Query().Get("locale"))
+	if revisionErr != nil {
+		s.app.Logger.Error("Error getting news revisions", zap.Error(revisionErr))
+		return nil, revisionErr
+	}
+	return revisions, nil
+}
+
+// GetNewsRevision retrieves the full snapshot of the revision in the path
+func (s *Service) GetNewsRevision(r *http.Request) (*entity.NewsRevision, error) {
+	news, err := s.GetNewsByID(r)
+	if err != nil {
+		return nil, err
+	}
+	return s.getRevision(r, news.ID, r.PathValue("revision"))
+}
+
+// DiffNewsRevisions compares the revisions given by the from and to query parameters
+func (s *Service) DiffNewsRevisions(r *http.Request) (*entity.RevisionDiff, error) {
+	news, err := s.GetNewsByID(r)
+	if err != nil {
+		return nil, err
+	}
+	queryValues := r.URL.Query()
+	from, err := s.getRevision(r, news.ID, queryValues.Get("from"))
+	if err != nil {
+		return nil, err
+	}
+	to, err := s.getRevision(r, news.ID, queryValues.Get("to"))
+	if err != nil {
+		return nil, err
+	}
+	return compareRevisions(from, to), nil
+}
+
+// RestoreNewsRevision restores the revision in the path as a new revision
+func (s *Service) RestoreNewsRevision(r *http.Request) error {
+	news, err := s.GetNewsByID(r)
+	if err != nil {
+		return err
+	}
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		return fmt.Errorf("unauthorized")
+	}
+	revision, err := s.getRevision(r, news.ID, r.PathValue("revision"))
+	if err != nil {
+		return err
+	}
+	if err := s.repo.RestoreNewsRevision(news, revision, userID, r); err != nil {
+		s.app.Logger.Error("Error restoring news revision", zap.Error(err))
+		return err
+	}
+	s.notifyNews(r.Context(), entity.LiveUpdate, news.ID, nil)
+	return nil
+}
+
+// getRevision loads a revision of the article by its ID given as text
+func (s *Service) getRevision(r *http.Request, newsID uint, value string) (*entity.NewsRevision, error) {
+	id, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid revision ID %q", entity.ErrInvalidQuery, value)
+	}
+	revision, revisionErr := s.repo.GetNewsRevision(r, newsID, uint(id))
+	if revisionErr != nil {
+		if !errors.Is(revisionErr, entity.ErrRevisionNotFound) {
+			s.app.Logger.Error("Error getting news revision", zap.Error(revisionErr))
+		}
+		return nil, revisionErr
+	}
+	return revision, nil
+}
+
+// compareRevisions lists the fields that differ between two revisions
+func compareRevisions(from, to *entity.NewsRevision) *entity.RevisionDiff {
+	result := &entity.RevisionDiff{
+		From:   summarize(from),
+		To:     summarize(to),
+		Fields: []*entity.FieldDiff{},
+	}
+	text := func(field, a, b string) {
+		if a != b {
+			result.Fields = append(result.Fields, &entity.FieldDiff{Field: field, From: a, To: b})
+		}
+	}
+	list := func(field string, a, b []string) {
+		if !slices.Equal(a, b) {
+			result.Fields = append(result.Fields, &entity.FieldDiff{Field: field, From: a, To: b})
+		}
+	}
+
+	text("locale", from.Locale, to.Locale)
+	text("title", from.Title, to.Title)
+	text("slug", from.Slug, to.Slug)
+	text("sub_title", from.SubTitle, to.SubTitle)
+	list("tags", from.Tags, to.Tags)
+	if from.Content != to.Content {
+		result.Fields = append(result.Fields, &entity.FieldDiff{Field: "content", Ops: diff.Words(from.Content, to.Content)})
+	}
+	text("meta_title", from.MetaTitle, to.MetaTitle)
+	text("meta_description", from.MetaDescription, to.MetaDescription)
+	list("meta_keywords", from.MetaKeywords, to.MetaKeywords)
+	return result
+}
+
+// summarize returns the list view of a revision
+func summarize(revision *entity.NewsRevision) *entity.NewsRevisionSummary {
+	return &entity.NewsRevisionSummary{
+		ID:           revision.ID,
+		Locale:       revision.Locale,
+		Number:       revision.Number,
+		Title:        revision.Title,
+		AuthorID:     revision.AuthorID,
+		AuthorName:   revision.AuthorName,
+		RestoredFrom: revision.RestoredFrom,
+		CreatedAt:    revision.CreatedAt,
+	}
+}
@@ -0,0 +1,225 @@
+package service
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/JubaerHossain/cn-api/domain/news/entity"
+	"github.com/JubaerHossain/cn-api/domain/news/infrastructure/persistence"
+	"github.com/JubaerHossain/cn-api/pkg/config"
+	"github.com/JubaerHossain/cn-api/pkg/utils"
+)
+
+// maxHeadlineLength is the longest NewsArticle headline search engines show
+const maxHeadlineLength = 110
+
+// seoArticle is what the SEO metadata is built from, common to list items and details
+type seoArticle struct {
+	title, metaTitle, description, excerpt string
+	keywords, tags                         []string
+	url, locale, author                    string
+	publishedAt, updatedAt                 string
+	categories                             []*entity.NewsCategory
+	images                                 []string // Stored paths, largest first
+}
+
+// includes reports whether the comma separated include query parameter holds the value
+func includes(r *http.Request, value string) bool {
+	for _, include := range strings.Split(r.URL.Query().Get("include"), ",") {
+		if strings.EqualFold(strings.TrimSpace(include), value) {
+			return true
+		}
+	}
+	return false
+}
+
+// attachListSEO adds the SEO metadata to the list items when the request asks for it
+func (s *Service) attachListSEO(r *http.Request, news []*entity.ScrollNews) {
+	if !includes(r, entity.IncludeSEO) {
+		return
+	}
+	for _, item := range news {
+		s.attachItemSEO(item)
+	}
+}
+
+// attachItemSEO builds the SEO metadata of a list item, its breadcrumbs stop
+// at the category the list was built with
+func (s *Service) attachItemSEO(item *entity.ScrollNews) {
+	var categories []*entity.NewsCategory
+	if item.CategorySlug != "" {
+		categories = []*entity.NewsCategory{{Title: item.Category, Slug: item.CategorySlug}}
+	}
+	item.SEO = s.buildSEO(seoArticle{
+		title:       item.Title,
+		metaTitle:   item.MetaTitle,
+		description: item.MetaDesc,
+		excerpt:     item.Excerpt,
+		keywords:    item.MetaKeywords,
+		tags:        item.Tags,
+		url:         item.URL,
+		locale:      item.Locale,
+		author:      item.Author,
+		publishedAt: item.PublishedAt,
+		updatedAt:   item.UpdatedAt,
+		categories:  categories,
+		images:      []string{item.PathLarge, item.PathMedium, item.PathSmall},
+	})
+}
+
+// attachDetailsSEO adds the SEO metadata to the article and its related
+// articles when the request asks for it
+func (s *Service) attachDetailsSEO(r *http.Request, news *entity.NewsDetails) {
+	if !includes(r, entity.IncludeSEO) {
+		return
+	}
+	news.SEO = s.buildSEO(seoArticle{
+		title:       news.Title,
+		metaTitle:   news.MetaTitle,
+		description: news.MetaDesc,
+		excerpt:     news.Excerpt,
+		keywords:    news.MetaKeywords,
+		tags:        news.Tags,
+		url:         news.URL,
+		locale:      news.Locale,
+		author:      news.Author.Name,
+		publishedAt: news.PublishedAt,
+		updatedAt:   news.UpdatedAt,
+		categories:  news.Categories,
+		images:      []string{news.Images.Large, news.Images.Medium, news.Images.Small},
+	})
+	for _, related := range news.Related {
+		s.attachItemSEO(related)
+	}
+}
+
+// buildSEO builds the NewsArticle and BreadcrumbList JSON-LD, the OpenGraph
+// and the Twitter card of an article. URLs are absolute on Config.Domain.
+func (s *Service) buildSEO(article seoArticle) *entity.SEO {
+	domain := strings.TrimSuffix(s.app.Config.Domain, "/")
+	siteName := config.GlobalConfig.SiteName
+
+	seo := &entity.SEO{
+		Title:       article.metaTitle,
+		Description: article.description,
+		Keywords:    article.keywords,
+		Canonical:   article.url,
+	}
+	if seo.Title == "" {
+		seo.Title = article.title
+	}
+	if seo.Description == "" {
+		seo.Description = article.excerpt
+	}
+	if len(seo.Keywords) == 0 {
+		seo.Keywords = article.tags
+	}
+	if seo.Keywords == nil {
+		seo.Keywords = []string{}
+	}
+
+	var images []string
+	for _, image := range article.images {
+		if image != "" {
+			images = append(images, persistence.AssetURL(domain, image))
+		}
+	}
+	published := isoDateTime(article.publishedAt)
+	modified := isoDateTime(article.updatedAt)
+	var sections []string
+	for _, category := range article.categories {
+		sections = append(sections, category.Title)
+	}
+
+	newsArticle := entity.NewsArticleLD{
+		Context:          "https://schema.org",
+		Type:             "NewsArticle",
+		MainEntityOfPage: entity.ThingLD{Type: "WebPage", ID: article.url},
+		Headline:         utils.Truncate(article.title, maxHeadlineLength-1), // Room for the ellipsis
+		Description:      seo.Description,
+		Image:            images,
+		DatePublished:    published,
+		DateModified:     modified,
+		Publisher:        entity.OrganizationLD{Type: "Organization", Name: siteName, URL: domain},
+		ArticleSection:   sections,
+		Keywords:         seo.Keywords,
+		InLanguage:       article.locale,
+	}
+	if article.author != "" {
+		newsArticle.Author = []entity.ThingLD{{Type: "Person", Name: article.author}}
+	}
+
+	breadcrumbs := entity.BreadcrumbListLD{
+		Context: "https://schema.org",
+		Type:    "BreadcrumbList",
+		ItemListElement: []entity.ListItemLD{
+			{Type: "ListItem", Position: 1, Name: siteName, Item: domain},
+		},
+	}
+	if len(article.categories) > 0 {
+		category := article.categories[0]
+		breadcrumbs.ItemListElement = append(breadcrumbs.ItemListElement, entity.ListItemLD{
+			Type: "ListItem", Position: 2, Name: category.Title, Item: fmt.Sprintf("%s/category/%s", domain, category.Slug),
+		})
+	}
+	breadcrumbs.ItemListElement = append(breadcrumbs.ItemListElement, entity.ListItemLD{
+		Type: "ListItem", Position: len(breadcrumbs.ItemListElement) + 1, Name: article.title, Item: article.url,
+	})
+	seo.JSONLD = []interface{}{newsArticle, breadcrumbs}
+
+	og := func(property, content string) {
+		if content != "" {
+			seo.OpenGraph = append(seo.OpenGraph, entity.MetaTag{Property: property, Content: content})
+		}
+	}
+	og("og:type", "article")
+	og("og:site_name", siteName)
+	og("og:title", seo.Title)
+	og("og:description", seo.Description)
+	og("og:url", article.url)
+	og("og:locale", article.locale)
+	if len(images) > 0 {
+		og("og:image", images[0])
+		og("og:image:alt", article.title)
+	}
+	og("article:published_time", published)
+	og("article:modified_time", modified)
+	og("article:author", article.author)
+	for _, section := range sections {
+		og("article:section", section)
+	}
+	for _, tag := range article.tags {
+		og("article:tag", tag)
+	}
+
+	twitter := func(name, content string) {
+		if content != "" {
+			seo.Twitter = append(seo.Twitter, entity.MetaTag{Name: name, Content: content})
+		}
+	}
+	if len(images) > 0 {
+		twitter("twitter:card", "summary_large_image")
+	} else {
+		twitter("twitter:card", "summary")
+	}
+	twitter("twitter:title", seo.Title)
+	twitter("twitter:description", seo.Description)
+	if len(images) > 0 {
+		twitter("twitter:image", images[0])
+		twitter("twitter:image:alt", article.title)
+	}
+	return seo
+}
+
+// isoDateTime turns a stored date time, UTC, into RFC 3339. Values it cannot
+// read are left out.
+func isoDateTime(value string) string {
+	for _, layout := range []string{time.DateTime, time.RFC3339} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.UTC().Format(time.RFC3339)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/JubaerHossain/cn-api/domain/news/entity"
+	"github.com/JubaerHossain/cn-api/pkg/config"
+	"github.com/JubaerHossain/cn-api/pkg/redisclient"
+	"github.com/JubaerHossain/cn-api/pkg/views"
+	"go.uber.org/zap"
+)
+
+var (
+	viewCounter     *views.Counter
+	viewCounterOnce sync.Once
+)
+
+// counter returns the view counter shared by every handler. Views are buffered in
+// Redis when it is enabled, so replicas share deduplication, and in memory otherwise.
+func (s *Service) counter() *views.Counter {
+	viewCounterOnce.Do(func() {
+		viewCounter = views.New(redisclient.Get(s.app.Config), time.Duration(config.GlobalConfig.ViewDedupeWindow)*time.Second)
+	})
+	return viewCounter
+}
+
+// RecordView counts a view of the item, ignoring bots and repeated views of a client
+func (s *Service) RecordView(r *http.Request, kind string, id uint) {
+	if _, err := s.counter().Hit(r, kind, id); err != nil {
+		s.app.Logger.Error("Error recording view", zap.String("kind", kind), zap.Uint("id", id), zap.Error(err))
+	}
+}
+
+// RecordHit counts a view of the item whose kind and ID are in the path
+func (s *Service) RecordHit(r *http.Request, kind string) error {
+	id, err := strconv.ParseUint(r.PathValue("id"), 10, 64)
+	if err != nil || id == 0 {
+		return fmt.Errorf("%w: invalid ID %q", entity.ErrInvalidQuery, r.PathValue("id"))
+	}
+	s.RecordView(r, kind, uint(id))
+	return nil
+}
+
+// StartViewFlusher writes buffered views to the database every VIEW_FLUSH_INTERVAL
+func (s *Service) StartViewFlusher() {
+	s.counter().Start(time.Duration(config.GlobalConfig.ViewFlushInterval)*time.Second, s.flushViews, s.app.Logger)
+}
+
+// StopViewFlusher stops the flusher and writes the views still buffered
+func (s *Service) StopViewFlusher(ctx context.Context) error {
+	return s.counter().Stop(ctx, s.flushViews)
+}
+
+// flushViews writes a batch of drained views to the database
+func (s *Service) flushViews(ctx context.Context, counts views.Counts) error {
+	return s.repo.FlushViews(ctx, counts[views.KindNews], counts[views.KindCategory])
+}
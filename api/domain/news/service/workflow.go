@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/JubaerHossain/cn-api/domain/news/entity"
+	"github.com/JubaerHossain/cn-api/pkg/middleware"
+	"go.uber.org/zap"
+)
+
+// TransitionNews moves an article to the requested workflow state when the
+// caller's role allows it
+func (s *Service) TransitionNews(r *http.Request, request *entity.NewsTransition) (*entity.News, error) {
+	news, err := s.GetNewsByID(r)
+	if err != nil {
+		return nil, err
+	}
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		return nil, fmt.Errorf("unauthorized")
+	}
+	role, ok := workflowRole(r)
+	if !ok {
+		return nil, entity.ErrTransitionForbidden
+	}
+	if err := checkReporterAccess(role, news, userID); err != nil {
+		return nil, err
+	}
+	to, ok := entity.ParseState(request.State)
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown state %q", entity.ErrInvalidQuery, request.State)
+	}
+
+	comment := strings.TrimSpace(request.Comment)
+	transition, err := entity.FindTransition(entity.State(news.PublishStatusID), to, role, comment)
+	if err != nil {
+		return nil, err
+	}
+	if transition.To == entity.StateScheduled && (news.PublishAt == nil || !news.PublishAt.After(time.Now())) {
+		return nil, fmt.Errorf("%w: publish_at must be set in the future to schedule an article", entity.ErrInvalidQuery)
+	}
+
+	history := &entity.NewsTransitionHistory{
+		NewsID:  news.ID,
+		Comment: comment,
+		UserID:  userID,
+		Role:    string(role),
+	}
+	if err := s.repo.TransitionNews(news, transition, history, r); err != nil {
+		if !errors.Is(err, entity.ErrTransitionNotAllowed) {
+			s.app.Logger.Error("Error transitioning news", zap.Error(err))
+		}
+		return nil, err
+	}
+
+	news.PublishStatusID = uint(transition.To)
+	news.State = transition.To.String()
+	news.UpdatedBy = userID
+	s.notifyTransition(r.Context(), news.ID, transition.From, transition.To)
+	return news, nil
+}
+
+// GetNewsTransitions retrieves the workflow history of an article
+func (s *Service) GetNewsTransitions(r *http.Request) ([]*entity.NewsTransitionHistory, error) {
+	news, err := s.GetNewsByID(r)
+	if err != nil {
+		return nil, err
+	}
+	transitions, transitionErr := s.repo.GetNewsTransitions(r, news.ID)
+	if transitionErr != nil {
+		s.app.Logger.Error("Error getting news transitions", zap.Error(transitionErr))
+		return nil, transitionErr
+	}
+	return transitions, nil
+}
+
+// GetDesk lists the articles waiting on the caller's desk. Without a state
+// filter it returns every state the caller's role can move articles out of;
+// department_id narrows the list to one desk.
+func (s *Service) GetDesk(r *http.Request) (*entity.NewsResponsePagination, error) {
+	role, ok := workflowRole(r)
+	if !ok {
+		return nil, entity.ErrTransitionForbidden
+	}
+
+	queryValues := r.URL.Query()
+	if queryValues.Get("state") == "" {
+		var names []string
+		for _, state := range entity.DeskStates(role) {
+			names = append(names, state.String())
+		}
+		queryValues.Set("state", strings.Join(names, ","))
+		r = r.Clone(r.Context())
+		r.URL.RawQuery = queryValues.Encode()
+	}
+	return s.GetNewses(r)
+}
+
+// RunSchedule publishes and unpublishes the articles that are due. It is the
+// job of the publishing scheduler started in main.
+func (s *Service) RunSchedule(ctx context.Context) error {
+	published, unpublished, err := s.repo.PublishScheduledNews(ctx)
+	if len(published)+len(unpublished) > 0 {
+		s.app.Logger.Info("Scheduled news applied", zap.Int("published", len(published)), zap.Int("unpublished", len(unpublished)))
+	}
+	for _, newsID := range published {
+		s.notifyTransition(ctx, newsID, entity.StateScheduled, entity.StatePublished)
+	}
+	for _, newsID := range unpublished {
+		s.notifyTransition(ctx, newsID, entity.StatePublished, entity.StateUnpublished)
+	}
+	if err != nil {
+		return err
+	}
+	// Pins leave their placement when they expire
+	return s.expireLivePins(ctx)
+}
+
+// validateSchedule checks that an article comes down after it goes live
+func validateSchedule(publishAt, unpublishAt *time.Time) error {
+	if publishAt != nil && unpublishAt != nil && !unpublishAt.After(*publishAt) {
+		return fmt.Errorf("%w: unpublish_at must be after publish_at", entity.ErrInvalidQuery)
+	}
+	return nil
+}
+
+// workflowRole maps the caller's JWT role ID to a workflow role through WORKFLOW_ROLES
+func workflowRole(r *http.Request) (entity.Role, bool) {
+	role, ok := middleware.WorkflowRole(r.Context())
+	return entity.Role(role), ok
+}
+
+// checkReporterAccess limits reporters to their own articles while they are
+// in draft or in review. Editors and admins may change any article.
+func checkReporterAccess(role entity.Role, news *entity.News, userID uint) error {
+	if role != entity.RoleReporter {
+		return nil
+	}
+	state := entity.State(news.PublishStatusID)
+	if news.CreatedBy != userID || (state != entity.StateDraft && state != entity.StateInReview) {
+		return entity.ErrNotOwnArticle
+	}
+	return nil
+}
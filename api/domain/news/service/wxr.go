@@ -0,0 +1,514 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/JubaerHossain/cn-api/domain/news/entity"
+	"github.com/JubaerHossain/cn-api/pkg/imaging"
+	"github.com/JubaerHossain/cn-api/pkg/locale"
+	"github.com/JubaerHossain/cn-api/pkg/utils"
+	"github.com/JubaerHossain/cn-api/pkg/wxr"
+)
+
+// importImageTimeout bounds the download of one featured image or file
+const importImageTimeout = 60 * time.Second
+
+var (
+	// imageTag matches the img elements of post content
+	imageTag = regexp.MustCompile(`(?i)<img\b[^>]*>`)
+	// imageSource matches the src attribute of an img element
+	imageSource = regexp.MustCompile(`(?i)\ssrc\s*=\s*(?:"([^"]*)"|'([^']*)')`)
+	// imageSourceSet matches the srcset and sizes attributes, their URLs point
+	// to resized copies on the exported site
+	imageSourceSet = regexp.MustCompile(`(?i)\s(?:srcset|sizes)\s*=\s*(?:"[^"]*"|'[^']*')`)
+	// linkAttribute matches the src and href attributes of any element
+	linkAttribute = regexp.MustCompile(`(?i)(\s(?:src|href)\s*=\s*)(?:"([^"]*)"|'([^']*)')`)
+)
+
+// importStatuses maps the WordPress statuses that are imported to workflow
+// states, trashed posts and auto drafts are left out
+var importStatuses = map[string]entity.State{
+	"publish": entity.StatePublished,
+	"future":  entity.StateScheduled,
+	"draft":   entity.StateDraft,
+	"pending": entity.StateDraft,
+	"private": entity.StateDraft,
+}
+
+// ImportWXR imports a WordPress export: authors become users, categories keep
+// their hierarchy, posts become articles with their tags and featured image.
+// Attachments and the images of the content are stored and the content links
+// to the stored copies. Each item is imported on its own and recorded by its
+// source key, so running the import again resumes it. Failed items are
+// reported, the import goes on.
+func (s *Service) ImportWXR(ctx context.Context, export *wxr.Export, options entity.ImportOptions) (*entity.ImportReport, error) {
+	report := entity.NewImportReport(options.DryRun)
+	if options.Locale == "" {
+		options.Locale = locale.Match(export.Language)
+	}
+	if !locale.IsSupported(options.Locale) {
+		return nil, fmt.Errorf("%w: locale %q of the export is not supported, choose one of %s",
+			entity.ErrInvalidQuery, options.Locale, strings.Join(locale.Supported(), ", "))
+	}
+	if options.Type == "" {
+		options.Type = "news"
+	}
+	if options.Site == "" {
+		options.Site = export.Link
+	}
+
+	authors, err := s.importAuthors(ctx, export.Authors, options, report)
+	if err != nil {
+		return report, err
+	}
+	categories, err := s.importCategories(ctx, export.Categories, options, report)
+	if err != nil {
+		return report, err
+	}
+
+	// Source URL to stored URL of every file the content may link to
+	media := map[string]string{}
+	attachments := make(map[uint]wxr.Item, len(export.Attachments))
+	for _, attachment := range export.Attachments {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+		attachments[attachment.PostID] = attachment
+		if options.Images {
+			s.importMedia(ctx, attachment.AttachmentURL, attachment.Title, false, media, options, report)
+		}
+	}
+	for _, post := range export.Posts {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+		newsID, imported := s.importPost(ctx, post, authors, categories, media, options, report)
+		attachment, hasImage := attachments[featuredImageID(post)]
+		if !imported || !hasImage || !options.Images {
+			continue
+		}
+		s.importFeaturedImage(ctx, newsID, post, attachment, options, report)
+	}
+	return report, nil
+}
+
+// importAuthors maps the author logins to users
+func (s *Service) importAuthors(ctx context.Context, authors []wxr.Author, options entity.ImportOptions, report *entity.ImportReport) (map[string]uint, error) {
+	users := make(map[string]uint, len(authors))
+	for _, author := range authors {
+		if author.Login == "" {
+			report.Skip(entity.ImportAuthor, "", author.Name(), "no login")
+			continue
+		}
+		userID, err := s.repo.ImportedID(ctx, entity.ImportAuthor, author.Login)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case userID != 0:
+			report.Skip(entity.ImportAuthor, author.Login, author.Name(), "already imported")
+		case options.DryRun:
+			report.Created[entity.ImportAuthor]++
+		default:
+			if userID, err = s.repo.ImportAuthor(ctx, author.Login, author.Name(), strings.TrimSpace(author.Email), options.RoleID); err != nil {
+				report.Fail(entity.ImportAuthor, author.Login, author.Name(), err)
+				continue
+			}
+			report.Created[entity.ImportAuthor]++
+		}
+		users[author.Login] = userID
+	}
+	return users, nil
+}
+
+// importCategories maps the category nicenames to categories. Parents are
+// imported before their children whatever the order of the export.
+func (s *Service) importCategories(ctx context.Context, exported []wxr.Category, options entity.ImportOptions, report *entity.ImportReport) (map[string]uint, error) {
+	categories := make(map[string]uint, len(exported))
+	names := make(map[string]bool, len(exported))
+	for _, category := range exported {
+		names[category.Nicename] = true
+	}
+	pending := exported
+	for len(pending) > 0 {
+		var waiting []wxr.Category
+		for _, category := range pending {
+			parentID, parentDone := categories[category.Parent]
+			if category.Parent != "" && names[category.Parent] && !parentDone {
+				waiting = append(waiting, category)
+				continue
+			}
+			slug := decodeSlug(category.Nicename)
+			title := html.UnescapeString(category.Name)
+			if slug == "" {
+				report.Skip(entity.ImportCategory, category.Nicename, title, "no slug")
+				continue
+			}
+			categoryID, err := s.repo.ImportedID(ctx, entity.ImportCategory, slug)
+			if err != nil {
+				return nil, err
+			}
+			switch {
+			case categoryID != 0:
+				report.Skip(entity.ImportCategory, slug, title, "already imported")
+			case options.DryRun:
+				report.Created[entity.ImportCategory]++
+			default:
+				if categoryID, err = s.repo.ImportCategory(ctx, slug, title, parentID); err != nil {
+					report.Fail(entity.ImportCategory, slug, title, err)
+					continue
+				}
+				report.Created[entity.ImportCategory]++
+			}
+			categories[category.Nicename] = categoryID
+		}
+		if len(waiting) == len(pending) {
+			// Parents that failed or that are their own ancestors
+			for _, category := range waiting {
+				report.Fail(entity.ImportCategory, category.Nicename, category.Name,
+					fmt.Errorf("parent %q was not imported", category.Parent))
+			}
+			break
+		}
+		pending = waiting
+	}
+	return categories, nil
+}
+
+// importPost creates the article of a post and returns its ID. It reports
+// true when the article exists, newly created or by an earlier import, and
+// in a dry run when it would be created.
+func (s *Service) importPost(ctx context.Context, post wxr.Item, authors, categories map[string]uint, media map[string]string, options entity.ImportOptions, report *entity.ImportReport) (uint, bool) {
+	title := utils.Truncate(strings.TrimSpace(html.UnescapeString(post.Title)), 190)
+	source := post.Key()
+	if post.PostType != "post" {
+		report.Skip(entity.ImportPost, source, title, fmt.Sprintf("post type %q is not imported", post.PostType))
+		return 0, false
+	}
+	state, ok := importStatuses[post.Status]
+	if !ok {
+		report.Skip(entity.ImportPost, source, title, fmt.Sprintf("status %q is not imported", post.Status))
+		return 0, false
+	}
+	if source == "" {
+		report.Fail(entity.ImportPost, "", title, errors.New("no GUID"))
+		return 0, false
+	}
+	newsID, err := s.repo.ImportedID(ctx, entity.ImportPost, source)
+	if err != nil {
+		report.Fail(entity.ImportPost, source, title, err)
+		return 0, false
+	}
+	if newsID != 0 {
+		report.Skip(entity.ImportPost, source, title, "already imported")
+		return newsID, true
+	}
+
+	authorID, ok := authors[post.Creator]
+	if !ok {
+		if options.AuthorID == 0 {
+			report.Skip(entity.ImportPost, source, title, fmt.Sprintf("author %q was not imported", post.Creator))
+			return 0, false
+		}
+		authorID = options.AuthorID
+	}
+	content := post.Content()
+	switch {
+	case len([]rune(title)) < 3:
+		report.Skip(entity.ImportPost, source, title, "title is shorter than 3 characters")
+		return 0, false
+	case strings.TrimSpace(utils.StripHTML(content)) == "" && !strings.Contains(content, "<img"):
+		report.Skip(entity.ImportPost, source, title, "no content")
+		return 0, false
+	}
+	if options.Images {
+		content = s.importContentImages(ctx, content, media, options, report)
+	}
+
+	news := &entity.News{
+		Type:            options.Type,
+		StatusID:        entity.StatusActive,
+		PublishStatusID: uint(state),
+		CreatedBy:       authorID,
+		Translations:    []*entity.NewsTranslation{importTranslation(post, title, content, options.Locale)},
+	}
+	for _, term := range post.TermsOf("category") {
+		if categoryID, ok := categories[term.Nicename]; ok && categoryID != 0 {
+			news.CategoryIDs = append(news.CategoryIDs, categoryID)
+		}
+	}
+	imported := &entity.ImportedNews{
+		News:      news,
+		Source:    source,
+		CreatedAt: post.Published(),
+		UpdatedAt: post.Modified(),
+	}
+	if imported.CreatedAt.IsZero() {
+		imported.CreatedAt = post.Modified()
+	}
+	if imported.CreatedAt.IsZero() {
+		imported.CreatedAt = time.Now().UTC().Truncate(time.Second)
+	}
+	if imported.UpdatedAt.IsZero() {
+		imported.UpdatedAt = imported.CreatedAt
+	}
+	switch state {
+	case entity.StatePublished:
+		imported.PublishedAt = &imported.CreatedAt
+	case entity.StateScheduled:
+		news.PublishAt = &imported.CreatedAt
+	}
+
+	if options.DryRun {
+		report.Created[entity.ImportPost]++
+		return 0, true
+	}
+	if err := s.repo.ImportNews(ctx, imported); err != nil {
+		report.Fail(entity.ImportPost, source, title, err)
+		return 0, false
+	}
+	report.Created[entity.ImportPost]++
+	return news.ID, true
+}
+
+// importFeaturedImage stores the renditions of the featured image of an
+// imported post, unless an earlier import did
+func (s *Service) importFeaturedImage(ctx context.Context, newsID uint, post, attachment wxr.Item, options entity.ImportOptions, report *entity.ImportReport) {
+	// Keyed by post: an attachment may be the featured image of several posts
+	source := post.Key()
+	done, err := s.repo.ImportedID(ctx, entity.ImportAttachment, source)
+	if err != nil {
+		report.Fail(entity.ImportAttachment, attachment.Key(), attachment.Title, err)
+		return
+	}
+	if done != 0 {
+		report.Skip(entity.ImportAttachment, attachment.Key(), attachment.Title, "already imported")
+		return
+	}
+	if options.DryRun {
+		report.Created[entity.ImportAttachment]++
+		return
+	}
+	if err := s.downloadNewsImage(ctx, newsID, attachment.AttachmentURL); err != nil {
+		report.Fail(entity.ImportAttachment, attachment.Key(), attachment.Title, err)
+		return
+	}
+	if err := s.repo.RecordImport(ctx, entity.ImportAttachment, source, newsID); err != nil {
+		report.Fail(entity.ImportAttachment, attachment.Key(), attachment.Title, err)
+		return
+	}
+	report.Created[entity.ImportAttachment]++
+}
+
+// importContentImages stores the images of post content and links the
+// content to the stored copies, and to the stored attachments it links to.
+// Images that fail to download are reported and keep their source URL.
+func (s *Service) importContentImages(ctx context.Context, content string, media map[string]string, options entity.ImportOptions, report *entity.ImportReport) string {
+	content = imageTag.ReplaceAllStringFunc(content, func(tag string) string {
+		if match := imageSource.FindStringSubmatch(tag); match != nil {
+			s.importMedia(ctx, match[1]+match[2], "", true, media, options, report)
+		}
+		return imageSourceSet.ReplaceAllString(tag, "")
+	})
+	if options.DryRun {
+		return content
+	}
+	return linkAttribute.ReplaceAllStringFunc(content, func(attribute string) string {
+		match := linkAttribute.FindStringSubmatch(attribute)
+		storedURL, ok := media[strings.TrimSpace(match[2]+match[3])]
+		if !ok {
+			return attribute
+		}
+		return match[1] + `"` + html.EscapeString(storedURL) + `"`
+	})
+}
+
+// importMedia stores the file at a source URL of the export and maps the
+// source to the stored URL, unless an earlier import did. Inline images must
+// be images, attachments may be any file.
+func (s *Service) importMedia(ctx context.Context, source, title string, image bool, media map[string]string, options entity.ImportOptions, report *entity.ImportReport) {
+	source = strings.TrimSpace(source)
+	if source == "" || strings.HasPrefix(source, "data:") {
+		return
+	}
+	if _, done := media[source]; done {
+		return
+	}
+	fileURL, err := resolveImportURL(options.Site, source)
+	if err != nil {
+		report.Fail(entity.ImportMedia, source, title, err)
+		return
+	}
+	storedURL, err := s.repo.ImportedURL(ctx, entity.ImportMedia, fileURL)
+	if err != nil {
+		report.Fail(entity.ImportMedia, source, title, err)
+		return
+	}
+	switch {
+	case storedURL != "":
+		report.Skip(entity.ImportMedia, source, title, "already imported")
+	case options.DryRun:
+		report.Created[entity.ImportMedia]++
+		storedURL = source
+	default:
+		if storedURL, err = s.storeImportedFile(ctx, fileURL, image); err != nil {
+			report.Fail(entity.ImportMedia, source, title, err)
+			return
+		}
+		if err := s.repo.RecordImportedURL(ctx, entity.ImportMedia, fileURL, storedURL); err != nil {
+			report.Fail(entity.ImportMedia, source, title, err)
+			return
+		}
+		report.Created[entity.ImportMedia]++
+	}
+	media[source] = storedURL
+	if fileURL != source {
+		media[fileURL] = storedURL
+	}
+}
+
+// storeImportedFile downloads a file and stores it as is, under a key derived
+// from its URL
+func (s *Service) storeImportedFile(ctx context.Context, fileURL string, image bool) (string, error) {
+	body, contentType, err := s.download(ctx, fileURL)
+	if err != nil {
+		return "", err
+	}
+	if image && !strings.HasPrefix(contentType, "image/") {
+		return "", fmt.Errorf("%s is not an image", contentType)
+	}
+	disk, err := s.disk()
+	if err != nil {
+		return "", err
+	}
+	name := path.Base(strings.SplitN(fileURL, "?", 2)[0])
+	if name == "." || name == "/" {
+		name = "file"
+	}
+	hash := sha1.Sum([]byte(fileURL))
+	key := fmt.Sprintf("imports/%s/%s", hex.EncodeToString(hash[:])[:16], name)
+	return disk.Put(ctx, key, imaging.File{Name: name, ContentType: contentType, Data: body})
+}
+
+// downloadNewsImage fetches an original and stores its renditions as the image of the article
+func (s *Service) downloadNewsImage(ctx context.Context, newsID uint, imageURL string) error {
+	if imageURL == "" {
+		return errors.New("no attachment URL")
+	}
+	body, _, err := s.download(ctx, imageURL)
+	if err != nil {
+		return err
+	}
+	// storeNewsImage only uses the request for its context
+	request := (&http.Request{}).WithContext(ctx)
+	_, err = s.storeNewsImage(request, &entity.News{ID: newsID}, bytes.NewReader(body))
+	return err
+}
+
+// download fetches a file of the exported site with its content type, up to
+// the largest accepted image upload
+func (s *Service) download(ctx context.Context, fileURL string) ([]byte, string, error) {
+	ctx, cancel := context.WithTimeout(ctx, importImageTimeout)
+	defer cancel()
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, fileURL, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return nil, "", err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("download failed with status %d", response.StatusCode)
+	}
+	limit := s.MaxImageUpload()
+	body, err := io.ReadAll(io.LimitReader(response.Body, limit+1))
+	if err != nil {
+		return nil, "", err
+	}
+	if int64(len(body)) > limit {
+		return nil, "", fmt.Errorf("file is larger than %d bytes", limit)
+	}
+	contentType, _, _ := mime.ParseMediaType(response.Header.Get("Content-Type"))
+	if contentType == "" {
+		contentType = http.DetectContentType(body)
+	}
+	return body, contentType, nil
+}
+
+// resolveImportURL makes a URL of the content absolute against the exported site
+func resolveImportURL(site, source string) (string, error) {
+	ref, err := url.Parse(html.UnescapeString(source))
+	if err != nil {
+		return "", err
+	}
+	if !ref.IsAbs() {
+		base, err := url.Parse(site)
+		if err != nil || base.Host == "" {
+			return "", fmt.Errorf("relative URL %q and no site link to resolve it", source)
+		}
+		ref = base.ResolveReference(ref)
+	}
+	if ref.Scheme != "http" && ref.Scheme != "https" {
+		return "", fmt.Errorf("unsupported URL scheme %q", ref.Scheme)
+	}
+	return ref.String(), nil
+}
+
+// importTranslation maps the fields of a post to a translation. SEO fields
+// written with Yoast are kept, unless they hold its %%variables%%.
+func importTranslation(post wxr.Item, title, content, translationLocale string) *entity.NewsTranslation {
+	translation := &entity.NewsTranslation{
+		Locale:   translationLocale,
+		Title:    title,
+		Slug:     post.Slug(),
+		SubTitle: utils.Truncate(utils.PlainText(post.Excerpt()), 490),
+		Content:  content,
+		Tags:     []string{},
+	}
+	for _, term := range post.TermsOf("post_tag") {
+		if name := strings.TrimSpace(html.UnescapeString(term.Name)); name != "" {
+			translation.Tags = append(translation.Tags, utils.Truncate(name, 99))
+		}
+	}
+	if metaTitle := post.MetaValue("_yoast_wpseo_title"); !strings.Contains(metaTitle, "%%") {
+		translation.MetaTitle = utils.Truncate(strings.TrimSpace(metaTitle), 190)
+	}
+	if metaDescription := post.MetaValue("_yoast_wpseo_metadesc"); !strings.Contains(metaDescription, "%%") {
+		translation.MetaDescription = utils.Truncate(strings.TrimSpace(metaDescription), 490)
+	}
+	return translation
+}
+
+// featuredImageID returns the post ID of the featured image attachment, 0 for none
+func featuredImageID(post wxr.Item) uint {
+	id, err := strconv.ParseUint(strings.TrimSpace(post.MetaValue("_thumbnail_id")), 10, 64)
+	if err != nil {
+		return 0
+	}
+	return uint(id)
+}
+
+// decodeSlug decodes a percent-encoded WordPress slug
+func decodeSlug(slug string) string {
+	if decoded, err := url.PathUnescape(slug); err == nil {
+		return decoded
+	}
+	return slug
+}
@@ -0,0 +1,191 @@
+package entity
+
+import (
+	"errors"
+	"time"
+
+	newsEntity "github.com/JubaerHossain/cn-api/domain/news/entity"
+	"github.com/JubaerHossain/rootx/pkg/core/entity"
+)
+
+var (
+	// ErrListNotFound is returned when a list does not exist, or is not active for readers
+	ErrListNotFound = errors.New("newsletter not found")
+	// ErrSubscriberNotFound is returned when a subscriber does not exist
+	ErrSubscriberNotFound = errors.New("subscriber not found")
+	// ErrInvalidList is returned for lists with a bad slug, locale or category
+	ErrInvalidList = errors.New("invalid newsletter")
+	// ErrListExists is returned when another list has the slug
+	ErrListExists = errors.New("a newsletter with this slug already exists")
+	// ErrIssueSending is returned when an issue of the list is still being delivered
+	ErrIssueSending = errors.New("an issue of this newsletter is still sending")
+	// ErrInvalidToken is returned for unknown or used confirmation and unsubscribe tokens
+	ErrInvalidToken = errors.New("invalid or expired link")
+	// ErrInvalidQuery is returned for bad filters
+	ErrInvalidQuery = errors.New("invalid query")
+	// ErrRateLimited is returned when an IP address asked for too many subscriptions
+	ErrRateLimited = errors.New("too many subscriptions, try again later")
+)
+
+// Digest frequencies
+const (
+	FrequencyDaily  = "daily"
+	FrequencyWeekly = "weekly"
+)
+
+// Subscriber statuses. Digests go to active subscribers only.
+const (
+	StatusPending      = "pending"      // Waiting for the confirmation link to be followed
+	StatusActive       = "active"       // Confirmed
+	StatusUnsubscribed = "unsubscribed" // Left through the unsubscribe link
+	StatusBounced      = "bounced"      // The address bounced too often, or for good
+)
+
+// Issue statuses
+const (
+	IssueSending = "sending" // Deliveries are pending, an interrupted send resumes
+	IssueSent    = "sent"
+)
+
+// Delivery statuses
+const (
+	DeliveryPending = "pending"
+	DeliverySent    = "sent"
+	DeliveryFailed  = "failed"  // Temporary failure, the subscriber stays active
+	DeliveryBounced = "bounced" // Permanent failure, counted as a bounce
+)
+
+// MaxBounces is the number of bounces after which a subscriber is no longer sent to
+const MaxBounces = 3
+
+// List represents a newsletter list
+type List struct {
+	ID              uint       `json:"id"`
+	Name            string     `json:"name"`
+	Slug            string     `json:"slug"`
+	Description     string     `json:"description"`
+	Frequency       string     `json:"frequency"`
+	Locale          string     `json:"locale"`
+	Categories      []string   `json:"categories"` // Category slugs, every active category when empty
+	PerCategory     int        `json:"per_category"`
+	Active          bool       `json:"active"`
+	SubscriberCount int        `json:"subscriber_count"` // Active subscribers
+	LastSentAt      *time.Time `json:"last_sent_at"`
+	CreatedBy       uint       `json:"created_by"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// ListRequest represents the request to create or update a list
+type ListRequest struct {
+	Name        string   `json:"name" validate:"required,max=100"`
+	Slug        string   `json:"slug" validate:"required,max=100"`
+	Description string   `json:"description" validate:"max=500"`
+	Frequency   string   `json:"frequency" validate:"required,oneof=daily weekly"`
+	Locale      string   `json:"locale"` // The default locale when empty
+	Categories  []string `json:"categories" validate:"max=50"`
+	PerCategory int      `json:"per_category" validate:"gte=0,lte=10"` // 3 when 0
+	Active      bool     `json:"active"`
+}
+
+// PublicList represents a list as readers see it
+type PublicList struct {
+	Slug        string `json:"slug"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Frequency   string `json:"frequency"`
+	Locale      string `json:"locale"`
+}
+
+// Subscriber represents a subscriber of a list
+type Subscriber struct {
+	ID               uint        `json:"id"`
+	ListID           uint        `json:"list_id"`
+	Email            string      `json:"email"`
+	Name             string      `json:"name"`
+	Status           string      `json:"status"`
+	ConfirmToken     string      `json:"-"`
+	UnsubscribeToken string      `json:"-"`
+	IPAddress        string      `json:"ip_address"`
+	ConfirmedAt      *time.Time  `json:"confirmed_at"`
+	UnsubscribedAt   *time.Time  `json:"unsubscribed_at"`
+	BounceCount      int         `json:"bounce_count"`
+	BouncedAt        *time.Time  `json:"bounced_at"`
+	BounceReason     string      `json:"bounce_reason"`
+	LastSentAt       *time.Time  `json:"last_sent_at"`
+	Deliveries       []*Delivery `json:"deliveries,omitempty"` // Send history, newest first
+	CreatedAt        time.Time   `json:"created_at"`
+	UpdatedAt        time.Time   `json:"updated_at"`
+}
+
+// SubscribeRequest represents the request of a reader to subscribe to a list
+type SubscribeRequest struct {
+	Email string `json:"email" validate:"required,email,max=255"`
+	Name  string `json:"name" validate:"max=100"`
+}
+
+// BounceRequest represents a bounce reported for an address, by the mail
+// provider or an editor. It applies to every list of the address.
+type BounceRequest struct {
+	Email     string `json:"email" validate:"required,email,max=255"`
+	Reason    string `json:"reason" validate:"max=500"`
+	Permanent bool   `json:"permanent"` // Stops sending at once, otherwise after MaxBounces
+}
+
+// Issue represents a digest sent to a list
+type Issue struct {
+	ID             uint                        `json:"id"`
+	ListID         uint                        `json:"list_id"`
+	Subject        string                      `json:"subject"`
+	NewsIDs        []uint                      `json:"news_ids"`
+	Sections       []*newsEntity.DigestSection `json:"-"` // The composed digest, loaded to send it
+	Status         string                      `json:"status"`
+	RecipientCount int                         `json:"recipient_count"`
+	SentCount      int                         `json:"sent_count"`
+	FailedCount    int                         `json:"failed_count"`
+	CreatedAt      time.Time                   `json:"created_at"`
+	SentAt         *time.Time                  `json:"sent_at"`
+}
+
+// Delivery represents the delivery of an issue to a subscriber
+type Delivery struct {
+	ID           uint        `json:"id"`
+	IssueID      uint        `json:"issue_id"`
+	SubscriberID uint        `json:"subscriber_id"`
+	Email        string      `json:"email,omitempty"`
+	Subject      string      `json:"subject,omitempty"`
+	Status       string      `json:"status"`
+	Error        string      `json:"error"`
+	Attempts     int         `json:"attempts"`
+	SentAt       *time.Time  `json:"sent_at"`
+	Subscriber   *Subscriber `json:"-"` // The recipient, loaded to send the issue
+}
+
+// Digest represents a composed digest of a list
+type Digest struct {
+	List     *List                       `json:"list"`
+	Subject  string                      `json:"subject"`
+	Since    time.Time                   `json:"since"`
+	Sections []*newsEntity.DigestSection `json:"sections"`
+}
+
+// NewsIDs returns the IDs of the articles of the digest, in order
+func (d *Digest) NewsIDs() []uint {
+	ids := []uint{}
+	for _, section := range d.Sections {
+		for _, news := range section.News {
+			ids = append(ids, news.ID)
+		}
+	}
+	return ids
+}
+
+type SubscriberResponsePagination struct {
+	Data       []*Subscriber     `json:"data"`
+	Pagination entity.Pagination `json:"pagination"`
+}
+
+type IssueResponsePagination struct {
+	Data       []*Issue          `json:"data"`
+	Pagination entity.Pagination `json:"pagination"`
+}
@@ -0,0 +1,811 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	newsEntity "github.com/JubaerHossain/cn-api/domain/news/entity"
+	"github.com/JubaerHossain/cn-api/domain/newsletters/entity"
+	"github.com/JubaerHossain/cn-api/domain/newsletters/repository"
+	utilQuery "github.com/JubaerHossain/cn-api/pkg/utils"
+	"github.com/JubaerHossain/rootx/pkg/core/app"
+)
+
+// listQuery selects a list with the number of its active subscribers
+var listQuery = fmt.Sprintf(`
+	SELECT id, name, slug, description, frequency, locale, COALESCE(CAST(categories AS CHAR), ''), per_category, active,
+	       (SELECT COUNT(*) FROM newsletter_subscribers WHERE newsletter_subscribers.list_id = newsletter_lists.id AND newsletter_subscribers.status = '%s'),
+	       last_sent_at, created_by, created_at, updated_at
+	FROM newsletter_lists`, entity.StatusActive)
+
+// subscriberQuery selects a subscriber
+const subscriberQuery = `
+	SELECT id, list_id, email, name, status, COALESCE(confirm_token, ''), unsubscribe_token, ip_address, confirmed_at, unsubscribed_at,
+	       bounce_count, bounced_at, bounce_reason, last_sent_at, created_at, updated_at
+	FROM newsletter_subscribers`
+
+// issueQuery selects an issue without its sections
+const issueQuery = `
+	SELECT id, list_id, subject, CAST(news_ids AS CHAR), status, recipient_count, sent_count, failed_count, created_at, sent_at
+	FROM newsletter_issues`
+
+// slugPattern restricts slugs to what can sit in a URL path
+var slugPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+type NewsletterRepositoryImpl struct {
+	app *app.App
+}
+
+// NewNewsletterRepository returns a new instance of NewsletterRepositoryImpl
+func NewNewsletterRepository(app *app.App) repository.NewsletterRepository {
+	return &NewsletterRepositoryImpl{
+		app: app,
+	}
+}
+
+// GetLists returns the lists sorted by name, the active ones only when activeOnly is set
+func (r *NewsletterRepositoryImpl) GetLists(ctx context.Context, activeOnly bool) ([]*entity.List, error) {
+	query := listQuery
+	if activeOnly {
+		query += " WHERE active = TRUE"
+	}
+	rows, err := r.app.MDB.QueryContext(ctx, query+" ORDER BY name ASC, id ASC")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query newsletters: %w", err)
+	}
+	defer rows.Close()
+
+	lists := []*entity.List{}
+	for rows.Next() {
+		list, err := scanList(rows)
+		if err != nil {
+			return nil, err
+		}
+		lists = append(lists, list)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return lists, nil
+}
+
+// GetList returns a list by ID
+func (r *NewsletterRepositoryImpl) GetList(ctx context.Context, listID uint) (*entity.List, error) {
+	list, err := scanList(r.app.MDB.QueryRowContext(ctx, listQuery+" WHERE id = ?", listID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, entity.ErrListNotFound
+	}
+	return list, err
+}
+
+// GetListBySlug returns an active list by slug
+func (r *NewsletterRepositoryImpl) GetListBySlug(ctx context.Context, slug string) (*entity.List, error) {
+	list, err := scanList(r.app.MDB.QueryRowContext(ctx, listQuery+" WHERE slug = ? AND active = TRUE", slug))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, entity.ErrListNotFound
+	}
+	return list, err
+}
+
+// CreateList stores a list and sets its ID
+func (r *NewsletterRepositoryImpl) CreateList(req *http.Request, list *entity.List) error {
+	ctx := req.Context()
+	categories, err := r.checkList(ctx, list)
+	if err != nil {
+		return err
+	}
+
+	result, err := r.app.MDB.ExecContext(ctx, `
+		INSERT INTO newsletter_lists (name, slug, description, frequency, locale, categories, per_category, active, created_by, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		list.Name, list.Slug, list.Description, list.Frequency, list.Locale, categories, list.PerCategory, list.Active,
+		list.CreatedBy, list.CreatedAt.Format(time.DateTime), list.UpdatedAt.Format(time.DateTime),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create newsletter: %w", err)
+	}
+	if id, err := result.LastInsertId(); err == nil {
+		list.ID = uint(id)
+	}
+	return nil
+}
+
+// UpdateList stores the settings of a list. Subscribers and issues are kept.
+func (r *NewsletterRepositoryImpl) UpdateList(req *http.Request, list *entity.List) error {
+	ctx := req.Context()
+	categories, err := r.checkList(ctx, list)
+	if err != nil {
+		return err
+	}
+
+	if _, err := r.app.MDB.ExecContext(ctx, `
+		UPDATE newsletter_lists
+		SET name = ?, slug = ?, description = ?, frequency = ?, locale = ?, categories = ?, per_category = ?, active = ?, updated_at = ?
+		WHERE id = ?`,
+		list.Name, list.Slug, list.Description, list.Frequency, list.Locale, categories, list.PerCategory, list.Active,
+		list.UpdatedAt.Format(time.DateTime), list.ID,
+	); err != nil {
+		return fmt.Errorf("failed to update newsletter: %w", err)
+	}
+	return nil
+}
+
+// DeleteList removes a list with its subscribers, issues and deliveries
+func (r *NewsletterRepositoryImpl) DeleteList(req *http.Request, list *entity.List) error {
+	ctx := req.Context()
+	tx, err := r.app.MDB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, query := range []string{
+		"DELETE FROM newsletter_deliveries WHERE issue_id IN (SELECT id FROM newsletter_issues WHERE list_id = ?)",
+		"DELETE FROM newsletter_issues WHERE list_id = ?",
+		"DELETE FROM newsletter_subscribers WHERE list_id = ?",
+		"DELETE FROM newsletter_lists WHERE id = ?",
+	} {
+		if _, err := tx.ExecContext(ctx, query, list.ID); err != nil {
+			return fmt.Errorf("failed to delete newsletter: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+// GetSubscribers returns a page of the subscribers of a list, newest first,
+// filtered by status and email search
+func (r *NewsletterRepositoryImpl) GetSubscribers(req *http.Request, listID uint) (*entity.SubscriberResponsePagination, error) {
+	ctx := req.Context()
+	baseQuery := subscriberQuery
+
+	filterQuery := " WHERE list_id = ?"
+	args := []interface{}{listID}
+	if status := req.URL.Query().Get("status"); status != "" {
+		switch status {
+		case entity.StatusPending, entity.StatusActive, entity.StatusUnsubscribed, entity.StatusBounced:
+		default:
+			return nil, fmt.Errorf("%w: unknown status %q", entity.ErrInvalidQuery, status)
+		}
+		filterQuery += " AND status = ?"
+		args = append(args, status)
+	}
+	if search := strings.TrimSpace(req.URL.Query().Get("search")); search != "" {
+		filterQuery += " AND email LIKE ?"
+		args = append(args, "%"+search+"%")
+	}
+
+	// Pagination and limits
+	pagination, limit, offset, err := utilQuery.Paginate(req, r.app, baseQuery, filterQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("pagination error: %w", err)
+	}
+	query := fmt.Sprintf("%s%s ORDER BY id DESC LIMIT %d OFFSET %d", baseQuery, filterQuery, limit, offset)
+
+	rows, err := r.app.MDB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query subscribers: %w", err)
+	}
+	defer rows.Close()
+
+	subscribers := []*entity.Subscriber{}
+	for rows.Next() {
+		subscriber, err := scanSubscriber(rows)
+		if err != nil {
+			return nil, err
+		}
+		subscribers = append(subscribers, subscriber)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return &entity.SubscriberResponsePagination{
+		Data:       subscribers,
+		Pagination: pagination,
+	}, nil
+}
+
+// GetSubscriber returns a subscriber of a list with their latest deliveries
+func (r *NewsletterRepositoryImpl) GetSubscriber(ctx context.Context, listID, subscriberID uint) (*entity.Subscriber, error) {
+	subscriber, err := scanSubscriber(r.app.MDB.QueryRowContext(ctx, subscriberQuery+" WHERE id = ? AND list_id = ?", subscriberID, listID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, entity.ErrSubscriberNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.app.MDB.QueryContext(ctx, `
+		SELECT newsletter_deliveries.id, newsletter_deliveries.issue_id, newsletter_deliveries.subscriber_id, COALESCE(newsletter_issues.subject, ''),
+		       newsletter_deliveries.status, newsletter_deliveries.error, newsletter_deliveries.attempts, newsletter_deliveries.sent_at
+		FROM newsletter_deliveries
+		LEFT JOIN newsletter_issues ON newsletter_issues.id = newsletter_deliveries.issue_id
+		WHERE newsletter_deliveries.subscriber_id = ?
+		ORDER BY newsletter_deliveries.id DESC
+		LIMIT 50`, subscriber.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	subscriber.Deliveries = []*entity.Delivery{}
+	for rows.Next() {
+		var (
+			delivery entity.Delivery
+			sentAt   sql.NullString
+		)
+		if err := rows.Scan(&delivery.ID, &delivery.IssueID, &delivery.SubscriberID, &delivery.Subject,
+			&delivery.Status, &delivery.Error, &delivery.Attempts, &sentAt); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		delivery.SentAt = parseNullDateTime(sentAt)
+		subscriber.Deliveries = append(subscriber.Deliveries, &delivery)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return subscriber, nil
+}
+
+// DeleteSubscriber removes a subscriber with their send history
+func (r *NewsletterRepositoryImpl) DeleteSubscriber(req *http.Request, subscriber *entity.Subscriber) error {
+	ctx := req.Context()
+	tx, err := r.app.MDB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM newsletter_deliveries WHERE subscriber_id = ?", subscriber.ID); err != nil {
+		return fmt.Errorf("failed to delete deliveries: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM newsletter_subscribers WHERE id = ?", subscriber.ID); err != nil {
+		return fmt.Errorf("failed to delete subscriber: %w", err)
+	}
+	return tx.Commit()
+}
+
+// Subscribe stores a subscription request and returns the subscriber as
+// stored. A new address is added as pending. A pending address keeps its
+// confirmation token, so an earlier confirmation email still works. An address
+// that unsubscribed or bounced is pending again with the new confirmation
+// token. An active subscriber is returned unchanged.
+func (r *NewsletterRepositoryImpl) Subscribe(ctx context.Context, subscriber *entity.Subscriber) (*entity.Subscriber, error) {
+	tx, err := r.app.MDB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	existing, err := scanSubscriber(tx.QueryRowContext(ctx, subscriberQuery+" WHERE list_id = ? AND email = ? FOR UPDATE",
+		subscriber.ListID, subscriber.Email))
+	now := subscriber.CreatedAt.Format(time.DateTime)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		result, err := tx.ExecContext(ctx, `
+			INSERT INTO newsletter_subscribers (list_id, email, name, status, confirm_token, unsubscribe_token, ip_address, created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			subscriber.ListID, subscriber.Email, subscriber.Name, entity.StatusPending, subscriber.ConfirmToken,
+			subscriber.UnsubscribeToken, subscriber.IPAddress, now, now,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create subscriber: %w", err)
+		}
+		id, err := result.LastInsertId()
+		if err != nil {
+			return nil, err
+		}
+		subscriber.ID = uint(id)
+		subscriber.Status = entity.StatusPending
+		subscriber.UpdatedAt = subscriber.CreatedAt
+		return subscriber, tx.Commit()
+	case err != nil:
+		return nil, err
+	case existing.Status == entity.StatusActive:
+		return existing, nil
+	}
+
+	if existing.Status != entity.StatusPending || existing.ConfirmToken == "" {
+		existing.ConfirmToken = subscriber.ConfirmToken
+	}
+	if subscriber.Name != "" {
+		existing.Name = subscriber.Name
+	}
+	existing.Status = entity.StatusPending
+	existing.IPAddress = subscriber.IPAddress
+	existing.UpdatedAt = subscriber.CreatedAt
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE newsletter_subscribers SET name = ?, status = ?, confirm_token = ?, ip_address = ?, updated_at = ?
+		WHERE id = ?`,
+		existing.Name, existing.Status, existing.ConfirmToken, existing.IPAddress, now, existing.ID,
+	); err != nil {
+		return nil, fmt.Errorf("failed to update subscriber: %w", err)
+	}
+	return existing, tx.Commit()
+}
+
+// Confirm activates the pending subscriber of a confirmation token. The
+// token works once, and the bounces of an earlier subscription are forgotten.
+func (r *NewsletterRepositoryImpl) Confirm(ctx context.Context, token string) (*entity.Subscriber, error) {
+	subscriber, err := scanSubscriber(r.app.MDB.QueryRowContext(ctx, subscriberQuery+" WHERE confirm_token = ?", token))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, entity.ErrInvalidToken
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	result, err := r.app.MDB.ExecContext(ctx, `
+		UPDATE newsletter_subscribers
+		SET status = ?, confirm_token = NULL, confirmed_at = ?, bounce_count = 0, bounced_at = NULL, bounce_reason = '', updated_at = ?
+		WHERE id = ? AND confirm_token = ?`,
+		entity.StatusActive, now.Format(time.DateTime), now.Format(time.DateTime), subscriber.ID, token,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to confirm subscriber: %w", err)
+	}
+	// Another request confirmed it in between
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return nil, entity.ErrInvalidToken
+	}
+	subscriber.Status = entity.StatusActive
+	subscriber.ConfirmToken = ""
+	subscriber.ConfirmedAt = &now
+	subscriber.BounceCount = 0
+	subscriber.BouncedAt = nil
+	subscriber.BounceReason = ""
+	subscriber.UpdatedAt = now
+	return subscriber, nil
+}
+
+// GetSubscriberByUnsubscribeToken returns the subscriber of an unsubscribe token
+func (r *NewsletterRepositoryImpl) GetSubscriberByUnsubscribeToken(ctx context.Context, token string) (*entity.Subscriber, error) {
+	subscriber, err := scanSubscriber(r.app.MDB.QueryRowContext(ctx, subscriberQuery+" WHERE unsubscribe_token = ?", token))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, entity.ErrInvalidToken
+	}
+	return subscriber, err
+}
+
+// Unsubscribe stops sending to the subscriber of an unsubscribe token. The
+// token keeps working, a second request changes nothing.
+func (r *NewsletterRepositoryImpl) Unsubscribe(ctx context.Context, token string) (*entity.Subscriber, error) {
+	subscriber, err := scanSubscriber(r.app.MDB.QueryRowContext(ctx, subscriberQuery+" WHERE unsubscribe_token = ?", token))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, entity.ErrInvalidToken
+	}
+	if err != nil {
+		return nil, err
+	}
+	if subscriber.Status == entity.StatusUnsubscribed {
+		return subscriber, nil
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	if _, err := r.app.MDB.ExecContext(ctx, `
+		UPDATE newsletter_subscribers SET status = ?, confirm_token = NULL, unsubscribed_at = ?, updated_at = ?
+		WHERE id = ?`,
+		entity.StatusUnsubscribed, now.Format(time.DateTime), now.Format(time.DateTime), subscriber.ID,
+	); err != nil {
+		return nil, fmt.Errorf("failed to unsubscribe: %w", err)
+	}
+	subscriber.Status = entity.StatusUnsubscribed
+	subscriber.ConfirmToken = ""
+	subscriber.UnsubscribedAt = &now
+	subscriber.UpdatedAt = now
+	return subscriber, nil
+}
+
+// RecordBounce counts a bounce against the pending and active subscriptions of
+// an address and returns how many there were. They are marked bounced when the
+// bounce is permanent or the address reached MaxBounces.
+func (r *NewsletterRepositoryImpl) RecordBounce(ctx context.Context, email, reason string, permanent bool) (int64, error) {
+	now := time.Now().UTC().Format(time.DateTime)
+	// The status is set first, so it sees the bounce count before the increment
+	result, err := r.app.MDB.ExecContext(ctx, `
+		UPDATE newsletter_subscribers
+		SET status = CASE WHEN ? OR bounce_count + 1 >= ? THEN ? ELSE status END,
+		    bounce_count = bounce_count + 1, bounced_at = ?, bounce_reason = ?, updated_at = ?
+		WHERE email = ? AND status IN (?, ?)`,
+		permanent, entity.MaxBounces, entity.StatusBounced, now, reason, now,
+		email, entity.StatusPending, entity.StatusActive,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to record bounce: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// GetIssues returns a page of the issues of a list, newest first
+func (r *NewsletterRepositoryImpl) GetIssues(req *http.Request, listID uint) (*entity.IssueResponsePagination, error) {
+	ctx := req.Context()
+	baseQuery := issueQuery
+	filterQuery := " WHERE list_id = ?"
+	args := []interface{}{listID}
+
+	// Pagination and limits
+	pagination, limit, offset, err := utilQuery.Paginate(req, r.app, baseQuery, filterQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("pagination error: %w", err)
+	}
+	query := fmt.Sprintf("%s%s ORDER BY id DESC LIMIT %d OFFSET %d", baseQuery, filterQuery, limit, offset)
+
+	rows, err := r.app.MDB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query issues: %w", err)
+	}
+	defer rows.Close()
+
+	issues := []*entity.Issue{}
+	for rows.Next() {
+		issue, err := scanIssue(rows)
+		if err != nil {
+			return nil, err
+		}
+		issues = append(issues, issue)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return &entity.IssueResponsePagination{
+		Data:       issues,
+		Pagination: pagination,
+	}, nil
+}
+
+// GetSendingIssues returns the issues with deliveries still to send, oldest
+// first, with their sections
+func (r *NewsletterRepositoryImpl) GetSendingIssues(ctx context.Context) ([]*entity.Issue, error) {
+	rows, err := r.app.MDB.QueryContext(ctx, `
+		SELECT id, list_id, subject, CAST(news_ids AS CHAR), status, recipient_count, sent_count, failed_count, created_at, sent_at,
+		       CAST(sections AS CHAR)
+		FROM newsletter_issues
+		WHERE status = ?
+		ORDER BY id ASC`, entity.IssueSending)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query issues: %w", err)
+	}
+	defer rows.Close()
+
+	issues := []*entity.Issue{}
+	for rows.Next() {
+		var sections string
+		issue, err := scanIssue(rows, &sections)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(sections), &issue.Sections); err != nil {
+			return nil, fmt.Errorf("failed to decode issue %d: %w", issue.ID, err)
+		}
+		issues = append(issues, issue)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return issues, nil
+}
+
+// CreateIssue stores an issue with a pending delivery for every active
+// subscriber of its list, and records sentAt as the last send of the list. It
+// returns ErrIssueSending while another issue of the list is being delivered.
+func (r *NewsletterRepositoryImpl) CreateIssue(ctx context.Context, issue *entity.Issue, sentAt time.Time) error {
+	newsIDs, err := json.Marshal(issue.NewsIDs)
+	if err != nil {
+		return err
+	}
+	sections, err := json.Marshal(issue.Sections)
+	if err != nil {
+		return err
+	}
+	tx, err := r.app.MDB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Lock the list so two issues of the same send cannot be created at once
+	var lastSentAt sql.NullString
+	err = tx.QueryRowContext(ctx, "SELECT last_sent_at FROM newsletter_lists WHERE id = ? FOR UPDATE", issue.ListID).Scan(&lastSentAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return entity.ErrListNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to lock newsletter: %w", err)
+	}
+	// One issue at a time: a second send, such as a double click, is refused
+	var sending int
+	if err := tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM newsletter_issues WHERE list_id = ? AND status = ?", issue.ListID, entity.IssueSending).Scan(&sending); err != nil {
+		return fmt.Errorf("failed to check sending issues: %w", err)
+	}
+	if sending > 0 {
+		return entity.ErrIssueSending
+	}
+
+	createdAt := issue.CreatedAt.Format(time.DateTime)
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO newsletter_issues (list_id, subject, news_ids, sections, status, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)`,
+		issue.ListID, issue.Subject, string(newsIDs), string(sections), entity.IssueSending, createdAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create issue: %w", err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	issue.ID = uint(id)
+	issue.Status = entity.IssueSending
+
+	result, err = tx.ExecContext(ctx, `
+		INSERT INTO newsletter_deliveries (issue_id, subscriber_id, status, created_at, updated_at)
+		SELECT ?, id, ?, ?, ? FROM newsletter_subscribers WHERE list_id = ? AND status = ?`,
+		issue.ID, entity.DeliveryPending, createdAt, createdAt, issue.ListID, entity.StatusActive,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create deliveries: %w", err)
+	}
+	recipients, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	issue.RecipientCount = int(recipients)
+
+	if _, err := tx.ExecContext(ctx, "UPDATE newsletter_issues SET recipient_count = ? WHERE id = ?", issue.RecipientCount, issue.ID); err != nil {
+		return fmt.Errorf("failed to update issue: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, "UPDATE newsletter_lists SET last_sent_at = ? WHERE id = ?", sentAt.UTC().Format(time.DateTime), issue.ListID); err != nil {
+		return fmt.Errorf("failed to update newsletter: %w", err)
+	}
+	return tx.Commit()
+}
+
+// MarkListSent records sentAt as the last send of a list without an issue,
+// for a digest that had no articles
+func (r *NewsletterRepositoryImpl) MarkListSent(ctx context.Context, listID uint, sentAt time.Time) error {
+	if _, err := r.app.MDB.ExecContext(ctx, "UPDATE newsletter_lists SET last_sent_at = ? WHERE id = ?", sentAt.UTC().Format(time.DateTime), listID); err != nil {
+		return fmt.Errorf("failed to update newsletter: %w", err)
+	}
+	return nil
+}
+
+// PendingDeliveries returns the next pending deliveries of an issue with their subscribers
+func (r *NewsletterRepositoryImpl) PendingDeliveries(ctx context.Context, issueID uint, limit int) ([]*entity.Delivery, error) {
+	rows, err := r.app.MDB.QueryContext(ctx, fmt.Sprintf(`
+		SELECT newsletter_deliveries.id, newsletter_deliveries.issue_id, newsletter_deliveries.status, newsletter_deliveries.attempts,
+		       newsletter_subscribers.id, newsletter_subscribers.list_id, newsletter_subscribers.email, newsletter_subscribers.name,
+		       newsletter_subscribers.status, newsletter_subscribers.unsubscribe_token
+		FROM newsletter_deliveries
+		INNER JOIN newsletter_subscribers ON newsletter_subscribers.id = newsletter_deliveries.subscriber_id
+		WHERE newsletter_deliveries.issue_id = ? AND newsletter_deliveries.status = ?
+		ORDER BY newsletter_deliveries.id ASC
+		LIMIT %d`, limit), issueID, entity.DeliveryPending)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	deliveries := []*entity.Delivery{}
+	for rows.Next() {
+		delivery := entity.Delivery{Subscriber: &entity.Subscriber{}}
+		subscriber := delivery.Subscriber
+		if err := rows.Scan(&delivery.ID, &delivery.IssueID, &delivery.Status, &delivery.Attempts,
+			&subscriber.ID, &subscriber.ListID, &subscriber.Email, &subscriber.Name, &subscriber.Status, &subscriber.UnsubscribeToken); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		delivery.SubscriberID = subscriber.ID
+		delivery.Email = subscriber.Email
+		deliveries = append(deliveries, &delivery)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return deliveries, nil
+}
+
+// SaveDelivery stores the outcome of a delivery attempt. A sent delivery is
+// also recorded as the last send of the subscriber.
+func (r *NewsletterRepositoryImpl) SaveDelivery(ctx context.Context, delivery *entity.Delivery) error {
+	tx, err := r.app.MDB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := time.Now().UTC().Format(time.DateTime)
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE newsletter_deliveries SET status = ?, error = ?, attempts = attempts + 1, sent_at = ?, updated_at = ?
+		WHERE id = ?`,
+		delivery.Status, delivery.Error, nullableTime(delivery.SentAt), now, delivery.ID,
+	); err != nil {
+		return fmt.Errorf("failed to update delivery: %w", err)
+	}
+	if delivery.Status == entity.DeliverySent {
+		if _, err := tx.ExecContext(ctx, "UPDATE newsletter_subscribers SET last_sent_at = ? WHERE id = ?",
+			nullableTime(delivery.SentAt), delivery.SubscriberID); err != nil {
+			return fmt.Errorf("failed to update subscriber: %w", err)
+		}
+	}
+	delivery.Attempts++
+	return tx.Commit()
+}
+
+// FinishIssue marks an issue sent and stores its delivery counts. Deliveries
+// left pending, to subscribers deleted meanwhile, are not counted.
+func (r *NewsletterRepositoryImpl) FinishIssue(ctx context.Context, issue *entity.Issue) error {
+	var sent, failed int
+	if err := r.app.MDB.QueryRowContext(ctx, `
+		SELECT COALESCE(SUM(CASE WHEN status = ? THEN 1 ELSE 0 END), 0),
+		       COALESCE(SUM(CASE WHEN status IN (?, ?) THEN 1 ELSE 0 END), 0)
+		FROM newsletter_deliveries WHERE issue_id = ?`,
+		entity.DeliverySent, entity.DeliveryFailed, entity.DeliveryBounced, issue.ID,
+	).Scan(&sent, &failed); err != nil {
+		return fmt.Errorf("failed to count deliveries: %w", err)
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	if _, err := r.app.MDB.ExecContext(ctx, `
+		UPDATE newsletter_issues SET status = ?, sent_count = ?, failed_count = ?, sent_at = ?
+		WHERE id = ?`,
+		entity.IssueSent, sent, failed, now.Format(time.DateTime), issue.ID,
+	); err != nil {
+		return fmt.Errorf("failed to update issue: %w", err)
+	}
+	issue.Status = entity.IssueSent
+	issue.SentCount = sent
+	issue.FailedCount = failed
+	issue.SentAt = &now
+	return nil
+}
+
+// checkList validates the slug and categories of a list and returns the
+// categories as stored, NULL for every category
+func (r *NewsletterRepositoryImpl) checkList(ctx context.Context, list *entity.List) (interface{}, error) {
+	if !slugPattern.MatchString(list.Slug) {
+		return nil, fmt.Errorf("%w: slug must be lowercase letters, digits and dashes", entity.ErrInvalidList)
+	}
+	var count int
+	if err := r.app.MDB.QueryRowContext(ctx, "SELECT COUNT(*) FROM newsletter_lists WHERE slug = ? AND id <> ?", list.Slug, list.ID).Scan(&count); err != nil {
+		return nil, fmt.Errorf("failed to check slug: %w", err)
+	}
+	if count > 0 {
+		return nil, entity.ErrListExists
+	}
+	if len(list.Categories) == 0 {
+		return nil, nil
+	}
+
+	args := []interface{}{newsEntity.StatusActive}
+	for _, slug := range list.Categories {
+		args = append(args, slug)
+	}
+	if err := r.app.MDB.QueryRowContext(ctx, fmt.Sprintf("SELECT COUNT(*) FROM news_categories WHERE status_id = ? AND slug IN (%s)",
+		placeholders(len(list.Categories))), args...).Scan(&count); err != nil {
+		return nil, fmt.Errorf("failed to check categories: %w", err)
+	}
+	if count != len(list.Categories) {
+		return nil, fmt.Errorf("%w: categories must be slugs of active categories", entity.ErrInvalidList)
+	}
+	categories, err := json.Marshal(list.Categories)
+	if err != nil {
+		return nil, err
+	}
+	return string(categories), nil
+}
+
+// scanList scans a row of listQuery
+func scanList(row interface{ Scan(...interface{}) error }) (*entity.List, error) {
+	var (
+		list                 entity.List
+		categories           string
+		lastSentAt           sql.NullString
+		createdAt, updatedAt sql.NullString
+	)
+	if err := row.Scan(&list.ID, &list.Name, &list.Slug, &list.Description, &list.Frequency, &list.Locale, &categories,
+		&list.PerCategory, &list.Active, &list.SubscriberCount, &lastSentAt, &list.CreatedBy, &createdAt, &updatedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to scan row: %w", err)
+	}
+	list.Categories = []string{}
+	if categories != "" {
+		if err := json.Unmarshal([]byte(categories), &list.Categories); err != nil {
+			return nil, fmt.Errorf("failed to decode categories: %w", err)
+		}
+	}
+	list.LastSentAt = parseNullDateTime(lastSentAt)
+	if t := parseNullDateTime(createdAt); t != nil {
+		list.CreatedAt = *t
+	}
+	if t := parseNullDateTime(updatedAt); t != nil {
+		list.UpdatedAt = *t
+	}
+	return &list, nil
+}
+
+// scanSubscriber scans a row of subscriberQuery
+func scanSubscriber(row interface{ Scan(...interface{}) error }) (*entity.Subscriber, error) {
+	var (
+		subscriber                  entity.Subscriber
+		confirmedAt, unsubscribedAt sql.NullString
+		bouncedAt, lastSentAt       sql.NullString
+		createdAt, updatedAt        sql.NullString
+	)
+	if err := row.Scan(&subscriber.ID, &subscriber.ListID, &subscriber.Email, &subscriber.Name, &subscriber.Status,
+		&subscriber.ConfirmToken, &subscriber.UnsubscribeToken, &subscriber.IPAddress, &confirmedAt, &unsubscribedAt,
+		&subscriber.BounceCount, &bouncedAt, &subscriber.BounceReason, &lastSentAt, &createdAt, &updatedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to scan row: %w", err)
+	}
+	subscriber.ConfirmedAt = parseNullDateTime(confirmedAt)
+	subscriber.UnsubscribedAt = parseNullDateTime(unsubscribedAt)
+	subscriber.BouncedAt = parseNullDateTime(bouncedAt)
+	subscriber.LastSentAt = parseNullDateTime(lastSentAt)
+	if t := parseNullDateTime(createdAt); t != nil {
+		subscriber.CreatedAt = *t
+	}
+	if t := parseNullDateTime(updatedAt); t != nil {
+		subscriber.UpdatedAt = *t
+	}
+	return &subscriber, nil
+}
+
+// scanIssue scans a row of issueQuery, followed by the extra columns
+func scanIssue(row interface{ Scan(...interface{}) error }, extra ...interface{}) (*entity.Issue, error) {
+	var (
+		issue             entity.Issue
+		newsIDs           string
+		createdAt, sentAt sql.NullString
+	)
+	dest := append([]interface{}{&issue.ID, &issue.ListID, &issue.Subject, &newsIDs, &issue.Status,
+		&issue.RecipientCount, &issue.SentCount, &issue.FailedCount, &createdAt, &sentAt}, extra...)
+	if err := row.Scan(dest...); err != nil {
+		return nil, fmt.Errorf("failed to scan row: %w", err)
+	}
+	issue.NewsIDs = []uint{}
+	if err := json.Unmarshal([]byte(newsIDs), &issue.NewsIDs); err != nil {
+		return nil, fmt.Errorf("failed to decode issue articles: %w", err)
+	}
+	if t := parseNullDateTime(createdAt); t != nil {
+		issue.CreatedAt = *t
+	}
+	issue.SentAt = parseNullDateTime(sentAt)
+	return &issue, nil
+}
+
+// placeholders returns n comma separated query placeholders
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?,", n), ",")
+}
+
+// nullableTime returns nil for a missing time so it is stored as NULL
+func nullableTime(t *time.Time) interface{} {
+	if t == nil || t.IsZero() {
+		return nil
+	}
+	return t.UTC().Format(time.DateTime)
+}
+
+// parseNullDateTime converts a nullable DATETIME column into a *time.Time
+func parseNullDateTime(value sql.NullString) *time.Time {
+	if !value.Valid {
+		return nil
+	}
+	t, err := time.Parse(time.DateTime, value.String)
+	if err != nil {
+		return nil
+	}
+	return &t
+}
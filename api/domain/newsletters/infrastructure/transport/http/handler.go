@@ -0,0 +1,475 @@
+package newsletterHttp
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/JubaerHossain/cn-api/domain/newsletters/entity"
+	"github.com/JubaerHossain/cn-api/domain/newsletters/service"
+	"github.com/JubaerHossain/rootx/pkg/core/app"
+	utilQuery "github.com/JubaerHossain/rootx/pkg/query"
+	"github.com/JubaerHossain/rootx/pkg/utils"
+)
+
+// Handler handles API requests
+type Handler struct {
+	App *service.Service
+}
+
+// NewHandler creates a new instance of Handler
+func NewHandler(app *app.App) *Handler {
+	return &Handler{
+		App: service.NewService(app),
+	}
+}
+
+// @Summary Get the newsletters
+// @Description Get the newsletters readers can subscribe to
+// @Tags newsletters
+// @Produce json
+// @Success 200 {array} entity.PublicList
+// @Router /newsletters [get]
+func (h *Handler) GetPublicLists(w http.ResponseWriter, r *http.Request) {
+	lists, err := h.App.GetPublicLists(r)
+	if err != nil {
+		h.writeNewsletterError(w, err)
+		return
+	}
+	// Write response
+	utils.JsonResponse(w, http.StatusOK, map[string]interface{}{
+		"results": lists,
+	})
+}
+
+// @Summary Subscribe to a newsletter
+// @Description Ask for a subscription. A confirmation link is emailed, the subscription starts once it is followed. The response does not tell whether the address was subscribed already.
+// @Tags newsletters
+// @Accept json
+// @Produce json
+// @Param slug path string true "The slug of the newsletter"
+// @Param subscriber body entity.SubscribeRequest true "The address to subscribe"
+// @Success 202 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 429 {object} map[string]interface{}
+// @Router /newsletters/{slug}/subscribers [post]
+func (h *Handler) Subscribe(w http.ResponseWriter, r *http.Request) {
+	var subscribe entity.SubscribeRequest
+	pareErr := utilQuery.BodyParse(&subscribe, w, r, true) // Parse request body and validate it
+	if pareErr != nil {
+		return
+	}
+
+	if err := h.App.Subscribe(r, &subscribe); err != nil {
+		h.writeNewsletterError(w, err)
+		return
+	}
+
+	// Write response
+	utils.WriteJSONResponse(w, http.StatusAccepted, map[string]interface{}{
+		"message": "Check your inbox to confirm the subscription",
+	})
+}
+
+// @Summary Confirm a subscription
+// @Description Follow the link of the confirmation email. The token works once.
+// @Tags newsletters
+// @Produce json
+// @Param token query string true "The confirmation token"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Router /newsletters/confirm [get]
+func (h *Handler) Confirm(w http.ResponseWriter, r *http.Request) {
+	list, err := h.App.Confirm(r)
+	if err != nil {
+		h.writeNewsletterError(w, err)
+		return
+	}
+	// Write response
+	utils.WriteJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"message": "Subscription confirmed",
+		"results": list,
+	})
+}
+
+// @Summary Open an unsubscribe link
+// @Description The page of the unsubscribe link of a digest. It changes nothing and asks the reader to confirm, so link scanners do not unsubscribe anyone.
+// @Tags newsletters
+// @Produce html
+// @Param token query string true "The unsubscribe token"
+// @Success 200 {string} string
+// @Failure 400 {object} map[string]interface{}
+// @Router /newsletters/unsubscribe [get]
+func (h *Handler) UnsubscribePage(w http.ResponseWriter, r *http.Request) {
+	page, err := h.App.UnsubscribePage(r)
+	if err != nil {
+		h.writeNewsletterError(w, err)
+		return
+	}
+	writeHTML(w, page)
+}
+
+// @Summary Unsubscribe from a newsletter
+// @Description Confirm on the unsubscribe page, or POST the unsubscribe link as mail clients do for one-click unsubscribe
+// @Tags newsletters
+// @Produce json
+// @Param token query string true "The unsubscribe token"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Router /newsletters/unsubscribe [post]
+func (h *Handler) Unsubscribe(w http.ResponseWriter, r *http.Request) {
+	list, err := h.App.Unsubscribe(r)
+	if err != nil {
+		h.writeNewsletterError(w, err)
+		return
+	}
+	// The form of the unsubscribe page gets a page back, mail clients get JSON
+	if r.PostFormValue("confirm") != "" {
+		page, err := h.App.UnsubscribedPage(list)
+		if err != nil {
+			h.writeNewsletterError(w, err)
+			return
+		}
+		writeHTML(w, page)
+		return
+	}
+	// Write response
+	utils.WriteJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"message": "You are unsubscribed",
+		"results": list,
+	})
+}
+
+// @Summary Get the newsletters
+// @Description Get every newsletter with its number of active subscribers
+// @Tags newsletters
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {array} entity.List
+// @Router /newsletters [get]
+func (h *Handler) GetLists(w http.ResponseWriter, r *http.Request) {
+	lists, err := h.App.GetLists(r)
+	if err != nil {
+		h.writeNewsletterError(w, err)
+		return
+	}
+	// Write response
+	utils.JsonResponse(w, http.StatusOK, map[string]interface{}{
+		"results": lists,
+	})
+}
+
+// @Summary Get a newsletter
+// @Description Get a newsletter by ID
+// @Tags newsletters
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "The ID of the newsletter"
+// @Success 200 {object} entity.List
+// @Failure 404 {object} map[string]interface{}
+// @Router /newsletters/{id} [get]
+func (h *Handler) GetList(w http.ResponseWriter, r *http.Request) {
+	list, err := h.App.GetList(r)
+	if err != nil {
+		h.writeNewsletterError(w, err)
+		return
+	}
+	// Write response
+	utils.JsonResponse(w, http.StatusOK, map[string]interface{}{
+		"results": list,
+	})
+}
+
+// @Summary Create a newsletter
+// @Description Create a daily or weekly digest of the top articles of its categories, every active category when none is given
+// @Tags newsletters
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param newsletter body entity.ListRequest true "The newsletter to create"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /newsletters [post]
+func (h *Handler) CreateList(w http.ResponseWriter, r *http.Request) {
+	var newList entity.ListRequest
+	pareErr := utilQuery.BodyParse(&newList, w, r, true) // Parse request body and validate it
+	if pareErr != nil {
+		return
+	}
+
+	list, err := h.App.CreateList(r, &newList)
+	if err != nil {
+		h.writeNewsletterError(w, err)
+		return
+	}
+
+	// Write response
+	utils.WriteJSONResponse(w, http.StatusCreated, map[string]interface{}{
+		"message": "Newsletter created successfully",
+		"results": list,
+	})
+}
+
+// @Summary Update a newsletter
+// @Description Change the settings of a newsletter. Subscribers and send history are kept.
+// @Tags newsletters
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "The ID of the newsletter"
+// @Param newsletter body entity.ListRequest true "The newsletter settings"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /newsletters/{id} [put]
+func (h *Handler) UpdateList(w http.ResponseWriter, r *http.Request) {
+	var updateList entity.ListRequest
+	pareErr := utilQuery.BodyParse(&updateList, w, r, true) // Parse request body and validate it
+	if pareErr != nil {
+		return
+	}
+
+	list, err := h.App.UpdateList(r, &updateList)
+	if err != nil {
+		h.writeNewsletterError(w, err)
+		return
+	}
+
+	// Write response
+	utils.WriteJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"message": "Newsletter updated successfully",
+		"results": list,
+	})
+}
+
+// @Summary Delete a newsletter
+// @Description Delete a newsletter with its subscribers and send history
+// @Tags newsletters
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "The ID of the newsletter"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /newsletters/{id} [delete]
+func (h *Handler) DeleteList(w http.ResponseWriter, r *http.Request) {
+	if err := h.App.DeleteList(r); err != nil {
+		h.writeNewsletterError(w, err)
+		return
+	}
+	// Write response
+	utils.WriteJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"message": "Newsletter deleted successfully",
+	})
+}
+
+// @Summary Get the subscribers of a newsletter
+// @Description Get a page of the subscribers of a newsletter, newest first
+// @Tags newsletters
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "The ID of the newsletter"
+// @Param status query string false "pending, active, unsubscribed or bounced"
+// @Param search query string false "Part of the email address"
+// @Param page query int false "Page number"
+// @Param limit query int false "Subscribers per page"
+// @Success 200 {object} entity.SubscriberResponsePagination
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /newsletters/{id}/subscribers [get]
+func (h *Handler) GetSubscribers(w http.ResponseWriter, r *http.Request) {
+	subscribers, err := h.App.GetSubscribers(r)
+	if err != nil {
+		h.writeNewsletterError(w, err)
+		return
+	}
+	// Write response
+	utils.JsonResponse(w, http.StatusOK, map[string]interface{}{
+		"results": subscribers,
+	})
+}
+
+// @Summary Get a subscriber
+// @Description Get a subscriber with their latest deliveries, bounces included
+// @Tags newsletters
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "The ID of the newsletter"
+// @Param subscriber path string true "The ID of the subscriber"
+// @Success 200 {object} entity.Subscriber
+// @Failure 404 {object} map[string]interface{}
+// @Router /newsletters/{id}/subscribers/{subscriber} [get]
+func (h *Handler) GetSubscriber(w http.ResponseWriter, r *http.Request) {
+	subscriber, err := h.App.GetSubscriber(r)
+	if err != nil {
+		h.writeNewsletterError(w, err)
+		return
+	}
+	// Write response
+	utils.JsonResponse(w, http.StatusOK, map[string]interface{}{
+		"results": subscriber,
+	})
+}
+
+// @Summary Delete a subscriber
+// @Description Delete a subscriber with their send history
+// @Tags newsletters
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "The ID of the newsletter"
+// @Param subscriber path string true "The ID of the subscriber"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /newsletters/{id}/subscribers/{subscriber} [delete]
+func (h *Handler) DeleteSubscriber(w http.ResponseWriter, r *http.Request) {
+	if err := h.App.DeleteSubscriber(r); err != nil {
+		h.writeNewsletterError(w, err)
+		return
+	}
+	// Write response
+	utils.WriteJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"message": "Subscriber deleted successfully",
+	})
+}
+
+// @Summary Get the issues of a newsletter
+// @Description Get a page of the digests sent to a newsletter, newest first, with their delivery counts
+// @Tags newsletters
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "The ID of the newsletter"
+// @Param page query int false "Page number"
+// @Param limit query int false "Issues per page"
+// @Success 200 {object} entity.IssueResponsePagination
+// @Failure 404 {object} map[string]interface{}
+// @Router /newsletters/{id}/issues [get]
+func (h *Handler) GetIssues(w http.ResponseWriter, r *http.Request) {
+	issues, err := h.App.GetIssues(r)
+	if err != nil {
+		h.writeNewsletterError(w, err)
+		return
+	}
+	// Write response
+	utils.JsonResponse(w, http.StatusOK, map[string]interface{}{
+		"results": issues,
+	})
+}
+
+// @Summary Preview a digest
+// @Description Compose the digest the newsletter would send now without sending it. format=html or format=text returns the rendered email, the digest is returned as JSON otherwise.
+// @Tags newsletters
+// @Produce json
+// @Produce html
+// @Produce plain
+// @Security ApiKeyAuth
+// @Param id path string true "The ID of the newsletter"
+// @Param format query string false "html or text"
+// @Success 200 {object} entity.Digest
+// @Failure 404 {object} map[string]interface{}
+// @Router /newsletters/{id}/preview [get]
+func (h *Handler) Preview(w http.ResponseWriter, r *http.Request) {
+	digest, html, text, err := h.App.Preview(r)
+	if err != nil {
+		h.writeNewsletterError(w, err)
+		return
+	}
+
+	switch r.URL.Query().Get("format") {
+	case "html":
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(html))
+	case "text":
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(text))
+	default:
+		// Write response
+		utils.JsonResponse(w, http.StatusOK, map[string]interface{}{
+			"results": digest,
+		})
+	}
+}
+
+// @Summary Send a digest now
+// @Description Compose the digest of a newsletter and queue it for its active subscribers, outside its schedule. The newsletter scheduler delivers it within NEWSLETTER_INTERVAL.
+// @Tags newsletters
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "The ID of the newsletter"
+// @Success 202 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /newsletters/{id}/send [post]
+func (h *Handler) SendNow(w http.ResponseWriter, r *http.Request) {
+	issue, err := h.App.SendNow(r)
+	if err != nil {
+		h.writeNewsletterError(w, err)
+		return
+	}
+	// Write response
+	utils.WriteJSONResponse(w, http.StatusAccepted, map[string]interface{}{
+		"message": "Newsletter issue queued",
+		"results": issue,
+	})
+}
+
+// @Summary Report a bounce
+// @Description Count a bounce against every subscription of an address, as reported by the mail provider. A permanent bounce stops sending at once, others after three.
+// @Tags newsletters
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param bounce body entity.BounceRequest true "The bounced address"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Router /newsletter-bounces [post]
+func (h *Handler) RecordBounce(w http.ResponseWriter, r *http.Request) {
+	var bounce entity.BounceRequest
+	pareErr := utilQuery.BodyParse(&bounce, w, r, true) // Parse request body and validate it
+	if pareErr != nil {
+		return
+	}
+
+	updated, err := h.App.RecordBounce(r, &bounce)
+	if err != nil {
+		h.writeNewsletterError(w, err)
+		return
+	}
+
+	// Write response
+	utils.WriteJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"message": "Bounce recorded",
+		"results": map[string]interface{}{
+			"subscriptions": updated,
+		},
+	})
+}
+
+// writeHTML writes a page that must not be cached or indexed
+func writeHTML(w http.ResponseWriter, page string) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte(page))
+}
+
+// writeNewsletterError maps newsletter errors to HTTP statuses
+func (h *Handler) writeNewsletterError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, entity.ErrInvalidList), errors.Is(err, entity.ErrInvalidToken), errors.Is(err, entity.ErrInvalidQuery):
+		utils.WriteJSONError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, entity.ErrListNotFound), errors.Is(err, entity.ErrSubscriberNotFound):
+		utils.WriteJSONError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, entity.ErrListExists), errors.Is(err, entity.ErrIssueSending):
+		utils.WriteJSONError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, entity.ErrRateLimited):
+		w.Header().Set("Retry-After", strconv.Itoa(int(h.App.RetryAfter().Seconds())))
+		utils.WriteJSONError(w, http.StatusTooManyRequests, err.Error())
+	default:
+		utils.WriteJSONError(w, http.StatusInternalServerError, err.Error())
+	}
+}
@@ -0,0 +1,52 @@
+package newsletterHttp
+
+import (
+	"net/http"
+
+	authMiddleware "github.com/JubaerHossain/cn-api/pkg/middleware"
+	"github.com/JubaerHossain/rootx/pkg/core/app"
+	"github.com/JubaerHossain/rootx/pkg/core/middleware"
+)
+
+// NewsletterRouter registers public subscription routes. Confirmation and
+// unsubscribe links are followed from email. The unsubscribe link opens a page
+// that confirms with a POST, the same request as the one-click unsubscribe of
+// mail clients.
+func NewsletterRouter(router *http.ServeMux, application *app.App) http.Handler {
+
+	handler := NewHandler(application)
+
+	router.Handle("GET /newsletters", middleware.LimiterMiddleware(http.HandlerFunc(handler.GetPublicLists)))
+	router.Handle("POST /newsletters/{slug}/subscribers", middleware.LimiterMiddleware(http.HandlerFunc(handler.Subscribe)))
+	router.Handle("GET /newsletters/confirm", middleware.LimiterMiddleware(http.HandlerFunc(handler.Confirm)))
+	router.Handle("GET /newsletters/unsubscribe", middleware.LimiterMiddleware(http.HandlerFunc(handler.UnsubscribePage)))
+	router.Handle("POST /newsletters/unsubscribe", middleware.LimiterMiddleware(http.HandlerFunc(handler.Unsubscribe)))
+
+	return router
+}
+
+// NewsletterAdminRouter registers newsletter management routes for editors and
+// admins. A token alone does not reach the subscribers or the send button.
+func NewsletterAdminRouter(router *http.ServeMux, application *app.App) http.Handler {
+
+	handler := NewHandler(application)
+	protect := func(h http.HandlerFunc) http.Handler {
+		return middleware.LimiterMiddleware(authMiddleware.AuthMiddleware(application,
+			authMiddleware.RoleMiddleware(h, authMiddleware.RoleEditor, authMiddleware.RoleAdmin)))
+	}
+
+	router.Handle("GET /newsletters", protect(handler.GetLists))
+	router.Handle("POST /newsletters", protect(handler.CreateList))
+	router.Handle("GET /newsletters/{id}", protect(handler.GetList))
+	router.Handle("PUT /newsletters/{id}", protect(handler.UpdateList))
+	router.Handle("DELETE /newsletters/{id}", protect(handler.DeleteList))
+	router.Handle("GET /newsletters/{id}/subscribers", protect(handler.GetSubscribers))
+	router.Handle("GET /newsletters/{id}/subscribers/{subscriber}", protect(handler.GetSubscriber))
+	router.Handle("DELETE /newsletters/{id}/subscribers/{subscriber}", protect(handler.DeleteSubscriber))
+	router.Handle("GET /newsletters/{id}/issues", protect(handler.GetIssues))
+	router.Handle("GET /newsletters/{id}/preview", protect(handler.Preview))
+	router.Handle("POST /newsletters/{id}/send", protect(handler.SendNow))
+	router.Handle("POST /newsletter-bounces", protect(handler.RecordBounce))
+
+	return router
+}
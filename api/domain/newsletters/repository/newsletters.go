@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/JubaerHossain/cn-api/domain/newsletters/entity"
+)
+
+// NewsletterRepository defines methods for newsletter list, subscriber and issue data access
+type NewsletterRepository interface {
+	GetLists(ctx context.Context, activeOnly bool) ([]*entity.List, error)
+	GetList(ctx context.Context, listID uint) (*entity.List, error)
+	GetListBySlug(ctx context.Context, slug string) (*entity.List, error)
+	CreateList(r *http.Request, list *entity.List) error
+	UpdateList(r *http.Request, list *entity.List) error
+	DeleteList(r *http.Request, list *entity.List) error
+	GetSubscribers(r *http.Request, listID uint) (*entity.SubscriberResponsePagination, error)
+	GetSubscriber(ctx context.Context, listID, subscriberID uint) (*entity.Subscriber, error)
+	DeleteSubscriber(r *http.Request, subscriber *entity.Subscriber) error
+	Subscribe(ctx context.Context, subscriber *entity.Subscriber) (*entity.Subscriber, error)
+	Confirm(ctx context.Context, token string) (*entity.Subscriber, error)
+	GetSubscriberByUnsubscribeToken(ctx context.Context, token string) (*entity.Subscriber, error)
+	Unsubscribe(ctx context.Context, token string) (*entity.Subscriber, error)
+	RecordBounce(ctx context.Context, email, reason string, permanent bool) (int64, error)
+	GetIssues(r *http.Request, listID uint) (*entity.IssueResponsePagination, error)
+	GetSendingIssues(ctx context.Context) ([]*entity.Issue, error)
+	CreateIssue(ctx context.Context, issue *entity.Issue, sentAt time.Time) error
+	MarkListSent(ctx context.Context, listID uint, sentAt time.Time) error
+	PendingDeliveries(ctx context.Context, issueID uint, limit int) ([]*entity.Delivery, error)
+	SaveDelivery(ctx context.Context, delivery *entity.Delivery) error
+	FinishIssue(ctx context.Context, issue *entity.Issue) error
+}
@@ -0,0 +1,364 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	newsEntity "github.com/JubaerHossain/cn-api/domain/news/entity"
+	newsPersistence "github.com/JubaerHossain/cn-api/domain/news/infrastructure/persistence"
+	"github.com/JubaerHossain/cn-api/domain/newsletters/entity"
+	"github.com/JubaerHossain/cn-api/pkg/config"
+	"github.com/JubaerHossain/cn-api/pkg/mailer"
+	"github.com/JubaerHossain/cn-api/pkg/ranking"
+	"github.com/JubaerHossain/cn-api/pkg/utils"
+	"go.uber.org/zap"
+)
+
+const (
+	deliveryBatch = 100 // Deliveries loaded at a time
+	maxAttempts   = 3   // Temporary failures of a delivery before it is given up
+)
+
+// Preview composes the digest the list in the path would send now, without
+// sending it, and renders it for a sample subscriber
+func (s *Service) Preview(r *http.Request) (*entity.Digest, string, string, error) {
+	list, err := s.GetList(r)
+	if err != nil {
+		return nil, "", "", err
+	}
+	digest, err := s.compose(r.Context(), list, time.Now().UTC())
+	if err != nil {
+		return nil, "", "", err
+	}
+	html, text, err := s.renderDigest(list, digest.Subject, digest.Sections, &entity.Subscriber{Name: "Reader"})
+	if err != nil {
+		s.app.Logger.Error("Error rendering digest", zap.Error(err))
+		return nil, "", "", err
+	}
+	return digest, html, text, nil
+}
+
+// SendNow composes the digest of the list in the path and queues it for its
+// active subscribers. The newsletter scheduler delivers it on its next run.
+// While an earlier issue is still sending it returns ErrIssueSending.
+func (s *Service) SendNow(r *http.Request) (*entity.Issue, error) {
+	list, err := s.GetList(r)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	digest, err := s.compose(r.Context(), list, now)
+	if err != nil {
+		return nil, err
+	}
+	if len(digest.Sections) == 0 {
+		return nil, fmt.Errorf("%w: no articles were published since %s", entity.ErrInvalidList, digest.Since.Format(time.DateTime))
+	}
+	return s.createIssue(r.Context(), list, digest, now)
+}
+
+// RunDigests delivers the issues still sending, then composes and delivers the
+// digests that are due. It is the job of the newsletter scheduler started in
+// main. A run stops after NEWSLETTER_INTERVAL so it ends before the lease, and
+// the next run resumes the deliveries left.
+func (s *Service) RunDigests(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(config.GlobalConfig.NewsletterInterval)*time.Second)
+	defer cancel()
+
+	issues, err := s.repo.GetSendingIssues(ctx)
+	if err != nil {
+		s.app.Logger.Error("Error getting sending issues", zap.Error(err))
+		return err
+	}
+	var errs []error
+	for _, issue := range issues {
+		if err := s.deliver(ctx, issue); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if ctx.Err() != nil {
+		return errors.Join(errs...)
+	}
+
+	lists, err := s.repo.GetLists(ctx, true)
+	if err != nil {
+		s.app.Logger.Error("Error getting newsletters", zap.Error(err))
+		return errors.Join(append(errs, err)...)
+	}
+	now := time.Now().UTC()
+	for _, list := range lists {
+		if !isDue(list, now) {
+			continue
+		}
+		if err := s.sendDigest(ctx, list, now); err != nil {
+			errs = append(errs, err)
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// sendDigest composes the digest of a due list and delivers it. A list
+// without subscribers or new articles is skipped until its next slot.
+func (s *Service) sendDigest(ctx context.Context, list *entity.List, now time.Time) error {
+	if list.SubscriberCount == 0 {
+		return s.repo.MarkListSent(ctx, list.ID, now)
+	}
+	digest, err := s.compose(ctx, list, now)
+	if err != nil {
+		return err
+	}
+	if len(digest.Sections) == 0 {
+		s.app.Logger.Info("Newsletter skipped, no new articles", zap.String("newsletter", list.Slug))
+		return s.repo.MarkListSent(ctx, list.ID, now)
+	}
+	issue, err := s.createIssue(ctx, list, digest, now)
+	if errors.Is(err, entity.ErrIssueSending) {
+		// An issue sent by hand is still going out, the digest waits for the next run
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return s.deliver(ctx, issue)
+}
+
+// compose selects the top articles of each category of a list published since
+// its last send, at most two periods back
+func (s *Service) compose(ctx context.Context, list *entity.List, now time.Time) (*entity.Digest, error) {
+	period, window, label := 24*time.Hour, ranking.Trending, "Daily"
+	if list.Frequency == entity.FrequencyWeekly {
+		period, window, label = 7*24*time.Hour, ranking.MostRead["7d"], "Weekly"
+	}
+	since := now.Add(-period)
+	if list.LastSentAt != nil && list.LastSentAt.Before(since) {
+		since = *list.LastSentAt
+		if earliest := now.Add(-2 * period); since.Before(earliest) {
+			since = earliest
+		}
+	}
+
+	sections, err := s.news.GetDigest(ctx, &newsEntity.DigestQuery{
+		Window:      window,
+		Since:       since,
+		Locale:      list.Locale,
+		Categories:  list.Categories,
+		PerCategory: list.PerCategory,
+	})
+	if err != nil {
+		return nil, err
+	}
+	// Issues keep their sections to resume a send, without the article bodies
+	for _, section := range sections {
+		for _, news := range section.News {
+			news.Content = ""
+			news.SEO = nil
+			if news.PathSmall != "" {
+				news.PathSmall = newsPersistence.AssetURL(s.app.Config.Domain, news.PathSmall)
+			}
+		}
+	}
+
+	return &entity.Digest{
+		List:     list,
+		Subject:  fmt.Sprintf("%s: %s digest, %s", list.Name, label, now.Format("2 January 2006")),
+		Since:    since,
+		Sections: sections,
+	}, nil
+}
+
+// createIssue stores a composed digest as an issue of the list, with a
+// pending delivery for every active subscriber
+func (s *Service) createIssue(ctx context.Context, list *entity.List, digest *entity.Digest, now time.Time) (*entity.Issue, error) {
+	issue := &entity.Issue{
+		ListID:    list.ID,
+		Subject:   digest.Subject,
+		NewsIDs:   digest.NewsIDs(),
+		Sections:  digest.Sections,
+		CreatedAt: now.Truncate(time.Second),
+	}
+	if err := s.repo.CreateIssue(ctx, issue, now); err != nil {
+		s.logError("Error creating issue", err)
+		return nil, err
+	}
+	s.app.Logger.Info("Newsletter issue created", zap.String("newsletter", list.Slug),
+		zap.Uint("issue_id", issue.ID), zap.Int("recipients", issue.RecipientCount))
+	return issue, nil
+}
+
+// deliver sends an issue to its pending deliveries and marks it sent once none
+// is left. It stops at the first temporary failure, which the next run retries.
+func (s *Service) deliver(ctx context.Context, issue *entity.Issue) error {
+	list, err := s.repo.GetList(ctx, issue.ListID)
+	if err != nil {
+		s.logError("Error getting newsletter", err)
+		return err
+	}
+	m, err := s.mailer()
+	if err != nil {
+		s.app.Logger.Error("Error creating mailer", zap.Error(err))
+		return err
+	}
+
+	for ctx.Err() == nil {
+		deliveries, err := s.repo.PendingDeliveries(ctx, issue.ID, deliveryBatch)
+		if err != nil {
+			s.app.Logger.Error("Error getting deliveries", zap.Uint("issue_id", issue.ID), zap.Error(err))
+			return err
+		}
+		if len(deliveries) == 0 {
+			break
+		}
+		for _, delivery := range deliveries {
+			if ctx.Err() != nil {
+				return nil
+			}
+			if err := s.send(ctx, m, list, issue, delivery); err != nil {
+				return err
+			}
+		}
+	}
+	if ctx.Err() != nil {
+		return nil
+	}
+
+	if err := s.repo.FinishIssue(ctx, issue); err != nil {
+		s.app.Logger.Error("Error finishing issue", zap.Uint("issue_id", issue.ID), zap.Error(err))
+		return err
+	}
+	s.app.Logger.Info("Newsletter issue sent", zap.String("newsletter", list.Slug), zap.Uint("issue_id", issue.ID),
+		zap.Int("sent", issue.SentCount), zap.Int("failed", issue.FailedCount))
+	return nil
+}
+
+// send delivers an issue to one subscriber and stores the outcome. A refused
+// address counts as a permanent bounce. Other failures keep the delivery
+// pending until maxAttempts, and stop the run.
+func (s *Service) send(ctx context.Context, m mailer.Mailer, list *entity.List, issue *entity.Issue, delivery *entity.Delivery) error {
+	subscriber := delivery.Subscriber
+	// The subscriber left or bounced after the issue was created
+	if subscriber.Status != entity.StatusActive {
+		delivery.Status = entity.DeliveryFailed
+		delivery.Error = "subscriber is " + subscriber.Status
+		return s.saveDelivery(ctx, delivery)
+	}
+
+	html, text, err := s.renderDigest(list, issue.Subject, issue.Sections, subscriber)
+	if err != nil {
+		s.app.Logger.Error("Error rendering digest", zap.Uint("issue_id", issue.ID), zap.Error(err))
+		return err
+	}
+	unsubscribeURL := s.link("unsubscribe", subscriber.UnsubscribeToken)
+	sendErr := m.Send(ctx, &mailer.Message{
+		To:      subscriber.Email,
+		Subject: issue.Subject,
+		Text:    text,
+		HTML:    html,
+		Headers: map[string]string{
+			"List-Unsubscribe":      "<" + unsubscribeURL + ">",
+			"List-Unsubscribe-Post": "List-Unsubscribe=One-Click",
+		},
+	})
+
+	switch {
+	case sendErr == nil:
+		now := time.Now().UTC().Truncate(time.Second)
+		delivery.Status = entity.DeliverySent
+		delivery.Error = ""
+		delivery.SentAt = &now
+		return s.saveDelivery(ctx, delivery)
+	case mailer.IsPermanent(sendErr):
+		delivery.Status = entity.DeliveryBounced
+		delivery.Error = utils.Truncate(sendErr.Error(), 499)
+		if err := s.saveDelivery(ctx, delivery); err != nil {
+			return err
+		}
+		if _, err := s.repo.RecordBounce(context.WithoutCancel(ctx), subscriber.Email, delivery.Error, true); err != nil {
+			s.app.Logger.Error("Error recording bounce", zap.Uint("subscriber_id", subscriber.ID), zap.Error(err))
+			return err
+		}
+		return nil
+	default:
+		// A cancelled run leaves the delivery for the next one
+		if ctx.Err() != nil {
+			return nil
+		}
+		delivery.Status = entity.DeliveryPending
+		if delivery.Attempts+1 >= maxAttempts {
+			delivery.Status = entity.DeliveryFailed
+		}
+		delivery.Error = utils.Truncate(sendErr.Error(), 499)
+		if err := s.saveDelivery(ctx, delivery); err != nil {
+			return err
+		}
+		s.app.Logger.Warn("Error sending newsletter", zap.Uint("issue_id", issue.ID),
+			zap.Uint("subscriber_id", subscriber.ID), zap.Error(sendErr))
+		if delivery.Status == entity.DeliveryFailed {
+			return nil
+		}
+		return sendErr
+	}
+}
+
+// saveDelivery stores the outcome of a delivery attempt. It is stored even
+// when the run ends meanwhile, so a sent message is not sent again.
+func (s *Service) saveDelivery(ctx context.Context, delivery *entity.Delivery) error {
+	if err := s.repo.SaveDelivery(context.WithoutCancel(ctx), delivery); err != nil {
+		s.app.Logger.Error("Error saving delivery", zap.Uint("delivery_id", delivery.ID), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// renderDigest renders a digest for a subscriber, with their unsubscribe link
+func (s *Service) renderDigest(list *entity.List, subject string, sections []*newsEntity.DigestSection, subscriber *entity.Subscriber) (string, string, error) {
+	return render(digestTemplates, &digestView{
+		SiteName:       config.GlobalConfig.SiteName,
+		SiteURL:        s.app.Config.Domain,
+		Subject:        subject,
+		List:           list.Name,
+		Name:           subscriber.Name,
+		Sections:       sections,
+		UnsubscribeURL: s.link("unsubscribe", subscriber.UnsubscribeToken),
+	})
+}
+
+// isDue reports whether a list has not been sent since its latest slot. A
+// new list waits for the first slot after it was created.
+func isDue(list *entity.List, now time.Time) bool {
+	last := list.CreatedAt
+	if list.LastSentAt != nil {
+		last = *list.LastSentAt
+	}
+	return latestSlot(list.Frequency, now).After(last)
+}
+
+// latestSlot returns the latest send time of a frequency at or before now:
+// NEWSLETTER_SEND_AT (UTC) every day, or on NEWSLETTER_WEEKDAY for weekly digests
+func latestSlot(frequency string, now time.Time) time.Time {
+	hour, minute := 6, 0
+	if t, err := time.Parse("15:04", config.GlobalConfig.NewsletterSendAt); err == nil {
+		hour, minute = t.Hour(), t.Minute()
+	}
+	now = now.UTC()
+	slot := time.Date(now.Year(), now.Month(), now.Day(), hour, minute, 0, 0, time.UTC)
+	if slot.After(now) {
+		slot = slot.AddDate(0, 0, -1)
+	}
+	if frequency != entity.FrequencyWeekly {
+		return slot
+	}
+	weekday := time.Monday
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if strings.EqualFold(day.String(), config.GlobalConfig.NewsletterWeekday) {
+			weekday = day
+		}
+	}
+	return slot.AddDate(0, 0, -((int(slot.Weekday()) - int(weekday) + 7) % 7))
+}
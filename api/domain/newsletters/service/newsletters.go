@@ -0,0 +1,488 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	newsService "github.com/JubaerHossain/cn-api/domain/news/service"
+	"github.com/JubaerHossain/cn-api/domain/newsletters/entity"
+	"github.com/JubaerHossain/cn-api/domain/newsletters/infrastructure/persistence"
+	"github.com/JubaerHossain/cn-api/domain/newsletters/repository"
+	"github.com/JubaerHossain/cn-api/pkg/config"
+	"github.com/JubaerHossain/cn-api/pkg/locale"
+	"github.com/JubaerHossain/cn-api/pkg/mailer"
+	"github.com/JubaerHossain/cn-api/pkg/middleware"
+	"github.com/JubaerHossain/cn-api/pkg/ratelimit"
+	"github.com/JubaerHossain/cn-api/pkg/redisclient"
+	"github.com/JubaerHossain/cn-api/pkg/utils"
+	"github.com/JubaerHossain/rootx/pkg/core/app"
+	"go.uber.org/zap"
+)
+
+var (
+	subscribeLimiter     *ratelimit.Limiter
+	subscribeLimiterOnce sync.Once
+	addressLimiter       *ratelimit.Limiter
+	addressLimiterOnce   sync.Once
+	sharedMailer         mailer.Mailer
+	sharedMailerErr      error
+	sharedMailerOnce     sync.Once
+)
+
+type Service struct {
+	app  *app.App
+	repo repository.NewsletterRepository
+	news *newsService.Service // Selects the articles of the digests
+}
+
+func NewService(app *app.App) *Service {
+	repo := persistence.NewNewsletterRepository(app)
+	return &Service{
+		app:  app,
+		repo: repo,
+		news: newsService.NewService(app),
+	}
+}
+
+// limiter returns the subscription rate limiter shared by every handler, on
+// Redis when it is enabled so the limits hold across replicas
+func (s *Service) limiter() *ratelimit.Limiter {
+	subscribeLimiterOnce.Do(func() {
+		subscribeLimiter = ratelimit.New(redisclient.Get(s.app.Config), config.GlobalConfig.NewsletterLimit, time.Hour)
+	})
+	return subscribeLimiter
+}
+
+// addressLimiter returns the limiter of subscription requests per address,
+// which keeps the confirmation emails one address gets in check
+func (s *Service) addressLimiter() *ratelimit.Limiter {
+	addressLimiterOnce.Do(func() {
+		addressLimiter = ratelimit.New(redisclient.Get(s.app.Config), config.GlobalConfig.NewsletterPerEmail, 24*time.Hour)
+	})
+	return addressLimiter
+}
+
+// mailer returns the mailer selected by MAIL_DRIVER, shared by every handler
+func (s *Service) mailer() (mailer.Mailer, error) {
+	sharedMailerOnce.Do(func() {
+		sharedMailer, sharedMailerErr = mailer.New(config.GlobalConfig)
+	})
+	return sharedMailer, sharedMailerErr
+}
+
+// RetryAfter is the time a rate limited client waits before subscribing again
+func (s *Service) RetryAfter() time.Duration {
+	return s.limiter().Window()
+}
+
+// GetLists returns every list with its number of active subscribers
+func (s *Service) GetLists(r *http.Request) ([]*entity.List, error) {
+	lists, err := s.repo.GetLists(r.Context(), false)
+	if err != nil {
+		s.app.Logger.Error("Error getting newsletters", zap.Error(err))
+		return nil, err
+	}
+	return lists, nil
+}
+
+// GetPublicLists returns the lists readers can subscribe to
+func (s *Service) GetPublicLists(r *http.Request) ([]*entity.PublicList, error) {
+	lists, err := s.repo.GetLists(r.Context(), true)
+	if err != nil {
+		s.app.Logger.Error("Error getting newsletters", zap.Error(err))
+		return nil, err
+	}
+	public := make([]*entity.PublicList, 0, len(lists))
+	for _, list := range lists {
+		public = append(public, &entity.PublicList{
+			Slug:        list.Slug,
+			Name:        list.Name,
+			Description: list.Description,
+			Frequency:   list.Frequency,
+			Locale:      list.Locale,
+		})
+	}
+	return public, nil
+}
+
+// GetList returns the list in the path
+func (s *Service) GetList(r *http.Request) (*entity.List, error) {
+	listID, err := strconv.ParseUint(r.PathValue("id"), 10, 64)
+	if err != nil {
+		return nil, entity.ErrListNotFound
+	}
+	list, err := s.repo.GetList(r.Context(), uint(listID))
+	if err != nil {
+		s.logError("Error getting newsletter", err)
+		return nil, err
+	}
+	return list, nil
+}
+
+// CreateList creates a list
+func (s *Service) CreateList(r *http.Request, request *entity.ListRequest) (*entity.List, error) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		return nil, fmt.Errorf("unauthorized")
+	}
+	now := time.Now().UTC().Truncate(time.Second)
+	list := &entity.List{CreatedBy: userID, CreatedAt: now, UpdatedAt: now}
+	if err := applyList(list, request); err != nil {
+		return nil, err
+	}
+	if err := s.repo.CreateList(r, list); err != nil {
+		s.logError("Error creating newsletter", err)
+		return nil, err
+	}
+	return list, nil
+}
+
+// UpdateList changes the settings of the list in the path
+func (s *Service) UpdateList(r *http.Request, request *entity.ListRequest) (*entity.List, error) {
+	list, err := s.GetList(r)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyList(list, request); err != nil {
+		return nil, err
+	}
+	list.UpdatedAt = time.Now().UTC().Truncate(time.Second)
+	if err := s.repo.UpdateList(r, list); err != nil {
+		s.logError("Error updating newsletter", err)
+		return nil, err
+	}
+	return list, nil
+}
+
+// DeleteList removes the list in the path with its subscribers and send history
+func (s *Service) DeleteList(r *http.Request) error {
+	list, err := s.GetList(r)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.DeleteList(r, list); err != nil {
+		s.logError("Error deleting newsletter", err)
+		return err
+	}
+	return nil
+}
+
+// GetSubscribers returns a page of the subscribers of the list in the path
+func (s *Service) GetSubscribers(r *http.Request) (*entity.SubscriberResponsePagination, error) {
+	list, err := s.GetList(r)
+	if err != nil {
+		return nil, err
+	}
+	subscribers, err := s.repo.GetSubscribers(r, list.ID)
+	if err != nil {
+		s.logError("Error getting subscribers", err)
+		return nil, err
+	}
+	return subscribers, nil
+}
+
+// GetSubscriber returns the subscriber in the path with their send history
+func (s *Service) GetSubscriber(r *http.Request) (*entity.Subscriber, error) {
+	listID, err := strconv.ParseUint(r.PathValue("id"), 10, 64)
+	if err != nil {
+		return nil, entity.ErrListNotFound
+	}
+	subscriberID, err := strconv.ParseUint(r.PathValue("subscriber"), 10, 64)
+	if err != nil {
+		return nil, entity.ErrSubscriberNotFound
+	}
+	subscriber, err := s.repo.GetSubscriber(r.Context(), uint(listID), uint(subscriberID))
+	if err != nil {
+		s.logError("Error getting subscriber", err)
+		return nil, err
+	}
+	return subscriber, nil
+}
+
+// DeleteSubscriber removes the subscriber in the path with their send history
+func (s *Service) DeleteSubscriber(r *http.Request) error {
+	subscriber, err := s.GetSubscriber(r)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.DeleteSubscriber(r, subscriber); err != nil {
+		s.logError("Error deleting subscriber", err)
+		return err
+	}
+	return nil
+}
+
+// GetIssues returns a page of the issues sent to the list in the path
+func (s *Service) GetIssues(r *http.Request) (*entity.IssueResponsePagination, error) {
+	list, err := s.GetList(r)
+	if err != nil {
+		return nil, err
+	}
+	issues, err := s.repo.GetIssues(r, list.ID)
+	if err != nil {
+		s.logError("Error getting issues", err)
+		return nil, err
+	}
+	return issues, nil
+}
+
+// Subscribe asks for a subscription to the active list in the path and sends
+// the confirmation email, limited per IP address and per email address. The response is the same
+// whether or not the address was subscribed already.
+func (s *Service) Subscribe(r *http.Request, request *entity.SubscribeRequest) error {
+	ctx := r.Context()
+	ip := utils.ClientIP(r)
+	allowed, err := s.limiter().Allow(ctx, "newsletter:ip:"+ip)
+	if err != nil {
+		s.app.Logger.Error("Error checking subscription rate limit", zap.Error(err))
+		return err
+	}
+	if !allowed {
+		return entity.ErrRateLimited
+	}
+	list, err := s.repo.GetListBySlug(ctx, r.PathValue("slug"))
+	if err != nil {
+		s.logError("Error getting newsletter", err)
+		return err
+	}
+	email := strings.ToLower(strings.TrimSpace(request.Email))
+	// Past the limit of the address nothing is stored or sent, and the response
+	// stays the same so it does not tell whether the address was tried before
+	sum := sha256.Sum256([]byte(email))
+	allowed, err = s.addressLimiter().Allow(ctx, "newsletter:email:"+hex.EncodeToString(sum[:]))
+	if err != nil {
+		s.app.Logger.Error("Error checking subscription rate limit", zap.Error(err))
+		return err
+	}
+	if !allowed {
+		return nil
+	}
+
+	confirmToken, err := newToken()
+	if err != nil {
+		return err
+	}
+	unsubscribeToken, err := newToken()
+	if err != nil {
+		return err
+	}
+	subscriber, err := s.repo.Subscribe(ctx, &entity.Subscriber{
+		ListID:           list.ID,
+		Email:            email,
+		Name:             strings.TrimSpace(request.Name),
+		ConfirmToken:     confirmToken,
+		UnsubscribeToken: unsubscribeToken,
+		IPAddress:        ip,
+		CreatedAt:        time.Now().UTC().Truncate(time.Second),
+	})
+	if err != nil {
+		s.logError("Error saving subscription", err)
+		return err
+	}
+	if subscriber.Status != entity.StatusPending {
+		return nil
+	}
+	return s.sendConfirmation(ctx, list, subscriber)
+}
+
+// Confirm activates the subscription of the confirmation token in the query
+func (s *Service) Confirm(r *http.Request) (*entity.PublicList, error) {
+	token := r.URL.Query().Get("token")
+	if token == "" {
+		return nil, entity.ErrInvalidToken
+	}
+	subscriber, err := s.repo.Confirm(r.Context(), token)
+	if err != nil {
+		s.logError("Error confirming subscription", err)
+		return nil, err
+	}
+	return s.publicList(r.Context(), subscriber.ListID)
+}
+
+// UnsubscribePage renders the page an unsubscribe link opens. Following the
+// link changes nothing: the page asks to confirm with a POST, so link scanners
+// and prefetchers do not unsubscribe anyone.
+func (s *Service) UnsubscribePage(r *http.Request) (string, error) {
+	token := r.URL.Query().Get("token")
+	if token == "" {
+		return "", entity.ErrInvalidToken
+	}
+	subscriber, err := s.repo.GetSubscriberByUnsubscribeToken(r.Context(), token)
+	if err != nil {
+		s.logError("Error getting subscriber", err)
+		return "", err
+	}
+	list, err := s.publicList(r.Context(), subscriber.ListID)
+	if err != nil {
+		return "", err
+	}
+	return s.renderUnsubscribePage(list, token, subscriber.Status == entity.StatusUnsubscribed)
+}
+
+// UnsubscribedPage renders the page shown once the reader confirmed on the unsubscribe page
+func (s *Service) UnsubscribedPage(list *entity.PublicList) (string, error) {
+	return s.renderUnsubscribePage(list, "", true)
+}
+
+func (s *Service) renderUnsubscribePage(list *entity.PublicList, token string, done bool) (string, error) {
+	view := &unsubscribeView{SiteName: config.GlobalConfig.SiteName, List: list.Name, Done: done}
+	if !done {
+		view.UnsubscribeURL = s.link("unsubscribe", token)
+	}
+	var page strings.Builder
+	if err := unsubscribePage.Execute(&page, view); err != nil {
+		s.app.Logger.Error("Error rendering unsubscribe page", zap.Error(err))
+		return "", err
+	}
+	return page.String(), nil
+}
+
+// Unsubscribe ends the subscription of the unsubscribe token in the query
+func (s *Service) Unsubscribe(r *http.Request) (*entity.PublicList, error) {
+	token := r.URL.Query().Get("token")
+	if token == "" {
+		return nil, entity.ErrInvalidToken
+	}
+	subscriber, err := s.repo.Unsubscribe(r.Context(), token)
+	if err != nil {
+		s.logError("Error unsubscribing", err)
+		return nil, err
+	}
+	return s.publicList(r.Context(), subscriber.ListID)
+}
+
+// RecordBounce counts a bounce reported for an address and returns the number
+// of subscriptions it applied to
+func (s *Service) RecordBounce(r *http.Request, request *entity.BounceRequest) (int64, error) {
+	email := strings.ToLower(strings.TrimSpace(request.Email))
+	updated, err := s.repo.RecordBounce(r.Context(), email, strings.TrimSpace(request.Reason), request.Permanent)
+	if err != nil {
+		s.app.Logger.Error("Error recording bounce", zap.Error(err))
+		return 0, err
+	}
+	return updated, nil
+}
+
+// sendConfirmation emails the confirmation link to a pending subscriber
+func (s *Service) sendConfirmation(ctx context.Context, list *entity.List, subscriber *entity.Subscriber) error {
+	view := &confirmView{
+		SiteName:   config.GlobalConfig.SiteName,
+		List:       list.Name,
+		Name:       subscriber.Name,
+		ConfirmURL: s.link("confirm", subscriber.ConfirmToken),
+	}
+	html, text, err := render(confirmTemplates, view)
+	if err != nil {
+		s.app.Logger.Error("Error rendering confirmation", zap.Error(err))
+		return err
+	}
+	m, err := s.mailer()
+	if err != nil {
+		s.app.Logger.Error("Error creating mailer", zap.Error(err))
+		return err
+	}
+	if err := m.Send(ctx, &mailer.Message{
+		To:      subscriber.Email,
+		Subject: fmt.Sprintf("Confirm your subscription to %s", list.Name),
+		Text:    text,
+		HTML:    html,
+	}); err != nil {
+		s.app.Logger.Error("Error sending confirmation", zap.Uint("subscriber_id", subscriber.ID), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// publicList returns a list as readers see it
+func (s *Service) publicList(ctx context.Context, listID uint) (*entity.PublicList, error) {
+	list, err := s.repo.GetList(ctx, listID)
+	if err != nil {
+		s.logError("Error getting newsletter", err)
+		return nil, err
+	}
+	return &entity.PublicList{
+		Slug:        list.Slug,
+		Name:        list.Name,
+		Description: list.Description,
+		Frequency:   list.Frequency,
+		Locale:      list.Locale,
+	}, nil
+}
+
+// link returns the confirmation or unsubscribe link of a token, under
+// NEWSLETTER_URL or the public newsletter endpoints
+func (s *Service) link(action, token string) string {
+	base := strings.TrimSuffix(config.GlobalConfig.NewsletterURL, "/")
+	if base == "" {
+		base = strings.TrimSuffix(s.app.Config.Domain, "/") + "/api/public/v1/newsletters"
+	}
+	return fmt.Sprintf("%s/%s?token=%s", base, action, url.QueryEscape(token))
+}
+
+// applyList validates a list request and copies it to the list
+func applyList(list *entity.List, request *entity.ListRequest) error {
+	name := strings.TrimSpace(request.Name)
+	if name == "" {
+		return fmt.Errorf("%w: name is required", entity.ErrInvalidList)
+	}
+	listLocale := strings.ToLower(strings.TrimSpace(request.Locale))
+	if listLocale == "" {
+		listLocale = locale.Default()
+	}
+	if !locale.IsSupported(listLocale) {
+		return fmt.Errorf("%w: locale %q is not supported", entity.ErrInvalidList, request.Locale)
+	}
+
+	categories := []string{}
+	seen := map[string]bool{}
+	for _, slug := range request.Categories {
+		slug = strings.TrimSpace(slug)
+		if slug == "" || seen[slug] {
+			continue
+		}
+		seen[slug] = true
+		categories = append(categories, slug)
+	}
+
+	list.Name = name
+	list.Slug = strings.TrimSpace(request.Slug)
+	list.Description = strings.TrimSpace(request.Description)
+	list.Frequency = request.Frequency
+	list.Locale = listLocale
+	list.Categories = categories
+	list.PerCategory = request.PerCategory
+	if list.PerCategory == 0 {
+		list.PerCategory = 3
+	}
+	list.Active = request.Active
+	return nil
+}
+
+// newToken returns a random confirmation or unsubscribe token
+func newToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// logError logs unexpected errors, not the ones caused by the request
+func (s *Service) logError(message string, err error) {
+	if errors.Is(err, entity.ErrListNotFound) || errors.Is(err, entity.ErrSubscriberNotFound) ||
+		errors.Is(err, entity.ErrInvalidList) || errors.Is(err, entity.ErrListExists) || errors.Is(err, entity.ErrIssueSending) ||
+		errors.Is(err, entity.ErrInvalidToken) || errors.Is(err, entity.ErrInvalidQuery) {
+		return
+	}
+	s.app.Logger.Error(message, zap.Error(err))
+}
@@ -0,0 +1,156 @@
+package service
+
+import (
+	"bytes"
+	htmltemplate "html/template"
+	texttemplate "text/template"
+
+	newsEntity "github.com/JubaerHossain/cn-api/domain/news/entity"
+)
+
+// templates renders an email as HTML and as plain text from the same view
+type templates struct {
+	html *htmltemplate.Template
+	text *texttemplate.Template
+}
+
+// confirmView is the data of the confirmation email
+type confirmView struct {
+	SiteName   string
+	List       string
+	Name       string
+	ConfirmURL string
+}
+
+// digestView is the data of a digest email, rendered for one subscriber
+type digestView struct {
+	SiteName       string
+	SiteURL        string
+	Subject        string
+	List           string
+	Name           string
+	Sections       []*newsEntity.DigestSection
+	UnsubscribeURL string
+}
+
+// unsubscribeView is the data of the unsubscribe page
+type unsubscribeView struct {
+	SiteName       string
+	List           string
+	UnsubscribeURL string
+	Done           bool
+}
+
+// unsubscribePage asks the reader to confirm with a POST, then tells them they are unsubscribed
+var unsubscribePage = htmltemplate.Must(htmltemplate.New("unsubscribe").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="robots" content="noindex">
+<title>{{if .Done}}Unsubscribed{{else}}Unsubscribe{{end}} - {{.SiteName}}</title>
+</head>
+<body style="margin:0;padding:24px;background:#f4f4f4;font-family:Arial,Helvetica,sans-serif;color:#222">
+<div style="max-width:600px;margin:0 auto;background:#fff;padding:24px">
+<h1 style="margin:0 0 16px;font-size:22px">{{.SiteName}}</h1>
+{{if .Done}}<p>You are unsubscribed from <strong>{{.List}}</strong> and will not receive it any more.</p>
+{{else}}<p>Do you want to stop receiving <strong>{{.List}}</strong>?</p>
+<form method="post" action="{{.UnsubscribeURL}}">
+<input type="hidden" name="confirm" value="1">
+<button type="submit" style="padding:10px 18px;background:#c0392b;color:#fff;border:0;border-radius:4px;cursor:pointer">Unsubscribe</button>
+</form>
+{{end}}</div>
+</body>
+</html>
+`))
+
+var confirmTemplates = templates{
+	html: htmltemplate.Must(htmltemplate.New("confirm").Parse(`<!DOCTYPE html>
+<html>
+<body style="margin:0;padding:24px;background:#f4f4f4;font-family:Arial,Helvetica,sans-serif;color:#222">
+<table role="presentation" width="100%" cellpadding="0" cellspacing="0" style="max-width:600px;margin:0 auto;background:#fff">
+<tr><td style="padding:24px">
+<h1 style="margin:0 0 16px;font-size:22px">{{.SiteName}}</h1>
+<p>{{if .Name}}Hello {{.Name}},{{else}}Hello,{{end}}</p>
+<p>Please confirm your subscription to <strong>{{.List}}</strong>.</p>
+<p><a href="{{.ConfirmURL}}" style="display:inline-block;padding:10px 18px;background:#c0392b;color:#fff;text-decoration:none;border-radius:4px">Confirm subscription</a></p>
+<p style="font-size:13px;color:#666">If you did not ask for this, ignore this email and you will not hear from us.</p>
+</td></tr>
+</table>
+</body>
+</html>
+`)),
+	text: texttemplate.Must(texttemplate.New("confirm").Parse(`{{if .Name}}Hello {{.Name}},{{else}}Hello,{{end}}
+
+Please confirm your subscription to {{.List}} by following this link:
+
+{{.ConfirmURL}}
+
+If you did not ask for this, ignore this email and you will not hear from us.
+
+{{.SiteName}}
+`)),
+}
+
+var digestTemplates = templates{
+	html: htmltemplate.Must(htmltemplate.New("digest").Parse(`<!DOCTYPE html>
+<html>
+<head><title>{{.Subject}}</title></head>
+<body style="margin:0;padding:24px;background:#f4f4f4;font-family:Arial,Helvetica,sans-serif;color:#222">
+<table role="presentation" width="100%" cellpadding="0" cellspacing="0" style="max-width:600px;margin:0 auto;background:#fff">
+<tr><td style="padding:24px 24px 8px">
+<h1 style="margin:0;font-size:22px"><a href="{{.SiteURL}}" style="color:#222;text-decoration:none">{{.SiteName}}</a></h1>
+<p style="margin:4px 0 0;color:#666">{{.List}}</p>
+{{if .Name}}<p>Hello {{.Name}}, here are the top stories for you.</p>{{end}}
+</td></tr>
+{{range .Sections}}
+<tr><td style="padding:16px 24px 0">
+<h2 style="margin:0 0 8px;font-size:18px;border-bottom:2px solid #c0392b;padding-bottom:4px">{{.Category}}</h2>
+{{range .News}}
+<table role="presentation" width="100%" cellpadding="0" cellspacing="0" style="margin:0 0 16px">
+<tr>
+{{if .PathSmall}}<td width="120" valign="top" style="padding-right:12px"><a href="{{.URL}}"><img src="{{.PathSmall}}" width="120" alt="" style="display:block;border:0"></a></td>{{end}}
+<td valign="top">
+<a href="{{.URL}}" style="font-size:16px;font-weight:bold;color:#222;text-decoration:none">{{.Title}}</a>
+{{if .Excerpt}}<p style="margin:4px 0 0;font-size:14px;color:#444">{{.Excerpt}}</p>{{end}}
+</td>
+</tr>
+</table>
+{{end}}
+</td></tr>
+{{end}}
+<tr><td style="padding:16px 24px 24px;font-size:12px;color:#888">
+You receive {{.List}} because you subscribed to it on {{.SiteName}}.
+<a href="{{.UnsubscribeURL}}" style="color:#888">Unsubscribe</a>
+</td></tr>
+</table>
+</body>
+</html>
+`)),
+	text: texttemplate.Must(texttemplate.New("digest").Parse(`{{.SiteName}}: {{.List}}
+{{if .Name}}
+Hello {{.Name}}, here are the top stories for you.
+{{end}}{{range .Sections}}
+== {{.Category}} ==
+{{range .News}}
+{{.Title}}
+{{if .Excerpt}}{{.Excerpt}}
+{{end}}{{.URL}}
+{{end}}{{end}}
+--
+You receive {{.List}} because you subscribed to it on {{.SiteName}}.
+Unsubscribe: {{.UnsubscribeURL}}
+`)),
+}
+
+// render returns the HTML and plain text bodies of an email
+func render(t templates, view interface{}) (string, string, error) {
+	var html, text bytes.Buffer
+	if err := t.html.Execute(&html, view); err != nil {
+		return "", "", err
+	}
+	if err := t.text.Execute(&text, view); err != nil {
+		return "", "", err
+	}
+	return html.String(), text.String(), nil
+}
@@ -0,0 +1,87 @@
+package entity
+
+import (
+	"errors"
+	"time"
+
+	"github.com/JubaerHossain/rootx/pkg/core/entity"
+)
+
+var (
+	// ErrPlacementNotFound is returned when a placement or a pinned item does not exist
+	ErrPlacementNotFound = errors.New("placement not found")
+	// ErrPlacementExists is returned when another placement already uses the name
+	ErrPlacementExists = errors.New("a placement with this name already exists")
+	// ErrInvalidPlacement is returned for invalid names, backfill rules, pins and orders
+	ErrInvalidPlacement = errors.New("invalid placement")
+)
+
+// Backfill rules fill the places of a slot that no pinned article takes
+const (
+	BackfillNone     = "none"     // Only pinned articles are served
+	BackfillRecent   = "recent"   // The latest published articles
+	BackfillCategory = "category" // The latest published articles of BackfillCategoryID
+)
+
+// Placement represents a named homepage slot
+type Placement struct {
+	ID                 uint             `json:"id"`                                                       // Primary key
+	Name               string           `json:"name" validate:"required,max=100"`                         // Lowercase letters, digits and dashes, served at /placements/{name}
+	Title              string           `json:"title" validate:"max=191"`                                 // Label for editors
+	MaxItems           uint             `json:"max_items" validate:"required,gte=1,lte=50"`               // Number of articles served
+	Backfill           string           `json:"backfill" validate:"omitempty,oneof=none recent category"` // Defaults to none
+	BackfillCategoryID uint             `json:"backfill_category_id"`                                     // Required by the category rule
+	Items              []*PlacementItem `json:"items,omitempty"`                                          // Pinned articles, in their order
+	CreatedAt          time.Time        `json:"created_at"`
+	UpdatedAt          time.Time        `json:"updated_at"`
+}
+
+// UpdatePlacement represents the placement update request
+type UpdatePlacement struct {
+	Name               string `json:"name" validate:"required,max=100"`
+	Title              string `json:"title" validate:"max=191"`
+	MaxItems           uint   `json:"max_items" validate:"required,gte=1,lte=50"`
+	Backfill           string `json:"backfill" validate:"omitempty,oneof=none recent category"`
+	BackfillCategoryID uint   `json:"backfill_category_id"`
+}
+
+// PlacementItem represents an article pinned to a placement
+type PlacementItem struct {
+	NewsID    uint       `json:"news_id"`
+	Title     string     `json:"title"`      // Title in the default locale
+	Position  uint       `json:"position"`   // 0 is served first
+	ExpiresAt *time.Time `json:"expires_at"` // When the article stops being served, nil for never
+	Expired   bool       `json:"expired"`
+	CreatedBy uint       `json:"created_by"`
+	CreatedAt string     `json:"created_at"`
+}
+
+// PinNews represents the request to pin an article to a placement. Pinning an
+// article again moves it and replaces its expiry.
+type PinNews struct {
+	NewsID    uint       `json:"news_id" validate:"required,gt=0"`
+	Position  *uint      `json:"position"`   // Defaults to the top of the slot
+	ExpiresAt *time.Time `json:"expires_at"` // Must be in the future when set
+	CreatedBy uint       `json:"-"`
+}
+
+// OrderItems represents the request to reorder the pinned articles of a placement
+type OrderItems struct {
+	NewsIDs []uint `json:"news_ids" validate:"required,min=1,dive,gt=0"` // Every pinned article, in the new order
+}
+
+// ResponsePlacement represents the placement list response
+type ResponsePlacement struct {
+	ID                 uint   `json:"id"`
+	Name               string `json:"name"`
+	Title              string `json:"title"`
+	MaxItems           uint   `json:"max_items"`
+	Backfill           string `json:"backfill"`
+	BackfillCategoryID uint   `json:"backfill_category_id"`
+	PinnedCount        uint   `json:"pinned_count"` // Pinned articles that have not expired
+}
+
+type PlacementResponsePagination struct {
+	Data       []*ResponsePlacement `json:"data"`
+	Pagination entity.Pagination    `json:"pagination"`
+}
@@ -0,0 +1,476 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/JubaerHossain/cn-api/domain/placements/entity"
+	"github.com/JubaerHossain/cn-api/domain/placements/repository"
+	"github.com/JubaerHossain/cn-api/pkg/locale"
+	utilQuery "github.com/JubaerHossain/cn-api/pkg/utils"
+	"github.com/JubaerHossain/rootx/pkg/core/app"
+	"github.com/JubaerHossain/rootx/pkg/core/cache"
+)
+
+// pinnedCount counts the pinned articles of a placement that have not expired
+const pinnedCount = "(SELECT COUNT(*) FROM placement_items WHERE placement_items.placement_id = placements.id AND (placement_items.expires_at IS NULL OR placement_items.expires_at > UTC_TIMESTAMP()))"
+
+// namePattern restricts names to what can sit in a URL path and a cache key
+var namePattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+type PlacementRepositoryImpl struct {
+	app *app.App
+}
+
+// NewPlacementRepository returns a new instance of PlacementRepositoryImpl
+func NewPlacementRepository(app *app.App) repository.PlacementRepository {
+	return &PlacementRepositoryImpl{
+		app: app,
+	}
+}
+
+// CacheClear drops the served articles of the named placements. The cache key
+// of a placement starts with placement_<name>_, names never hold an underscore.
+func CacheClear(req *http.Request, cache cache.CacheService, names ...string) error {
+	ctx := req.Context()
+	for _, name := range names {
+		if _, err := cache.ClearPattern(ctx, "placement_"+name+"_*"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetPlacements returns a page of placements, sorted by name
+func (r *PlacementRepositoryImpl) GetPlacements(req *http.Request) (*entity.PlacementResponsePagination, error) {
+	ctx := req.Context()
+	baseQuery := `
+		SELECT placements.id, placements.name, placements.title, placements.max_items, placements.backfill,
+		       COALESCE(placements.backfill_category_id, 0), ` + pinnedCount + ` AS pinned_count
+		FROM placements`
+
+	filterQuery := ""
+	var args []interface{}
+	if search := strings.TrimSpace(req.URL.Query().Get("search")); search != "" {
+		filterQuery = " WHERE placements.name LIKE ? OR placements.title LIKE ?"
+		args = append(args, "%"+search+"%", "%"+search+"%")
+	}
+
+	// Pagination and limits
+	pagination, limit, offset, err := utilQuery.Paginate(req, r.app, baseQuery, filterQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("pagination error: %w", err)
+	}
+	query := fmt.Sprintf("%s%s ORDER BY placements.name ASC LIMIT %d OFFSET %d", baseQuery, filterQuery, limit, offset)
+
+	rows, err := r.app.MDB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query placements: %w", err)
+	}
+	defer rows.Close()
+
+	placements := []*entity.ResponsePlacement{}
+	for rows.Next() {
+		var placement entity.ResponsePlacement
+		if err := rows.Scan(&placement.ID, &placement.Name, &placement.Title, &placement.MaxItems, &placement.Backfill,
+			&placement.BackfillCategoryID, &placement.PinnedCount); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		placements = append(placements, &placement)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return &entity.PlacementResponsePagination{
+		Data:       placements,
+		Pagination: pagination,
+	}, nil
+}
+
+// GetPlacementByID returns the placement row by ID from the database
+func (r *PlacementRepositoryImpl) GetPlacementByID(placementID uint) (*entity.Placement, error) {
+	placement := &entity.Placement{}
+	var createdAt, updatedAt sql.NullString
+	err := r.app.MDB.QueryRow(`
+		SELECT id, name, title, max_items, backfill, COALESCE(backfill_category_id, 0), created_at, updated_at
+		FROM placements WHERE id = ?`, placementID).
+		Scan(&placement.ID, &placement.Name, &placement.Title, &placement.MaxItems, &placement.Backfill,
+			&placement.BackfillCategoryID, &createdAt, &updatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, entity.ErrPlacementNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get placement: %w", err)
+	}
+	placement.CreatedAt = parseDateTime(createdAt)
+	placement.UpdatedAt = parseDateTime(updatedAt)
+	return placement, nil
+}
+
+// GetPlacement returns a placement with its pinned articles, expired ones included
+func (r *PlacementRepositoryImpl) GetPlacement(req *http.Request, placementID uint) (*entity.Placement, error) {
+	placement, err := r.GetPlacementByID(placementID)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.app.MDB.QueryContext(req.Context(), `
+		SELECT placement_items.news_id, COALESCE(news_translations.title, ''), placement_items.position,
+		       placement_items.expires_at, COALESCE(placement_items.created_by, 0), placement_items.created_at
+		FROM placement_items
+		LEFT JOIN news_translations ON news_translations.news_id = placement_items.news_id AND news_translations.locale = ?
+		WHERE placement_items.placement_id = ?
+		ORDER BY placement_items.position ASC, placement_items.news_id DESC`, locale.Default(), placement.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query placement items: %w", err)
+	}
+	defer rows.Close()
+
+	now := time.Now()
+	placement.Items = []*entity.PlacementItem{}
+	for rows.Next() {
+		var (
+			item      entity.PlacementItem
+			expiresAt sql.NullString
+			createdAt sql.NullString
+		)
+		if err := rows.Scan(&item.NewsID, &item.Title, &item.Position, &expiresAt, &item.CreatedBy, &createdAt); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		if expiresAt.Valid {
+			t := parseDateTime(expiresAt)
+			item.ExpiresAt = &t
+			item.Expired = !t.After(now)
+		}
+		item.CreatedAt = createdAt.String
+		placement.Items = append(placement.Items, &item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return placement, nil
+}
+
+// CreatePlacement inserts a placement
+func (r *PlacementRepositoryImpl) CreatePlacement(placement *entity.Placement, req *http.Request) error {
+	ctx := req.Context()
+	categoryID, err := r.checkPlacement(ctx, placement.Name, &placement.Backfill, placement.BackfillCategoryID, 0)
+	if err != nil {
+		return err
+	}
+
+	result, err := r.app.MDB.ExecContext(ctx, `
+		INSERT INTO placements (name, title, max_items, backfill, backfill_category_id, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)`,
+		placement.Name, placement.Title, placement.MaxItems, placement.Backfill, categoryID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create placement: %w", err)
+	}
+	if id, err := result.LastInsertId(); err == nil {
+		placement.ID = uint(id)
+	}
+
+	// Clear cache
+	return CacheClear(req, r.app.Cache, placement.Name)
+}
+
+// UpdatePlacement replaces the placement settings. Pinned articles are kept.
+func (r *PlacementRepositoryImpl) UpdatePlacement(oldPlacement *entity.Placement, placement *entity.UpdatePlacement, req *http.Request) error {
+	ctx := req.Context()
+	categoryID, err := r.checkPlacement(ctx, placement.Name, &placement.Backfill, placement.BackfillCategoryID, oldPlacement.ID)
+	if err != nil {
+		return err
+	}
+
+	if _, err := r.app.MDB.ExecContext(ctx, `
+		UPDATE placements
+		SET name = ?, title = ?, max_items = ?, backfill = ?, backfill_category_id = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ?`,
+		placement.Name, placement.Title, placement.MaxItems, placement.Backfill, categoryID, oldPlacement.ID,
+	); err != nil {
+		return fmt.Errorf("failed to update placement: %w", err)
+	}
+
+	// Clear cache of both names when the placement is renamed
+	return CacheClear(req, r.app.Cache, oldPlacement.Name, placement.Name)
+}
+
+// DeletePlacement deletes a placement with its pinned articles
+func (r *PlacementRepositoryImpl) DeletePlacement(placement *entity.Placement, req *http.Request) error {
+	ctx := req.Context()
+	tx, err := r.app.MDB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, query := range []string{
+		"DELETE FROM placement_items WHERE placement_id = ?",
+		"DELETE FROM placements WHERE id = ?",
+	} {
+		if _, err := tx.ExecContext(ctx, query, placement.ID); err != nil {
+			return fmt.Errorf("failed to delete placement: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	// Clear cache
+	return CacheClear(req, r.app.Cache, placement.Name)
+}
+
+// PinNews pins an article at a position of the placement, moving the articles
+// below it down. Expired pins are dropped first and do not count towards the
+// size of the placement.
+func (r *PlacementRepositoryImpl) PinNews(placement *entity.Placement, pin *entity.PinNews, req *http.Request) error {
+	ctx := req.Context()
+	if pin.ExpiresAt != nil && !pin.ExpiresAt.After(time.Now()) {
+		return fmt.Errorf("%w: expires_at must be in the future", entity.ErrInvalidPlacement)
+	}
+
+	tx, err := r.app.MDB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Pins of a placement are serialized on its row
+	if err := lockPlacement(ctx, tx, placement.ID); err != nil {
+		return err
+	}
+	var found int
+	if err := tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM news WHERE id = ?", pin.NewsID).Scan(&found); err != nil {
+		return fmt.Errorf("failed to check news: %w", err)
+	}
+	if found == 0 {
+		return fmt.Errorf("%w: news %d does not exist", entity.ErrInvalidPlacement, pin.NewsID)
+	}
+	if _, err := tx.ExecContext(ctx, `
+		DELETE FROM placement_items
+		WHERE placement_id = ? AND (news_id = ? OR expires_at <= UTC_TIMESTAMP())`, placement.ID, pin.NewsID); err != nil {
+		return fmt.Errorf("failed to unpin news: %w", err)
+	}
+
+	ids, err := pinnedNews(ctx, tx, placement.ID)
+	if err != nil {
+		return err
+	}
+	if uint(len(ids)) >= placement.MaxItems {
+		return fmt.Errorf("%w: the placement already holds %d pinned articles", entity.ErrInvalidPlacement, placement.MaxItems)
+	}
+	position := 0
+	if pin.Position != nil && int(*pin.Position) < len(ids) {
+		position = int(*pin.Position)
+	} else if pin.Position != nil {
+		position = len(ids)
+	}
+	ids = append(ids[:position], append([]uint{pin.NewsID}, ids[position:]...)...)
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO placement_items (placement_id, news_id, position, expires_at, created_by, created_at)
+		VALUES (?, ?, ?, ?, ?, CURRENT_TIMESTAMP)`,
+		placement.ID, pin.NewsID, position, nullableTime(pin.ExpiresAt), nullableID(pin.CreatedBy),
+	); err != nil {
+		return fmt.Errorf("failed to pin news: %w", err)
+	}
+	if err := writePositions(ctx, tx, placement.ID, ids); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	// Clear cache
+	return CacheClear(req, r.app.Cache, placement.Name)
+}
+
+// UnpinNews removes an article from the placement, the articles below it move up
+func (r *PlacementRepositoryImpl) UnpinNews(placement *entity.Placement, newsID uint, req *http.Request) error {
+	ctx := req.Context()
+	tx, err := r.app.MDB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := lockPlacement(ctx, tx, placement.ID); err != nil {
+		return err
+	}
+	result, err := tx.ExecContext(ctx, "DELETE FROM placement_items WHERE placement_id = ? AND news_id = ?", placement.ID, newsID)
+	if err != nil {
+		return fmt.Errorf("failed to unpin news: %w", err)
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return fmt.Errorf("%w: news %d is not pinned", entity.ErrPlacementNotFound, newsID)
+	}
+	ids, err := pinnedNews(ctx, tx, placement.ID)
+	if err != nil {
+		return err
+	}
+	if err := writePositions(ctx, tx, placement.ID, ids); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	// Clear cache
+	return CacheClear(req, r.app.Cache, placement.Name)
+}
+
+// OrderItems sets the order of the pinned articles. newsIDs must list every
+// pinned article of the placement, expired ones included, exactly once.
+func (r *PlacementRepositoryImpl) OrderItems(placement *entity.Placement, newsIDs []uint, req *http.Request) error {
+	ctx := req.Context()
+	tx, err := r.app.MDB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := lockPlacement(ctx, tx, placement.ID); err != nil {
+		return err
+	}
+	ids, err := pinnedNews(ctx, tx, placement.ID)
+	if err != nil {
+		return err
+	}
+	pinned := make(map[uint]bool, len(ids))
+	for _, id := range ids {
+		pinned[id] = true
+	}
+	for _, id := range newsIDs {
+		if !pinned[id] {
+			return fmt.Errorf("%w: news %d is not pinned or listed twice", entity.ErrInvalidPlacement, id)
+		}
+		delete(pinned, id)
+	}
+	if len(pinned) > 0 {
+		return fmt.Errorf("%w: every pinned article must be listed", entity.ErrInvalidPlacement)
+	}
+	if err := writePositions(ctx, tx, placement.ID, newsIDs); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	// Clear cache
+	return CacheClear(req, r.app.Cache, placement.Name)
+}
+
+// checkPlacement validates the name and backfill rule of a placement, defaulting
+// the rule to none. Returns the backfill category to store, nil unless the rule
+// is category.
+func (r *PlacementRepositoryImpl) checkPlacement(ctx context.Context, name string, backfill *string, categoryID, exceptID uint) (interface{}, error) {
+	if !namePattern.MatchString(name) {
+		return nil, fmt.Errorf("%w: name must be lowercase letters, digits and dashes", entity.ErrInvalidPlacement)
+	}
+	var count int
+	if err := r.app.MDB.QueryRowContext(ctx, "SELECT COUNT(*) FROM placements WHERE name = ? AND id <> ?", name, exceptID).Scan(&count); err != nil {
+		return nil, fmt.Errorf("failed to check name: %w", err)
+	}
+	if count > 0 {
+		return nil, entity.ErrPlacementExists
+	}
+
+	if *backfill == "" {
+		*backfill = entity.BackfillNone
+	}
+	if *backfill != entity.BackfillCategory {
+		return nil, nil
+	}
+	if categoryID == 0 {
+		return nil, fmt.Errorf("%w: backfill_category_id is required by the category rule", entity.ErrInvalidPlacement)
+	}
+	if err := r.app.MDB.QueryRowContext(ctx, "SELECT COUNT(*) FROM news_categories WHERE id = ?", categoryID).Scan(&count); err != nil {
+		return nil, fmt.Errorf("failed to check category: %w", err)
+	}
+	if count == 0 {
+		return nil, fmt.Errorf("%w: category %d does not exist", entity.ErrInvalidPlacement, categoryID)
+	}
+	return categoryID, nil
+}
+
+// lockPlacement locks the placement row until the transaction ends
+func lockPlacement(ctx context.Context, tx *sql.Tx, placementID uint) error {
+	var id uint
+	err := tx.QueryRowContext(ctx, "SELECT id FROM placements WHERE id = ? FOR UPDATE", placementID).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return entity.ErrPlacementNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to lock placement: %w", err)
+	}
+	return nil
+}
+
+// pinnedNews returns the pinned articles of a placement in their order
+func pinnedNews(ctx context.Context, tx *sql.Tx, placementID uint) ([]uint, error) {
+	rows, err := tx.QueryContext(ctx, "SELECT news_id FROM placement_items WHERE placement_id = ? ORDER BY position ASC, news_id DESC", placementID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query placement items: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []uint
+	for rows.Next() {
+		var id uint
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return ids, nil
+}
+
+// writePositions numbers the pinned articles of a placement in the order of ids
+func writePositions(ctx context.Context, tx *sql.Tx, placementID uint, ids []uint) error {
+	for position, id := range ids {
+		if _, err := tx.ExecContext(ctx, "UPDATE placement_items SET position = ? WHERE placement_id = ? AND news_id = ?", position, placementID, id); err != nil {
+			return fmt.Errorf("failed to order placement items: %w", err)
+		}
+	}
+	return nil
+}
+
+// nullableTime stores an optional time as a UTC DATETIME
+func nullableTime(t *time.Time) interface{} {
+	if t == nil || t.IsZero() {
+		return nil
+	}
+	return t.UTC().Format(time.DateTime)
+}
+
+// nullableID stores 0 as NULL
+func nullableID(id uint) interface{} {
+	if id == 0 {
+		return nil
+	}
+	return id
+}
+
+// parseDateTime converts a MySQL DATETIME column into time.Time, returning the zero time for NULL
+func parseDateTime(value sql.NullString) time.Time {
+	if !value.Valid {
+		return time.Time{}
+	}
+	t, _ := time.Parse("2006-01-02 15:04:05", value.String)
+	return t
+}
@@ -0,0 +1,250 @@
+package placementHttp
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/JubaerHossain/cn-api/domain/placements/entity"
+	"github.com/JubaerHossain/cn-api/domain/placements/service"
+	"github.com/JubaerHossain/rootx/pkg/core/app"
+	utilQuery "github.com/JubaerHossain/rootx/pkg/query"
+	"github.com/JubaerHossain/rootx/pkg/utils"
+)
+
+// Handler handles API requests
+type Handler struct {
+	App *service.Service
+}
+
+// NewHandler creates a new instance of Handler
+func NewHandler(app *app.App) *Handler {
+	return &Handler{
+		App: service.NewService(app),
+	}
+}
+
+// @Summary Get all placements
+// @Description Get all homepage placements with their number of pinned articles
+// @Tags placements
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param page query int false "Page number"
+// @Param limit query int false "Number of items per page"
+// @Param search query string false "Search query"
+// @Success 200 {object} entity.PlacementResponsePagination
+// @Router /placements [get]
+func (h *Handler) GetPlacements(w http.ResponseWriter, r *http.Request) {
+	placements, err := h.App.GetPlacements(r)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to fetch placements")
+		return
+	}
+	// Write response
+	utils.JsonResponse(w, http.StatusOK, map[string]interface{}{
+		"results": placements,
+	})
+}
+
+// @Summary Create a new Placement
+// @Description Create a named homepage slot. Places that no pinned article takes are filled by the backfill rule.
+// @Tags placements
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Param placement body entity.Placement true "The Placement to be created"
+// @Router /placements [post]
+func (h *Handler) CreatePlacement(w http.ResponseWriter, r *http.Request) {
+	var newPlacement entity.Placement
+
+	pareErr := utilQuery.BodyParse(&newPlacement, w, r, true) // Parse request body and validate it
+	if pareErr != nil {
+		return
+	}
+	newPlacement.Items = nil
+
+	if err := h.App.CreatePlacement(&newPlacement, r); err != nil {
+		writePlacementError(w, err)
+		return
+	}
+
+	// Write response
+	utils.WriteJSONResponse(w, http.StatusCreated, map[string]interface{}{
+		"message": "Placement created successfully",
+		"results": newPlacement,
+	})
+}
+
+// @Summary Get detailed information about a Placement by ID
+// @Description Get a Placement with its pinned articles in their order, expired ones included
+// @Tags placements
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} entity.Placement
+// @Failure 404 {object} map[string]interface{}
+// @Param id path string true "The ID of the Placement"
+// @Router /placements/{id} [get]
+func (h *Handler) GetPlacementDetails(w http.ResponseWriter, r *http.Request) {
+	placement, err := h.App.GetPlacementDetails(r)
+	if err != nil {
+		writePlacementError(w, err)
+		return
+	}
+	// Write response
+	utils.WriteJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"message": "Placement fetched successfully",
+		"results": placement,
+	})
+}
+
+// @Summary Update an existing Placement
+// @Description Update the name, size and backfill rule of a Placement. Pinned articles are kept.
+// @Tags placements
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Param id path string true "The ID of the Placement"
+// @Param placement body entity.UpdatePlacement true "Updated Placement object"
+// @Router /placements/{id} [put]
+func (h *Handler) UpdatePlacement(w http.ResponseWriter, r *http.Request) {
+	var updatePlacement entity.UpdatePlacement
+	pareErr := utilQuery.BodyParse(&updatePlacement, w, r, true) // Parse request body and validate it
+	if pareErr != nil {
+		return
+	}
+
+	if err := h.App.UpdatePlacement(r, &updatePlacement); err != nil {
+		writePlacementError(w, err)
+		return
+	}
+
+	// Write response
+	utils.WriteJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"message": "Placement updated successfully",
+	})
+}
+
+// @Summary Delete a Placement
+// @Description Delete a Placement with its pinned articles
+// @Tags placements
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Param id path string true "The ID of the Placement"
+// @Router /placements/{id} [delete]
+func (h *Handler) DeletePlacement(w http.ResponseWriter, r *http.Request) {
+	if err := h.App.DeletePlacement(r); err != nil {
+		writePlacementError(w, err)
+		return
+	}
+	// Write response
+	utils.WriteJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"message": "Placement deleted successfully",
+	})
+}
+
+// @Summary Pin an article
+// @Description Pin an article to a Placement, at the top unless a position is given. Pinning a pinned article moves it and replaces its expiry.
+// @Tags placements
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Param id path string true "The ID of the Placement"
+// @Param pin body entity.PinNews true "The article to pin"
+// @Router /placements/{id}/items [post]
+func (h *Handler) PinNews(w http.ResponseWriter, r *http.Request) {
+	var pin entity.PinNews
+	pareErr := utilQuery.BodyParse(&pin, w, r, true) // Parse request body and validate it
+	if pareErr != nil {
+		return
+	}
+
+	if err := h.App.PinNews(r, &pin); err != nil {
+		writePlacementError(w, err)
+		return
+	}
+
+	// Write response
+	utils.WriteJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"message": "News pinned successfully",
+	})
+}
+
+// @Summary Reorder pinned articles
+// @Description Set the order of the pinned articles of a Placement. Every pinned article must be listed once.
+// @Tags placements
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Param id path string true "The ID of the Placement"
+// @Param order body entity.OrderItems true "Pinned articles in their new order"
+// @Router /placements/{id}/items [put]
+func (h *Handler) OrderItems(w http.ResponseWriter, r *http.Request) {
+	var order entity.OrderItems
+	pareErr := utilQuery.BodyParse(&order, w, r, true) // Parse request body and validate it
+	if pareErr != nil {
+		return
+	}
+
+	if err := h.App.OrderItems(r, &order); err != nil {
+		writePlacementError(w, err)
+		return
+	}
+
+	// Write response
+	utils.WriteJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"message": "Placement items ordered successfully",
+	})
+}
+
+// @Summary Unpin an article
+// @Description Remove an article from a Placement
+// @Tags placements
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Param id path string true "The ID of the Placement"
+// @Param newsId path string true "The ID of the pinned article"
+// @Router /placements/{id}/items/{newsId} [delete]
+func (h *Handler) UnpinNews(w http.ResponseWriter, r *http.Request) {
+	if err := h.App.UnpinNews(r); err != nil {
+		writePlacementError(w, err)
+		return
+	}
+	// Write response
+	utils.WriteJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"message": "News unpinned successfully",
+	})
+}
+
+// writePlacementError maps placement errors to HTTP statuses
+func writePlacementError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, entity.ErrInvalidPlacement):
+		utils.WriteJSONError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, entity.ErrPlacementNotFound):
+		utils.WriteJSONError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, entity.ErrPlacementExists):
+		utils.WriteJSONError(w, http.StatusConflict, err.Error())
+	default:
+		utils.WriteJSONError(w, http.StatusInternalServerError, err.Error())
+	}
+}
@@ -0,0 +1,32 @@
+package placementHttp
+
+import (
+	"net/http"
+
+	authMiddleware "github.com/JubaerHossain/cn-api/pkg/middleware"
+	"github.com/JubaerHossain/rootx/pkg/core/app"
+	"github.com/JubaerHossain/rootx/pkg/core/middleware"
+)
+
+// PlacementAdminRouter registers placement management routes for editors and
+// admins. The articles of a placement, GET /placements/{name}, are served by
+// the news router.
+func PlacementAdminRouter(router *http.ServeMux, application *app.App) http.Handler {
+
+	handler := NewHandler(application)
+	protect := func(h http.HandlerFunc) http.Handler {
+		return middleware.LimiterMiddleware(authMiddleware.AuthMiddleware(application,
+			authMiddleware.RoleMiddleware(h, authMiddleware.RoleEditor, authMiddleware.RoleAdmin)))
+	}
+
+	router.Handle("GET /placements", protect(handler.GetPlacements))
+	router.Handle("POST /placements", protect(handler.CreatePlacement))
+	router.Handle("GET /placements/{id}", protect(handler.GetPlacementDetails))
+	router.Handle("PUT /placements/{id}", protect(handler.UpdatePlacement))
+	router.Handle("DELETE /placements/{id}", protect(handler.DeletePlacement))
+	router.Handle("POST /placements/{id}/items", protect(handler.PinNews))
+	router.Handle("PUT /placements/{id}/items", protect(handler.OrderItems))
+	router.Handle("DELETE /placements/{id}/items/{newsId}", protect(handler.UnpinNews))
+
+	return router
+}
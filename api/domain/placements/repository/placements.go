@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"net/http"
+
+	"github.com/JubaerHossain/cn-api/domain/placements/entity"
+)
+
+// PlacementRepository defines methods for placement data access
+type PlacementRepository interface {
+	GetPlacements(r *http.Request) (*entity.PlacementResponsePagination, error)
+	GetPlacementByID(placementID uint) (*entity.Placement, error)
+	GetPlacement(r *http.Request, placementID uint) (*entity.Placement, error)
+	CreatePlacement(placement *entity.Placement, r *http.Request) error
+	UpdatePlacement(oldPlacement *entity.Placement, placement *entity.UpdatePlacement, r *http.Request) error
+	DeletePlacement(placement *entity.Placement, r *http.Request) error
+	PinNews(placement *entity.Placement, pin *entity.PinNews, r *http.Request) error
+	UnpinNews(placement *entity.Placement, newsID uint, r *http.Request) error
+	OrderItems(placement *entity.Placement, newsIDs []uint, r *http.Request) error
+}
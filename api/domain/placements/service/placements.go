@@ -0,0 +1,164 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	newsEntity "github.com/JubaerHossain/cn-api/domain/news/entity"
+	newsService "github.com/JubaerHossain/cn-api/domain/news/service"
+	"github.com/JubaerHossain/cn-api/domain/placements/entity"
+	"github.com/JubaerHossain/cn-api/domain/placements/infrastructure/persistence"
+	"github.com/JubaerHossain/cn-api/domain/placements/repository"
+	"github.com/JubaerHossain/cn-api/pkg/middleware"
+	"github.com/JubaerHossain/rootx/pkg/core/app"
+	"go.uber.org/zap"
+)
+
+type Service struct {
+	app  *app.App
+	repo repository.PlacementRepository
+	news *newsService.Service // Pushes the changes of breaking placements
+}
+
+func NewService(app *app.App) *Service {
+	repo := persistence.NewPlacementRepository(app)
+	return &Service{
+		app:  app,
+		repo: repo,
+		news: newsService.NewService(app),
+	}
+}
+
+func (s *Service) GetPlacements(r *http.Request) (*entity.PlacementResponsePagination, error) {
+	// Call repository to get all placements
+	placements, placementErr := s.repo.GetPlacements(r)
+	if placementErr != nil {
+		s.app.Logger.Error("Error getting placements", zap.Error(placementErr))
+		return nil, placementErr
+	}
+	return placements, nil
+}
+
+// CreatePlacement creates a new placement
+func (s *Service) CreatePlacement(placement *entity.Placement, r *http.Request) error {
+	if err := s.repo.CreatePlacement(placement, r); err != nil {
+		s.logError("Error creating placement", err)
+		return err
+	}
+	return nil
+}
+
+func (s *Service) GetPlacementByID(r *http.Request) (*entity.Placement, error) {
+	id, err := strconv.ParseUint(r.PathValue("id"), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid placement ID", entity.ErrInvalidPlacement)
+	}
+	placement, placementErr := s.repo.GetPlacementByID(uint(id))
+	if placementErr != nil {
+		s.logError("Error getting placement", placementErr)
+		return nil, placementErr
+	}
+	return placement, nil
+}
+
+// GetPlacementDetails retrieves a placement with its pinned articles by ID
+func (s *Service) GetPlacementDetails(r *http.Request) (*entity.Placement, error) {
+	id, err := strconv.ParseUint(r.PathValue("id"), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid placement ID", entity.ErrInvalidPlacement)
+	}
+	placement, placementErr := s.repo.GetPlacement(r, uint(id))
+	if placementErr != nil {
+		s.logError("Error getting placement", placementErr)
+		return nil, placementErr
+	}
+	return placement, nil
+}
+
+// UpdatePlacement updates the settings of an existing placement
+func (s *Service) UpdatePlacement(r *http.Request, placement *entity.UpdatePlacement) error {
+	oldPlacement, err := s.GetPlacementByID(r)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.UpdatePlacement(oldPlacement, placement, r); err != nil {
+		s.logError("Error updating placement", err)
+		return err
+	}
+	return nil
+}
+
+// DeletePlacement deletes a placement by ID
+func (s *Service) DeletePlacement(r *http.Request) error {
+	placement, err := s.GetPlacementByID(r)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.DeletePlacement(placement, r); err != nil {
+		s.logError("Error deleting placement", err)
+		return err
+	}
+	return nil
+}
+
+// PinNews pins an article to the placement of the path
+func (s *Service) PinNews(r *http.Request, pin *entity.PinNews) error {
+	placement, err := s.GetPlacementByID(r)
+	if err != nil {
+		return err
+	}
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		return fmt.Errorf("unauthorized")
+	}
+	pin.CreatedBy = userID
+	if err := s.repo.PinNews(placement, pin, r); err != nil {
+		s.logError("Error pinning news", err)
+		return err
+	}
+	s.news.NotifyPlacement(r.Context(), newsEntity.LiveEnter, placement.Name, pin.NewsID)
+	return nil
+}
+
+// UnpinNews removes the article of the path from the placement
+func (s *Service) UnpinNews(r *http.Request) error {
+	placement, err := s.GetPlacementByID(r)
+	if err != nil {
+		return err
+	}
+	newsID, err := strconv.ParseUint(r.PathValue("newsId"), 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: invalid news ID", entity.ErrInvalidPlacement)
+	}
+	if err := s.repo.UnpinNews(placement, uint(newsID), r); err != nil {
+		s.logError("Error unpinning news", err)
+		return err
+	}
+	s.news.NotifyPlacement(r.Context(), newsEntity.LiveLeave, placement.Name, uint(newsID))
+	return nil
+}
+
+// OrderItems reorders the pinned articles of the placement
+func (s *Service) OrderItems(r *http.Request, order *entity.OrderItems) error {
+	placement, err := s.GetPlacementByID(r)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.OrderItems(placement, order.NewsIDs, r); err != nil {
+		s.logError("Error ordering placement items", err)
+		return err
+	}
+	s.news.NotifyPlacement(r.Context(), newsEntity.LiveOrder, placement.Name, 0)
+	return nil
+}
+
+// logError logs unexpected errors, the sentinel errors are reported to the client
+func (s *Service) logError(message string, err error) {
+	if errors.Is(err, entity.ErrPlacementNotFound) || errors.Is(err, entity.ErrPlacementExists) ||
+		errors.Is(err, entity.ErrInvalidPlacement) {
+		return
+	}
+	s.app.Logger.Error(message, zap.Error(err))
+}
@@ -0,0 +1,52 @@
+package entity
+
+import (
+	"errors"
+	"time"
+
+	"github.com/JubaerHossain/rootx/pkg/core/entity"
+)
+
+var (
+	// ErrTagNotFound is returned when a tag does not exist
+	ErrTagNotFound = errors.New("tag not found")
+	// ErrTagExists is returned when another tag already uses the slug
+	ErrTagExists = errors.New("a tag with this slug already exists")
+	// ErrInvalidTag is returned when no slug can be derived from the tag name
+	ErrInvalidTag = errors.New("invalid tag")
+	// ErrInvalidMerge is returned when the tags to merge are missing or include the target
+	ErrInvalidMerge = errors.New("invalid tag merge")
+)
+
+// Tag represents the tag entity. Tags are shared by all locales and matched on their slug.
+type Tag struct {
+	ID        uint      `json:"id"` // Primary key
+	Name      string    `json:"name" validate:"required,min=2,max=100"`
+	Slug      string    `json:"slug" validate:"omitempty,max=191"` // Derived from the name when empty
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// UpdateTag represents the tag update request. Renaming a tag renames it on every article.
+type UpdateTag struct {
+	Name string `json:"name" validate:"required,min=2,max=100"`
+	Slug string `json:"slug" validate:"omitempty,max=191"`
+}
+
+// MergeTags represents the request to fold tags into another one
+type MergeTags struct {
+	SourceIDs []uint `json:"source_ids" validate:"required,min=1,dive,gt=0"` // Tags moved into the target and deleted
+}
+
+// ResponseTag represents the tag response
+type ResponseTag struct {
+	ID        uint   `json:"id"`
+	Name      string `json:"name"`
+	Slug      string `json:"slug"`
+	NewsCount uint   `json:"news_count"` // Number of articles with the tag
+}
+
+type TagResponsePagination struct {
+	Data       []*ResponseTag    `json:"data"`
+	Pagination entity.Pagination `json:"pagination"`
+}
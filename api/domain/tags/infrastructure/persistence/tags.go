@@ -0,0 +1,417 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	newsPersistence "github.com/JubaerHossain/cn-api/domain/news/infrastructure/persistence"
+	"github.com/JubaerHossain/cn-api/domain/tags/entity"
+	"github.com/JubaerHossain/cn-api/domain/tags/repository"
+	utilQuery "github.com/JubaerHossain/cn-api/pkg/utils"
+	"github.com/JubaerHossain/rootx/pkg/core/app"
+)
+
+// newsCount counts the articles with the tag, whatever the locale
+const newsCount = "(SELECT COUNT(DISTINCT news_tags.news_id) FROM news_tags WHERE news_tags.tag_id = tags.id)"
+
+type TagRepositoryImpl struct {
+	app *app.App
+}
+
+// NewTagRepository returns a new instance of TagRepositoryImpl
+func NewTagRepository(app *app.App) repository.TagRepository {
+	return &TagRepositoryImpl{
+		app: app,
+	}
+}
+
+// GetTags returns a page of tags, filtered by the search query parameter and
+// sorted by name or, with sort=popular, by number of articles
+func (r *TagRepositoryImpl) GetTags(req *http.Request) (*entity.TagResponsePagination, error) {
+	ctx := req.Context()
+	cacheKey := fmt.Sprintf("get_all_tags_%s", req.URL.Query().Encode()) // Encode query parameters
+	if cachedData, errCache := r.app.Cache.Get(ctx, cacheKey); errCache == nil && cachedData != "" {
+		tags := &entity.TagResponsePagination{}
+		if err := json.Unmarshal([]byte(cachedData), tags); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal cached data: %w", err)
+		}
+		return tags, nil
+	}
+
+	baseQuery := "SELECT tags.id, tags.name, tags.slug, " + newsCount + " AS news_count FROM tags"
+
+	// Apply filters from query parameters
+	queryValues := req.URL.Query()
+	filterQuery := ""
+	var args []interface{}
+	if search := strings.TrimSpace(queryValues.Get("search")); search != "" {
+		filterQuery = " WHERE tags.name LIKE ?"
+		args = append(args, "%"+escapeLike(search)+"%")
+	}
+
+	sortBy := " ORDER BY tags.name ASC"
+	if queryValues.Get("sort") == "popular" {
+		sortBy = " ORDER BY news_count DESC, tags.name ASC"
+	}
+
+	// Pagination and limits
+	pagination, limit, offset, err := utilQuery.Paginate(req, r.app, baseQuery, filterQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("pagination error: %w", err)
+	}
+	query := fmt.Sprintf("%s%s%s LIMIT %d OFFSET %d", baseQuery, filterQuery, sortBy, limit, offset)
+
+	tags, err := r.queryTags(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	response := &entity.TagResponsePagination{
+		Data:       tags,
+		Pagination: pagination,
+	}
+
+	// Cache the response
+	jsonData, err := json.Marshal(response)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal response: %w", err)
+	}
+	if err := r.app.Cache.Set(ctx, cacheKey, string(jsonData), time.Duration(r.app.Config.RedisExp)*time.Second); err != nil {
+		return nil, fmt.Errorf("failed to set cache: %w", err)
+	}
+	return response, nil
+}
+
+// AutocompleteTags returns the most used tags whose name or slug starts with prefix
+func (r *TagRepositoryImpl) AutocompleteTags(req *http.Request, prefix string, limit int) ([]*entity.ResponseTag, error) {
+	ctx := req.Context()
+	cacheKey := fmt.Sprintf("tag_autocomplete_%s_%d", strings.ToLower(prefix), limit)
+	if cachedData, errCache := r.app.Cache.Get(ctx, cacheKey); errCache == nil && cachedData != "" {
+		tags := []*entity.ResponseTag{}
+		if err := json.Unmarshal([]byte(cachedData), &tags); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal cached data: %w", err)
+		}
+		return tags, nil
+	}
+
+	tags, err := r.queryTags(ctx, `
+		SELECT tags.id, tags.name, tags.slug, `+newsCount+` AS news_count
+		FROM tags
+		WHERE tags.name LIKE ? OR tags.slug LIKE ?
+		ORDER BY news_count DESC, tags.name ASC
+		LIMIT ?`,
+		escapeLike(prefix)+"%", escapeLike(utilQuery.Slugify(prefix))+"%", limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// Cache the response
+	jsonData, err := json.Marshal(tags)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal response: %w", err)
+	}
+	if err := r.app.Cache.Set(ctx, cacheKey, string(jsonData), time.Duration(r.app.Config.RedisExp)*time.Second); err != nil {
+		return nil, fmt.Errorf("failed to set cache: %w", err)
+	}
+	return tags, nil
+}
+
+// queryTags runs a query selecting id, name, slug and news_count
+func (r *TagRepositoryImpl) queryTags(ctx context.Context, query string, args ...interface{}) ([]*entity.ResponseTag, error) {
+	rows, err := r.app.MDB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tags: %w", err)
+	}
+	defer rows.Close()
+
+	tags := []*entity.ResponseTag{}
+	for rows.Next() {
+		var tag entity.ResponseTag
+		if err := rows.Scan(&tag.ID, &tag.Name, &tag.Slug, &tag.NewsCount); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		tags = append(tags, &tag)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return tags, nil
+}
+
+// GetTagByID returns the tag row by ID from the database
+func (r *TagRepositoryImpl) GetTagByID(tagID uint) (*entity.Tag, error) {
+	tag := &entity.Tag{}
+	var createdAt, updatedAt sql.NullString
+	err := r.app.MDB.QueryRow("SELECT id, name, slug, created_at, updated_at FROM tags WHERE id = ?", tagID).
+		Scan(&tag.ID, &tag.Name, &tag.Slug, &createdAt, &updatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, entity.ErrTagNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tag: %w", err)
+	}
+	tag.CreatedAt = parseDateTime(createdAt)
+	tag.UpdatedAt = parseDateTime(updatedAt)
+	return tag, nil
+}
+
+// GetTag returns a tag with its number of articles
+func (r *TagRepositoryImpl) GetTag(tagID uint) (*entity.ResponseTag, error) {
+	tags, err := r.queryTags(context.Background(), "SELECT tags.id, tags.name, tags.slug, "+newsCount+" AS news_count FROM tags WHERE tags.id = ?", tagID)
+	if err != nil {
+		return nil, err
+	}
+	if len(tags) == 0 {
+		return nil, entity.ErrTagNotFound
+	}
+	return tags[0], nil
+}
+
+// CreateTag inserts a tag
+func (r *TagRepositoryImpl) CreateTag(tag *entity.Tag, req *http.Request) error {
+	ctx := req.Context()
+	tag.Name = strings.Join(strings.Fields(tag.Name), " ")
+	tag.Slug = tagSlug(tag.Slug, tag.Name)
+	if tag.Slug == "" {
+		return fmt.Errorf("%w: could not derive a slug from the name", entity.ErrInvalidTag)
+	}
+	if err := r.checkSlug(ctx, tag.Slug, 0); err != nil {
+		return err
+	}
+
+	result, err := r.app.MDB.ExecContext(ctx, "INSERT INTO tags (name, slug, created_at, updated_at) VALUES (?, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)", tag.Name, tag.Slug)
+	if err != nil {
+		return fmt.Errorf("failed to create tag: %w", err)
+	}
+	if id, err := result.LastInsertId(); err == nil {
+		tag.ID = uint(id)
+	}
+
+	// Clear cache
+	return newsPersistence.CacheClear(req, r.app.Cache)
+}
+
+// UpdateTag renames a tag and refreshes the tag copies of its articles
+func (r *TagRepositoryImpl) UpdateTag(oldTag *entity.Tag, tag *entity.UpdateTag, req *http.Request) error {
+	ctx := req.Context()
+	name := strings.Join(strings.Fields(tag.Name), " ")
+	slug := tagSlug(tag.Slug, name)
+	if slug == "" {
+		return fmt.Errorf("%w: could not derive a slug from the name", entity.ErrInvalidTag)
+	}
+	if err := r.checkSlug(ctx, slug, oldTag.ID); err != nil {
+		return err
+	}
+
+	tx, err := r.app.MDB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "UPDATE tags SET name = ?, slug = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?", name, slug, oldTag.ID); err != nil {
+		return fmt.Errorf("failed to update tag: %w", err)
+	}
+	if err := refreshTranslationTags(ctx, tx, []uint{oldTag.ID}); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	// Clear cache
+	return newsPersistence.CacheClear(req, r.app.Cache)
+}
+
+// DeleteTag removes a tag from every article and deletes it
+func (r *TagRepositoryImpl) DeleteTag(tag *entity.Tag, req *http.Request) error {
+	ctx := req.Context()
+	tx, err := r.app.MDB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	newsIDs, err := taggedNews(ctx, tx, []uint{tag.ID})
+	if err != nil {
+		return err
+	}
+	for _, query := range []string{
+		"DELETE FROM news_tags WHERE tag_id = ?",
+		"DELETE FROM tags WHERE id = ?",
+	} {
+		if _, err := tx.ExecContext(ctx, query, tag.ID); err != nil {
+			return fmt.Errorf("failed to delete tag: %w", err)
+		}
+	}
+	if err := refreshNewsTags(ctx, tx, newsIDs); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	// Clear cache
+	return newsPersistence.CacheClear(req, r.app.Cache)
+}
+
+// MergeTags moves the articles of the source tags to the target and deletes the
+// sources. An article that already has the target keeps its position for it.
+func (r *TagRepositoryImpl) MergeTags(target *entity.Tag, sourceIDs []uint, req *http.Request) error {
+	ctx := req.Context()
+	seen := map[uint]bool{}
+	var ids []uint
+	for _, id := range sourceIDs {
+		if id == target.ID {
+			return fmt.Errorf("%w: a tag cannot be merged into itself", entity.ErrInvalidMerge)
+		}
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	tx, err := r.app.MDB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(ids)), ",")
+	args := make([]interface{}, 0, len(ids))
+	for _, id := range ids {
+		args = append(args, id)
+	}
+	var found int
+	if err := tx.QueryRowContext(ctx, fmt.Sprintf("SELECT COUNT(*) FROM tags WHERE id IN (%s) FOR UPDATE", placeholders), args...).Scan(&found); err != nil {
+		return fmt.Errorf("failed to check tags: %w", err)
+	}
+	if found != len(ids) {
+		return fmt.Errorf("%w: some source tags do not exist", entity.ErrInvalidMerge)
+	}
+
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf(`
+		INSERT IGNORE INTO news_tags (news_id, locale, tag_id, position)
+		SELECT news_id, locale, ?, MIN(position) FROM news_tags
+		WHERE tag_id IN (%s)
+		GROUP BY news_id, locale`, placeholders), append([]interface{}{target.ID}, args...)...); err != nil {
+		return fmt.Errorf("failed to move tagged articles: %w", err)
+	}
+	for _, query := range []string{
+		"DELETE FROM news_tags WHERE tag_id IN (%s)",
+		"DELETE FROM tags WHERE id IN (%s)",
+	} {
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf(query, placeholders), args...); err != nil {
+			return fmt.Errorf("failed to delete merged tags: %w", err)
+		}
+	}
+	if err := refreshTranslationTags(ctx, tx, []uint{target.ID}); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	// Clear cache
+	return newsPersistence.CacheClear(req, r.app.Cache)
+}
+
+// checkSlug returns ErrTagExists when a tag other than exceptID uses the slug
+func (r *TagRepositoryImpl) checkSlug(ctx context.Context, slug string, exceptID uint) error {
+	var count int
+	if err := r.app.MDB.QueryRowContext(ctx, "SELECT COUNT(*) FROM tags WHERE slug = ? AND id <> ?", slug, exceptID).Scan(&count); err != nil {
+		return fmt.Errorf("failed to check slug: %w", err)
+	}
+	if count > 0 {
+		return entity.ErrTagExists
+	}
+	return nil
+}
+
+// refreshTranslationTags rewrites the tag copies of the articles with the tags
+func refreshTranslationTags(ctx context.Context, tx *sql.Tx, tagIDs []uint) error {
+	newsIDs, err := taggedNews(ctx, tx, tagIDs)
+	if err != nil {
+		return err
+	}
+	return refreshNewsTags(ctx, tx, newsIDs)
+}
+
+// taggedNews returns the articles with any of the tags
+func taggedNews(ctx context.Context, tx *sql.Tx, tagIDs []uint) ([]interface{}, error) {
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(tagIDs)), ",")
+	args := make([]interface{}, 0, len(tagIDs))
+	for _, id := range tagIDs {
+		args = append(args, id)
+	}
+	rows, err := tx.QueryContext(ctx, fmt.Sprintf("SELECT DISTINCT news_id FROM news_tags WHERE tag_id IN (%s)", placeholders), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tagged news: %w", err)
+	}
+	defer rows.Close()
+
+	var newsIDs []interface{}
+	for rows.Next() {
+		var id uint
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		newsIDs = append(newsIDs, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return newsIDs, nil
+}
+
+// refreshNewsTags rewrites news_translations.tags, the copy of the tag names
+// kept for the search index, from the tag rows of the articles
+func refreshNewsTags(ctx context.Context, tx *sql.Tx, newsIDs []interface{}) error {
+	if len(newsIDs) == 0 {
+		return nil
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(newsIDs)), ",")
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf(`
+		UPDATE news_translations
+		SET tags = COALESCE((
+			SELECT JSON_ARRAYAGG(tags.name)
+			FROM news_tags
+			JOIN tags ON tags.id = news_tags.tag_id
+			WHERE news_tags.news_id = news_translations.news_id AND news_tags.locale = news_translations.locale
+		), JSON_ARRAY())
+		WHERE news_id IN (%s)`, placeholders), newsIDs...); err != nil {
+		return fmt.Errorf("failed to refresh translation tags: %w", err)
+	}
+	return nil
+}
+
+// tagSlug returns the requested slug, or one derived from the name
+func tagSlug(slug, name string) string {
+	if slug = utilQuery.Slugify(slug); slug != "" {
+		return slug
+	}
+	return utilQuery.Slugify(name)
+}
+
+// escapeLike escapes the LIKE wildcards in a user supplied pattern
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+// parseDateTime parses a DATETIME column scanned as text
+func parseDateTime(value sql.NullString) time.Time {
+	if !value.Valid {
+		return time.Time{}
+	}
+	t, _ := time.Parse("2006-01-02 15:04:05", value.String)
+	return t
+}
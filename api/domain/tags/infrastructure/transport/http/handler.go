@@ -0,0 +1,217 @@
+package tagHttp
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/JubaerHossain/cn-api/domain/tags/entity"
+	"github.com/JubaerHossain/cn-api/domain/tags/service"
+	"github.com/JubaerHossain/rootx/pkg/core/app"
+	utilQuery "github.com/JubaerHossain/rootx/pkg/query"
+	"github.com/JubaerHossain/rootx/pkg/utils"
+)
+
+// Handler handles API requests
+type Handler struct {
+	App *service.Service
+}
+
+// NewHandler creates a new instance of Handler
+func NewHandler(app *app.App) *Handler {
+	return &Handler{
+		App: service.NewService(app),
+	}
+}
+
+// @Summary Get all tags
+// @Description Get all tags with their number of articles
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param page query int false "Page number"
+// @Param limit query int false "Number of items per page"
+// @Param search query string false "Search query"
+// @Param sort query string false "Sort by name (default) or popular"
+// @Success 200 {object} entity.TagResponsePagination
+// @Router /tags [get]
+func (h *Handler) GetTags(w http.ResponseWriter, r *http.Request) {
+	tags, err := h.App.GetTags(r)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to fetch tags")
+		return
+	}
+	// Write response
+	utils.JsonResponse(w, http.StatusOK, map[string]interface{}{
+		"results": tags,
+	})
+}
+
+// @Summary Autocomplete tags
+// @Description Suggest the most used tags whose name starts with the query
+// @Tags tags
+// @Produce json
+// @Param q query string true "Tag name prefix"
+// @Param limit query int false "Number of suggestions (default 10, max 20)"
+// @Success 200 {array} entity.ResponseTag
+// @Failure 400 {object} map[string]interface{}
+// @Router /tags/autocomplete [get]
+func (h *Handler) AutocompleteTags(w http.ResponseWriter, r *http.Request) {
+	tags, err := h.App.AutocompleteTags(r)
+	if err != nil {
+		writeTagError(w, err)
+		return
+	}
+	// Write response
+	utils.JsonResponse(w, http.StatusOK, map[string]interface{}{
+		"results": tags,
+	})
+}
+
+// @Summary Create a new Tag
+// @Description Create a new Tag. The slug is derived from the name when omitted.
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 201 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Param tag body entity.Tag true "The Tag to be created"
+// @Router /tags [post]
+func (h *Handler) CreateTag(w http.ResponseWriter, r *http.Request) {
+	var newTag entity.Tag
+
+	pareErr := utilQuery.BodyParse(&newTag, w, r, true) // Parse request body and validate it
+	if pareErr != nil {
+		return
+	}
+
+	if err := h.App.CreateTag(&newTag, r); err != nil {
+		writeTagError(w, err)
+		return
+	}
+
+	// Write response
+	utils.WriteJSONResponse(w, http.StatusCreated, map[string]interface{}{
+		"message": "Tag created successfully",
+		"results": newTag,
+	})
+}
+
+// @Summary Get detailed information about a Tag by ID
+// @Description Get a Tag with its number of articles
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} entity.ResponseTag
+// @Failure 404 {object} map[string]interface{}
+// @Param id path string true "The ID of the Tag"
+// @Router /tags/{id} [get]
+func (h *Handler) GetTagDetails(w http.ResponseWriter, r *http.Request) {
+	tag, err := h.App.GetTagDetails(r)
+	if err != nil {
+		writeTagError(w, err)
+		return
+	}
+	// Write response
+	utils.WriteJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"message": "Tag fetched successfully",
+		"results": tag,
+	})
+}
+
+// @Summary Update an existing Tag
+// @Description Rename a Tag. The new name is applied to every article with the tag.
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Param id path string true "The ID of the Tag"
+// @Param tag body entity.UpdateTag true "Updated Tag object"
+// @Router /tags/{id} [put]
+func (h *Handler) UpdateTag(w http.ResponseWriter, r *http.Request) {
+	var updateTag entity.UpdateTag
+	pareErr := utilQuery.BodyParse(&updateTag, w, r, true) // Parse request body and validate it
+	if pareErr != nil {
+		return
+	}
+
+	if err := h.App.UpdateTag(r, &updateTag); err != nil {
+		writeTagError(w, err)
+		return
+	}
+
+	// Write response
+	utils.WriteJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"message": "Tag updated successfully",
+	})
+}
+
+// @Summary Delete a Tag
+// @Description Delete a Tag and remove it from every article
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Param id path string true "The ID of the Tag"
+// @Router /tags/{id} [delete]
+func (h *Handler) DeleteTag(w http.ResponseWriter, r *http.Request) {
+	if err := h.App.DeleteTag(r); err != nil {
+		writeTagError(w, err)
+		return
+	}
+	// Write response
+	utils.WriteJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"message": "Tag deleted successfully",
+	})
+}
+
+// @Summary Merge Tags
+// @Description Move the articles of the source tags to the Tag and delete the sources
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Param id path string true "The ID of the target Tag"
+// @Param merge body entity.MergeTags true "Tags to merge into the target"
+// @Router /tags/{id}/merge [post]
+func (h *Handler) MergeTags(w http.ResponseWriter, r *http.Request) {
+	var merge entity.MergeTags
+	pareErr := utilQuery.BodyParse(&merge, w, r, true) // Parse request body and validate it
+	if pareErr != nil {
+		return
+	}
+
+	if err := h.App.MergeTags(r, &merge); err != nil {
+		writeTagError(w, err)
+		return
+	}
+
+	// Write response
+	utils.WriteJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"message": "Tags merged successfully",
+	})
+}
+
+// writeTagError maps tag errors to HTTP statuses
+func writeTagError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, entity.ErrInvalidTag), errors.Is(err, entity.ErrInvalidMerge):
+		utils.WriteJSONError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, entity.ErrTagNotFound):
+		utils.WriteJSONError(w, http.StatusNotFound, "Tag not found")
+	case errors.Is(err, entity.ErrTagExists):
+		utils.WriteJSONError(w, http.StatusConflict, err.Error())
+	default:
+		utils.WriteJSONError(w, http.StatusInternalServerError, err.Error())
+	}
+}
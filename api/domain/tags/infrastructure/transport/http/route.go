@@ -0,0 +1,39 @@
+package tagHttp
+
+import (
+	"net/http"
+
+	authMiddleware "github.com/JubaerHossain/cn-api/pkg/middleware"
+	"github.com/JubaerHossain/rootx/pkg/core/app"
+	"github.com/JubaerHossain/rootx/pkg/core/middleware"
+)
+
+// TagRouter registers public routes for API endpoints
+func TagRouter(router *http.ServeMux, application *app.App) http.Handler {
+
+	handler := NewHandler(application)
+
+	// The article listing of a tag, GET /tags/{slug}, is served by the news router
+	router.Handle("GET /tags/autocomplete", middleware.LimiterMiddleware(http.HandlerFunc(handler.AutocompleteTags)))
+
+	return router
+}
+
+// TagAdminRouter registers tag management routes for editors and admins
+func TagAdminRouter(router *http.ServeMux, application *app.App) http.Handler {
+
+	handler := NewHandler(application)
+	protect := func(h http.HandlerFunc) http.Handler {
+		return middleware.LimiterMiddleware(authMiddleware.AuthMiddleware(application,
+			authMiddleware.RoleMiddleware(h, authMiddleware.RoleEditor, authMiddleware.RoleAdmin)))
+	}
+
+	router.Handle("GET /tags", protect(handler.GetTags))
+	router.Handle("POST /tags", protect(handler.CreateTag))
+	router.Handle("GET /tags/{id}", protect(handler.GetTagDetails))
+	router.Handle("PUT /tags/{id}", protect(handler.UpdateTag))
+	router.Handle("DELETE /tags/{id}", protect(handler.DeleteTag))
+	router.Handle("POST /tags/{id}/merge", protect(handler.MergeTags))
+
+	return router
+}
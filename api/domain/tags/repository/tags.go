@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"net/http"
+
+	"github.com/JubaerHossain/cn-api/domain/tags/entity"
+)
+
+// TagRepository defines methods for tag data access
+type TagRepository interface {
+	GetTags(r *http.Request) (*entity.TagResponsePagination, error)
+	AutocompleteTags(r *http.Request, prefix string, limit int) ([]*entity.ResponseTag, error)
+	GetTagByID(tagID uint) (*entity.Tag, error)
+	GetTag(tagID uint) (*entity.ResponseTag, error)
+	CreateTag(tag *entity.Tag, r *http.Request) error
+	UpdateTag(oldTag *entity.Tag, tag *entity.UpdateTag, r *http.Request) error
+	DeleteTag(tag *entity.Tag, r *http.Request) error
+	MergeTags(target *entity.Tag, sourceIDs []uint, r *http.Request) error
+}
@@ -0,0 +1,149 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/JubaerHossain/cn-api/domain/tags/entity"
+	"github.com/JubaerHossain/cn-api/domain/tags/infrastructure/persistence"
+	"github.com/JubaerHossain/cn-api/domain/tags/repository"
+	"github.com/JubaerHossain/rootx/pkg/core/app"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultAutocompleteLimit = 10
+	maxAutocompleteLimit     = 20
+)
+
+type Service struct {
+	app  *app.App
+	repo repository.TagRepository
+}
+
+func NewService(app *app.App) *Service {
+	repo := persistence.NewTagRepository(app)
+	return &Service{
+		app:  app,
+		repo: repo,
+	}
+}
+
+func (s *Service) GetTags(r *http.Request) (*entity.TagResponsePagination, error) {
+	// Call repository to get all tags
+	tags, tagErr := s.repo.GetTags(r)
+	if tagErr != nil {
+		s.app.Logger.Error("Error getting tags", zap.Error(tagErr))
+		return nil, tagErr
+	}
+	return tags, nil
+}
+
+// AutocompleteTags suggests tags starting with the q query parameter, most used first
+func (s *Service) AutocompleteTags(r *http.Request) ([]*entity.ResponseTag, error) {
+	prefix := strings.TrimSpace(r.URL.Query().Get("q"))
+	if prefix == "" {
+		return []*entity.ResponseTag{}, nil
+	}
+	limit := defaultAutocompleteLimit
+	if value := r.URL.Query().Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > maxAutocompleteLimit {
+			return nil, fmt.Errorf("%w: limit must be between 1 and %d", entity.ErrInvalidTag, maxAutocompleteLimit)
+		}
+		limit = parsed
+	}
+	tags, tagErr := s.repo.AutocompleteTags(r, prefix, limit)
+	if tagErr != nil {
+		s.app.Logger.Error("Error autocompleting tags", zap.Error(tagErr))
+		return nil, tagErr
+	}
+	return tags, nil
+}
+
+// CreateTag creates a new tag
+func (s *Service) CreateTag(tag *entity.Tag, r *http.Request) error {
+	if err := s.repo.CreateTag(tag, r); err != nil {
+		s.logError("Error creating tag", err)
+		return err
+	}
+	return nil
+}
+
+func (s *Service) GetTagByID(r *http.Request) (*entity.Tag, error) {
+	id, err := strconv.ParseUint(r.PathValue("id"), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid tag ID", entity.ErrInvalidTag)
+	}
+	tag, tagErr := s.repo.GetTagByID(uint(id))
+	if tagErr != nil {
+		s.logError("Error getting tag", tagErr)
+		return nil, tagErr
+	}
+	return tag, nil
+}
+
+// GetTagDetails retrieves a tag with its number of articles by ID
+func (s *Service) GetTagDetails(r *http.Request) (*entity.ResponseTag, error) {
+	id, err := strconv.ParseUint(r.PathValue("id"), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid tag ID", entity.ErrInvalidTag)
+	}
+	tag, tagErr := s.repo.GetTag(uint(id))
+	if tagErr != nil {
+		s.logError("Error getting tag", tagErr)
+		return nil, tagErr
+	}
+	return tag, nil
+}
+
+// UpdateTag renames an existing tag
+func (s *Service) UpdateTag(r *http.Request, tag *entity.UpdateTag) error {
+	oldTag, err := s.GetTagByID(r)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.UpdateTag(oldTag, tag, r); err != nil {
+		s.logError("Error updating tag", err)
+		return err
+	}
+	return nil
+}
+
+// DeleteTag deletes a tag by ID and removes it from its articles
+func (s *Service) DeleteTag(r *http.Request) error {
+	tag, err := s.GetTagByID(r)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.DeleteTag(tag, r); err != nil {
+		s.logError("Error deleting tag", err)
+		return err
+	}
+	return nil
+}
+
+// MergeTags folds the source tags into the tag of the path
+func (s *Service) MergeTags(r *http.Request, merge *entity.MergeTags) error {
+	target, err := s.GetTagByID(r)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.MergeTags(target, merge.SourceIDs, r); err != nil {
+		s.logError("Error merging tags", err)
+		return err
+	}
+	return nil
+}
+
+// logError logs unexpected errors, the sentinel errors are reported to the client
+func (s *Service) logError(message string, err error) {
+	if errors.Is(err, entity.ErrTagNotFound) || errors.Is(err, entity.ErrTagExists) ||
+		errors.Is(err, entity.ErrInvalidTag) || errors.Is(err, entity.ErrInvalidMerge) {
+		return
+	}
+	s.app.Logger.Error(message, zap.Error(err))
+}
@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS news_slug_redirects (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    news_id BIGINT UNSIGNED NOT NULL,
+    locale VARCHAR(10) NOT NULL,
+    slug VARCHAR(191) NOT NULL,
+    created_at TIMESTAMP NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE KEY news_slug_redirects_locale_slug_unique (locale, slug),
+    KEY news_slug_redirects_news_id_index (news_id)
+);
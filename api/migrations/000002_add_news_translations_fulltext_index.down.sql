@@ -0,0 +1 @@
+ALTER TABLE news_translations DROP INDEX news_translations_search_fulltext;
@@ -0,0 +1,2 @@
+ALTER TABLE news_translations
+    ADD FULLTEXT INDEX news_translations_search_fulltext (title, sub_title, content, tags);
@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS sitemaps (
+    name VARCHAR(64) NOT NULL PRIMARY KEY,
+    body LONGTEXT NOT NULL,
+    url_count INT UNSIGNED NOT NULL DEFAULT 0,
+    last_modified TIMESTAMP NULL DEFAULT NULL,
+    stale TINYINT(1) NOT NULL DEFAULT 1,
+    version BIGINT UNSIGNED NOT NULL DEFAULT 0,
+    generated_at TIMESTAMP NULL DEFAULT NULL
+);
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS news_workflow_transitions;
+
+ALTER TABLE news
+    DROP KEY news_publish_status_department_index,
+    DROP COLUMN department_id;
@@ -0,0 +1,15 @@
+ALTER TABLE news
+    ADD COLUMN department_id BIGINT UNSIGNED NULL DEFAULT NULL,
+    ADD KEY news_publish_status_department_index (publish_status_id, department_id);
+
+CREATE TABLE IF NOT EXISTS news_workflow_transitions (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    news_id BIGINT UNSIGNED NOT NULL,
+    from_status_id INT UNSIGNED NOT NULL,
+    to_status_id INT UNSIGNED NOT NULL,
+    comment TEXT NULL,
+    user_id BIGINT UNSIGNED NOT NULL,
+    role VARCHAR(32) NOT NULL,
+    created_at TIMESTAMP NULL DEFAULT CURRENT_TIMESTAMP,
+    KEY news_workflow_transitions_news_id_index (news_id)
+);
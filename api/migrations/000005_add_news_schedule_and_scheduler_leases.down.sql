@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS scheduler_leases;
+
+ALTER TABLE news
+    DROP KEY news_publish_status_publish_at_index,
+    DROP KEY news_publish_status_unpublish_at_index,
+    DROP COLUMN publish_at,
+    DROP COLUMN unpublish_at;
@@ -0,0 +1,11 @@
+ALTER TABLE news
+    ADD COLUMN publish_at DATETIME NULL DEFAULT NULL,
+    ADD COLUMN unpublish_at DATETIME NULL DEFAULT NULL,
+    ADD KEY news_publish_status_publish_at_index (publish_status_id, publish_at),
+    ADD KEY news_publish_status_unpublish_at_index (publish_status_id, unpublish_at);
+
+CREATE TABLE IF NOT EXISTS scheduler_leases (
+    name VARCHAR(64) NOT NULL PRIMARY KEY,
+    holder VARCHAR(191) NOT NULL,
+    expires_at DATETIME NOT NULL
+);
@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS news_revisions (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    news_id BIGINT UNSIGNED NOT NULL,
+    locale VARCHAR(10) NOT NULL,
+    number INT UNSIGNED NOT NULL,
+    title VARCHAR(191) NOT NULL,
+    slug VARCHAR(191) NOT NULL,
+    sub_title VARCHAR(500) NULL,
+    tags JSON NULL,
+    content LONGTEXT NULL,
+    meta_title VARCHAR(191) NULL,
+    meta_description VARCHAR(500) NULL,
+    meta_keywords JSON NULL,
+    author_id BIGINT UNSIGNED NOT NULL,
+    restored_from BIGINT UNSIGNED NULL DEFAULT NULL,
+    created_at TIMESTAMP NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE KEY news_revisions_news_locale_number_unique (news_id, locale, number)
+);
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS news_view_stats;
+
+ALTER TABLE news
+    DROP COLUMN view_count;
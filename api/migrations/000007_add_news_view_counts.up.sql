@@ -0,0 +1,10 @@
+ALTER TABLE news
+    ADD COLUMN view_count BIGINT UNSIGNED NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS news_view_stats (
+    news_id BIGINT UNSIGNED NOT NULL,
+    bucket DATETIME NOT NULL,
+    views BIGINT UNSIGNED NOT NULL DEFAULT 0,
+    PRIMARY KEY (news_id, bucket),
+    KEY news_view_stats_bucket_index (bucket)
+);
@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS tags (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    slug VARCHAR(191) NOT NULL,
+    created_at TIMESTAMP NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE KEY tags_slug_unique (slug),
+    KEY tags_name_index (name)
+);
+
+-- Tags of each translation, in their editorial order. news_translations.tags is
+-- kept as a copy for the full-text search index.
+CREATE TABLE IF NOT EXISTS news_tags (
+    news_id BIGINT UNSIGNED NOT NULL,
+    locale VARCHAR(10) NOT NULL,
+    tag_id BIGINT UNSIGNED NOT NULL,
+    position INT UNSIGNED NOT NULL DEFAULT 0,
+    PRIMARY KEY (news_id, locale, tag_id),
+    KEY news_tags_tag_id_index (tag_id, news_id)
+);
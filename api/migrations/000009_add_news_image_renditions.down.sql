@@ -0,0 +1,6 @@
+ALTER TABLE news
+    DROP COLUMN path_small_webp,
+    DROP COLUMN path_medium_webp,
+    DROP COLUMN path_large_webp,
+    DROP COLUMN image_placeholder,
+    DROP COLUMN image_files;
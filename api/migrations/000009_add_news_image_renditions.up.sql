@@ -0,0 +1,10 @@
+-- path_small, path_medium and path_large hold the JPEG or PNG renditions of the
+-- uploaded original; the WebP variants and the blur placeholder sit next to them.
+-- image_files lists the storage keys of the generated files, so they can be
+-- removed when the image is replaced or the article deleted.
+ALTER TABLE news
+    ADD COLUMN path_small_webp VARCHAR(255) NULL,
+    ADD COLUMN path_medium_webp VARCHAR(255) NULL,
+    ADD COLUMN path_large_webp VARCHAR(255) NULL,
+    ADD COLUMN image_placeholder TEXT NULL,
+    ADD COLUMN image_files JSON NULL;
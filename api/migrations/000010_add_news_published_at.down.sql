@@ -0,0 +1,3 @@
+ALTER TABLE news
+    DROP KEY news_published_at_id_index,
+    DROP COLUMN published_at;
@@ -0,0 +1,14 @@
+-- published_at is set the first time an article is published and orders the
+-- public lists; keyset cursors page over (published_at, id).
+ALTER TABLE news
+    ADD COLUMN published_at DATETIME NULL DEFAULT NULL,
+    ADD KEY news_published_at_id_index (published_at, id);
+
+-- Articles published before the column existed take their first publish
+-- transition, or their creation date when they have no workflow history
+UPDATE news
+SET published_at = COALESCE(
+    (SELECT MIN(t.created_at) FROM news_workflow_transitions t WHERE t.news_id = news.id AND t.to_status_id = 9),
+    news.created_at
+)
+WHERE news.publish_status_id = 9;
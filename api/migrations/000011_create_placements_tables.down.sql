@@ -0,0 +1,16 @@
+ALTER TABLE news
+    ADD COLUMN breaking_scroll_news TINYINT(1) NOT NULL DEFAULT 0,
+    ADD COLUMN breaking_thumb_news TINYINT(1) NOT NULL DEFAULT 0;
+
+UPDATE news
+JOIN placement_items ON placement_items.news_id = news.id
+JOIN placements ON placements.id = placement_items.placement_id AND placements.name = 'breaking-scroll'
+SET news.breaking_scroll_news = 1;
+
+UPDATE news
+JOIN placement_items ON placement_items.news_id = news.id
+JOIN placements ON placements.id = placement_items.placement_id AND placements.name = 'breaking-thumb'
+SET news.breaking_thumb_news = 1;
+
+DROP TABLE IF EXISTS placement_items;
+DROP TABLE IF EXISTS placements;
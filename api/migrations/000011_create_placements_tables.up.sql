@@ -0,0 +1,49 @@
+-- Named homepage slots. A slot serves its pinned articles first, in their
+-- position order, and fills the remaining places from its backfill rule:
+-- none, recent (latest published) or category (latest of backfill_category_id).
+CREATE TABLE IF NOT EXISTS placements (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    title VARCHAR(191) NOT NULL DEFAULT '',
+    max_items INT UNSIGNED NOT NULL DEFAULT 3,
+    backfill VARCHAR(20) NOT NULL DEFAULT 'none',
+    backfill_category_id BIGINT UNSIGNED NULL,
+    created_at TIMESTAMP NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE KEY placements_name_unique (name)
+);
+
+-- Pinned articles. An item with an expires_at in the past is no longer served.
+CREATE TABLE IF NOT EXISTS placement_items (
+    placement_id BIGINT UNSIGNED NOT NULL,
+    news_id BIGINT UNSIGNED NOT NULL,
+    position INT UNSIGNED NOT NULL DEFAULT 0,
+    expires_at DATETIME NULL DEFAULT NULL,
+    created_by BIGINT UNSIGNED NULL,
+    created_at TIMESTAMP NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (placement_id, news_id),
+    KEY placement_items_news_id_index (news_id)
+);
+
+-- The breaking flags become the two slots they used to feed, newest first as before
+INSERT INTO placements (name, title, max_items, backfill)
+VALUES ('breaking-scroll', 'Breaking scroll', 3, 'none'),
+       ('breaking-thumb', 'Breaking thumbnails', 3, 'none');
+
+INSERT INTO placement_items (placement_id, news_id, position)
+SELECT placements.id, news.id,
+       (SELECT COUNT(*) FROM news newer WHERE newer.breaking_scroll_news = 1 AND newer.id > news.id)
+FROM news
+JOIN placements ON placements.name = 'breaking-scroll'
+WHERE news.breaking_scroll_news = 1;
+
+INSERT INTO placement_items (placement_id, news_id, position)
+SELECT placements.id, news.id,
+       (SELECT COUNT(*) FROM news newer WHERE newer.breaking_thumb_news = 1 AND newer.id > news.id)
+FROM news
+JOIN placements ON placements.name = 'breaking-thumb'
+WHERE news.breaking_thumb_news = 1;
+
+ALTER TABLE news
+    DROP COLUMN breaking_scroll_news,
+    DROP COLUMN breaking_thumb_news;
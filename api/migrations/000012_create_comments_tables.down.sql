@@ -0,0 +1,5 @@
+ALTER TABLE news
+    DROP COLUMN comment_count;
+
+DROP TABLE IF EXISTS comment_moderations;
+DROP TABLE IF EXISTS comments;
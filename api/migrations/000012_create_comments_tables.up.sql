@@ -0,0 +1,33 @@
+-- Reader comments. A reply keeps the first comment of its thread in root_id so
+-- a thread loads in one query. New comments wait in the moderation queue as
+-- pending until an editor approves, rejects or marks them as spam.
+CREATE TABLE IF NOT EXISTS comments (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    news_id BIGINT UNSIGNED NOT NULL,
+    parent_id BIGINT UNSIGNED NULL,
+    root_id BIGINT UNSIGNED NULL,
+    user_id BIGINT UNSIGNED NOT NULL,
+    body TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    ip_address VARCHAR(45) NOT NULL DEFAULT '',
+    created_at TIMESTAMP NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NULL DEFAULT CURRENT_TIMESTAMP,
+    KEY comments_news_status_index (news_id, status, root_id),
+    KEY comments_status_created_index (status, created_at)
+);
+
+-- Every moderation action, with the status it changed
+CREATE TABLE IF NOT EXISTS comment_moderations (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    comment_id BIGINT UNSIGNED NOT NULL,
+    moderator_id BIGINT UNSIGNED NOT NULL,
+    from_status VARCHAR(20) NOT NULL,
+    to_status VARCHAR(20) NOT NULL,
+    reason VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP NULL DEFAULT CURRENT_TIMESTAMP,
+    KEY comment_moderations_comment_id_index (comment_id)
+);
+
+-- Approved comments, kept up to date by moderation so lists need no count query
+ALTER TABLE news
+    ADD COLUMN comment_count INT UNSIGNED NOT NULL DEFAULT 0;
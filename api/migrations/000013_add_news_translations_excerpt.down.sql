@@ -0,0 +1,2 @@
+ALTER TABLE news_translations
+    DROP COLUMN excerpt;
@@ -0,0 +1,7 @@
+-- excerpt is derived on save: the sub title when set, otherwise the start of the
+-- sanitized content. Translations saved before the column existed take their
+-- sub title here; run cmd/content-sanitize to sanitize them and fill the rest.
+ALTER TABLE news_translations
+    ADD COLUMN excerpt VARCHAR(500) NOT NULL DEFAULT '';
+
+UPDATE news_translations SET excerpt = sub_title WHERE sub_title IS NOT NULL AND sub_title <> '';
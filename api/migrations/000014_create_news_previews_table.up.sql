@@ -0,0 +1,13 @@
+-- Preview links of unpublished revisions. A link is signed with its expiry;
+-- the row lets editors revoke it before then.
+CREATE TABLE IF NOT EXISTS news_previews (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    news_id BIGINT UNSIGNED NOT NULL,
+    revision_id BIGINT UNSIGNED NOT NULL,
+    expires_at DATETIME NOT NULL,
+    revoked_at DATETIME NULL,
+    revoked_by BIGINT UNSIGNED NULL,
+    created_by BIGINT UNSIGNED NOT NULL,
+    created_at TIMESTAMP NULL DEFAULT CURRENT_TIMESTAMP,
+    KEY news_previews_news_id_index (news_id)
+);
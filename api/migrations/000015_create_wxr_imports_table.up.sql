@@ -0,0 +1,12 @@
+-- Records imported from a WordPress export, by kind (author, category, post,
+-- attachment) and source key (login, nicename or GUID). Running an import
+-- again skips what is recorded here, an interrupted import resumes.
+CREATE TABLE IF NOT EXISTS wxr_imports (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    kind VARCHAR(20) NOT NULL,
+    source_hash CHAR(40) NOT NULL,
+    source VARCHAR(1000) NOT NULL,
+    target_id BIGINT UNSIGNED NOT NULL,
+    created_at TIMESTAMP NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE KEY wxr_imports_kind_source_unique (kind, source_hash)
+);
@@ -0,0 +1,15 @@
+-- Timestamped entries of live blog articles, news.type = 'live'
+CREATE TABLE IF NOT EXISTS news_live_entries (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    news_id BIGINT UNSIGNED NOT NULL,
+    body TEXT NOT NULL,
+    media_url VARCHAR(500) NULL,
+    media_type VARCHAR(20) NULL,
+    media_caption VARCHAR(255) NULL,
+    pinned BOOLEAN NOT NULL DEFAULT FALSE,
+    created_by BIGINT UNSIGNED NOT NULL,
+    updated_by BIGINT UNSIGNED NOT NULL,
+    created_at TIMESTAMP NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NULL DEFAULT CURRENT_TIMESTAMP,
+    KEY news_live_entries_news_id_index (news_id, id)
+);
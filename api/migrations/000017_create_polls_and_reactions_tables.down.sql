@@ -0,0 +1,9 @@
+ALTER TABLE news
+    DROP COLUMN reactions,
+    DROP COLUMN reaction_count,
+    DROP COLUMN poll_vote_count;
+
+DROP TABLE IF EXISTS news_reactions;
+DROP TABLE IF EXISTS poll_votes;
+DROP TABLE IF EXISTS poll_options;
+DROP TABLE IF EXISTS polls;
@@ -0,0 +1,55 @@
+-- Reader polls attached to articles. A poll accepts ballots between opens_at
+-- and closes_at when they are set; a multiple choice poll accepts up to
+-- max_choices options per ballot, 0 for no limit.
+CREATE TABLE IF NOT EXISTS polls (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    news_id BIGINT UNSIGNED NOT NULL,
+    question VARCHAR(255) NOT NULL,
+    multiple BOOLEAN NOT NULL DEFAULT FALSE,
+    max_choices INT UNSIGNED NOT NULL DEFAULT 0,
+    opens_at TIMESTAMP NULL,
+    closes_at TIMESTAMP NULL,
+    voter_count INT NOT NULL DEFAULT 0,
+    created_by BIGINT UNSIGNED NOT NULL,
+    created_at TIMESTAMP NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NULL DEFAULT CURRENT_TIMESTAMP,
+    KEY polls_news_id_index (news_id)
+);
+
+CREATE TABLE IF NOT EXISTS poll_options (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    poll_id BIGINT UNSIGNED NOT NULL,
+    label VARCHAR(255) NOT NULL,
+    position INT UNSIGNED NOT NULL DEFAULT 0,
+    vote_count INT NOT NULL DEFAULT 0,
+    KEY poll_options_poll_id_index (poll_id, position)
+);
+
+-- One ballot per poll and voter, "user:<id>" or "anon:<client token ID>"
+CREATE TABLE IF NOT EXISTS poll_votes (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    poll_id BIGINT UNSIGNED NOT NULL,
+    voter VARCHAR(64) NOT NULL,
+    user_id BIGINT UNSIGNED NULL,
+    option_ids JSON NOT NULL,
+    created_at TIMESTAMP NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE KEY poll_votes_poll_voter_unique (poll_id, voter)
+);
+
+-- One reaction per article and voter, a reader may switch or remove it
+CREATE TABLE IF NOT EXISTS news_reactions (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    news_id BIGINT UNSIGNED NOT NULL,
+    voter VARCHAR(64) NOT NULL,
+    reaction VARCHAR(20) NOT NULL,
+    created_at TIMESTAMP NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE KEY news_reactions_news_voter_unique (news_id, voter)
+);
+
+-- Totals per reaction and of the ballots of the article's polls, written by
+-- the tally flusher so lists need no count query
+ALTER TABLE news
+    ADD COLUMN reactions JSON NULL,
+    ADD COLUMN reaction_count INT NOT NULL DEFAULT 0,
+    ADD COLUMN poll_vote_count INT NOT NULL DEFAULT 0;
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS newsletter_deliveries;
+DROP TABLE IF EXISTS newsletter_issues;
+DROP TABLE IF EXISTS newsletter_subscribers;
+DROP TABLE IF EXISTS newsletter_lists;
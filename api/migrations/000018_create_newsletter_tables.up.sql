@@ -0,0 +1,77 @@
+-- Newsletter lists. Each list sends a daily or weekly digest of the top
+-- articles of its categories, every active category when categories is NULL.
+CREATE TABLE IF NOT EXISTS newsletter_lists (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    slug VARCHAR(100) NOT NULL,
+    description VARCHAR(500) NOT NULL DEFAULT '',
+    frequency VARCHAR(20) NOT NULL DEFAULT 'daily',
+    locale VARCHAR(10) NOT NULL,
+    categories JSON NULL,
+    per_category INT UNSIGNED NOT NULL DEFAULT 3,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    last_sent_at TIMESTAMP NULL,
+    created_by BIGINT UNSIGNED NOT NULL,
+    created_at TIMESTAMP NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE KEY newsletter_lists_slug_unique (slug)
+);
+
+-- Subscribers of a list. A subscription stays pending until the confirmation
+-- link is followed; the unsubscribe token is in every digest.
+CREATE TABLE IF NOT EXISTS newsletter_subscribers (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    list_id BIGINT UNSIGNED NOT NULL,
+    email VARCHAR(255) NOT NULL,
+    name VARCHAR(100) NOT NULL DEFAULT '',
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    confirm_token VARCHAR(64) NULL,
+    unsubscribe_token VARCHAR(64) NOT NULL,
+    ip_address VARCHAR(45) NOT NULL DEFAULT '',
+    confirmed_at TIMESTAMP NULL,
+    unsubscribed_at TIMESTAMP NULL,
+    bounce_count INT UNSIGNED NOT NULL DEFAULT 0,
+    bounced_at TIMESTAMP NULL,
+    bounce_reason VARCHAR(500) NOT NULL DEFAULT '',
+    last_sent_at TIMESTAMP NULL,
+    created_at TIMESTAMP NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE KEY newsletter_subscribers_list_email_unique (list_id, email),
+    UNIQUE KEY newsletter_subscribers_confirm_token_unique (confirm_token),
+    UNIQUE KEY newsletter_subscribers_unsubscribe_token_unique (unsubscribe_token),
+    KEY newsletter_subscribers_list_status_index (list_id, status)
+);
+
+-- Every digest sent, with the articles it listed. The sections keep the
+-- composed content so an interrupted send resumes with the same digest.
+CREATE TABLE IF NOT EXISTS newsletter_issues (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    list_id BIGINT UNSIGNED NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    news_ids JSON NOT NULL,
+    sections JSON NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'sending',
+    recipient_count INT UNSIGNED NOT NULL DEFAULT 0,
+    sent_count INT UNSIGNED NOT NULL DEFAULT 0,
+    failed_count INT UNSIGNED NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NULL DEFAULT CURRENT_TIMESTAMP,
+    sent_at TIMESTAMP NULL,
+    KEY newsletter_issues_list_id_index (list_id, id),
+    KEY newsletter_issues_status_index (status)
+);
+
+-- Delivery of an issue to a subscriber, the send history of the subscriber
+CREATE TABLE IF NOT EXISTS newsletter_deliveries (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    issue_id BIGINT UNSIGNED NOT NULL,
+    subscriber_id BIGINT UNSIGNED NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    error VARCHAR(500) NOT NULL DEFAULT '',
+    attempts INT UNSIGNED NOT NULL DEFAULT 0,
+    sent_at TIMESTAMP NULL,
+    created_at TIMESTAMP NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE KEY newsletter_deliveries_issue_subscriber_unique (issue_id, subscriber_id),
+    KEY newsletter_deliveries_subscriber_index (subscriber_id, id),
+    KEY newsletter_deliveries_issue_status_index (issue_id, status)
+);
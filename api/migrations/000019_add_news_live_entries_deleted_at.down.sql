@@ -0,0 +1,6 @@
+DELETE FROM news_live_entries WHERE deleted_at IS NOT NULL;
+
+ALTER TABLE news_live_entries
+    DROP KEY news_live_entries_news_id_deleted_at_index,
+    DROP KEY news_live_entries_news_id_updated_at_index,
+    DROP COLUMN deleted_at;
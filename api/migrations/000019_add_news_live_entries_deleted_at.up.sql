@@ -0,0 +1,5 @@
+-- Deleted entries stay as tombstones so polling readers learn to drop them
+ALTER TABLE news_live_entries
+    ADD COLUMN deleted_at TIMESTAMP NULL,
+    ADD KEY news_live_entries_news_id_updated_at_index (news_id, updated_at),
+    ADD KEY news_live_entries_news_id_deleted_at_index (news_id, deleted_at);
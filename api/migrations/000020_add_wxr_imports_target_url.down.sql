@@ -0,0 +1,4 @@
+DELETE FROM wxr_imports WHERE kind = 'media';
+
+ALTER TABLE wxr_imports
+    DROP COLUMN target_url;
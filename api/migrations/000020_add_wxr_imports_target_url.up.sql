@@ -0,0 +1,4 @@
+-- Imported files are recorded with the URL they were stored at, so the
+-- content of later posts links to the stored copy without downloading it again
+ALTER TABLE wxr_imports
+    ADD COLUMN target_url VARCHAR(1000) NULL AFTER target_id;
@@ -0,0 +1,6 @@
+UPDATE news
+JOIN news_legacy_publish_statuses legacy ON legacy.news_id = news.id
+SET news.publish_status_id = legacy.publish_status_id
+WHERE news.publish_status_id = 1;
+
+DROP TABLE IF EXISTS news_legacy_publish_statuses;
@@ -0,0 +1,20 @@
+-- Before the editorial workflow, publish_status_id 9 meant published and any
+-- other value, NULL included, meant not published. Workflow articles start as
+-- drafts (1) and only reach the other states through a recorded transition,
+-- or as scheduled imports with a publish_at; the remaining rows are legacy
+-- and become drafts. Their previous values are kept for the down migration.
+CREATE TABLE IF NOT EXISTS news_legacy_publish_statuses (
+    news_id BIGINT UNSIGNED NOT NULL PRIMARY KEY,
+    publish_status_id INT UNSIGNED NULL
+);
+
+INSERT INTO news_legacy_publish_statuses (news_id, publish_status_id)
+SELECT news.id, news.publish_status_id
+FROM news
+WHERE (news.publish_status_id IS NULL OR news.publish_status_id NOT IN (1, 9))
+  AND NOT (news.publish_status_id = 4 AND news.publish_at IS NOT NULL)
+  AND NOT EXISTS (SELECT 1 FROM news_workflow_transitions t WHERE t.news_id = news.id);
+
+UPDATE news
+JOIN news_legacy_publish_statuses legacy ON legacy.news_id = news.id
+SET news.publish_status_id = 1;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS news_translations_search_vector_index;
+ALTER TABLE news_translations DROP COLUMN IF EXISTS search_vector;
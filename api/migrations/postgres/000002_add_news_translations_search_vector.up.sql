@@ -0,0 +1,12 @@
+-- The news domain runs on MySQL (app.MDB); only search can also run on Postgres
+-- (app.DB), which needs this column. Every other table lives in ../ alone.
+-- The simple configuration is used because articles are written in several languages
+ALTER TABLE news_translations
+    ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+        setweight(to_tsvector('simple', coalesce(title, '')), 'A') ||
+        setweight(to_tsvector('simple', coalesce(sub_title, '')), 'B') ||
+        setweight(to_tsvector('simple', coalesce(tags::text, '')), 'B') ||
+        setweight(to_tsvector('simple', coalesce(content, '')), 'C')
+    ) STORED;
+
+CREATE INDEX IF NOT EXISTS news_translations_search_vector_index ON news_translations USING GIN (search_vector);
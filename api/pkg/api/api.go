@@ -3,7 +3,13 @@ package api
 import (
 	"net/http"
 
+	commentHttp "github.com/JubaerHossain/cn-api/domain/comments/infrastructure/transport/http"
 	departmentHttp "github.com/JubaerHossain/cn-api/domain/departments/infrastructure/transport/http"
+	engagementHttp "github.com/JubaerHossain/cn-api/domain/engagement/infrastructure/transport/http"
+	newsHttp "github.com/JubaerHossain/cn-api/domain/news/infrastructure/transport/http"
+	newsletterHttp "github.com/JubaerHossain/cn-api/domain/newsletters/infrastructure/transport/http"
+	placementHttp "github.com/JubaerHossain/cn-api/domain/placements/infrastructure/transport/http"
+	tagHttp "github.com/JubaerHossain/cn-api/domain/tags/infrastructure/transport/http"
 	"github.com/JubaerHossain/rootx/pkg/core/app"
 )
 
@@ -11,6 +17,18 @@ func APIRouter(application *app.App) http.Handler {
 	router := http.NewServeMux()
 	//Register department routes
 	departmentHttp.DepartmentRouter(router, application)
+	//Register news management routes
+	newsHttp.NewsAdminRouter(router, application)
+	//Register tag management routes
+	tagHttp.TagAdminRouter(router, application)
+	//Register placement management routes
+	placementHttp.PlacementAdminRouter(router, application)
+	//Register comment moderation routes
+	commentHttp.CommentAdminRouter(router, application)
+	//Register poll management routes
+	engagementHttp.EngagementAdminRouter(router, application)
+	//Register newsletter management routes
+	newsletterHttp.NewsletterAdminRouter(router, application)
 
 	return router
 }
@@ -4,7 +4,11 @@ import (
 	"net/http"
 
 	categoryHttp "github.com/JubaerHossain/cn-api/domain/categories/infrastructure/transport/http"
+	commentHttp "github.com/JubaerHossain/cn-api/domain/comments/infrastructure/transport/http"
+	engagementHttp "github.com/JubaerHossain/cn-api/domain/engagement/infrastructure/transport/http"
 	newsHttp "github.com/JubaerHossain/cn-api/domain/news/infrastructure/transport/http"
+	newsletterHttp "github.com/JubaerHossain/cn-api/domain/newsletters/infrastructure/transport/http"
+	tagHttp "github.com/JubaerHossain/cn-api/domain/tags/infrastructure/transport/http"
 	"github.com/JubaerHossain/rootx/pkg/core/app"
 )
 
@@ -14,6 +18,10 @@ func PublicAPIRouter(application *app.App) http.Handler {
 	//public routes
 	categoryHttp.CategoryRouter(router, application)
 	newsHttp.NewsRouter(router, application)
+	tagHttp.TagRouter(router, application)
+	commentHttp.CommentRouter(router, application)
+	engagementHttp.EngagementRouter(router, application)
+	newsletterHttp.NewsletterRouter(router, application)
 
 	return router
 }
@@ -0,0 +1,17 @@
+package api
+
+import (
+	"net/http"
+
+	newsHttp "github.com/JubaerHossain/cn-api/domain/news/infrastructure/transport/http"
+	"github.com/JubaerHossain/rootx/pkg/core/app"
+)
+
+func StreamRouter(application *app.App) http.Handler {
+	router := http.NewServeMux()
+
+	//public event streams
+	newsHttp.NewsStreamRouter(router, application)
+
+	return router
+}
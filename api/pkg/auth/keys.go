@@ -0,0 +1,20 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+)
+
+// SigningKey returns the key signing one kind of token: the configured secret
+// when it is set, else a key derived from the JWT secret for the purpose.
+// Each purpose gets its own key so a signature made for one kind of token,
+// say a list cursor, never verifies as another, say a preview link, and none
+// of them is made with the key that signs the access tokens.
+func SigningKey(configured, jwtSecret, purpose string) []byte {
+	if configured != "" {
+		return []byte(configured)
+	}
+	mac := hmac.New(sha256.New, []byte(jwtSecret))
+	mac.Write([]byte("cn-api/" + purpose))
+	return mac.Sum(nil)
+}
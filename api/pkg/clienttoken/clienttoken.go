@@ -0,0 +1,51 @@
+// Package clienttoken issues the tokens that identify anonymous readers, so
+// their poll votes and reactions count once without an account. A token is a
+// random ID and an HMAC of it; only the server can mint one, but a client may
+// ask for as many as the issuing rate limit allows.
+package clienttoken
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"strings"
+)
+
+// Header carries the token of an anonymous reader
+const Header = "X-Client-Token"
+
+// idSize is the number of random bytes of a token ID
+const idSize = 16
+
+// New returns a token with a fresh random ID
+func New(secret []byte) (string, error) {
+	id := make([]byte, idSize)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(id)
+	return encoded + "." + sign(secret, encoded), nil
+}
+
+// Verify returns the ID of a token signed with the secret
+func Verify(secret []byte, token string) (string, bool) {
+	id, signature, ok := strings.Cut(token, ".")
+	if !ok || id == "" {
+		return "", false
+	}
+	if decoded, err := base64.RawURLEncoding.DecodeString(id); err != nil || len(decoded) != idSize {
+		return "", false
+	}
+	if !hmac.Equal([]byte(signature), []byte(sign(secret, id))) {
+		return "", false
+	}
+	return id, true
+}
+
+// sign returns the signature of an encoded ID
+func sign(secret []byte, id string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte("client:" + id))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
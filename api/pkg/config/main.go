@@ -0,0 +1,188 @@
+package config
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/spf13/viper"
+)
+
+// Config holds the news API settings that are not part of the rootx core config.
+// Values are read from the same .env file and environment as the core config.
+type Config struct {
+	AppEnv             string `mapstructure:"APP_ENV"` // development enables the local-only helpers, e.g. the file mail driver
+	DefaultLocale      string `mapstructure:"DEFAULT_LOCALE"`
+	SupportedLocales   string `mapstructure:"SUPPORTED_LOCALES"`
+	LocaleFallbacks    string `mapstructure:"LOCALE_FALLBACKS"`
+	SiteName           string `mapstructure:"SITE_NAME"`
+	WorkflowRoles      string `mapstructure:"WORKFLOW_ROLES"`       // role_id:workflow_role pairs, e.g. 1:admin,2:editor,3:reporter
+	SchedulerInterval  int    `mapstructure:"SCHEDULER_INTERVAL"`   // Seconds between publishing scheduler runs
+	ViewDedupeWindow   int    `mapstructure:"VIEW_DEDUPE_WINDOW"`   // Seconds a client's repeated views of an item count once
+	ViewFlushInterval  int    `mapstructure:"VIEW_FLUSH_INTERVAL"`  // Seconds between view count flushes to the database
+	RankingRefresh     int    `mapstructure:"RANKING_REFRESH"`      // Seconds before trending and most-read rankings are rebuilt
+	ImageSizes         string `mapstructure:"IMAGE_SIZES"`          // Rendition widths, small:400,medium:800,large:1600
+	ImageQuality       int    `mapstructure:"IMAGE_QUALITY"`        // JPEG quality of the renditions, 1 to 100
+	ImageMaxUpload     int    `mapstructure:"IMAGE_MAX_UPLOAD"`     // Largest accepted original in megabytes
+	CursorSecret       string `mapstructure:"CURSOR_SECRET"`        // Signs list cursors, derived from the JWT secret when empty
+	LivePlacements     string `mapstructure:"LIVE_PLACEMENTS"`      // Placements pushed on the breaking news stream, comma separated
+	LiveReplay         int    `mapstructure:"LIVE_REPLAY"`          // Events kept for streams resuming with Last-Event-ID
+	LiveHeartbeat      int    `mapstructure:"LIVE_HEARTBEAT"`       // Seconds between stream heartbeats
+	CommentBannedWords string `mapstructure:"COMMENT_BANNED_WORDS"` // Words a comment may not contain, comma separated
+	CommentRateLimit   int    `mapstructure:"COMMENT_RATE_LIMIT"`   // Comments a user or an IP address may post per window
+	CommentRateWindow  int    `mapstructure:"COMMENT_RATE_WINDOW"`  // Seconds of the comment rate limit window
+	CommentMaxLength   int    `mapstructure:"COMMENT_MAX_LENGTH"`   // Longest comment in characters
+	PreviewSecret      string `mapstructure:"PREVIEW_SECRET"`       // Signs preview links, derived from the JWT secret when empty
+	PreviewURL         string `mapstructure:"PREVIEW_URL"`          // Base of preview links, the public preview endpoint
+	PreviewTTL         int    `mapstructure:"PREVIEW_TTL"`          // Default seconds a preview link stays valid
+	PreviewMaxTTL      int    `mapstructure:"PREVIEW_MAX_TTL"`      // Longest validity editors may ask for, in seconds
+	ClientTokenSecret  string `mapstructure:"CLIENT_TOKEN_SECRET"`  // Signs anonymous reader tokens, derived from the JWT secret when empty
+	ClientTokenLimit   int    `mapstructure:"CLIENT_TOKEN_LIMIT"`   // Anonymous reader tokens an IP address may get per hour
+	TallyFlushInterval int    `mapstructure:"TALLY_FLUSH_INTERVAL"` // Seconds between poll vote and reaction count flushes to the database
+	MailDriver         string `mapstructure:"MAIL_DRIVER"`          // smtp, or file to write messages to MAIL_OUTBOX in development
+	MailHost           string `mapstructure:"MAIL_HOST"`            // SMTP server
+	MailPort           int    `mapstructure:"MAIL_PORT"`            // 465 for implicit TLS, STARTTLS is used on other ports when offered
+	MailUsername       string `mapstructure:"MAIL_USERNAME"`        // SMTP login, no authentication when empty
+	MailPassword       string `mapstructure:"MAIL_PASSWORD"`        // SMTP password
+	MailFrom           string `mapstructure:"MAIL_FROM"`            // Sender address of outgoing mail
+	MailOutbox         string `mapstructure:"MAIL_OUTBOX"`          // Directory the file driver writes .eml files to, never under the served storage
+	NewsletterURL      string `mapstructure:"NEWSLETTER_URL"`       // Base of confirmation and unsubscribe links, the public newsletter endpoints
+	NewsletterSendAt   string `mapstructure:"NEWSLETTER_SEND_AT"`   // UTC time of day digests go out, HH:MM
+	NewsletterWeekday  string `mapstructure:"NEWSLETTER_WEEKDAY"`   // Day weekly digests go out, e.g. monday
+	NewsletterInterval int    `mapstructure:"NEWSLETTER_INTERVAL"`  // Seconds between checks for due digests
+	NewsletterLimit    int    `mapstructure:"NEWSLETTER_LIMIT"`     // Subscriptions an IP address may request per hour
+	NewsletterPerEmail int    `mapstructure:"NEWSLETTER_PER_EMAIL"` // Subscriptions, and so confirmation emails, an address may get per day
+	TrustedProxies     string `mapstructure:"TRUSTED_PROXIES"`      // Proxy addresses and CIDR ranges whose X-Forwarded-For is believed, comma separated
+}
+
+var (
+	GlobalConfig = defaultConfig()
+	configMutex  sync.Mutex
+)
+
+func LoadConfig() (*Config, error) {
+	// Lock the mutex to ensure thread safety during configuration loading
+	configMutex.Lock()
+	defer configMutex.Unlock()
+
+	viper.SetConfigFile(".env")
+	viper.AutomaticEnv()
+
+	// Read the configuration file
+	if err := viper.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	// Unmarshal the configuration into a Config struct
+	var cfg Config
+	if err := viper.Unmarshal(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
+	}
+
+	// Set default values for configuration fields
+	setDefaultValues(&cfg)
+
+	// Set the global configuration variable
+	GlobalConfig = &cfg
+
+	return &cfg, nil
+}
+
+func defaultConfig() *Config {
+	cfg := &Config{}
+	setDefaultValues(cfg)
+	return cfg
+}
+
+// setDefaultValues sets default values for configuration fields
+func setDefaultValues(cfg *Config) {
+	if cfg.DefaultLocale == "" {
+		cfg.DefaultLocale = "en"
+	}
+	if cfg.SupportedLocales == "" {
+		cfg.SupportedLocales = cfg.DefaultLocale
+	}
+	if cfg.SiteName == "" {
+		cfg.SiteName = "News"
+	}
+	if cfg.WorkflowRoles == "" {
+		cfg.WorkflowRoles = "1:admin,2:editor,3:reporter"
+	}
+	if cfg.SchedulerInterval <= 0 {
+		cfg.SchedulerInterval = 30
+	}
+	if cfg.ViewDedupeWindow <= 0 {
+		cfg.ViewDedupeWindow = 1800
+	}
+	if cfg.ViewFlushInterval <= 0 {
+		cfg.ViewFlushInterval = 60
+	}
+	if cfg.RankingRefresh <= 0 {
+		cfg.RankingRefresh = 300
+	}
+	if cfg.ImageSizes == "" {
+		cfg.ImageSizes = "small:400,medium:800,large:1600"
+	}
+	if cfg.ImageQuality <= 0 || cfg.ImageQuality > 100 {
+		cfg.ImageQuality = 82
+	}
+	if cfg.ImageMaxUpload <= 0 {
+		cfg.ImageMaxUpload = 10
+	}
+	if cfg.LivePlacements == "" {
+		cfg.LivePlacements = "breaking-scroll,breaking-thumb"
+	}
+	if cfg.LiveReplay <= 0 {
+		cfg.LiveReplay = 100
+	}
+	if cfg.LiveHeartbeat <= 0 {
+		cfg.LiveHeartbeat = 15
+	}
+	if cfg.CommentRateLimit <= 0 {
+		cfg.CommentRateLimit = 5
+	}
+	if cfg.CommentRateWindow <= 0 {
+		cfg.CommentRateWindow = 600
+	}
+	if cfg.CommentMaxLength <= 0 {
+		cfg.CommentMaxLength = 2000
+	}
+	if cfg.PreviewMaxTTL <= 0 {
+		cfg.PreviewMaxTTL = 604800
+	}
+	if cfg.PreviewTTL <= 0 || cfg.PreviewTTL > cfg.PreviewMaxTTL {
+		cfg.PreviewTTL = min(86400, cfg.PreviewMaxTTL)
+	}
+	if cfg.ClientTokenLimit <= 0 {
+		cfg.ClientTokenLimit = 20
+	}
+	if cfg.TallyFlushInterval <= 0 {
+		cfg.TallyFlushInterval = 30
+	}
+	if cfg.MailDriver == "" {
+		cfg.MailDriver = "file"
+	}
+	if cfg.MailPort <= 0 {
+		cfg.MailPort = 587
+	}
+	if cfg.MailFrom == "" {
+		cfg.MailFrom = "no-reply@localhost"
+	}
+	if cfg.MailOutbox == "" {
+		cfg.MailOutbox = "outbox"
+	}
+	if cfg.NewsletterSendAt == "" {
+		cfg.NewsletterSendAt = "06:00"
+	}
+	if cfg.NewsletterWeekday == "" {
+		cfg.NewsletterWeekday = "monday"
+	}
+	if cfg.NewsletterInterval <= 0 {
+		cfg.NewsletterInterval = 300
+	}
+	if cfg.NewsletterLimit <= 0 {
+		cfg.NewsletterLimit = 10
+	}
+	if cfg.NewsletterPerEmail <= 0 {
+		cfg.NewsletterPerEmail = 3
+	}
+}
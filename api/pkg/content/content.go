@@ -0,0 +1,166 @@
+// Package content cleans the article HTML written by editors before it is
+// stored. Content is sanitized with a policy for newsroom markup: text,
+// headings, lists, quotes, figures, tables and the embeds of a few trusted
+// providers. Everything else, scripts and event handlers included, is removed.
+package content
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/JubaerHossain/cn-api/pkg/utils"
+	"github.com/microcosm-cc/bluemonday"
+	"golang.org/x/net/html"
+)
+
+// ExcerptLength is the longest excerpt derived from the content, in characters
+const ExcerptLength = 300
+
+// embedSandbox is the sandbox of embedded players, they need scripts and
+// their own origin to play
+const embedSandbox = "allow-scripts allow-same-origin allow-popups allow-presentation"
+
+// externalRel is the rel of links leaving the site
+const externalRel = "nofollow noopener noreferrer"
+
+var (
+	// embedSource matches the iframe sources of the YouTube, X/Twitter and Facebook embeds
+	embedSource = regexp.MustCompile(`^https://(www\.youtube(-nocookie)?\.com/embed/[A-Za-z0-9_-]+|platform\.twitter\.com/embed/Tweet\.html|www\.facebook\.com/plugins/(post|video)\.php)(\?[^\s"'<>]*)?$`)
+	// facebookPost matches the data-href of Facebook SDK embeds
+	facebookPost = regexp.MustCompile(`^https://www\.facebook\.com/[^\s"'<>]+$`)
+
+	policy     *bluemonday.Policy
+	policyOnce sync.Once
+)
+
+// Policy returns the newsroom policy. It extends the bluemonday user content
+// policy, which already covers headings, figures and tables, with image
+// loading hints and the trusted embeds. X/Twitter and Facebook SDK embeds keep
+// their blockquote or div, the page loads the provider script.
+func Policy() *bluemonday.Policy {
+	policyOnce.Do(func() {
+		p := bluemonday.UGCPolicy()
+		p.AllowElements("figure", "figcaption")
+		p.AllowAttrs("loading").Matching(regexp.MustCompile(`^(lazy|eager)$`)).OnElements("img")
+
+		p.AllowAttrs("src").Matching(embedSource).OnElements("iframe")
+		p.AllowAttrs("width", "height").Matching(bluemonday.Number).OnElements("iframe")
+		p.AllowAttrs("title").Matching(bluemonday.Paragraph).OnElements("iframe")
+		p.AllowAttrs("allowfullscreen").Matching(regexp.MustCompile(`^(|allowfullscreen|true)$`)).OnElements("iframe")
+		p.AllowAttrs("class").Matching(regexp.MustCompile(`^twitter-tweet$`)).OnElements("blockquote")
+		p.AllowAttrs("class").Matching(regexp.MustCompile(`^fb-(post|video)$`)).OnElements("div")
+		p.AllowAttrs("data-href").Matching(facebookPost).OnElements("div")
+		policy = p
+	})
+	return policy
+}
+
+// Sanitize cleans article HTML. Iframes outside the trusted embeds are
+// dropped and the others sandboxed. Links leaving siteURL open in a new tab
+// with rel="nofollow noopener noreferrer", links within it lose rel and target.
+func Sanitize(content, siteURL string) string {
+	return rewrite(Policy().Sanitize(content), siteHost(siteURL))
+}
+
+// Excerpt returns the sub title when it is set, otherwise the start of the
+// text of the content
+func Excerpt(subTitle, content string) string {
+	if subTitle = strings.TrimSpace(subTitle); subTitle != "" {
+		return subTitle
+	}
+	return utils.Truncate(utils.StripHTML(content), ExcerptLength)
+}
+
+// rewrite runs over sanitized HTML to finish embeds and links, which the
+// policy cannot do. Tokens that need no change are copied as they are.
+func rewrite(content, host string) string {
+	var (
+		b        strings.Builder
+		tokens   = html.NewTokenizer(strings.NewReader(content))
+		skipping bool // Inside an iframe that lost its source
+	)
+	for {
+		switch tokens.Next() {
+		case html.ErrorToken:
+			return b.String()
+		case html.StartTagToken, html.SelfClosingTagToken:
+			token := tokens.Token()
+			switch token.Data {
+			case "iframe":
+				if attr(token, "src") == "" {
+					skipping = token.Type == html.StartTagToken
+					continue
+				}
+				setAttr(&token, "sandbox", embedSandbox)
+				b.WriteString(token.String())
+				continue
+			case "a":
+				setAttr(&token, "rel", "")
+				setAttr(&token, "target", "")
+				if isExternal(attr(token, "href"), host) {
+					setAttr(&token, "rel", externalRel)
+					setAttr(&token, "target", "_blank")
+				}
+				b.WriteString(token.String())
+				continue
+			}
+		case html.EndTagToken:
+			if skipping {
+				if name, _ := tokens.TagName(); string(name) == "iframe" {
+					skipping = false
+				}
+				continue
+			}
+		}
+		if !skipping {
+			b.Write(tokens.Raw())
+		}
+	}
+}
+
+// isExternal reports whether the link leaves the site
+func isExternal(href, host string) bool {
+	link, err := url.Parse(href)
+	if err != nil || (link.Scheme != "http" && link.Scheme != "https") {
+		return false
+	}
+	return host == "" || !strings.EqualFold(link.Hostname(), host)
+}
+
+// siteHost returns the host name of the site URL, it may be given without scheme
+func siteHost(siteURL string) string {
+	if !strings.Contains(siteURL, "://") {
+		siteURL = "https://" + siteURL
+	}
+	site, err := url.Parse(siteURL)
+	if err != nil {
+		return ""
+	}
+	return site.Hostname()
+}
+
+// attr returns the value of an attribute of the token
+func attr(token html.Token, key string) string {
+	for _, a := range token.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+// setAttr replaces an attribute of the token, an empty value removes it
+func setAttr(token *html.Token, key, val string) {
+	attrs := token.Attr[:0]
+	for _, a := range token.Attr {
+		if a.Key != key {
+			attrs = append(attrs, a)
+		}
+	}
+	if val != "" {
+		attrs = append(attrs, html.Attribute{Key: key, Val: val})
+	}
+	token.Attr = attrs
+}
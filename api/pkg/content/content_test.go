@@ -0,0 +1,74 @@
+package content
+
+import "testing"
+
+func TestSanitize(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "plain markup", in: `<p>Hello <b>world</b></p>`, want: `<p>Hello <b>world</b></p>`},
+		{name: "scripts and handlers", in: `<p onclick="x()">Hi</p><script>alert(1)</script>`, want: `<p>Hi</p>`},
+		{name: "external link", in: `<a href="https://example.org/a">out</a>`,
+			want: `<a href="https://example.org/a" rel="nofollow noopener noreferrer" target="_blank">out</a>`},
+		{name: "internal link", in: `<a href="https://news.test/b" target="_blank" rel="nofollow">in</a>`,
+			want: `<a href="https://news.test/b">in</a>`},
+		{name: "relative link", in: `<a href="/c">relative</a>`, want: `<a href="/c">relative</a>`},
+		{name: "javascript link", in: `<a href="javascript:alert(1)">js</a>`, want: `js`},
+		{name: "trusted embed", in: `<iframe src="https://www.youtube.com/embed/abc_123" width="560" height="315" allowfullscreen></iframe>`,
+			want: `<iframe src="https://www.youtube.com/embed/abc_123" width="560" height="315" allowfullscreen="" sandbox="` + embedSandbox + `"></iframe>`},
+		{name: "untrusted embed", in: `<iframe src="https://evil.example/embed">fallback</iframe><p>after</p>`, want: `fallback<p>after</p>`},
+		{name: "tweet", in: `<blockquote class="twitter-tweet"><p>tweet</p></blockquote>`,
+			want: `<blockquote class="twitter-tweet"><p>tweet</p></blockquote>`},
+		{name: "facebook post", in: `<div class="fb-post" data-href="https://www.facebook.com/page/posts/1"></div>`,
+			want: `<div class="fb-post" data-href="https://www.facebook.com/page/posts/1"></div>`},
+		{name: "figure", in: `<figure><img src="https://news.test/i.jpg" alt="x" loading="lazy" onerror="x()"><figcaption>c</figcaption></figure>`,
+			want: `<figure><img src="https://news.test/i.jpg" alt="x" loading="lazy"><figcaption>c</figcaption></figure>`},
+		{name: "unknown loading hint", in: `<img src="a.jpg" loading="sometimes">`, want: `<img src="a.jpg">`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Sanitize(tt.in, "https://news.test"); got != tt.want {
+				t.Errorf("Sanitize() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSiteHost(t *testing.T) {
+	tests := []struct {
+		siteURL string
+		want    string
+	}{
+		{siteURL: "https://news.test", want: "news.test"},
+		{siteURL: "news.test", want: "news.test"},
+		{siteURL: "http://news.test:8080/path", want: "news.test"},
+		{siteURL: "", want: ""},
+	}
+	for _, tt := range tests {
+		if got := siteHost(tt.siteURL); got != tt.want {
+			t.Errorf("siteHost(%q) = %q, want %q", tt.siteURL, got, tt.want)
+		}
+	}
+}
+
+func TestExcerpt(t *testing.T) {
+	tests := []struct {
+		name     string
+		subTitle string
+		content  string
+		want     string
+	}{
+		{name: "sub title", subTitle: "  Sub  ", content: "<p>x</p>", want: "Sub"},
+		{name: "content text", content: "<p>Hello <b>world</b></p>", want: "Hello world"},
+		{name: "empty", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Excerpt(tt.subTitle, tt.content); got != tt.want {
+				t.Errorf("Excerpt() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
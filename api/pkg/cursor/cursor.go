@@ -0,0 +1,113 @@
+// Package cursor encodes the keyset positions used to page through lists that
+// grow at the top. A cursor is an opaque, signed token: clients cannot forge a
+// position, and pages do not shift when new items arrive.
+package cursor
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"time"
+)
+
+const (
+	payloadSize   = 1 + 8 + 8 // direction, unix seconds, id
+	signatureSize = 16
+)
+
+// ErrInvalidCursor is returned for tokens that are malformed or not signed with the secret
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Direction tells on which side of the position the requested page is
+type Direction byte
+
+const (
+	// Next pages towards older items
+	Next Direction = 1
+	// Prev pages towards newer items
+	Prev Direction = 2
+)
+
+// Cursor is a position in a list ordered by time then ID, newest first
+type Cursor struct {
+	Direction Direction
+	Time      time.Time // Second precision
+	ID        uint
+}
+
+// Encode returns the signed token of the cursor
+func Encode(secret []byte, c Cursor) string {
+	buf := make([]byte, payloadSize, payloadSize+signatureSize)
+	buf[0] = byte(c.Direction)
+	binary.BigEndian.PutUint64(buf[1:], uint64(c.Time.Unix()))
+	binary.BigEndian.PutUint64(buf[9:], uint64(c.ID))
+	buf = append(buf, sign(secret, buf)...)
+	return base64.RawURLEncoding.EncodeToString(buf)
+}
+
+// Decode verifies a token and returns its cursor
+func Decode(secret []byte, token string) (Cursor, error) {
+	buf, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil || len(buf) != payloadSize+signatureSize {
+		return Cursor{}, ErrInvalidCursor
+	}
+	payload, signature := buf[:payloadSize], buf[payloadSize:]
+	if !hmac.Equal(signature, sign(secret, payload)) {
+		return Cursor{}, ErrInvalidCursor
+	}
+	c := Cursor{
+		Direction: Direction(payload[0]),
+		Time:      time.Unix(int64(binary.BigEndian.Uint64(payload[1:])), 0).UTC(),
+		ID:        uint(binary.BigEndian.Uint64(payload[9:])),
+	}
+	if c.Direction != Next && c.Direction != Prev {
+		return Cursor{}, ErrInvalidCursor
+	}
+	return c, nil
+}
+
+func sign(secret, payload []byte) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(payload)
+	return mac.Sum(nil)[:signatureSize]
+}
+
+// Item is the position of a listed item
+type Item struct {
+	Time time.Time
+	ID   uint
+}
+
+// Page trims the rows fetched for a page and returns the cursors around it.
+// Rows must be fetched with one extra row beyond limit, in list order for a
+// Next or first page and in reverse order for a Prev page. after is nil for
+// the first page. Returned items are in list order; a cursor is empty when
+// there is nothing on that side.
+func Page(secret []byte, after *Cursor, rows []Item, limit int) (items []Item, next, prev string) {
+	more := len(rows) > limit
+	if more {
+		rows = rows[:limit]
+	}
+	backwards := after != nil && after.Direction == Prev
+	if backwards {
+		for i, j := 0, len(rows)-1; i < j; i, j = i+1, j-1 {
+			rows[i], rows[j] = rows[j], rows[i]
+		}
+	}
+	if len(rows) == 0 {
+		return rows, "", ""
+	}
+
+	first, last := rows[0], rows[len(rows)-1]
+	hasNext := backwards || more
+	hasPrev := (backwards && more) || (!backwards && after != nil)
+	if hasNext {
+		next = Encode(secret, Cursor{Direction: Next, Time: last.Time, ID: last.ID})
+	}
+	if hasPrev {
+		prev = Encode(secret, Cursor{Direction: Prev, Time: first.Time, ID: first.ID})
+	}
+	return rows, next, prev
+}
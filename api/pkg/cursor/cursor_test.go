@@ -0,0 +1,128 @@
+package cursor
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+	"time"
+)
+
+var secret = []byte("test-secret")
+
+func TestDecode(t *testing.T) {
+	valid := Cursor{Direction: Next, Time: time.Date(2024, 5, 1, 12, 30, 45, 0, time.UTC), ID: 42}
+	token := Encode(secret, valid)
+	tests := []struct {
+		name    string
+		secret  []byte
+		token   string
+		want    Cursor
+		wantErr bool
+	}{
+		{name: "round trip", secret: secret, token: token, want: valid},
+		{name: "prev round trip", secret: secret, token: Encode(secret, Cursor{Direction: Prev, Time: valid.Time, ID: 7}),
+			want: Cursor{Direction: Prev, Time: valid.Time, ID: 7}},
+		{name: "other secret", secret: []byte("other"), token: token, wantErr: true},
+		{name: "empty", secret: secret, token: "", wantErr: true},
+		{name: "not base64", secret: secret, token: "!!!", wantErr: true},
+		{name: "truncated", secret: secret, token: token[:len(token)-2], wantErr: true},
+		{name: "tampered id", secret: secret, token: tamper(token, payloadSize-1), wantErr: true},
+		{name: "unknown direction", secret: secret, token: Encode(secret, Cursor{Direction: 3, Time: valid.Time, ID: 1}), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Decode(tt.secret, tt.token)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidCursor) {
+					t.Fatalf("Decode() error = %v, want ErrInvalidCursor", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			if got.Direction != tt.want.Direction || !got.Time.Equal(tt.want.Time) || got.ID != tt.want.ID {
+				t.Errorf("Decode() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// tamper flips a byte of a token, keeping its signature
+func tamper(token string, at int) string {
+	buf, _ := base64.RawURLEncoding.DecodeString(token)
+	buf[at] ^= 1
+	return base64.RawURLEncoding.EncodeToString(buf)
+}
+
+func TestPage(t *testing.T) {
+	at := func(id uint) Item {
+		return Item{Time: time.Unix(int64(1000+id), 0).UTC(), ID: id}
+	}
+	// Newest first: ids 5, 4, 3, 2, 1
+	rows := func(ids ...uint) []Item {
+		items := make([]Item, len(ids))
+		for i, id := range ids {
+			items[i] = at(id)
+		}
+		return items
+	}
+	next := &Cursor{Direction: Next, Time: at(4).Time, ID: 4}
+	prev := &Cursor{Direction: Prev, Time: at(2).Time, ID: 2}
+	tests := []struct {
+		name     string
+		after    *Cursor
+		rows     []Item
+		limit    int
+		wantIDs  []uint
+		wantNext *Cursor
+		wantPrev *Cursor
+	}{
+		{name: "empty", rows: nil, limit: 2},
+		{name: "first page with more", rows: rows(5, 4, 3), limit: 2, wantIDs: []uint{5, 4},
+			wantNext: &Cursor{Direction: Next, Time: at(4).Time, ID: 4}},
+		{name: "only page", rows: rows(5, 4), limit: 2, wantIDs: []uint{5, 4}},
+		{name: "next page with more", after: next, rows: rows(3, 2, 1), limit: 2, wantIDs: []uint{3, 2},
+			wantNext: &Cursor{Direction: Next, Time: at(2).Time, ID: 2},
+			wantPrev: &Cursor{Direction: Prev, Time: at(3).Time, ID: 3}},
+		{name: "last page", after: next, rows: rows(3), limit: 2, wantIDs: []uint{3},
+			wantPrev: &Cursor{Direction: Prev, Time: at(3).Time, ID: 3}},
+		{name: "prev page with more", after: prev, rows: rows(3, 4, 5), limit: 2, wantIDs: []uint{4, 3},
+			wantNext: &Cursor{Direction: Next, Time: at(3).Time, ID: 3},
+			wantPrev: &Cursor{Direction: Prev, Time: at(4).Time, ID: 4}},
+		{name: "prev page reaching the top", after: prev, rows: rows(3, 4), limit: 2, wantIDs: []uint{4, 3},
+			wantNext: &Cursor{Direction: Next, Time: at(3).Time, ID: 3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			items, nextToken, prevToken := Page(secret, tt.after, tt.rows, tt.limit)
+			if len(items) != len(tt.wantIDs) {
+				t.Fatalf("Page() returned %d items, want %d", len(items), len(tt.wantIDs))
+			}
+			for i, item := range items {
+				if item.ID != tt.wantIDs[i] {
+					t.Errorf("item %d = %d, want %d", i, item.ID, tt.wantIDs[i])
+				}
+			}
+			checkToken(t, "next", nextToken, tt.wantNext)
+			checkToken(t, "prev", prevToken, tt.wantPrev)
+		})
+	}
+}
+
+func checkToken(t *testing.T, name, token string, want *Cursor) {
+	t.Helper()
+	if want == nil {
+		if token != "" {
+			t.Errorf("%s cursor = %q, want none", name, token)
+		}
+		return
+	}
+	got, err := Decode(secret, token)
+	if err != nil {
+		t.Fatalf("%s cursor: %v", name, err)
+	}
+	if got.Direction != want.Direction || !got.Time.Equal(want.Time) || got.ID != want.ID {
+		t.Errorf("%s cursor = %+v, want %+v", name, got, *want)
+	}
+}
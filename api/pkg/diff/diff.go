@@ -0,0 +1,161 @@
+package diff
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Operation types
+const (
+	Equal  = "equal"
+	Insert = "insert"
+	Delete = "delete"
+)
+
+// maxEdits bounds the work spent on very different texts. Beyond it the
+// whole old text is reported as deleted and the new text as inserted.
+const maxEdits = 2000
+
+// Op is one run of unchanged, inserted or deleted text
+type Op struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// tokenPattern splits text into HTML tags, whitespace runs, words and single other characters
+var tokenPattern = regexp.MustCompile(`<[^>]*>|\s+|[\p{L}\p{N}\p{M}_]+|.`)
+
+// Words returns a word-level diff turning a into b. HTML tags are kept whole so
+// markup changes do not split words.
+func Words(a, b string) []Op {
+	return Tokens(tokenPattern.FindAllString(a, -1), tokenPattern.FindAllString(b, -1))
+}
+
+// Tokens returns the shortest edit script turning a into b, with runs of the
+// same operation merged
+func Tokens(a, b []string) []Op {
+	// Common prefix and suffix need no search
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	var ops []Op
+	ops = appendOp(ops, Equal, a[:prefix]...)
+	ops = append(ops, myers(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	ops = appendOp(ops, Equal, a[len(a)-suffix:]...)
+	return merge(ops)
+}
+
+// myers implements Myers' O((N+M)D) difference algorithm
+func myers(a, b []string) []Op {
+	n, m := len(a), len(b)
+	if n == 0 || m == 0 {
+		return append(appendOp(nil, Delete, a...), appendOp(nil, Insert, b...)...)
+	}
+
+	limit := n + m
+	if limit > maxEdits {
+		limit = maxEdits
+	}
+	offset := limit + 1
+	v := make([]int, 2*limit+3)
+	// trace[d] holds the furthest x on diagonals -d..d before step d
+	var trace [][]int
+
+	found := false
+	for d := 0; d <= limit && !found; d++ {
+		trace = append(trace, append([]int(nil), v[offset-d:offset+d+1]...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				found = true
+				break
+			}
+		}
+	}
+	if !found {
+		return append(appendOp(nil, Delete, a...), appendOp(nil, Insert, b...)...)
+	}
+
+	// Walk back from the end, collecting operations in reverse
+	var reversed []Op
+	x, y := n, m
+	for d := len(trace) - 1; d >= 0; d-- {
+		if d == 0 {
+			// The remaining path is the snake from the origin
+			for x > 0 && y > 0 {
+				reversed = append(reversed, Op{Type: Equal, Text: a[x-1]})
+				x--
+				y--
+			}
+			break
+		}
+		previous := trace[d]
+		at := func(k int) int { return previous[k+d] }
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && at(k-1) < at(k+1)) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := at(prevK)
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			reversed = append(reversed, Op{Type: Equal, Text: a[x-1]})
+			x--
+			y--
+		}
+		if x == prevX {
+			reversed = append(reversed, Op{Type: Insert, Text: b[y-1]})
+		} else {
+			reversed = append(reversed, Op{Type: Delete, Text: a[x-1]})
+		}
+		x, y = prevX, prevY
+	}
+
+	ops := make([]Op, 0, len(reversed))
+	for i := len(reversed) - 1; i >= 0; i-- {
+		ops = append(ops, reversed[i])
+	}
+	return ops
+}
+
+// appendOp adds one operation per token
+func appendOp(ops []Op, opType string, tokens ...string) []Op {
+	for _, token := range tokens {
+		ops = append(ops, Op{Type: opType, Text: token})
+	}
+	return ops
+}
+
+// merge joins adjacent operations of the same type
+func merge(ops []Op) []Op {
+	merged := []Op{}
+	var text strings.Builder
+	for i, op := range ops {
+		text.WriteString(op.Text)
+		if i+1 < len(ops) && ops[i+1].Type == op.Type {
+			continue
+		}
+		merged = append(merged, Op{Type: op.Type, Text: text.String()})
+		text.Reset()
+	}
+	return merged
+}
@@ -0,0 +1,75 @@
+package diff
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWords(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want []Op
+	}{
+		{name: "both empty", want: []Op{}},
+		{name: "unchanged", a: "same text", b: "same text", want: []Op{{Equal, "same text"}}},
+		{name: "added", a: "", b: "new text", want: []Op{{Insert, "new text"}}},
+		{name: "removed", a: "old text", b: "", want: []Op{{Delete, "old text"}}},
+		{name: "word replaced", a: "the quick fox", b: "the slow fox",
+			want: []Op{{Equal, "the "}, {Delete, "quick"}, {Insert, "slow"}, {Equal, " fox"}}},
+		{name: "word inserted", a: "the fox", b: "the red fox",
+			want: []Op{{Equal, "the "}, {Insert, "red "}, {Equal, "fox"}}},
+		{name: "punctuation", a: "Hello, world", b: "Hello; world",
+			want: []Op{{Equal, "Hello"}, {Delete, ","}, {Insert, ";"}, {Equal, " world"}}},
+		{name: "markup kept whole", a: "<p>Old title</p>", b: `<p class="lead">Old title</p>`,
+			want: []Op{{Delete, "<p>"}, {Insert, `<p class="lead">`}, {Equal, "Old title</p>"}}},
+		{name: "bengali words", a: "আজকের খবর", b: "আজকের বড় খবর",
+			want: []Op{{Equal, "আজকের "}, {Insert, "বড় "}, {Equal, "খবর"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Words(tt.a, tt.b)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Words(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+			}
+			checkScript(t, got, tt.a, tt.b)
+		})
+	}
+}
+
+func TestTokensLimit(t *testing.T) {
+	a := make([]string, maxEdits)
+	b := make([]string, maxEdits)
+	for i := range a {
+		a[i], b[i] = "a", "b"
+	}
+	got := Tokens(a, b)
+	want := []Op{{Delete, strings.Repeat("a", maxEdits)}, {Insert, strings.Repeat("b", maxEdits)}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Tokens() of texts beyond the edit limit = %d ops, want the whole texts replaced", len(got))
+	}
+}
+
+// checkScript verifies the operations rebuild both texts and are merged
+func checkScript(t *testing.T, ops []Op, a, b string) {
+	t.Helper()
+	var from, to strings.Builder
+	for i, op := range ops {
+		if i > 0 && ops[i-1].Type == op.Type {
+			t.Errorf("operations %d and %d are both %s", i-1, i, op.Type)
+		}
+		switch op.Type {
+		case Equal:
+			from.WriteString(op.Text)
+			to.WriteString(op.Text)
+		case Delete:
+			from.WriteString(op.Text)
+		case Insert:
+			to.WriteString(op.Text)
+		}
+	}
+	if from.String() != a || to.String() != b {
+		t.Errorf("operations rebuild %q -> %q, want %q -> %q", from.String(), to.String(), a, b)
+	}
+}
@@ -0,0 +1,153 @@
+package feed
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testFeed(items ...*Item) *Feed {
+	return &Feed{
+		Title:       "News",
+		Link:        "https://news.test",
+		SelfLink:    "https://news.test/feeds/latest.rss",
+		Description: "The latest articles",
+		Language:    "bn",
+		Updated:     time.Date(2024, 5, 2, 8, 0, 0, 0, time.UTC),
+		Items:       items,
+	}
+}
+
+func TestRSS(t *testing.T) {
+	published := time.Date(2024, 5, 1, 12, 0, 0, 0, time.FixedZone("BST", 6*3600))
+	tests := []struct {
+		name     string
+		item     *Item
+		contains []string
+		excludes []string
+	}{
+		{
+			name: "full item",
+			item: &Item{
+				Title: "A & B", Link: "https://news.test/a", GUID: "https://news.test/a", Description: "Summary",
+				Author: "Reporter", Categories: []string{"Politics", "World"}, Published: published,
+				Media: &Media{URL: "https://news.test/a.jpg", Type: "image/jpeg", Medium: "image"},
+			},
+			contains: []string{
+				"<title>A &amp; B</title>",
+				`<guid isPermaLink="true">https://news.test/a</guid>`,
+				"<dc:creator>Reporter</dc:creator>",
+				"<category>Politics</category>", "<category>World</category>",
+				"<pubDate>Wed, 01 May 2024 06:00:00 +0000</pubDate>",
+				`<media:content url="https://news.test/a.jpg" type="image/jpeg" medium="image"></media:content>`,
+			},
+			excludes: []string{"<enclosure"},
+		},
+		{
+			name:     "guid other than the link",
+			item:     &Item{Title: "A", Link: "https://news.test/a", GUID: "urn:news:1"},
+			contains: []string{`<guid isPermaLink="false">urn:news:1</guid>`},
+			excludes: []string{"<pubDate>", "<media:content", "<dc:creator>"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, err := RSS(testFeed(tt.item))
+			if err != nil {
+				t.Fatalf("RSS() error = %v", err)
+			}
+			document := string(body)
+			checkWellFormed(t, body)
+			for _, want := range append(tt.contains,
+				`xmlns:media="http://search.yahoo.com/mrss/"`,
+				`<atom:link href="https://news.test/feeds/latest.rss" rel="self" type="application/rss+xml"></atom:link>`,
+				"<language>bn</language>",
+				"<lastBuildDate>Thu, 02 May 2024 08:00:00 +0000</lastBuildDate>",
+			) {
+				if !strings.Contains(document, want) {
+					t.Errorf("RSS() misses %s in\n%s", want, document)
+				}
+			}
+			for _, unwanted := range tt.excludes {
+				if strings.Contains(document, unwanted) {
+					t.Errorf("RSS() has %s in\n%s", unwanted, document)
+				}
+			}
+		})
+	}
+}
+
+func TestAtom(t *testing.T) {
+	published := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		item     *Item
+		contains []string
+		excludes []string
+	}{
+		{
+			name: "full item",
+			item: &Item{
+				Title: "A", Link: "https://news.test/a", GUID: "https://news.test/a", Description: "Summary",
+				Author: "Reporter", Categories: []string{"Politics"}, Published: published, Updated: published.Add(time.Hour),
+				Media: &Media{URL: "https://news.test/a.jpg", Type: "image/jpeg", Medium: "image"},
+			},
+			contains: []string{
+				"<id>https://news.test/a</id>",
+				"<updated>2024-05-01T13:00:00Z</updated>",
+				"<published>2024-05-01T12:00:00Z</published>",
+				"<name>Reporter</name>",
+				`<category term="Politics"></category>`,
+				"<summary>Summary</summary>",
+				`<link href="https://news.test/a.jpg" rel="enclosure" type="image/jpeg"></link>`,
+			},
+			excludes: []string{"length="},
+		},
+		{
+			name:     "updated defaults to published",
+			item:     &Item{Title: "A", Link: "https://news.test/a", GUID: "https://news.test/a", Published: published},
+			contains: []string{"<updated>2024-05-01T12:00:00Z</updated>"},
+			excludes: []string{"<author>", `rel="enclosure"`, "<summary>"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, err := Atom(testFeed(tt.item))
+			if err != nil {
+				t.Fatalf("Atom() error = %v", err)
+			}
+			document := string(body)
+			checkWellFormed(t, body)
+			for _, want := range append(tt.contains,
+				`<feed xmlns="http://www.w3.org/2005/Atom" xml:lang="bn">`,
+				`<link href="https://news.test/feeds/latest.rss" rel="self" type="application/atom+xml"></link>`,
+			) {
+				if !strings.Contains(document, want) {
+					t.Errorf("Atom() misses %s in\n%s", want, document)
+				}
+			}
+			for _, unwanted := range tt.excludes {
+				if strings.Contains(document, unwanted) {
+					t.Errorf("Atom() has %s in\n%s", unwanted, document)
+				}
+			}
+		})
+	}
+}
+
+// checkWellFormed reads every token of the document
+func checkWellFormed(t *testing.T, body []byte) {
+	t.Helper()
+	decoder := xml.NewDecoder(bytes.NewReader(body))
+	for {
+		if _, err := decoder.Token(); err != nil {
+			if err != io.EOF {
+				t.Errorf("document is not well formed: %v", err)
+			}
+			return
+		}
+	}
+}
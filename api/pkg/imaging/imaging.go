@@ -0,0 +1,219 @@
+// Package imaging turns an uploaded image into the renditions served with an
+// article: resized JPEG or PNG files, their WebP variants and a tiny blurred
+// placeholder shown while they load. Every file is re-encoded from the decoded
+// pixels, so EXIF and other metadata of the upload never reach the output.
+package imaging
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif" // Register the GIF decoder
+	"image/jpeg"
+	"image/png"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/HugoSmits86/nativewebp"
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp" // Register the WebP decoder
+)
+
+const (
+	// maxPixels rejects images that would take too much memory to decode
+	maxPixels = 50_000_000
+	// placeholderWidth is the width of the blur placeholder in pixels
+	placeholderWidth = 16
+)
+
+// ErrUnsupportedImage is returned when the upload is not a decodable JPEG, PNG, GIF or WebP image
+var ErrUnsupportedImage = errors.New("unsupported image")
+
+// Size is a named rendition width
+type Size struct {
+	Name  string
+	Width int
+}
+
+// ParseSizes parses name:width pairs, e.g. small:400,medium:800,large:1600
+func ParseSizes(value string) ([]Size, error) {
+	var sizes []Size
+	for _, pair := range strings.Split(value, ",") {
+		name, width, ok := strings.Cut(strings.TrimSpace(pair), ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid image size %q", pair)
+		}
+		w, err := strconv.Atoi(strings.TrimSpace(width))
+		if err != nil || w < 1 {
+			return nil, fmt.Errorf("invalid width in image size %q", pair)
+		}
+		sizes = append(sizes, Size{Name: strings.TrimSpace(name), Width: w})
+	}
+	return sizes, nil
+}
+
+// File is one encoded rendition
+type File struct {
+	Name        string // Rendition name with extension, e.g. small.jpg or small.webp
+	ContentType string
+	Width       int
+	Height      int
+	Data        []byte
+}
+
+// Result holds the files generated from an original
+type Result struct {
+	Original    File            // Full size copy, metadata stripped
+	Renditions  map[string]File // By size name
+	WebP        map[string]File // By size name
+	Placeholder string          // Data URI of the blurred placeholder
+	Width       int             // Of the original, after orientation
+	Height      int
+}
+
+// Process decodes the original, applies its EXIF orientation and encodes the
+// renditions. Images with transparency are encoded as PNG, others as JPEG at
+// quality. Renditions are never wider than the original.
+func Process(r io.Reader, sizes []Size, quality int) (*Result, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image: %w", err)
+	}
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupportedImage, err)
+	}
+	if config.Width*config.Height > maxPixels {
+		return nil, fmt.Errorf("%w: %dx%d is too large", ErrUnsupportedImage, config.Width, config.Height)
+	}
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupportedImage, err)
+	}
+	if format == "jpeg" {
+		src = orient(src, jpegOrientation(data))
+	}
+
+	bounds := src.Bounds()
+	result := &Result{
+		Renditions: make(map[string]File, len(sizes)),
+		WebP:       make(map[string]File, len(sizes)),
+		Width:      bounds.Dx(),
+		Height:     bounds.Dy(),
+	}
+	opaque := isOpaque(src)
+
+	if result.Original, err = encode("original", src, opaque, 95); err != nil {
+		return nil, err
+	}
+	for _, size := range sizes {
+		img := resize(src, size.Width)
+		if result.Renditions[size.Name], err = encode(size.Name, img, opaque, quality); err != nil {
+			return nil, err
+		}
+		if result.WebP[size.Name], err = encodeWebP(size.Name, img); err != nil {
+			return nil, err
+		}
+	}
+	if result.Placeholder, err = placeholder(src); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// resize scales img down to width, keeping the aspect ratio
+func resize(img image.Image, width int) image.Image {
+	bounds := img.Bounds()
+	if bounds.Dx() <= width {
+		return img
+	}
+	height := bounds.Dy() * width / bounds.Dx()
+	if height < 1 {
+		height = 1
+	}
+	dst := image.NewNRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, bounds, draw.Src, nil)
+	return dst
+}
+
+func encode(name string, img image.Image, opaque bool, quality int) (File, error) {
+	var buf bytes.Buffer
+	file := File{Width: img.Bounds().Dx(), Height: img.Bounds().Dy()}
+	if opaque {
+		if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality}); err != nil {
+			return File{}, fmt.Errorf("failed to encode %s: %w", name, err)
+		}
+		file.Name, file.ContentType = name+".jpg", "image/jpeg"
+	} else {
+		if err := png.Encode(&buf, img); err != nil {
+			return File{}, fmt.Errorf("failed to encode %s: %w", name, err)
+		}
+		file.Name, file.ContentType = name+".png", "image/png"
+	}
+	file.Data = buf.Bytes()
+	return file, nil
+}
+
+func encodeWebP(name string, img image.Image) (File, error) {
+	var buf bytes.Buffer
+	if err := nativewebp.Encode(&buf, img, nil); err != nil {
+		return File{}, fmt.Errorf("failed to encode %s.webp: %w", name, err)
+	}
+	return File{
+		Name:        name + ".webp",
+		ContentType: "image/webp",
+		Width:       img.Bounds().Dx(),
+		Height:      img.Bounds().Dy(),
+		Data:        buf.Bytes(),
+	}, nil
+}
+
+// placeholder shrinks the image to a few pixels, blurs it and returns it as a
+// data URI small enough to inline in list responses
+func placeholder(img image.Image) (string, error) {
+	scaled := resize(img, placeholderWidth)
+	small := image.NewNRGBA(scaled.Bounds().Sub(scaled.Bounds().Min))
+	draw.Draw(small, small.Bounds(), scaled, scaled.Bounds().Min, draw.Src)
+	blurred := boxBlur(small)
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, blurred); err != nil {
+		return "", fmt.Errorf("failed to encode placeholder: %w", err)
+	}
+	return "data:image/png;base64," + base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+// boxBlur averages every pixel with its neighbours
+func boxBlur(img *image.NRGBA) *image.NRGBA {
+	bounds := img.Bounds()
+	dst := image.NewNRGBA(bounds)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			var r, g, b, a, n int
+			for dy := -1; dy <= 1; dy++ {
+				for dx := -1; dx <= 1; dx++ {
+					p := image.Pt(x+dx, y+dy)
+					if !p.In(bounds) {
+						continue
+					}
+					c := img.NRGBAAt(p.X, p.Y)
+					r, g, b, a, n = r+int(c.R), g+int(c.G), b+int(c.B), a+int(c.A), n+1
+				}
+			}
+			dst.SetNRGBA(x, y, color.NRGBA{R: uint8(r / n), G: uint8(g / n), B: uint8(b / n), A: uint8(a / n)})
+		}
+	}
+	return dst
+}
+
+// isOpaque reports whether the image has no transparent pixels
+func isOpaque(img image.Image) bool {
+	if img, ok := img.(interface{ Opaque() bool }); ok {
+		return img.Opaque()
+	}
+	return false
+}
@@ -0,0 +1,128 @@
+package imaging
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/JubaerHossain/rootx/pkg/core/config"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// cacheControl is sent with the generated files. Their keys change with the
+// content, so they never need revalidation.
+const cacheControl = "public, max-age=31536000, immutable"
+
+// Disk stores generated files under slash separated keys
+type Disk interface {
+	// Put stores the file and returns its public URL
+	Put(ctx context.Context, key string, file File) (string, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// NewDisk returns the disk selected by STORAGE_DISK, local or s3
+func NewDisk(cfg *config.Config) (Disk, error) {
+	switch cfg.StorageDisk {
+	case "local":
+		root := cfg.StoragePath
+		if root == "" {
+			root = "storage"
+		}
+		return &LocalDisk{Root: root, BaseURL: cfg.Domain + "/uploads"}, nil
+	case "s3":
+		sess, err := session.NewSession(&aws.Config{
+			Region:      aws.String(cfg.AwsRegion),
+			Credentials: credentials.NewStaticCredentials(cfg.AwsAccessKey, cfg.AwsSecretKey, ""),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create AWS session: %w", err)
+		}
+		return &S3Disk{
+			Client:  s3.New(sess),
+			Bucket:  cfg.AwsBucket,
+			BaseURL: fmt.Sprintf("%s/%s", strings.TrimSuffix(cfg.AwsEndpoint, "/"), cfg.AwsBucket),
+		}, nil
+	default:
+		return nil, fmt.Errorf("storage disk %q not supported", cfg.StorageDisk)
+	}
+}
+
+// LocalDisk stores files below Root, served by the /uploads file server
+type LocalDisk struct {
+	Root    string
+	BaseURL string
+}
+
+func (d *LocalDisk) Put(ctx context.Context, key string, file File) (string, error) {
+	path, err := d.path(key)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", fmt.Errorf("failed to create directories: %w", err)
+	}
+	if err := os.WriteFile(path, file.Data, 0o644); err != nil {
+		return "", fmt.Errorf("failed to save file to local disk: %w", err)
+	}
+	return d.BaseURL + "/" + key, nil
+}
+
+func (d *LocalDisk) Delete(ctx context.Context, key string) error {
+	path, err := d.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete file from local disk: %w", err)
+	}
+	// Drop the directory of the image once it is empty
+	os.Remove(filepath.Dir(path))
+	return nil
+}
+
+// path maps a key to a file below Root, refusing keys that escape it
+func (d *LocalDisk) path(key string) (string, error) {
+	clean := filepath.Clean(filepath.FromSlash(key))
+	if filepath.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("invalid storage key %q", key)
+	}
+	return filepath.Join(d.Root, clean), nil
+}
+
+// S3Disk stores public files in an S3 bucket
+type S3Disk struct {
+	Client  *s3.S3
+	Bucket  string
+	BaseURL string
+}
+
+func (d *S3Disk) Put(ctx context.Context, key string, file File) (string, error) {
+	_, err := d.Client.PutObjectWithContext(ctx, &s3.PutObjectInput{
+		Bucket:       aws.String(d.Bucket),
+		Key:          aws.String(key),
+		Body:         bytes.NewReader(file.Data),
+		ContentType:  aws.String(file.ContentType),
+		CacheControl: aws.String(cacheControl),
+		ACL:          aws.String("public-read"),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to upload file to S3: %w", err)
+	}
+	return d.BaseURL + "/" + key, nil
+}
+
+func (d *S3Disk) Delete(ctx context.Context, key string) error {
+	if _, err := d.Client.DeleteObjectWithContext(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(d.Bucket),
+		Key:    aws.String(key),
+	}); err != nil {
+		return fmt.Errorf("failed to delete file from S3: %w", err)
+	}
+	return nil
+}
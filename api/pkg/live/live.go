@@ -0,0 +1,211 @@
+// Package live fans events out to long lived subscribers, such as Server-Sent
+// Events streams. With Redis, events are numbered by a shared counter and go
+// through pub/sub so every replica sees the events published by the others.
+// Without it, they stay in the process.
+package live
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+
+	"github.com/go-redis/redis/v8"
+	"go.uber.org/zap"
+)
+
+// subscriberBuffer is the number of events a subscriber may lag behind before
+// it is dropped. A dropped client reconnects and resumes from its last event.
+const subscriberBuffer = 16
+
+// ErrClosed is returned once the broker has been closed
+var ErrClosed = errors.New("live: broker closed")
+
+// Event is a message pushed to subscribers. IDs increase with every event.
+type Event struct {
+	ID   uint64          `json:"id"`
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data"`
+}
+
+// Subscription receives the events published after it was made. C is closed
+// when the subscriber falls too far behind or the broker closes.
+type Subscription struct {
+	C chan Event
+}
+
+// Broker publishes events to the subscribers of every replica and keeps the
+// latest ones for subscribers resuming after a disconnect
+type Broker struct {
+	client  *redis.Client
+	channel string
+	replay  int
+	logger  *zap.Logger
+
+	mu          sync.Mutex
+	seq         uint64  // Last event ID, without Redis
+	buffer      []Event // Latest events, in the order they were received
+	subscribers map[*Subscription]struct{}
+	closed      bool
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// New creates a broker on the Redis channel, or in process when client is nil.
+// replay is the number of events kept for resuming subscribers.
+func New(client *redis.Client, channel string, replay int, logger *zap.Logger) *Broker {
+	return &Broker{
+		client:      client,
+		channel:     channel,
+		replay:      replay,
+		logger:      logger,
+		subscribers: map[*Subscription]struct{}{},
+		done:        make(chan struct{}),
+	}
+}
+
+// Start listens to the events published by every replica until Close is called
+func (b *Broker) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	b.cancel = cancel
+	if b.client == nil {
+		close(b.done)
+		return
+	}
+
+	pubsub := b.client.Subscribe(ctx, b.channel)
+	go func() {
+		defer close(b.done)
+		defer pubsub.Close()
+		// The channel is closed with pubsub, go-redis reconnects on its own until then
+		messages := pubsub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case message, ok := <-messages:
+				if !ok {
+					return
+				}
+				var event Event
+				if err := json.Unmarshal([]byte(message.Payload), &event); err != nil {
+					b.logger.Error("Error decoding live event", zap.Error(err))
+					continue
+				}
+				b.deliver(event)
+			}
+		}
+	}()
+}
+
+// Publish sends an event of the type to the subscribers of every replica
+func (b *Broker) Publish(ctx context.Context, eventType string, data interface{}) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	event := Event{Type: eventType, Data: payload}
+
+	if b.client == nil {
+		b.mu.Lock()
+		b.seq++
+		event.ID = b.seq
+		b.mu.Unlock()
+		b.deliver(event)
+		return nil
+	}
+
+	id, err := b.client.Incr(ctx, b.channel+":seq").Result()
+	if err != nil {
+		return err
+	}
+	event.ID = uint64(id)
+	message, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return b.client.Publish(ctx, b.channel, message).Err()
+}
+
+// Subscribe registers a subscriber and returns the buffered events after
+// lastID, 0 for none. complete is false when events after lastID are no longer
+// buffered, the subscriber should then reload what it shows.
+func (b *Broker) Subscribe(lastID uint64) (sub *Subscription, missed []Event, complete bool, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return nil, nil, false, ErrClosed
+	}
+
+	complete = true
+	if lastID > 0 {
+		// The event right after lastID must still be buffered, or lastID must be the latest
+		complete = false
+		for _, event := range b.buffer {
+			if event.ID > lastID {
+				missed = append(missed, event)
+			}
+			if event.ID == lastID || event.ID == lastID+1 {
+				complete = true
+			}
+		}
+	}
+
+	sub = &Subscription{C: make(chan Event, subscriberBuffer)}
+	b.subscribers[sub] = struct{}{}
+	return sub, missed, complete, nil
+}
+
+// Unsubscribe removes a subscriber and closes its channel
+func (b *Broker) Unsubscribe(sub *Subscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.subscribers[sub]; ok {
+		delete(b.subscribers, sub)
+		close(sub.C)
+	}
+}
+
+// Close disconnects every subscriber and stops listening. Subscribing afterwards fails.
+func (b *Broker) Close() {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return
+	}
+	b.closed = true
+	for sub := range b.subscribers {
+		delete(b.subscribers, sub)
+		close(sub.C)
+	}
+	b.mu.Unlock()
+
+	if b.cancel != nil {
+		b.cancel()
+		<-b.done
+	}
+}
+
+// deliver buffers an event and hands it to the subscribers, dropping the ones
+// that cannot keep up
+func (b *Broker) deliver(event Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return
+	}
+
+	b.buffer = append(b.buffer, event)
+	if len(b.buffer) > b.replay {
+		b.buffer = b.buffer[len(b.buffer)-b.replay:]
+	}
+	for sub := range b.subscribers {
+		select {
+		case sub.C <- event:
+		default:
+			delete(b.subscribers, sub)
+			close(sub.C)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package locale
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/JubaerHossain/cn-api/pkg/config"
+)
+
+// Default returns the configured default locale
+func Default() string {
+	return config.GlobalConfig.DefaultLocale
+}
+
+// Supported returns the configured list of locales content can be served in
+func Supported() []string {
+	var locales []string
+	for _, l := range strings.Split(config.GlobalConfig.SupportedLocales, ",") {
+		if l = normalize(l); l != "" {
+			locales = append(locales, l)
+		}
+	}
+	return locales
+}
+
+// IsSupported reports whether the locale is one of the supported locales
+func IsSupported(locale string) bool {
+	locale = normalize(locale)
+	for _, l := range Supported() {
+		if l == locale {
+			return true
+		}
+	}
+	return false
+}
+
+// Negotiate picks the locale for a request from the ?locale= query parameter,
+// then the Accept-Language header, then the configured default.
+func Negotiate(r *http.Request) string {
+	if l := Match(r.URL.Query().Get("locale")); l != "" {
+		return l
+	}
+	for _, candidate := range parseAcceptLanguage(r.Header.Get("Accept-Language")) {
+		if l := Match(candidate); l != "" {
+			return l
+		}
+	}
+	return Default()
+}
+
+// Chain returns the locale followed by its configured fallbacks and finally the
+// default locale, e.g. bn -> en. Each locale appears once.
+func Chain(locale string) []string {
+	fallbacks := fallbackMap()
+	seen := map[string]bool{}
+	var chain []string
+	for l := normalize(locale); l != "" && !seen[l]; l = fallbacks[l] {
+		seen[l] = true
+		chain = append(chain, l)
+	}
+	if def := Default(); !seen[def] {
+		chain = append(chain, def)
+	}
+	return chain
+}
+
+// fallbackMap parses LOCALE_FALLBACKS, a comma separated list of from:to pairs such as "bn:en"
+func fallbackMap() map[string]string {
+	fallbacks := map[string]string{}
+	for _, pair := range strings.Split(config.GlobalConfig.LocaleFallbacks, ",") {
+		from, to, ok := strings.Cut(pair, ":")
+		if !ok {
+			continue
+		}
+		if from, to = normalize(from), normalize(to); from != "" && to != "" {
+			fallbacks[from] = to
+		}
+	}
+	return fallbacks
+}
+
+// Match returns the supported locale for a language tag, trying the full tag
+// first and then its primary language (bn-BD -> bn), empty when none matches
+func Match(tag string) string {
+	tag = normalize(tag)
+	if tag == "" {
+		return ""
+	}
+	if IsSupported(tag) {
+		return tag
+	}
+	if base, _, ok := strings.Cut(tag, "-"); ok && IsSupported(base) {
+		return base
+	}
+	return ""
+}
+
+// parseAcceptLanguage returns the language tags of an Accept-Language header ordered by quality
+func parseAcceptLanguage(header string) []string {
+	type weighted struct {
+		tag string
+		q   float64
+	}
+	var tags []weighted
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if tag == "" || tag == "*" {
+			continue
+		}
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if q > 0 {
+			tags = append(tags, weighted{tag: tag, q: q})
+		}
+	}
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].q > tags[j].q })
+
+	result := make([]string, len(tags))
+	for i, t := range tags {
+		result[i] = t.tag
+	}
+	return result
+}
+
+func normalize(tag string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(tag)), "_", "-")
+}
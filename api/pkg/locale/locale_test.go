@@ -0,0 +1,94 @@
+package locale
+
+import (
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/JubaerHossain/cn-api/pkg/config"
+)
+
+// configure sets the locale settings for a test and restores them after it
+func configure(t *testing.T, def, supported, fallbacks string) {
+	t.Helper()
+	saved := *config.GlobalConfig
+	t.Cleanup(func() { *config.GlobalConfig = saved })
+	config.GlobalConfig.DefaultLocale = def
+	config.GlobalConfig.SupportedLocales = supported
+	config.GlobalConfig.LocaleFallbacks = fallbacks
+}
+
+func TestChain(t *testing.T) {
+	tests := []struct {
+		name      string
+		fallbacks string
+		locale    string
+		want      []string
+	}{
+		{name: "default only", locale: "en", want: []string{"en"}},
+		{name: "no fallback", locale: "bn", want: []string{"bn", "en"}},
+		{name: "fallback to default", fallbacks: "bn:en", locale: "bn", want: []string{"bn", "en"}},
+		{name: "fallback chain", fallbacks: "bn-in:bn,bn:hi", locale: "bn-IN", want: []string{"bn-in", "bn", "hi", "en"}},
+		{name: "cycle", fallbacks: "bn:hi,hi:bn", locale: "bn", want: []string{"bn", "hi", "en"}},
+		{name: "malformed pairs", fallbacks: "bn,:hi,bn:", locale: "bn", want: []string{"bn", "en"}},
+		{name: "underscore tag", fallbacks: "bn:hi", locale: " BN_bd ", want: []string{"bn-bd", "en"}},
+		{name: "empty locale", locale: "", want: []string{"en"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configure(t, "en", "en,bn,hi", tt.fallbacks)
+			if got := Chain(tt.locale); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Chain(%q) = %v, want %v", tt.locale, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatch(t *testing.T) {
+	configure(t, "en", "en, bn ,pt-br", "")
+	tests := []struct {
+		tag  string
+		want string
+	}{
+		{tag: "bn", want: "bn"},
+		{tag: "BN-bd", want: "bn"},
+		{tag: "pt_BR", want: "pt-br"},
+		{tag: "pt", want: ""},
+		{tag: "fr", want: ""},
+		{tag: "", want: ""},
+	}
+	for _, tt := range tests {
+		if got := Match(tt.tag); got != tt.want {
+			t.Errorf("Match(%q) = %q, want %q", tt.tag, got, tt.want)
+		}
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	configure(t, "en", "en,bn,hi", "")
+	tests := []struct {
+		name           string
+		query          string
+		acceptLanguage string
+		want           string
+	}{
+		{name: "default", want: "en"},
+		{name: "query", query: "?locale=bn", acceptLanguage: "hi", want: "bn"},
+		{name: "unsupported query", query: "?locale=fr", acceptLanguage: "hi", want: "hi"},
+		{name: "header quality", acceptLanguage: "fr;q=0.9, hi;q=0.5, bn;q=0.8", want: "bn"},
+		{name: "header region", acceptLanguage: "bn-BD", want: "bn"},
+		{name: "header excluded", acceptLanguage: "bn;q=0, *", want: "en"},
+		{name: "header bad quality", acceptLanguage: "bn;q=x, hi", want: "hi"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/news"+tt.query, nil)
+			if tt.acceptLanguage != "" {
+				r.Header.Set("Accept-Language", tt.acceptLanguage)
+			}
+			if got := Negotiate(r); got != tt.want {
+				t.Errorf("Negotiate() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,178 @@
+// Package mailer sends email through a pluggable driver: SMTP in production,
+// or a file outbox that writes each message as an .eml file for local
+// development.
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/JubaerHossain/cn-api/pkg/config"
+)
+
+// Message is an email with a plain text body, an HTML alternative, or both
+type Message struct {
+	From    string // MAIL_FROM when empty
+	To      string
+	Subject string
+	Text    string
+	HTML    string
+	Headers map[string]string // Extra headers, e.g. List-Unsubscribe
+}
+
+// Mailer delivers messages
+type Mailer interface {
+	Send(ctx context.Context, msg *Message) error
+}
+
+// New returns the mailer selected by MAIL_DRIVER, smtp or file. The file
+// driver keeps every message readable on disk, confirm and unsubscribe tokens
+// included, so it is refused outside development.
+func New(cfg *config.Config) (Mailer, error) {
+	switch cfg.MailDriver {
+	case "smtp":
+		if cfg.MailHost == "" {
+			return nil, errors.New("MAIL_HOST is required by the smtp mail driver")
+		}
+		return &SMTP{
+			Host:     cfg.MailHost,
+			Port:     cfg.MailPort,
+			Username: cfg.MailUsername,
+			Password: cfg.MailPassword,
+			From:     cfg.MailFrom,
+		}, nil
+	case "file":
+		if cfg.AppEnv != "development" {
+			return nil, fmt.Errorf("the file mail driver is only allowed when APP_ENV is development, not %q", cfg.AppEnv)
+		}
+		return &Outbox{Dir: cfg.MailOutbox, From: cfg.MailFrom}, nil
+	default:
+		return nil, fmt.Errorf("mail driver %q not supported", cfg.MailDriver)
+	}
+}
+
+// IsPermanent reports whether the server refused the message for good, a 5xx
+// SMTP reply such as an unknown mailbox. Sending it again would fail the same way.
+func IsPermanent(err error) bool {
+	var reply *textproto.Error
+	return errors.As(err, &reply) && reply.Code >= 500 && reply.Code < 600
+}
+
+// encode renders the message as RFC 5322 text. A message with both bodies is
+// sent as multipart/alternative, plain text first.
+func encode(msg *Message, from string, now time.Time) ([]byte, error) {
+	if msg.From != "" {
+		from = msg.From
+	}
+	sender, err := mail.ParseAddress(from)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sender %q: %w", from, err)
+	}
+	recipient, err := mail.ParseAddress(msg.To)
+	if err != nil {
+		return nil, fmt.Errorf("invalid recipient %q: %w", msg.To, err)
+	}
+	if msg.Text == "" && msg.HTML == "" {
+		return nil, errors.New("message has no body")
+	}
+
+	var buf bytes.Buffer
+	headers := map[string]string{
+		"From":         sender.String(),
+		"To":           recipient.String(),
+		"Subject":      mime.QEncoding.Encode("utf-8", msg.Subject),
+		"Date":         now.Format(time.RFC1123Z),
+		"Message-ID":   messageID(sender.Address),
+		"MIME-Version": "1.0",
+	}
+	for name, value := range msg.Headers {
+		headers[textproto.CanonicalMIMEHeaderKey(name)] = value
+	}
+
+	switch {
+	case msg.Text != "" && msg.HTML != "":
+		// The writer writes nothing before the first part, so the headers go first
+		parts := multipart.NewWriter(&buf)
+		headers["Content-Type"] = "multipart/alternative; boundary=" + parts.Boundary()
+		writeHeaders(&buf, headers)
+		for _, part := range []struct{ contentType, content string }{
+			{"text/plain; charset=utf-8", msg.Text},
+			{"text/html; charset=utf-8", msg.HTML},
+		} {
+			w, err := parts.CreatePart(textproto.MIMEHeader{
+				"Content-Type":              {part.contentType},
+				"Content-Transfer-Encoding": {"quoted-printable"},
+			})
+			if err != nil {
+				return nil, err
+			}
+			if err := writeQuotedPrintable(w, part.content); err != nil {
+				return nil, err
+			}
+		}
+		if err := parts.Close(); err != nil {
+			return nil, err
+		}
+	default:
+		content, contentType := msg.Text, "text/plain; charset=utf-8"
+		if content == "" {
+			content, contentType = msg.HTML, "text/html; charset=utf-8"
+		}
+		headers["Content-Type"] = contentType
+		headers["Content-Transfer-Encoding"] = "quoted-printable"
+		writeHeaders(&buf, headers)
+		if err := writeQuotedPrintable(&buf, content); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// writeHeaders writes the headers in a stable order followed by the blank line
+func writeHeaders(buf *bytes.Buffer, headers map[string]string) {
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		// Header values must not break out of their line
+		value := strings.NewReplacer("\r", "", "\n", "").Replace(headers[name])
+		fmt.Fprintf(buf, "%s: %s\r\n", name, value)
+	}
+	buf.WriteString("\r\n")
+}
+
+// writeQuotedPrintable writes content with CRLF line ends, quoted-printable encoded
+func writeQuotedPrintable(w io.Writer, content string) error {
+	qp := quotedprintable.NewWriter(w)
+	content = strings.ReplaceAll(strings.ReplaceAll(content, "\r\n", "\n"), "\n", "\r\n")
+	if _, err := qp.Write([]byte(content)); err != nil {
+		return err
+	}
+	return qp.Close()
+}
+
+// messageID returns a unique Message-ID in the domain of the sender
+func messageID(address string) string {
+	domain := "localhost"
+	if at := strings.LastIndex(address, "@"); at >= 0 && at < len(address)-1 {
+		domain = address[at+1:]
+	}
+	id := make([]byte, 12)
+	_, _ = rand.Read(id)
+	return fmt.Sprintf("<%d.%s@%s>", time.Now().UnixNano(), hex.EncodeToString(id), domain)
+}
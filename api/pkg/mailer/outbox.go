@@ -0,0 +1,40 @@
+package mailer
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Outbox writes every message to an .eml file in Dir instead of sending it,
+// so mail can be read in any mail client during local development
+type Outbox struct {
+	Dir  string
+	From string
+}
+
+// Send implements Mailer
+func (o *Outbox) Send(ctx context.Context, msg *Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	now := time.Now()
+	data, err := encode(msg, o.From, now)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(o.Dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create outbox: %w", err)
+	}
+	suffix := make([]byte, 4)
+	_, _ = rand.Read(suffix)
+	name := fmt.Sprintf("%s-%s.eml", now.UTC().Format("20060102-150405.000000"), hex.EncodeToString(suffix))
+	if err := os.WriteFile(filepath.Join(o.Dir, name), data, 0o644); err != nil {
+		return fmt.Errorf("failed to write message: %w", err)
+	}
+	return nil
+}
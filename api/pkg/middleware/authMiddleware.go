@@ -46,8 +46,47 @@ func AuthMiddleware(app *app.App, next http.Handler) http.Handler {
 	})
 }
 
+// OptionalAuthMiddleware adds the claims of a valid JWT token to the context
+// and lets requests without a token through, for routes that also serve anonymous readers
+func OptionalAuthMiddleware(app *app.App, next http.Handler) http.Handler {
+	authenticated := AuthMiddleware(app, next)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		authenticated.ServeHTTP(w, r)
+	})
+}
+
 // GetClaimsFromContext retrieves claims from request context
 func GetClaimsFromContext(ctx context.Context) (jwt.MapClaims, bool) {
 	claims, ok := ctx.Value(claimsKey).(jwt.MapClaims)
 	return claims, ok
 }
+
+// GetUserIDFromContext retrieves the authenticated user ID (the JWT "sub" claim) from request context
+func GetUserIDFromContext(ctx context.Context) (uint, bool) {
+	claims, ok := GetClaimsFromContext(ctx)
+	if !ok {
+		return 0, false
+	}
+	sub, ok := claims["sub"].(float64)
+	if !ok || sub <= 0 {
+		return 0, false
+	}
+	return uint(sub), true
+}
+
+// GetRoleFromContext retrieves the authenticated user's role ID (the JWT "role" claim) from request context
+func GetRoleFromContext(ctx context.Context) (uint, bool) {
+	claims, ok := GetClaimsFromContext(ctx)
+	if !ok {
+		return 0, false
+	}
+	role, ok := claims["role"].(float64)
+	if !ok || role <= 0 {
+		return 0, false
+	}
+	return uint(role), true
+}
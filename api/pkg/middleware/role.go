@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/JubaerHossain/cn-api/pkg/config"
+	"github.com/JubaerHossain/rootx/pkg/utils"
+)
+
+// Workflow roles. Editors and admins manage the newsroom: they moderate,
+// publish, export and send. Reporters write and submit their own articles.
+const (
+	RoleReporter = "reporter"
+	RoleEditor   = "editor"
+	RoleAdmin    = "admin"
+)
+
+// WorkflowRole maps the role ID of the authenticated user to a workflow role through WORKFLOW_ROLES
+func WorkflowRole(ctx context.Context) (string, bool) {
+	roleID, ok := GetRoleFromContext(ctx)
+	if !ok {
+		return "", false
+	}
+	for _, pair := range strings.Split(config.GlobalConfig.WorkflowRoles, ",") {
+		id, role, found := strings.Cut(strings.TrimSpace(pair), ":")
+		if !found {
+			continue
+		}
+		if parsed, err := strconv.ParseUint(strings.TrimSpace(id), 10, 64); err == nil && uint(parsed) == roleID {
+			return strings.TrimSpace(role), true
+		}
+	}
+	return "", false
+}
+
+// RoleMiddleware lets through users whose workflow role is one of roles and
+// answers 403 otherwise. It runs inside AuthMiddleware, which sets the claims.
+func RoleMiddleware(next http.Handler, roles ...string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		role, ok := WorkflowRole(r.Context())
+		if ok {
+			for _, allowed := range roles {
+				if role == allowed {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+		}
+		utils.WriteJSONError(w, http.StatusForbidden, "Forbidden: your role may not do this")
+	})
+}
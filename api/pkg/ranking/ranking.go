@@ -0,0 +1,123 @@
+package ranking
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// Window is a sliding time window whose views decay with a half life, so recent
+// views weigh more than old ones
+type Window struct {
+	Name     string
+	Span     time.Duration
+	HalfLife time.Duration
+}
+
+// Trending favours what is being read right now
+var Trending = Window{Name: "trending", Span: 48 * time.Hour, HalfLife: 6 * time.Hour}
+
+// MostRead are the windows of the most-read lists, by query value
+var MostRead = map[string]Window{
+	"24h": {Name: "24h", Span: 24 * time.Hour, HalfLife: 24 * time.Hour},
+	"7d":  {Name: "7d", Span: 7 * 24 * time.Hour, HalfLife: 7 * 24 * time.Hour},
+	"30d": {Name: "30d", Span: 30 * 24 * time.Hour, HalfLife: 30 * 24 * time.Hour},
+}
+
+// Windows lists every window views are ranked in
+func Windows() []Window {
+	return []Window{Trending, MostRead["24h"], MostRead["7d"], MostRead["30d"]}
+}
+
+// weight is the factor of a view at t in a ranking whose scores are relative to epoch
+func (w Window) weight(t, epoch time.Time) float64 {
+	return math.Exp2(t.Sub(epoch).Hours() / w.HalfLife.Hours())
+}
+
+// Bucket is the number of views of an item in the period starting at Start
+type Bucket struct {
+	ID    uint
+	Start time.Time
+	Views int64
+}
+
+// Store keeps the decayed scores of each ranking
+type Store interface {
+	// Top returns the IDs with the highest scores, ok is false when the ranking
+	// is not built
+	Top(ctx context.Context, key string, limit int) (ids []uint, ok bool, err error)
+	// Replace stores a freshly built ranking, scored relative to epoch, for ttl
+	Replace(ctx context.Context, key string, scores map[uint]float64, epoch time.Time, ttl time.Duration) error
+	// Add adds views made now to a built ranking, and does nothing when it is not built
+	Add(ctx context.Context, key string, halfLife time.Duration, views map[uint]int64) error
+}
+
+// RebuildFunc returns the view buckets of a scope that started at or after since
+type RebuildFunc func(ctx context.Context, since time.Time) ([]Bucket, error)
+
+// Ranker ranks items by their decayed views. Rankings are built from the view
+// history and expire after ttl, which moves the windows forward; views recorded
+// in between are added to the built rankings.
+type Ranker struct {
+	store Store
+	ttl   time.Duration
+
+	mu sync.Mutex
+}
+
+// New creates a ranker that rebuilds its rankings every ttl
+func New(store Store, ttl time.Duration) *Ranker {
+	return &Ranker{store: store, ttl: ttl}
+}
+
+// Key returns the ranking key of a window and scope
+func Key(window Window, scope string) string {
+	return fmt.Sprintf("%s:%s", window.Name, scope)
+}
+
+// Top returns the IDs ranked highest in the window and scope, building the
+// ranking with rebuild when it is cold
+func (r *Ranker) Top(ctx context.Context, window Window, scope string, limit int, rebuild RebuildFunc) ([]uint, error) {
+	key := Key(window, scope)
+	ids, ok, err := r.store.Top(ctx, key, limit)
+	if err != nil || ok {
+		return ids, err
+	}
+
+	// One build at a time, the others find the ranking built when they get the lock
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if ids, ok, err := r.store.Top(ctx, key, limit); err != nil || ok {
+		return ids, err
+	}
+
+	now := time.Now()
+	buckets, err := rebuild(ctx, now.Add(-window.Span))
+	if err != nil {
+		return nil, fmt.Errorf("failed to rebuild ranking %s: %w", key, err)
+	}
+	scores := make(map[uint]float64)
+	for _, bucket := range buckets {
+		scores[bucket.ID] += float64(bucket.Views) * window.weight(bucket.Start, now)
+	}
+	if err := r.store.Replace(ctx, key, scores, now, r.ttl); err != nil {
+		return nil, fmt.Errorf("failed to store ranking %s: %w", key, err)
+	}
+	ids, _, err = r.store.Top(ctx, key, limit)
+	return ids, err
+}
+
+// Record adds views made now to the built rankings of every window in the scope
+func (r *Ranker) Record(ctx context.Context, scope string, views map[uint]int64) error {
+	if len(views) == 0 {
+		return nil
+	}
+	for _, window := range Windows() {
+		if err := r.store.Add(ctx, Key(window, scope), window.HalfLife, views); err != nil {
+			return fmt.Errorf("failed to record ranking %s: %w", Key(window, scope), err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,175 @@
+package ranking
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// MemoryStore keeps rankings in the process
+type MemoryStore struct {
+	mu       sync.Mutex
+	rankings map[string]*memoryRanking
+}
+
+type memoryRanking struct {
+	scores  map[uint]float64
+	epoch   time.Time
+	expires time.Time
+}
+
+// NewMemoryStore creates an empty in-process store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{rankings: map[string]*memoryRanking{}}
+}
+
+// get returns the ranking when it is built and not expired. The lock must be held.
+func (s *MemoryStore) get(key string) *memoryRanking {
+	ranking, ok := s.rankings[key]
+	if !ok {
+		return nil
+	}
+	if !time.Now().Before(ranking.expires) {
+		delete(s.rankings, key)
+		return nil
+	}
+	return ranking
+}
+
+// Top implements Store
+func (s *MemoryStore) Top(_ context.Context, key string, limit int) ([]uint, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ranking := s.get(key)
+	if ranking == nil {
+		return nil, false, nil
+	}
+	ids := make([]uint, 0, len(ranking.scores))
+	for id := range ranking.scores {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		if ranking.scores[ids[i]] != ranking.scores[ids[j]] {
+			return ranking.scores[ids[i]] > ranking.scores[ids[j]]
+		}
+		return ids[i] > ids[j]
+	})
+	if len(ids) > limit {
+		ids = ids[:limit]
+	}
+	return ids, true, nil
+}
+
+// Replace implements Store
+func (s *MemoryStore) Replace(_ context.Context, key string, scores map[uint]float64, epoch time.Time, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rankings[key] = &memoryRanking{scores: scores, epoch: epoch, expires: time.Now().Add(ttl)}
+	return nil
+}
+
+// Add implements Store
+func (s *MemoryStore) Add(_ context.Context, key string, halfLife time.Duration, views map[uint]int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ranking := s.get(key)
+	if ranking == nil {
+		return nil
+	}
+	weight := Window{HalfLife: halfLife}.weight(time.Now(), ranking.epoch)
+	for id, n := range views {
+		ranking.scores[id] += float64(n) * weight
+	}
+	return nil
+}
+
+// RedisStore keeps each ranking in a sorted set, next to a key holding the
+// epoch its scores are relative to. A sentinel member with ID 0 marks an empty
+// ranking as built.
+type RedisStore struct {
+	client *redis.Client
+}
+
+// NewRedisStore creates a store on the Redis client
+func NewRedisStore(client *redis.Client) *RedisStore {
+	return &RedisStore{client: client}
+}
+
+// sentinel is the member that keeps a built ranking's sorted set from being
+// empty. Scores are never negative, so it always ranks last.
+const sentinel = "0"
+
+// addScript increments the scores of a built ranking by the views weighted for now
+var addScript = redis.NewScript(`
+local epoch = redis.call('GET', KEYS[2])
+if not epoch then
+	return 0
+end
+local weight = math.pow(2, (tonumber(ARGV[1]) - tonumber(epoch)) / tonumber(ARGV[2]))
+for i = 3, #ARGV, 2 do
+	redis.call('ZINCRBY', KEYS[1], tonumber(ARGV[i + 1]) * weight, ARGV[i])
+end
+return 1
+`)
+
+func rankingKey(key string) string { return "ranking:" + key }
+func epochKey(key string) string   { return "ranking:" + key + ":epoch" }
+
+// Top implements Store
+func (s *RedisStore) Top(ctx context.Context, key string, limit int) ([]uint, bool, error) {
+	members, err := s.client.ZRevRange(ctx, rankingKey(key), 0, int64(limit)).Result()
+	if err != nil {
+		return nil, false, err
+	}
+	if len(members) == 0 {
+		return nil, false, nil
+	}
+	ids := make([]uint, 0, len(members))
+	for _, member := range members {
+		id, err := strconv.ParseUint(member, 10, 64)
+		if err != nil || id == 0 {
+			continue
+		}
+		ids = append(ids, uint(id))
+	}
+	if len(ids) > limit {
+		ids = ids[:limit]
+	}
+	return ids, true, nil
+}
+
+// Replace implements Store
+func (s *RedisStore) Replace(ctx context.Context, key string, scores map[uint]float64, epoch time.Time, ttl time.Duration) error {
+	members := make([]*redis.Z, 0, len(scores)+1)
+	members = append(members, &redis.Z{Score: -1, Member: sentinel})
+	for id, score := range scores {
+		members = append(members, &redis.Z{Score: score, Member: strconv.FormatUint(uint64(id), 10)})
+	}
+	_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, rankingKey(key))
+		pipe.ZAdd(ctx, rankingKey(key), members...)
+		pipe.Expire(ctx, rankingKey(key), ttl)
+		pipe.Set(ctx, epochKey(key), unixSeconds(epoch), ttl)
+		return nil
+	})
+	return err
+}
+
+// Add implements Store
+func (s *RedisStore) Add(ctx context.Context, key string, halfLife time.Duration, views map[uint]int64) error {
+	args := make([]interface{}, 0, 2+2*len(views))
+	args = append(args, unixSeconds(time.Now()), halfLife.Seconds())
+	for id, n := range views {
+		args = append(args, strconv.FormatUint(uint64(id), 10), n)
+	}
+	return addScript.Run(ctx, s.client, []string{rankingKey(key), epochKey(key)}, args...).Err()
+}
+
+// unixSeconds formats t as fractional Unix seconds
+func unixSeconds(t time.Time) string {
+	return strconv.FormatFloat(float64(t.UnixNano())/1e9, 'f', 3, 64)
+}
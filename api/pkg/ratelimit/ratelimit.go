@@ -0,0 +1,129 @@
+// Package ratelimit counts actions per key in fixed windows. With Redis the
+// counts are shared by every replica, otherwise each replica counts its own.
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// Store counts the actions of a key in the current window
+type Store interface {
+	// Incr counts an action of the key and returns the number counted in the
+	// window, which starts with the first action
+	Incr(ctx context.Context, key string, window time.Duration) (int64, error)
+}
+
+// Limiter allows a number of actions per key and window
+type Limiter struct {
+	store  Store
+	limit  int64
+	window time.Duration
+}
+
+// New creates a limiter of limit actions per window, on Redis when client is
+// not nil and in the process otherwise
+func New(client *redis.Client, limit int, window time.Duration) *Limiter {
+	var store Store = NewMemoryStore()
+	if client != nil {
+		store = NewRedisStore(client)
+	}
+	return &Limiter{store: store, limit: int64(limit), window: window}
+}
+
+// Allow counts an action of every key and reports whether none of them went
+// over the limit. Refused actions count too, so a client that keeps trying
+// stays limited until the window ends.
+func (l *Limiter) Allow(ctx context.Context, keys ...string) (bool, error) {
+	allowed := true
+	for _, key := range keys {
+		n, err := l.store.Incr(ctx, key, l.window)
+		if err != nil {
+			return false, err
+		}
+		if n > l.limit {
+			allowed = false
+		}
+	}
+	return allowed, nil
+}
+
+// Window is the time an action counts against the limit
+func (l *Limiter) Window() time.Duration {
+	return l.window
+}
+
+type memoryCount struct {
+	n       int64
+	expires time.Time
+}
+
+// MemoryStore counts in the process
+type MemoryStore struct {
+	mu     sync.Mutex
+	counts map[string]*memoryCount
+	swept  time.Time
+}
+
+// NewMemoryStore creates an empty in-process store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{counts: map[string]*memoryCount{}}
+}
+
+// Incr implements Store. Ended windows are dropped at most once a minute.
+func (s *MemoryStore) Incr(_ context.Context, key string, window time.Duration) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	if now.Sub(s.swept) > time.Minute {
+		for k, count := range s.counts {
+			if !now.Before(count.expires) {
+				delete(s.counts, k)
+			}
+		}
+		s.swept = now
+	}
+	count, ok := s.counts[key]
+	if !ok || !now.Before(count.expires) {
+		count = &memoryCount{expires: now.Add(window)}
+		s.counts[key] = count
+	}
+	count.n++
+	return count.n, nil
+}
+
+// RedisStore counts in Redis so every replica shares the limits
+type RedisStore struct {
+	client *redis.Client
+}
+
+// NewRedisStore creates a store on the Redis client
+func NewRedisStore(client *redis.Client) *RedisStore {
+	return &RedisStore{client: client}
+}
+
+// Incr implements Store. The expiry is set again when a key is found without
+// one, so a failed Expire cannot keep a key forever.
+func (s *RedisStore) Incr(ctx context.Context, key string, window time.Duration) (int64, error) {
+	key = "ratelimit:" + key
+	var (
+		incr *redis.IntCmd
+		ttl  *redis.DurationCmd
+	)
+	if _, err := s.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		incr = pipe.Incr(ctx, key)
+		ttl = pipe.TTL(ctx, key)
+		return nil
+	}); err != nil {
+		return 0, err
+	}
+	if ttl.Val() < 0 {
+		if err := s.client.Expire(ctx, key, window).Err(); err != nil {
+			return 0, err
+		}
+	}
+	return incr.Val(), nil
+}
@@ -0,0 +1,38 @@
+package redisclient
+
+import (
+	"sync"
+
+	"github.com/JubaerHossain/rootx/pkg/core/config"
+	"github.com/go-redis/redis/v8"
+)
+
+var (
+	client     *redis.Client
+	clientOnce sync.Once
+)
+
+// Get returns the shared Redis client for features that need more than the
+// key/value cache service, such as counters, sorted sets and pub/sub. It uses
+// the same REDIS_* settings as the cache and returns nil when IS_REDIS is off.
+func Get(cfg *config.Config) *redis.Client {
+	clientOnce.Do(func() {
+		if !cfg.IsRedis {
+			return
+		}
+		addr := cfg.RedisURI
+		if addr == "" {
+			addr = "localhost:6379"
+		}
+		db := cfg.RedisDB
+		if db < 0 {
+			db = 0
+		}
+		client = redis.NewClient(&redis.Options{
+			Addr:     addr,
+			Password: cfg.RedisPassword,
+			DB:       db,
+		})
+	})
+	return client
+}
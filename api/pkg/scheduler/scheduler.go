@@ -0,0 +1,137 @@
+package scheduler
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Job is the work run on every tick by the replica holding the lease
+type Job func(ctx context.Context) error
+
+// Scheduler runs a job periodically on exactly one replica at a time. Replicas
+// compete for a row in the scheduler_leases table; the holder renews it on every
+// tick and other replicas take over once it expires.
+type Scheduler struct {
+	db       *sql.DB
+	name     string
+	holder   string
+	interval time.Duration
+	lease    time.Duration
+	job      Job
+	logger   *zap.Logger
+
+	once   sync.Once
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// New creates a scheduler for the job. The lease lasts three intervals so a
+// single slow tick does not hand the job to another replica.
+func New(db *sql.DB, name string, interval time.Duration, job Job, logger *zap.Logger) *Scheduler {
+	return &Scheduler{
+		db:       db,
+		name:     name,
+		holder:   holderID(),
+		interval: interval,
+		lease:    3 * interval,
+		job:      job,
+		logger:   logger,
+		done:     make(chan struct{}),
+	}
+}
+
+// Start runs the scheduler loop in the background until Stop is called
+func (s *Scheduler) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+
+	go func() {
+		defer close(s.done)
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+		for {
+			s.tick(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop cancels the running job, waits for the loop to exit and releases the
+// lease so another replica can take over at once. It gives up when ctx ends.
+func (s *Scheduler) Stop(ctx context.Context) error {
+	var err error
+	s.once.Do(func() {
+		if s.cancel == nil {
+			return
+		}
+		s.cancel()
+		select {
+		case <-s.done:
+		case <-ctx.Done():
+			err = fmt.Errorf("scheduler %s did not stop: %w", s.name, ctx.Err())
+			return
+		}
+		if _, releaseErr := s.db.ExecContext(ctx, "DELETE FROM scheduler_leases WHERE name = ? AND holder = ?", s.name, s.holder); releaseErr != nil {
+			err = fmt.Errorf("failed to release lease %s: %w", s.name, releaseErr)
+		}
+	})
+	return err
+}
+
+// tick runs the job when this replica holds the lease
+func (s *Scheduler) tick(ctx context.Context) {
+	held, err := s.acquire(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			s.logger.Error("Error acquiring scheduler lease", zap.String("scheduler", s.name), zap.Error(err))
+		}
+		return
+	}
+	if !held {
+		return
+	}
+	if err := s.job(ctx); err != nil && ctx.Err() == nil {
+		s.logger.Error("Error running scheduled job", zap.String("scheduler", s.name), zap.Error(err))
+	}
+}
+
+// acquire takes or renews the lease and reports whether this replica holds it
+func (s *Scheduler) acquire(ctx context.Context) (bool, error) {
+	// Assignments run left to right: expires_at is only extended once holder is ours
+	if _, err := s.db.ExecContext(ctx, `
+		INSERT INTO scheduler_leases (name, holder, expires_at)
+		VALUES (?, ?, UTC_TIMESTAMP() + INTERVAL ? SECOND)
+		ON DUPLICATE KEY UPDATE
+		    holder = IF(holder = VALUES(holder) OR expires_at < UTC_TIMESTAMP(), VALUES(holder), holder),
+		    expires_at = IF(holder = VALUES(holder), VALUES(expires_at), expires_at)`,
+		s.name, s.holder, int(s.lease.Seconds()),
+	); err != nil {
+		return false, err
+	}
+
+	var holder string
+	if err := s.db.QueryRowContext(ctx, "SELECT holder FROM scheduler_leases WHERE name = ?", s.name).Scan(&holder); err != nil {
+		return false, err
+	}
+	return holder == s.holder, nil
+}
+
+// holderID identifies this process among the replicas
+func holderID() string {
+	host, _ := os.Hostname()
+	suffix := make([]byte, 4)
+	rand.Read(suffix)
+	return fmt.Sprintf("%s-%d-%s", host, os.Getpid(), hex.EncodeToString(suffix))
+}
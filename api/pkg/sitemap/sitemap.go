@@ -0,0 +1,142 @@
+package sitemap
+
+import (
+	"encoding/xml"
+	"errors"
+	"time"
+)
+
+// Protocol limits for a single sitemap file
+const (
+	MaxURLs  = 50000
+	MaxBytes = 50 * 1024 * 1024
+)
+
+// ErrTooLarge is returned when a sitemap exceeds the protocol limits
+var ErrTooLarge = errors.New("sitemap exceeds 50,000 URLs or 50MB")
+
+// URL is one page of a url set
+type URL struct {
+	Loc          string
+	LastModified time.Time
+	Images       []Image
+	News         *News
+}
+
+// Image is an image that appears on a page
+type Image struct {
+	Loc     string
+	Caption string
+}
+
+// News holds the Google News fields of an article page
+type News struct {
+	PublicationName string
+	Language        string
+	PublicationDate time.Time
+	Title           string
+}
+
+// Sitemap is an entry of a sitemap index
+type Sitemap struct {
+	Loc          string
+	LastModified time.Time
+}
+
+type urlSet struct {
+	XMLName xml.Name `xml:"urlset"`
+	NS      string   `xml:"xmlns,attr"`
+	ImageNS string   `xml:"xmlns:image,attr,omitempty"`
+	NewsNS  string   `xml:"xmlns:news,attr,omitempty"`
+	URLs    []xmlURL `xml:"url"`
+}
+
+type xmlURL struct {
+	Loc     string     `xml:"loc"`
+	LastMod string     `xml:"lastmod,omitempty"`
+	News    *xmlNews   `xml:"news:news"`
+	Images  []xmlImage `xml:"image:image"`
+}
+
+type xmlImage struct {
+	Loc     string `xml:"image:loc"`
+	Caption string `xml:"image:caption,omitempty"`
+}
+
+type xmlNews struct {
+	Publication     xmlPublication `xml:"news:publication"`
+	PublicationDate string         `xml:"news:publication_date"`
+	Title           string         `xml:"news:title"`
+}
+
+type xmlPublication struct {
+	Name     string `xml:"news:name"`
+	Language string `xml:"news:language"`
+}
+
+type sitemapIndex struct {
+	XMLName  xml.Name     `xml:"sitemapindex"`
+	NS       string       `xml:"xmlns,attr"`
+	Sitemaps []xmlSitemap `xml:"sitemap"`
+}
+
+type xmlSitemap struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+// URLSet renders a url set. Image and news namespaces are declared only when used.
+func URLSet(urls []URL) ([]byte, error) {
+	if len(urls) > MaxURLs {
+		return nil, ErrTooLarge
+	}
+	set := urlSet{NS: "http://www.sitemaps.org/schemas/sitemap/0.9"}
+	for _, u := range urls {
+		entry := xmlURL{Loc: u.Loc, LastMod: formatTime(u.LastModified)}
+		for _, image := range u.Images {
+			set.ImageNS = "http://www.google.com/schemas/sitemap-image/1.1"
+			entry.Images = append(entry.Images, xmlImage{Loc: image.Loc, Caption: image.Caption})
+		}
+		if u.News != nil {
+			set.NewsNS = "http://www.google.com/schemas/sitemap-news/0.9"
+			entry.News = &xmlNews{
+				Publication:     xmlPublication{Name: u.News.PublicationName, Language: u.News.Language},
+				PublicationDate: formatTime(u.News.PublicationDate),
+				Title:           u.News.Title,
+			}
+		}
+		set.URLs = append(set.URLs, entry)
+	}
+	return encode(set)
+}
+
+// Index renders a sitemap index
+func Index(sitemaps []Sitemap) ([]byte, error) {
+	if len(sitemaps) > MaxURLs {
+		return nil, ErrTooLarge
+	}
+	index := sitemapIndex{NS: "http://www.sitemaps.org/schemas/sitemap/0.9"}
+	for _, s := range sitemaps {
+		index.Sitemaps = append(index.Sitemaps, xmlSitemap{Loc: s.Loc, LastMod: formatTime(s.LastModified)})
+	}
+	return encode(index)
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func encode(doc interface{}) ([]byte, error) {
+	body, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	body = append([]byte(xml.Header), body...)
+	if len(body) > MaxBytes {
+		return nil, ErrTooLarge
+	}
+	return body, nil
+}
@@ -0,0 +1,125 @@
+package sitemap
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestURLSet(t *testing.T) {
+	modified := time.Date(2024, 5, 1, 12, 0, 0, 0, time.FixedZone("BST", 6*3600))
+	tests := []struct {
+		name     string
+		urls     []URL
+		contains []string
+		excludes []string
+	}{
+		{
+			name:     "plain",
+			urls:     []URL{{Loc: "https://news.test/a", LastModified: modified}},
+			contains: []string{`<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">`, "<loc>https://news.test/a</loc>", "<lastmod>2024-05-01T06:00:00Z</lastmod>"},
+			excludes: []string{"xmlns:image", "xmlns:news", "<news:news>"},
+		},
+		{
+			name:     "no modification time",
+			urls:     []URL{{Loc: "https://news.test/a"}},
+			excludes: []string{"<lastmod>"},
+		},
+		{
+			name: "images",
+			urls: []URL{{Loc: "https://news.test/a", Images: []Image{{Loc: "https://news.test/a.jpg", Caption: "A & B"}}}},
+			contains: []string{`xmlns:image="http://www.google.com/schemas/sitemap-image/1.1"`,
+				"<image:loc>https://news.test/a.jpg</image:loc>", "<image:caption>A &amp; B</image:caption>"},
+			excludes: []string{"xmlns:news"},
+		},
+		{
+			name: "news",
+			urls: []URL{{Loc: "https://news.test/a", News: &News{PublicationName: "News", Language: "bn", PublicationDate: modified, Title: "Title"}}},
+			contains: []string{`xmlns:news="http://www.google.com/schemas/sitemap-news/0.9"`,
+				"<news:name>News</news:name>", "<news:language>bn</news:language>",
+				"<news:publication_date>2024-05-01T06:00:00Z</news:publication_date>", "<news:title>Title</news:title>"},
+			excludes: []string{"xmlns:image"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, err := URLSet(tt.urls)
+			if err != nil {
+				t.Fatalf("URLSet() error = %v", err)
+			}
+			document := string(body)
+			if !strings.HasPrefix(document, "<?xml") {
+				t.Errorf("URLSet() has no XML header")
+			}
+			for _, want := range tt.contains {
+				if !strings.Contains(document, want) {
+					t.Errorf("URLSet() misses %s in\n%s", want, document)
+				}
+			}
+			for _, unwanted := range tt.excludes {
+				if strings.Contains(document, unwanted) {
+					t.Errorf("URLSet() has %s in\n%s", unwanted, document)
+				}
+			}
+		})
+	}
+}
+
+func TestLimits(t *testing.T) {
+	urls := func(n, locLength int) []URL {
+		loc := "https://news.test/" + strings.Repeat("a", locLength)
+		list := make([]URL, n)
+		for i := range list {
+			list[i] = URL{Loc: loc}
+		}
+		return list
+	}
+	sitemaps := func(n int) []Sitemap {
+		return make([]Sitemap, n)
+	}
+	tests := []struct {
+		name    string
+		render  func() ([]byte, error)
+		wantErr bool
+	}{
+		{name: "url set at the URL limit", render: func() ([]byte, error) { return URLSet(urls(MaxURLs, 10)) }},
+		{name: "url set over the URL limit", render: func() ([]byte, error) { return URLSet(urls(MaxURLs+1, 10)) }, wantErr: true},
+		{name: "url set over the size limit", render: func() ([]byte, error) { return URLSet(urls(MaxURLs/2, MaxBytes/(MaxURLs/2))) }, wantErr: true},
+		{name: "index at the URL limit", render: func() ([]byte, error) { return Index(sitemaps(MaxURLs)) }},
+		{name: "index over the URL limit", render: func() ([]byte, error) { return Index(sitemaps(MaxURLs + 1)) }, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.render()
+			if tt.wantErr != errors.Is(err, ErrTooLarge) {
+				t.Errorf("error = %v, want ErrTooLarge %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("error = %v", err)
+			}
+		})
+	}
+}
+
+func TestIndex(t *testing.T) {
+	body, err := Index([]Sitemap{
+		{Loc: "https://news.test/sitemaps/news-0.xml", LastModified: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)},
+		{Loc: "https://news.test/sitemaps/categories.xml"},
+	})
+	if err != nil {
+		t.Fatalf("Index() error = %v", err)
+	}
+	want := `<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <sitemap>
+    <loc>https://news.test/sitemaps/news-0.xml</loc>
+    <lastmod>2024-05-01T00:00:00Z</lastmod>
+  </sitemap>
+  <sitemap>
+    <loc>https://news.test/sitemaps/categories.xml</loc>
+  </sitemap>
+</sitemapindex>`
+	if got := strings.TrimPrefix(string(body), `<?xml version="1.0" encoding="UTF-8"?>`+"\n"); got != want {
+		t.Errorf("Index() =\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,186 @@
+package tally
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// MemoryStore buffers changes in the process. Each replica flushes its own
+// changes and only sees its own pending ones.
+type MemoryStore struct {
+	mu      sync.Mutex
+	pending Counts
+}
+
+// NewMemoryStore creates an empty in-process store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{pending: Counts{}}
+}
+
+// Add implements Store
+func (s *MemoryStore) Add(_ context.Context, name string, id uint, n int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.add(name, id, n)
+	return nil
+}
+
+func (s *MemoryStore) add(name string, id uint, n int64) {
+	if s.pending[name] == nil {
+		s.pending[name] = map[uint]int64{}
+	}
+	s.pending[name][id] += n
+}
+
+// Pending implements Store
+func (s *MemoryStore) Pending(_ context.Context, name string, ids ...uint) (map[uint]int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	pending := make(map[uint]int64, len(ids))
+	for _, id := range ids {
+		if n := s.pending[name][id]; n != 0 {
+			pending[id] = n
+		}
+	}
+	return pending, nil
+}
+
+// Drain implements Store
+func (s *MemoryStore) Drain(_ context.Context) (Counts, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	counts := s.pending
+	s.pending = Counts{}
+	return counts, nil
+}
+
+// Restore implements Store
+func (s *MemoryStore) Restore(_ context.Context, counts Counts) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for name, items := range counts {
+		for id, n := range items {
+			s.add(name, id, n)
+		}
+	}
+	return nil
+}
+
+// drainScript reads and deletes a pending hash in one step, so there is no
+// window in which a failure leaves changes in neither the hash nor the batch
+var drainScript = redis.NewScript(`
+local values = redis.call('HGETALL', KEYS[1])
+redis.call('DEL', KEYS[1])
+return values
+`)
+
+// RedisStore buffers changes in Redis so every replica shares them and any
+// replica can flush
+type RedisStore struct {
+	client *redis.Client
+	prefix string
+}
+
+// NewRedisStore creates a store on the Redis client whose keys start with prefix
+func NewRedisStore(client *redis.Client, prefix string) *RedisStore {
+	return &RedisStore{client: client, prefix: prefix}
+}
+
+// namesKey is the Redis set of the counter names that were ever added to.
+// Names are few and fixed, they are never removed.
+func (s *RedisStore) namesKey() string {
+	return s.prefix + ":names"
+}
+
+func (s *RedisStore) pendingKey(name string) string {
+	return s.prefix + ":pending:" + name
+}
+
+// Add implements Store
+func (s *RedisStore) Add(ctx context.Context, name string, id uint, n int64) error {
+	pipe := s.client.TxPipeline()
+	pipe.SAdd(ctx, s.namesKey(), name)
+	pipe.HIncrBy(ctx, s.pendingKey(name), strconv.FormatUint(uint64(id), 10), n)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// Pending implements Store. Changes drained and not yet written are left out.
+func (s *RedisStore) Pending(ctx context.Context, name string, ids ...uint) (map[uint]int64, error) {
+	fields := make([]string, len(ids))
+	for i, id := range ids {
+		fields[i] = strconv.FormatUint(uint64(id), 10)
+	}
+	values, err := s.client.HMGet(ctx, s.pendingKey(name), fields...).Result()
+	if err != nil {
+		return nil, err
+	}
+	pending := make(map[uint]int64, len(ids))
+	for i, value := range values {
+		text, ok := value.(string)
+		if !ok {
+			continue
+		}
+		if n, err := strconv.ParseInt(text, 10, 64); err == nil && n != 0 {
+			pending[ids[i]] = n
+		}
+	}
+	return pending, nil
+}
+
+// Drain implements Store. Each pending hash is read and deleted atomically, so
+// changes added meanwhile go to a new hash and each replica drains a disjoint
+// batch.
+func (s *RedisStore) Drain(ctx context.Context) (Counts, error) {
+	names, err := s.client.SMembers(ctx, s.namesKey()).Result()
+	if err != nil {
+		return nil, err
+	}
+	counts := Counts{}
+	for _, name := range names {
+		result, err := drainScript.Run(ctx, s.client, []string{s.pendingKey(name)}).Result()
+		if err != nil {
+			// Hashes drained before this one are already gone from Redis
+			if restoreErr := s.Restore(context.Background(), counts); restoreErr != nil {
+				return nil, fmt.Errorf("%w, and failed to restore drained counts: %v", err, restoreErr)
+			}
+			return nil, err
+		}
+		values, _ := result.([]interface{})
+		items := map[uint]int64{}
+		for i := 0; i+1 < len(values); i += 2 {
+			field, _ := values[i].(string)
+			value, _ := values[i+1].(string)
+			id, idErr := strconv.ParseUint(field, 10, 64)
+			n, nErr := strconv.ParseInt(value, 10, 64)
+			if idErr == nil && nErr == nil && n != 0 {
+				items[uint(id)] = n
+			}
+		}
+		if len(items) > 0 {
+			counts[name] = items
+		}
+	}
+	return counts, nil
+}
+
+// Restore implements Store. The changes are added in one transaction so a
+// failure restores none of them rather than part.
+func (s *RedisStore) Restore(ctx context.Context, counts Counts) error {
+	if len(counts) == 0 {
+		return nil
+	}
+	pipe := s.client.TxPipeline()
+	for name, items := range counts {
+		pipe.SAdd(ctx, s.namesKey(), name)
+		for id, n := range items {
+			pipe.HIncrBy(ctx, s.pendingKey(name), strconv.FormatUint(uint64(id), 10), n)
+		}
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
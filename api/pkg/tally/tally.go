@@ -0,0 +1,132 @@
+// Package tally buffers changes to aggregate counts, such as article views,
+// poll votes and reactions, and writes them to the database in batches so
+// popular items do not serialize their writers on one row. Pending changes are
+// readable, so live totals are the stored count plus what is pending.
+package tally
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"go.uber.org/zap"
+)
+
+// Counts holds pending changes per counter name and item ID
+type Counts map[string]map[uint]int64
+
+// FlushFunc writes a batch of drained changes to permanent storage
+type FlushFunc func(ctx context.Context, counts Counts) error
+
+// Store buffers changes between flushes
+type Store interface {
+	// Add adds n, which may be negative, to a counter of the item
+	Add(ctx context.Context, name string, id uint, n int64) error
+	// Pending returns the changes of a counter not flushed yet, by item ID
+	Pending(ctx context.Context, name string, ids ...uint) (map[uint]int64, error)
+	// Drain returns and clears the pending changes. When it fails nothing is
+	// cleared: changes it had already taken are put back.
+	Drain(ctx context.Context) (Counts, error)
+	// Restore puts drained changes back, adding them to those pending since
+	Restore(ctx context.Context, counts Counts) error
+}
+
+// Counter buffers changes and flushes them periodically
+type Counter struct {
+	store Store
+	name  string
+
+	once   sync.Once
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// New creates a counter on Redis when client is not nil, so every replica
+// shares the pending changes, and in the process otherwise. Counters with
+// different prefixes keep separate Redis keys; the prefix also names the
+// counter in logs.
+func New(client *redis.Client, prefix string) *Counter {
+	var store Store = NewMemoryStore()
+	if client != nil {
+		store = NewRedisStore(client, prefix)
+	}
+	return &Counter{store: store, name: prefix, done: make(chan struct{})}
+}
+
+// Add adds n to a counter of the item
+func (c *Counter) Add(ctx context.Context, name string, id uint, n int64) error {
+	if n == 0 {
+		return nil
+	}
+	return c.store.Add(ctx, name, id, n)
+}
+
+// Pending returns the changes of a counter not flushed yet, by item ID
+func (c *Counter) Pending(ctx context.Context, name string, ids ...uint) (map[uint]int64, error) {
+	if len(ids) == 0 {
+		return map[uint]int64{}, nil
+	}
+	return c.store.Pending(ctx, name, ids...)
+}
+
+// Flush drains the pending changes and passes them to fn. When fn fails the
+// changes are put back so the next flush retries them.
+func (c *Counter) Flush(ctx context.Context, fn FlushFunc) error {
+	counts, err := c.store.Drain(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to drain %s counts: %w", c.name, err)
+	}
+	if len(counts) == 0 {
+		return nil
+	}
+	if err := fn(ctx, counts); err != nil {
+		// Use a fresh context, ctx may be what failed
+		if restoreErr := c.store.Restore(context.Background(), counts); restoreErr != nil {
+			return fmt.Errorf("failed to flush %s counts: %w, and to restore them: %v", c.name, err, restoreErr)
+		}
+		return fmt.Errorf("failed to flush %s counts: %w", c.name, err)
+	}
+	return nil
+}
+
+// Start flushes the pending changes every interval until Stop is called
+func (c *Counter) Start(interval time.Duration, fn FlushFunc, logger *zap.Logger) {
+	ctx, cancel := context.WithCancel(context.Background())
+	c.cancel = cancel
+	go func() {
+		defer close(c.done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := c.Flush(ctx, fn); err != nil && ctx.Err() == nil {
+					logger.Error("Error flushing counts", zap.String("counter", c.name), zap.Error(err))
+				}
+			}
+		}
+	}()
+}
+
+// Stop ends the flush loop and writes the remaining changes, giving up when ctx ends
+func (c *Counter) Stop(ctx context.Context, fn FlushFunc) error {
+	var err error
+	c.once.Do(func() {
+		if c.cancel == nil {
+			return
+		}
+		c.cancel()
+		select {
+		case <-c.done:
+		case <-ctx.Done():
+			err = fmt.Errorf("%s flusher did not stop: %w", c.name, ctx.Err())
+			return
+		}
+		err = c.Flush(ctx, fn)
+	})
+	return err
+}
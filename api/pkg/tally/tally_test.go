@@ -0,0 +1,73 @@
+package tally
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestFlush(t *testing.T) {
+	failed := errors.New("database down")
+	tests := []struct {
+		name        string
+		adds        []Counts
+		flushErr    error
+		wantFlushed Counts
+		wantPending map[uint]int64
+	}{
+		{
+			name:        "writes and clears",
+			adds:        []Counts{{"votes": {1: 2, 2: 1}}},
+			wantFlushed: Counts{"votes": {1: 2, 2: 1}},
+			wantPending: map[uint]int64{},
+		},
+		{
+			name:        "failed write is put back",
+			adds:        []Counts{{"votes": {1: 2, 2: 1}}},
+			flushErr:    failed,
+			wantFlushed: Counts{"votes": {1: 2, 2: 1}},
+			wantPending: map[uint]int64{1: 2, 2: 1},
+		},
+		{
+			name:        "negative changes",
+			adds:        []Counts{{"votes": {1: 3}}, {"votes": {1: -1}}},
+			flushErr:    failed,
+			wantFlushed: Counts{"votes": {1: 2}},
+			wantPending: map[uint]int64{1: 2},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			counter := New(nil, "test")
+			for _, counts := range tt.adds {
+				for name, items := range counts {
+					for id, n := range items {
+						if err := counter.Add(ctx, name, id, n); err != nil {
+							t.Fatal(err)
+						}
+					}
+				}
+			}
+			var flushed Counts
+			err := counter.Flush(ctx, func(_ context.Context, counts Counts) error {
+				flushed = counts
+				return tt.flushErr
+			})
+			if !errors.Is(err, tt.flushErr) {
+				t.Fatalf("Flush() error = %v, want %v", err, tt.flushErr)
+			}
+			if !reflect.DeepEqual(flushed, tt.wantFlushed) {
+				t.Errorf("flushed %v, want %v", flushed, tt.wantFlushed)
+			}
+			pending, err := counter.Pending(ctx, "votes", 1, 2)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(pending, tt.wantPending) {
+				t.Errorf("pending %v, want %v", pending, tt.wantPending)
+			}
+		})
+	}
+}
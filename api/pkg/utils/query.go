@@ -9,18 +9,20 @@ import (
 	"github.com/JubaerHossain/rootx/pkg/core/entity"
 )
 
-func Paginate(req *http.Request, app *app.App, baseQuery, filterQuery string) (entity.Pagination, int, int, error) {
+// Paginate counts the filtered rows and derives limit/offset from the page and
+// limit query parameters. Optional args are bound to placeholders in filterQuery.
+func Paginate(req *http.Request, app *app.App, baseQuery, filterQuery string, args ...interface{}) (entity.Pagination, int, int, error) {
 	ctx := req.Context()
 
 	// Count total items with filters applied
 	var totalItems int
 	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM (%s%s) AS filtered", baseQuery, filterQuery)
 	if app.Config.DBType == "mysql" {
-		if err := app.MDB.QueryRowContext(ctx, countQuery).Scan(&totalItems); err != nil {
+		if err := app.MDB.QueryRowContext(ctx, countQuery, args...).Scan(&totalItems); err != nil {
 			return entity.Pagination{}, 0, 0, err
 		}
 	} else {
-		if err := app.DB.QueryRow(ctx, countQuery).Scan(&totalItems); err != nil {
+		if err := app.DB.QueryRow(ctx, countQuery, args...).Scan(&totalItems); err != nil {
 			return entity.Pagination{}, 0, 0, err
 		}
 	}
@@ -0,0 +1,95 @@
+package utils
+
+import (
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/JubaerHossain/cn-api/pkg/config"
+)
+
+// ClientIP returns the address of the client. The peer address is used unless
+// the peer is one of TRUSTED_PROXIES; only then are X-Forwarded-For and
+// X-Real-IP read, taking the right-most hop that is not a trusted proxy.
+// Clients set these headers themselves, so they are not believed otherwise.
+func ClientIP(r *http.Request) string {
+	peer, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		peer = r.RemoteAddr
+	}
+	proxies := trustedProxies(config.GlobalConfig.TrustedProxies)
+	if !proxies.contains(peer) {
+		return peer
+	}
+	var hops []string
+	for _, header := range r.Header.Values("X-Forwarded-For") {
+		hops = append(hops, strings.Split(header, ",")...)
+	}
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(hops[i])
+		if net.ParseIP(hop) == nil {
+			// A hop that is not an address cannot be checked, so the chain stops here
+			break
+		}
+		if !proxies.contains(hop) {
+			return hop
+		}
+	}
+	if realIP := strings.TrimSpace(r.Header.Get("X-Real-IP")); net.ParseIP(realIP) != nil && !proxies.contains(realIP) {
+		return realIP
+	}
+	return peer
+}
+
+// proxyList is a parsed TRUSTED_PROXIES setting
+type proxyList []*net.IPNet
+
+var (
+	proxyMutex  sync.Mutex
+	proxySource string
+	proxyParsed proxyList
+)
+
+// trustedProxies parses a comma separated list of addresses and CIDR ranges,
+// keeping the result until the setting changes
+func trustedProxies(setting string) proxyList {
+	proxyMutex.Lock()
+	defer proxyMutex.Unlock()
+	if setting == proxySource && proxyParsed != nil {
+		return proxyParsed
+	}
+	parsed := proxyList{}
+	for _, entry := range strings.Split(setting, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if !strings.Contains(entry, "/") {
+			if ip := net.ParseIP(entry); ip != nil && ip.To4() != nil {
+				entry += "/32"
+			} else {
+				entry += "/128"
+			}
+		}
+		if _, network, err := net.ParseCIDR(entry); err == nil {
+			parsed = append(parsed, network)
+		}
+	}
+	proxySource, proxyParsed = setting, parsed
+	return parsed
+}
+
+// contains reports whether address is in one of the trusted ranges
+func (l proxyList) contains(address string) bool {
+	ip := net.ParseIP(address)
+	if ip == nil {
+		return false
+	}
+	for _, network := range l {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,46 @@
+package utils
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/JubaerHossain/cn-api/pkg/config"
+)
+
+func TestClientIP(t *testing.T) {
+	tests := []struct {
+		name      string
+		trusted   string
+		remote    string
+		forwarded string
+		realIP    string
+		want      string
+	}{
+		{name: "no proxy", remote: "203.0.113.7:5000", want: "203.0.113.7"},
+		{name: "untrusted peer ignores forwarded", remote: "203.0.113.7:5000", forwarded: "198.51.100.1", want: "203.0.113.7"},
+		{name: "untrusted peer ignores real ip", remote: "203.0.113.7:5000", realIP: "198.51.100.1", want: "203.0.113.7"},
+		{name: "trusted peer", trusted: "10.0.0.0/8", remote: "10.0.0.2:5000", forwarded: "198.51.100.1", want: "198.51.100.1"},
+		{name: "spoofed first hop", trusted: "10.0.0.0/8", remote: "10.0.0.2:5000", forwarded: "1.1.1.1, 198.51.100.1", want: "198.51.100.1"},
+		{name: "chain of proxies", trusted: "10.0.0.0/8,192.0.2.5", remote: "10.0.0.2:5000", forwarded: "198.51.100.1, 192.0.2.5, 10.0.0.9", want: "198.51.100.1"},
+		{name: "garbage hop", trusted: "10.0.0.2", remote: "10.0.0.2:5000", forwarded: "not-an-ip", want: "10.0.0.2"},
+		{name: "trusted peer real ip", trusted: "10.0.0.2", remote: "10.0.0.2:5000", realIP: "198.51.100.1", want: "198.51.100.1"},
+		{name: "ipv6 peer", trusted: "::1", remote: "[::1]:5000", forwarded: "2001:db8::1", want: "2001:db8::1"},
+	}
+	defer func(trusted string) { config.GlobalConfig.TrustedProxies = trusted }(config.GlobalConfig.TrustedProxies)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config.GlobalConfig.TrustedProxies = tt.trusted
+			r := httptest.NewRequest("GET", "/", nil)
+			r.RemoteAddr = tt.remote
+			if tt.forwarded != "" {
+				r.Header.Set("X-Forwarded-For", tt.forwarded)
+			}
+			if tt.realIP != "" {
+				r.Header.Set("X-Real-IP", tt.realIP)
+			}
+			if got := ClientIP(r); got != tt.want {
+				t.Errorf("ClientIP() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,171 @@
+package utils
+
+import (
+	"html"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+var (
+	tagPattern   = regexp.MustCompile(`(?s)<(script|style)[^>]*>.*?</(script|style)>|<[^>]*>`)
+	spacePattern = regexp.MustCompile(`\s+`)
+	breakPattern = regexp.MustCompile(`(?i)<br\s*/?>|</(p|div|li|blockquote|h[1-6])\s*>`)
+	linePattern  = regexp.MustCompile(`[^\S\n]+`)
+	blankPattern = regexp.MustCompile(`\n{3,}`)
+	openPattern  = regexp.MustCompile(`<([A-Za-z/!?])`)
+)
+
+// StripHTML removes markup from an HTML fragment and returns plain text with
+// entities decoded and whitespace collapsed. Entities are decoded before tags
+// are removed, so encoded markup cannot come out as a tag.
+func StripHTML(s string) string {
+	s = stripTags(decodeEntities(s))
+	return strings.TrimSpace(spacePattern.ReplaceAllString(s, " "))
+}
+
+// PlainText is StripHTML for text whose line breaks matter, such as comments.
+// Line breaks and the ends of blocks become new lines, control and bidi
+// characters are dropped and at most one blank line is kept between paragraphs.
+func PlainText(s string) string {
+	s = decodeEntities(strings.ReplaceAll(s, "\r\n", "\n"))
+	s = breakPattern.ReplaceAllString(s, "\n")
+	s = stripTags(s)
+	s = strings.Map(func(r rune) rune {
+		if r != '\n' && (unicode.IsControl(r) || unicode.Is(unicode.Bidi_Control, r)) {
+			return ' '
+		}
+		return r
+	}, s)
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(linePattern.ReplaceAllString(line, " "))
+	}
+	return strings.TrimSpace(blankPattern.ReplaceAllString(strings.Join(lines, "\n"), "\n\n"))
+}
+
+// decodeEntities unescapes HTML entities, several times for text encoded more
+// than once
+func decodeEntities(s string) string {
+	for i := 0; i < 3; i++ {
+		decoded := html.UnescapeString(s)
+		if decoded == s {
+			break
+		}
+		s = decoded
+	}
+	return s
+}
+
+// stripTags removes tags, and breaks up what is left of a tag opening, such as
+// an unclosed <img, so the text cannot start a tag where it is inserted
+func stripTags(s string) string {
+	s = tagPattern.ReplaceAllString(s, " ")
+	return openPattern.ReplaceAllString(s, "< $1")
+}
+
+// Truncate shortens plain text to at most max runes, cutting at a word
+// boundary when possible and appending an ellipsis.
+func Truncate(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	cut := max
+	for i := max; i > max/2; i-- {
+		if unicode.IsSpace(runes[i]) {
+			cut = i
+			break
+		}
+	}
+	return strings.TrimSpace(string(runes[:cut])) + "…"
+}
+
+// SearchTerms splits a search query into lower cased words, dropping search
+// operators and duplicates.
+func SearchTerms(query string) []string {
+	seen := map[string]bool{}
+	var terms []string
+	for _, field := range strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !(unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsMark(r))
+	}) {
+		if !seen[field] {
+			seen[field] = true
+			terms = append(terms, field)
+		}
+	}
+	return terms
+}
+
+// Highlight returns an HTML escaped snippet of about width runes taken around
+// the first matching term, with every term occurrence wrapped in <mark>.
+func Highlight(text string, terms []string, width int) string {
+	runes := []rune(text)
+	lower := []rune(strings.ToLower(text))
+	if len(lower) != len(runes) {
+		// Lower casing changed the length, fall back to matching the original text
+		lower = runes
+	}
+
+	// Centre the window on the first match
+	start := 0
+	if pos := indexAny(lower, terms, 0); pos >= 0 {
+		start = pos - width/3
+	}
+	if start < 0 {
+		start = 0
+	}
+	end := start + width
+	if end > len(runes) {
+		end = len(runes)
+		if start = end - width; start < 0 {
+			start = 0
+		}
+	}
+
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString("…")
+	}
+	for i := start; i < end; {
+		term := matchAt(lower, terms, i)
+		if term == 0 || i+term > end {
+			b.WriteString(html.EscapeString(string(runes[i])))
+			i++
+			continue
+		}
+		b.WriteString("<mark>")
+		b.WriteString(html.EscapeString(string(runes[i : i+term])))
+		b.WriteString("</mark>")
+		i += term
+	}
+	if end < len(runes) {
+		b.WriteString("…")
+	}
+	return b.String()
+}
+
+// indexAny returns the first rune index at or after from where any term starts
+func indexAny(text []rune, terms []string, from int) int {
+	for i := from; i < len(text); i++ {
+		if matchAt(text, terms, i) > 0 {
+			return i
+		}
+	}
+	return -1
+}
+
+// matchAt returns the rune length of the longest term starting at i, or 0
+func matchAt(text []rune, terms []string, i int) int {
+	longest := 0
+	for _, term := range terms {
+		t := []rune(term)
+		if len(t) <= longest || i+len(t) > len(text) {
+			continue
+		}
+		if string(text[i:i+len(t)]) == term {
+			longest = len(t)
+		}
+	}
+	return longest
+}
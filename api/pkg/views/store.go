@@ -0,0 +1,57 @@
+package views
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// MemoryStore remembers views in the process. Deduplication only covers
+// clients that hit the same replica.
+type MemoryStore struct {
+	mu    sync.Mutex
+	seen  map[string]time.Time
+	sweep time.Time
+}
+
+// NewMemoryStore creates an empty in-process store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{seen: map[string]time.Time{}}
+}
+
+// FirstSeen implements SeenStore. Expired entries are dropped once per window.
+func (s *MemoryStore) FirstSeen(_ context.Context, key string, window time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	if !now.Before(s.sweep) {
+		for seenKey, expires := range s.seen {
+			if !now.Before(expires) {
+				delete(s.seen, seenKey)
+			}
+		}
+		s.sweep = now.Add(window)
+	}
+	if expires, ok := s.seen[key]; ok && now.Before(expires) {
+		return false, nil
+	}
+	s.seen[key] = now.Add(window)
+	return true, nil
+}
+
+// RedisStore remembers views in Redis so every replica shares deduplication
+type RedisStore struct {
+	client *redis.Client
+}
+
+// NewRedisStore creates a store on the Redis client
+func NewRedisStore(client *redis.Client) *RedisStore {
+	return &RedisStore{client: client}
+}
+
+// FirstSeen implements SeenStore
+func (s *RedisStore) FirstSeen(ctx context.Context, key string, window time.Duration) (bool, error) {
+	return s.client.SetNX(ctx, "views:seen:"+key, 1, window).Result()
+}
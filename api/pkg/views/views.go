@@ -0,0 +1,97 @@
+// Package views counts deduplicated human views of articles and categories.
+// The counts are buffered and flushed by a tally counter.
+package views
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/JubaerHossain/cn-api/pkg/tally"
+	"github.com/JubaerHossain/cn-api/pkg/utils"
+	"github.com/go-redis/redis/v8"
+	"go.uber.org/zap"
+)
+
+// botPattern matches user agents of crawlers, link previews and scripts
+var botPattern = regexp.MustCompile(`(?i)bot|crawl|spider|slurp|archiver|preview|facebookexternalhit|embedly|curl|wget|python|java/|go-http-client|okhttp|headless|phantomjs|lighthouse|pingdom|monitor|uptime`)
+
+// Kinds of items whose views are counted
+const (
+	KindNews     = "news"
+	KindCategory = "category"
+)
+
+// Counts holds pending views per kind and ID
+type Counts = tally.Counts
+
+// FlushFunc writes a batch of drained counts to permanent storage
+type FlushFunc = tally.FlushFunc
+
+// SeenStore remembers which clients viewed which items
+type SeenStore interface {
+	// FirstSeen records that the client viewed the item and reports whether
+	// this is the first view within window
+	FirstSeen(ctx context.Context, key string, window time.Duration) (bool, error)
+}
+
+// Counter counts deduplicated human views and flushes them periodically
+type Counter struct {
+	seen   SeenStore
+	counts *tally.Counter
+	window time.Duration
+}
+
+// New creates a counter that counts a client once per item within window. With
+// a Redis client every replica shares deduplication and pending views,
+// otherwise each replica keeps its own.
+func New(client *redis.Client, window time.Duration) *Counter {
+	var seen SeenStore = NewMemoryStore()
+	if client != nil {
+		seen = NewRedisStore(client)
+	}
+	return &Counter{seen: seen, counts: tally.New(client, "views"), window: window}
+}
+
+// IsBot reports whether the request comes from a crawler or script
+func IsBot(r *http.Request) bool {
+	userAgent := r.UserAgent()
+	return userAgent == "" || botPattern.MatchString(userAgent)
+}
+
+// Hit counts a view of the item unless the client is a bot or already viewed it
+// within the window. It reports whether the view was counted.
+func (c *Counter) Hit(r *http.Request, kind string, id uint) (bool, error) {
+	if IsBot(r) {
+		return false, nil
+	}
+	sum := sha1.Sum([]byte(utils.ClientIP(r) + "|" + r.UserAgent()))
+	key := fmt.Sprintf("%s:%d:%s", kind, id, hex.EncodeToString(sum[:]))
+	first, err := c.seen.FirstSeen(r.Context(), key, c.window)
+	if err != nil || !first {
+		return false, err
+	}
+	if err := c.counts.Add(r.Context(), kind, id, 1); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Flush drains the pending views and passes them to fn, see tally.Counter.Flush
+func (c *Counter) Flush(ctx context.Context, fn FlushFunc) error {
+	return c.counts.Flush(ctx, fn)
+}
+
+// Start flushes the pending views every interval until Stop is called
+func (c *Counter) Start(interval time.Duration, fn FlushFunc, logger *zap.Logger) {
+	c.counts.Start(interval, fn, logger)
+}
+
+// Stop ends the flush loop and writes the remaining views, giving up when ctx ends
+func (c *Counter) Stop(ctx context.Context, fn FlushFunc) error {
+	return c.counts.Stop(ctx, fn)
+}
@@ -0,0 +1,289 @@
+// Package wxr reads WordPress eXtended RSS exports, the file written by
+// Tools > Export. Elements are matched by local name so exports of every WXR
+// version (1.0 to 1.2) read the same.
+package wxr
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// contentSpace is the namespace of content:encoded, the post body
+const contentSpace = "http://purl.org/rss/1.0/modules/content/"
+
+// ErrNotWXR is returned for files without a WordPress export channel
+var ErrNotWXR = errors.New("not a WordPress export")
+
+var (
+	// blockStart matches content that opens with a block element, it needs no paragraph
+	blockStart = regexp.MustCompile(`(?i)^<(p|div|h[1-6]|ul|ol|li|blockquote|pre|table|figure|iframe|hr|dl|section|address)[\s>/]`)
+	// shortcode matches the WordPress shortcode tags, their content is kept
+	shortcode = regexp.MustCompile(`\[/?[a-z_][a-z0-9_-]*(\s[^\]]*)?\]`)
+	// paragraphBreak separates paragraphs in classic editor content
+	paragraphBreak = regexp.MustCompile(`\n\s*\n`)
+)
+
+// Export is the content of a WordPress export
+type Export struct {
+	Title       string
+	Link        string
+	Language    string // e.g. en-US
+	Authors     []Author
+	Categories  []Category // Parents before their children when the export is complete
+	Tags        []Tag
+	Posts       []Item // Posts and pages
+	Attachments []Item
+}
+
+// Author is a wp:author
+type Author struct {
+	Login       string `xml:"author_login"`
+	Email       string `xml:"author_email"`
+	DisplayName string `xml:"author_display_name"`
+	FirstName   string `xml:"author_first_name"`
+	LastName    string `xml:"author_last_name"`
+}
+
+// Name returns the display name, or the full name, or the login of the author
+func (a Author) Name() string {
+	if name := strings.TrimSpace(a.DisplayName); name != "" {
+		return name
+	}
+	if name := strings.TrimSpace(a.FirstName + " " + a.LastName); name != "" {
+		return name
+	}
+	return a.Login
+}
+
+// Category is a wp:category
+type Category struct {
+	TermID   uint   `xml:"term_id"`
+	Nicename string `xml:"category_nicename"`
+	Parent   string `xml:"category_parent"` // Nicename of the parent
+	Name     string `xml:"cat_name"`
+}
+
+// Tag is a wp:tag
+type Tag struct {
+	TermID uint   `xml:"term_id"`
+	Slug   string `xml:"tag_slug"`
+	Name   string `xml:"tag_name"`
+}
+
+// Term is a category or tag of an item
+type Term struct {
+	Domain   string `xml:"domain,attr"` // category or post_tag
+	Nicename string `xml:"nicename,attr"`
+	Name     string `xml:",chardata"`
+}
+
+// Meta is a wp:postmeta entry
+type Meta struct {
+	Key   string `xml:"meta_key"`
+	Value string `xml:"meta_value"`
+}
+
+// encoded is a content:encoded or excerpt:encoded element
+type encoded struct {
+	XMLName xml.Name
+	Value   string `xml:",chardata"`
+}
+
+// Item is a post, page or attachment
+type Item struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	GUID          string    `xml:"guid"`
+	Creator       string    `xml:"creator"` // Login of the author
+	Encoded       []encoded `xml:"encoded"`
+	PostID        uint      `xml:"post_id"`
+	PostDateGMT   string    `xml:"post_date_gmt"`
+	ModifiedGMT   string    `xml:"post_modified_gmt"`
+	PostName      string    `xml:"post_name"` // Slug, percent-encoded
+	Status        string    `xml:"status"`    // publish, future, draft, pending, private, trash
+	PostParent    uint      `xml:"post_parent"`
+	PostType      string    `xml:"post_type"`
+	AttachmentURL string    `xml:"attachment_url"`
+	Terms         []Term    `xml:"category"`
+	Meta          []Meta    `xml:"postmeta"`
+}
+
+// Content returns the post body as HTML. Classic editor paragraphs become
+// <p> elements, shortcode tags are dropped.
+func (i Item) Content() string {
+	for _, e := range i.Encoded {
+		if e.XMLName.Space == contentSpace {
+			return autop(shortcode.ReplaceAllString(e.Value, ""))
+		}
+	}
+	return ""
+}
+
+// Excerpt returns the hand-written excerpt of the post
+func (i Item) Excerpt() string {
+	for _, e := range i.Encoded {
+		if e.XMLName.Space != contentSpace {
+			return strings.TrimSpace(e.Value)
+		}
+	}
+	return ""
+}
+
+// Key returns what identifies the item across imports, its GUID or else its link
+func (i Item) Key() string {
+	if guid := strings.TrimSpace(i.GUID); guid != "" {
+		return guid
+	}
+	return strings.TrimSpace(i.Link)
+}
+
+// Slug returns the decoded post name
+func (i Item) Slug() string {
+	if slug, err := url.PathUnescape(i.PostName); err == nil {
+		return slug
+	}
+	return i.PostName
+}
+
+// Published returns the publication time, zero for posts never published
+func (i Item) Published() time.Time {
+	return parseTime(i.PostDateGMT)
+}
+
+// Modified returns the last modification time, zero when unknown
+func (i Item) Modified() time.Time {
+	return parseTime(i.ModifiedGMT)
+}
+
+// MetaValue returns the value of a post meta key
+func (i Item) MetaValue(key string) string {
+	for _, m := range i.Meta {
+		if m.Key == key {
+			return m.Value
+		}
+	}
+	return ""
+}
+
+// TermsOf returns the terms of the item in a domain, category or post_tag
+func (i Item) TermsOf(domain string) []Term {
+	var terms []Term
+	for _, t := range i.Terms {
+		if t.Domain == domain {
+			terms = append(terms, t)
+		}
+	}
+	return terms
+}
+
+// Parse reads an export
+func Parse(r io.Reader) (*Export, error) {
+	var (
+		export  Export
+		decoder = xml.NewDecoder(r)
+		channel bool
+	)
+	decoder.Strict = false // Exports of broken plugins hold stray entities
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read export: %w", err)
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		switch start.Name.Local {
+		case "channel":
+			channel = true
+		case "item":
+			var item Item
+			if err := decoder.DecodeElement(&item, &start); err != nil {
+				return nil, fmt.Errorf("failed to read item: %w", err)
+			}
+			if item.PostType == "attachment" {
+				export.Attachments = append(export.Attachments, item)
+			} else {
+				export.Posts = append(export.Posts, item)
+			}
+		case "author":
+			var author Author
+			if err := decoder.DecodeElement(&author, &start); err != nil {
+				return nil, fmt.Errorf("failed to read author: %w", err)
+			}
+			export.Authors = append(export.Authors, author)
+		case "category":
+			var category Category
+			if err := decoder.DecodeElement(&category, &start); err != nil {
+				return nil, fmt.Errorf("failed to read category: %w", err)
+			}
+			export.Categories = append(export.Categories, category)
+		case "tag":
+			var tag Tag
+			if err := decoder.DecodeElement(&tag, &start); err != nil {
+				return nil, fmt.Errorf("failed to read tag: %w", err)
+			}
+			export.Tags = append(export.Tags, tag)
+		case "title", "link", "language":
+			// Items are decoded whole above, the first ones are the channel's own
+			var value string
+			if err := decoder.DecodeElement(&value, &start); err != nil {
+				return nil, fmt.Errorf("failed to read channel: %w", err)
+			}
+			switch {
+			case start.Name.Local == "title" && export.Title == "":
+				export.Title = value
+			case start.Name.Local == "link" && export.Link == "":
+				export.Link = value
+			case start.Name.Local == "language" && export.Language == "":
+				export.Language = value
+			}
+		}
+	}
+	if !channel {
+		return nil, ErrNotWXR
+	}
+	return &export, nil
+}
+
+// parseTime reads a WordPress GMT date, drafts carry 0000-00-00 00:00:00
+func parseTime(value string) time.Time {
+	t, err := time.Parse(time.DateTime, strings.TrimSpace(value))
+	if err != nil || t.Year() < 1970 {
+		return time.Time{}
+	}
+	return t
+}
+
+// autop wraps the paragraphs of classic editor content, which WordPress only
+// adds when rendering. Block editor content already has its markup.
+func autop(content string) string {
+	content = strings.ReplaceAll(strings.TrimSpace(content), "\r\n", "\n")
+	if content == "" || strings.Contains(content, "<!-- wp:") || strings.Contains(strings.ToLower(content), "<p") {
+		return content
+	}
+	var b strings.Builder
+	for _, paragraph := range paragraphBreak.Split(content, -1) {
+		paragraph = strings.TrimSpace(paragraph)
+		switch {
+		case paragraph == "":
+			continue
+		case blockStart.MatchString(paragraph):
+			b.WriteString(paragraph)
+		default:
+			b.WriteString("<p>" + strings.ReplaceAll(paragraph, "\n", "<br>\n") + "</p>")
+		}
+		b.WriteString("\n")
+	}
+	return strings.TrimSpace(b.String())
+}